@@ -15,6 +15,7 @@ import {
   ShoppingCart as CartIcon
 } from '@mui/icons-material';
 import type { Product } from '../types';
+import { formatMoneyMinor } from '../utils/money';
 
 interface ProductCardProps {
   product: Product;
@@ -29,8 +30,7 @@ const ProductCard: React.FC<ProductCardProps> = ({
 
   const formatMoney = (m?: { amount: number; currency: string }) => {
     if (!m) return '';
-    const major = (m.amount / 100).toFixed(2);
-    return `${major} ${m.currency}`;
+    return formatMoneyMinor(m.amount, m.currency);
   };
 
   const handleAddToCart = () => {
@@ -6,6 +6,7 @@ type Props = { status: string };
 const StatusChip: React.FC<Props> = ({ status }) => {
   const map: Record<string, { label: string; color: 'default' | 'success' | 'warning' | 'info' | 'error' }> = {
     PENDING: { label: 'Pending', color: 'warning' },
+    PAYMENT_REVIEW: { label: 'Payment review', color: 'warning' },
     CONFIRMED: { label: 'Confirmed', color: 'info' },
     PROCESSING: { label: 'Processing', color: 'info' },
     SHIPPED: { label: 'Shipped', color: 'success' },
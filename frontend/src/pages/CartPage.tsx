@@ -1,7 +1,7 @@
 import React, { useState, useEffect } from 'react';
 import { useNavigate } from 'react-router-dom';
-import { ordersAPI } from '../services/api';
-import type { CartItem, PaymentDetails, CreateOrderRequest } from '../types';
+import { ordersAPI, paymentMethodsAPI } from '../services/api';
+import type { Address, CartItem, PaymentDetails, CreateOrderRequest, SavedPaymentMethod, ShippingQuote } from '../types';
 import { isAxiosError } from 'axios';
 import { formatMoneyMinor, sumMinorWithSameCurrency } from '../utils/money';
 import Container from '@mui/material/Container';
@@ -14,12 +14,12 @@ import Button from '@mui/material/Button';
 import Alert from '@mui/material/Alert';
 import Stack from '@mui/material/Stack';
 import Snackbar from '@mui/material/Snackbar';
+import MenuItem from '@mui/material/MenuItem';
 import { useForm } from 'react-hook-form';
 import { z } from 'zod';
 import { zodResolver } from '@hookform/resolvers/zod';
 
 type OrderForm = {
-  shipping_address: string;
   payment_method: string;
   payment_details: PaymentDetails;
 };
@@ -37,7 +37,6 @@ const CartPage: React.FC<CartPageProps> = ({ isAuthenticated }) => {
   const [loading, setLoading] = useState<boolean>(false);
   const [error, setError] = useState<string>('');
   const [orderForm, setOrderForm] = useState<OrderForm>({
-    shipping_address: '',
     payment_method: 'credit_card', 
     payment_details: {
       card_number: '',
@@ -48,6 +47,8 @@ const CartPage: React.FC<CartPageProps> = ({ isAuthenticated }) => {
     },
   });
   const [snackbarOpen, setSnackbarOpen] = useState(false);
+  const [quotes, setQuotes] = useState<ShippingQuote[]>([]);
+  const [shippingMethod, setShippingMethod] = useState<string>('STANDARD');
 
   const navigate = useNavigate();
 
@@ -101,7 +102,13 @@ const CartPage: React.FC<CartPageProps> = ({ isAuthenticated }) => {
   };
 
   const schema = z.object({
-    shipping_address: z.string().min(5, 'Enter full address'),
+    recipient: z.string().min(2, 'Enter recipient name'),
+    line1: z.string().min(3, 'Enter street address'),
+    line2: z.string().optional(),
+    city: z.string().min(1, 'Enter city'),
+    region: z.string().max(6, 'Enter state or province code').optional(),
+    postal_code: z.string().max(16, 'Enter valid postal code').optional(),
+    country: z.string().regex(/^[A-Za-z]{2}$/, 'Enter 2-letter country code'),
     card_holder: z.string().min(2, 'Enter card holder name'),
     card_number: z.string().min(13, 'Enter valid card number'),
     expiry_month: z.string().regex(/^(0[1-9]|1[0-2])$/, 'Enter valid month (01-12)'),
@@ -111,10 +118,40 @@ const CartPage: React.FC<CartPageProps> = ({ isAuthenticated }) => {
 
   type FormValues = z.infer<typeof schema>;
 
-  const { register: rhfRegister, handleSubmit, formState: { errors } } = useForm<FormValues>({
+  const { register: rhfRegister, handleSubmit, getValues, formState: { errors } } = useForm<FormValues>({
     resolver: zodResolver(schema),
   });
 
+  const addressFrom = (data: Partial<FormValues>): Address => ({
+    recipient: data.recipient ?? '',
+    line1: data.line1 ?? '',
+    line2: data.line2 || undefined,
+    city: data.city ?? '',
+    region: data.region?.toUpperCase() || undefined,
+    postal_code: data.postal_code || undefined,
+    country: (data.country ?? '').toUpperCase(),
+  });
+
+  // Quotes depend on the cart weight and destination; the chosen method is charged on the order
+  const loadQuotes = async () => {
+    setError('');
+    try {
+      const response = await ordersAPI.quoteShipping({
+        shipping: addressFrom(getValues()),
+        items: cartItems.map(item => ({ product_id: item.product_id, quantity: item.quantity })),
+        currency: cartItems[0]?.price?.currency,
+      });
+      const loaded = (response.data?.data?.quotes ?? []) as ShippingQuote[];
+      setQuotes(loaded);
+      if (loaded.length > 0 && !loaded.some(q => q.method === shippingMethod)) {
+        setShippingMethod(loaded[0].method);
+      }
+    } catch (err: unknown) {
+      setQuotes([]);
+      setError(isAxiosError(err) ? (err.response?.data as any)?.error ?? err.message : 'Failed to quote shipping');
+    }
+  };
+
   const onSubmit = async (data: FormValues) => {
     // Check authentication before proceeding
     if (!isAuthenticated) {
@@ -126,21 +163,29 @@ const CartPage: React.FC<CartPageProps> = ({ isAuthenticated }) => {
     setLoading(true);
 
     try {
+      // The card goes to the payment vault; the order only references its token
+      const saved = await paymentMethodsAPI.add({
+        method: orderForm.payment_method,
+        card: {
+          card_holder: data.card_holder,
+          card_number: data.card_number,
+          expiry_month: data.expiry_month,
+          expiry_year: data.expiry_year,
+          cvv: data.cvv,
+        },
+      });
+      const paymentMethod = saved.data?.data?.payment_method as SavedPaymentMethod;
+
       const orderData: CreateOrderRequest = {
         user_id: 'dev-user-1', // Temporary user_id for development
         items: cartItems.map(item => ({
           product_id: item.product_id,
           quantity: item.quantity,
         })),
-        shipping_address: data.shipping_address,
+        shipping: addressFrom(data),
+        shipping_method: shippingMethod,
         payment_method: orderForm.payment_method,
-        payment_details: {
-          card_holder: data.card_holder,
-          card_number: data.card_number,
-          expiry_month: data.expiry_month,
-          expiry_year: data.expiry_year,
-          cvv: data.cvv,
-        },
+        payment_method_token: paymentMethod.token,
       };
 
       try {
@@ -226,18 +271,99 @@ const CartPage: React.FC<CartPageProps> = ({ isAuthenticated }) => {
               )}
               <form onSubmit={handleSubmit(onSubmit)}>
                 <TextField 
-                  id="shipping_address" 
-                  label="Shipping Address" 
-                  {...rhfRegister('shipping_address')} 
-                  error={!!errors.shipping_address} 
-                  helperText={errors.shipping_address?.message} 
+                  id="recipient" 
+                  label="Recipient" 
+                  {...rhfRegister('recipient')} 
+                  error={!!errors.recipient} 
+                  helperText={errors.recipient?.message} 
                   fullWidth 
-                  multiline 
-                  rows={3} 
                   sx={{ mb: 2 }} 
                   disabled={!isAuthenticated}
                   placeholder={!isAuthenticated ? "Sign in to enter address" : ""}
                 />
+                <TextField 
+                  id="line1" 
+                  label="Address Line 1" 
+                  {...rhfRegister('line1')} 
+                  error={!!errors.line1} 
+                  helperText={errors.line1?.message} 
+                  fullWidth 
+                  sx={{ mb: 2 }} 
+                  disabled={!isAuthenticated}
+                />
+                <TextField 
+                  id="line2" 
+                  label="Address Line 2" 
+                  {...rhfRegister('line2')} 
+                  fullWidth 
+                  sx={{ mb: 2 }} 
+                  disabled={!isAuthenticated}
+                />
+                <Stack direction="row" spacing={2} sx={{ mb: 2 }}>
+                  <TextField 
+                    id="city" 
+                    label="City" 
+                    {...rhfRegister('city')} 
+                    error={!!errors.city} 
+                    helperText={errors.city?.message} 
+                    fullWidth 
+                    disabled={!isAuthenticated}
+                  />
+                  <TextField 
+                    id="region" 
+                    label="State" 
+                    {...rhfRegister('region')} 
+                    error={!!errors.region} 
+                    helperText={errors.region?.message} 
+                    placeholder="CA" 
+                    fullWidth 
+                    disabled={!isAuthenticated}
+                  />
+                </Stack>
+                <Stack direction="row" spacing={2} sx={{ mb: 2 }}>
+                  <TextField 
+                    id="postal_code" 
+                    label="Postal Code" 
+                    {...rhfRegister('postal_code')} 
+                    error={!!errors.postal_code} 
+                    helperText={errors.postal_code?.message} 
+                    fullWidth 
+                    disabled={!isAuthenticated}
+                  />
+                  <TextField 
+                    id="country" 
+                    label="Country" 
+                    {...rhfRegister('country')} 
+                    error={!!errors.country} 
+                    helperText={errors.country?.message} 
+                    placeholder="US" 
+                    fullWidth 
+                    disabled={!isAuthenticated}
+                  />
+                </Stack>
+                <Stack direction="row" spacing={2} alignItems="center" sx={{ mb: 2 }}>
+                  <TextField 
+                    id="shipping_method" 
+                    select 
+                    label="Shipping" 
+                    value={shippingMethod} 
+                    onChange={(e) => setShippingMethod(e.target.value)} 
+                    fullWidth 
+                    disabled={!isAuthenticated}
+                  >
+                    {quotes.length > 0
+                      ? quotes.map(q => (
+                          <MenuItem key={q.method} value={q.method}>
+                            {q.name} ({q.min_days}-{q.max_days} days) - {formatMinor(q.cost.amount, q.cost.currency)}
+                          </MenuItem>
+                        ))
+                      : [
+                          <MenuItem key="STANDARD" value="STANDARD">Standard</MenuItem>,
+                          <MenuItem key="EXPRESS" value="EXPRESS">Express</MenuItem>,
+                        ]}
+                  </TextField>
+                  <Button variant="outlined" onClick={loadQuotes} disabled={!isAuthenticated}>Quote</Button>
+                </Stack>
                 <TextField 
                   id="card_holder" 
                   label="Card Holder Name" 
@@ -125,7 +125,16 @@ const OrdersPage: React.FC<OrdersPageProps> = ({ isAuthenticated }) => {
                     </div>
                     <div style={{ textAlign: 'right' as const }}>
                       <Typography variant="h6">{formatMoneyMinor((order as any).total_amount?.amount, (order as any).total_amount?.currency)}</Typography>
-                      
+                      {order.tax_amount?.amount ? (
+                        <Typography variant="body2" color="text.secondary">
+                          Subtotal {formatMoneyMinor(order.subtotal?.amount, order.subtotal?.currency)} · Tax {formatMoneyMinor(order.tax_amount.amount, order.tax_amount.currency)}
+                        </Typography>
+                      ) : null}
+                      {order.shipping_method ? (
+                        <Typography variant="body2" color="text.secondary">
+                          Shipping ({order.shipping_method.toLowerCase()}) {formatMoneyMinor(order.shipping_cost?.amount, order.shipping_cost?.currency)}
+                        </Typography>
+                      ) : null}
                     </div>
                   </Stack>
                   <Typography variant="subtitle1" sx={{ mb: 1 }}>Items:</Typography>
@@ -2,6 +2,7 @@ import React, { useState, useEffect } from 'react';
 import { productsAPI } from '../services/api';
 import { useQuery } from '@tanstack/react-query';
 import type { Product, Category, CartItem } from '../types';
+import { formatMoneyMinor } from '../utils/money';
 import Container from '@mui/material/Container';
 import Grid from '@mui/material/Grid';
 import Card from '@mui/material/Card';
@@ -84,8 +85,7 @@ const ProductsPage: React.FC<ProductsPageProps> = () => {
 
   const formatMoney = (m?: { amount: number; currency: string }) => {
     if (!m) return '';
-    const major = (m.amount / 100).toFixed(2);
-    return `${major} ${m.currency}`;
+    return formatMoneyMinor(m.amount, m.currency);
   };
 
   const addToCart = (product: Product) => {
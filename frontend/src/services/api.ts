@@ -1,5 +1,5 @@
 import axios from 'axios';
-import type { CreateOrderRequest } from '../types';
+import type { AddPaymentMethodRequest, CreateOrderRequest, QuoteShippingRequest } from '../types';
 
 const API_BASE_URL = (import.meta as any).env?.VITE_API_URL || process.env.REACT_APP_API_URL || 'http://localhost:8080';
 
@@ -28,10 +28,10 @@ api.interceptors.request.use(
 
 // Products API (public endpoints: no credentials needed)
 export const productsAPI = {
-  getProducts: (params: { category_id?: string; search?: string } = {}) =>
+  getProducts: (params: { category_id?: string; search?: string; currency?: string } = {}) =>
     api.get('/inventory/products', { params, withCredentials: false }),
-  getProduct: (id: string) =>
-    api.get(`/inventory/products/${id}`, { withCredentials: false }),
+  getProduct: (id: string, currency?: string) =>
+    api.get(`/inventory/products/${id}`, { params: currency ? { currency } : undefined, withCredentials: false }),
   getCategories: () =>
     api.get('/inventory/categories?active_only=true', { withCredentials: false }),
 };
@@ -47,6 +47,16 @@ export const ordersAPI = {
   getOrder: (id: string) => {
     return api.get(`/orders/${id}`);
   },
+  quoteShipping: (req: QuoteShippingRequest) => {
+    return api.post('/orders/shipping-quotes', req);
+  },
+};
+
+// Payment methods API (requires authentication): cards are saved in the payment vault
+export const paymentMethodsAPI = {
+  list: () => api.get('/payments/methods'),
+  add: (method: AddPaymentMethodRequest) => api.post('/payments/methods', method),
+  remove: (token: string) => api.delete(`/payments/methods/${token}`),
 };
 
 // Auth API
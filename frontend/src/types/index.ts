@@ -14,6 +14,14 @@ export interface Money {
   currency: string;
 }
 
+// Rate a price was converted with: amount in `to` = amount in `from` * rate
+export interface ExchangeRate {
+  from: string;
+  to: string;
+  rate: number;
+  as_of: string; // when the rate was published
+}
+
 export interface Product {
   id: string;
   name: string;
@@ -26,6 +34,8 @@ export interface Product {
   is_active: boolean;
   created_at: string;
   updated_at: string;
+  catalog_price?: Money; // set when price was converted
+  exchange_rate?: ExchangeRate; // set when price was converted
 }
 
 export interface Category {
@@ -49,6 +59,19 @@ export interface OrderItem {
   quantity: number;
   price: Money; // minor units in price.amount
   total: Money; // minor units in total.amount
+  catalog_price?: Money; // set when price was converted
+  exchange_rate?: ExchangeRate; // rate used at checkout
+  tax_category?: string; // empty for the standard rate
+  tax_lines?: TaxLine[];
+}
+
+// Tax charged on an order item; inclusive taxes are contained in the item total
+export interface TaxLine {
+  name: string;
+  jurisdiction: string; // country, or country-region (US-CA)
+  rate: number; // 0.19 for 19%
+  amount: Money;
+  inclusive: boolean;
 }
 
 export interface Order {
@@ -56,12 +79,44 @@ export interface Order {
   user_id: string;
   status: string;
   items: OrderItem[];
-  total_amount: Money; // minor units in total_amount.amount
-  shipping_address: string;
+  subtotal: Money; // items net of tax
+  tax_amount: Money; // inclusive and exclusive taxes
+  shipping_cost: Money;
+  total_amount: Money; // subtotal plus tax and shipping
+  shipping_address: string; // one-line form of shipping
+  shipping_country?: string; // ISO 3166-1 alpha-2
+  shipping_region?: string; // ISO 3166-2 subdivision
+  shipping?: Address;
+  shipping_method?: string; // STANDARD, EXPRESS
   created_at: string;
   updated_at: string;
 }
 
+export interface Address {
+  recipient: string;
+  line1: string;
+  line2?: string;
+  city: string;
+  region?: string; // ISO 3166-2 subdivision without the country prefix, e.g. CA for US-CA
+  postal_code?: string;
+  country: string; // ISO 3166-1 alpha-2
+}
+
+// Cost of a shipping method for a cart and destination
+export interface ShippingQuote {
+  method: string; // STANDARD, EXPRESS
+  name: string;
+  cost: Money;
+  min_days: number; // business days
+  max_days: number;
+}
+
+export interface QuoteShippingRequest {
+  shipping: Address;
+  items: { product_id: string; quantity: number }[];
+  currency?: string; // ISO 4217 currency of the quotes
+}
+
 export interface AuthResponse {
   user: User;
   message: string;
@@ -100,12 +155,33 @@ export interface PaymentDetails {
   expiry_month: string;
   expiry_year: string;
   cvv: string;
+  billing_country?: string; // ISO 3166-1 alpha-2
+}
+
+// Card saved in the payment vault; only display details are returned
+export interface SavedPaymentMethod {
+  token: string;
+  method: string;
+  brand: string;
+  last4: string;
+  expiry_month: number;
+  expiry_year: number;
+  card_holder: string;
+  billing_country?: string;
+  created_at: string;
+}
+
+export interface AddPaymentMethodRequest {
+  method: string;
+  card: PaymentDetails;
 }
 
 export interface CreateOrderRequest {
   user_id: string; // Required user_id for order creation
   items: { product_id: string; quantity: number }[];
-  shipping_address: string;
+  shipping: Address;
+  shipping_method?: string; // one of the quoted methods, default STANDARD
   payment_method: string;
-  payment_details: PaymentDetails;
+  payment_method_token?: string; // saved card, card methods only
+  currency?: string; // ISO 4217 presentment currency
 }
\ No newline at end of file
@@ -1,15 +1,12 @@
 // Currency utilities and money formatting in minor units
 // Single Responsibility: isolate currency exponent rules and formatting
 
+// ISO 4217 minor units of currencies that do not use 2 decimals (see pkg/money on the backend)
 const CURRENCY_FRACTION_DIGITS: Record<string, number> = {
-  JPY: 0,
-  KRW: 0,
-  VND: 0,
-  KWD: 3,
-  BHD: 3,
-  JOD: 3,
-  OMR: 3,
-  TND: 3,
+  BIF: 0, CLP: 0, DJF: 0, GNF: 0, ISK: 0, JPY: 0, KMF: 0, KRW: 0, PYG: 0,
+  RWF: 0, UGX: 0, UYI: 0, VND: 0, VUV: 0, XAF: 0, XOF: 0, XPF: 0,
+  BHD: 3, IQD: 3, JOD: 3, KWD: 3, LYD: 3, OMR: 3, TND: 3,
+  CLF: 4, UYW: 4,
 };
 
 export function getFractionDigits(currency?: string): number {
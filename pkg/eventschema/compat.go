@@ -0,0 +1,93 @@
+package eventschema
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// CheckCompatible reports changes from old to new that break decoding one with the other:
+// fields that were renumbered, renamed, removed without reserving their number, or that
+// changed type or cardinality, and enum values that were renumbered or removed. Nested
+// message fields are checked recursively; the names of message types may change.
+func CheckCompatible(old, new protoreflect.MessageDescriptor) error {
+	c := &compatChecker{seen: make(map[[2]protoreflect.FullName]bool)}
+	c.message(string(old.Name()), old, new)
+	return errors.Join(c.errs...)
+}
+
+type compatChecker struct {
+	seen map[[2]protoreflect.FullName]bool
+	errs []error
+}
+
+func (c *compatChecker) fail(path, format string, args ...any) {
+	c.errs = append(c.errs, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+}
+
+func (c *compatChecker) message(path string, old, new protoreflect.MessageDescriptor) {
+	// recursive messages are checked once per pair
+	pair := [2]protoreflect.FullName{old.FullName(), new.FullName()}
+	if c.seen[pair] {
+		return
+	}
+	c.seen[pair] = true
+
+	oldFields := old.Fields()
+	for i := 0; i < oldFields.Len(); i++ {
+		of := oldFields.Get(i)
+		fieldPath := path + "." + string(of.Name())
+		nf := new.Fields().ByNumber(of.Number())
+		if nf == nil {
+			if moved := new.Fields().ByName(of.Name()); moved != nil {
+				c.fail(fieldPath, "renumbered from %d to %d", of.Number(), moved.Number())
+			} else if !new.ReservedRanges().Has(of.Number()) {
+				c.fail(fieldPath, "field %d removed without reserving its number", of.Number())
+			}
+			continue
+		}
+		c.field(fieldPath, of, nf)
+	}
+
+	newFields := new.Fields()
+	for i := 0; i < newFields.Len(); i++ {
+		nf := newFields.Get(i)
+		if old.ReservedRanges().Has(nf.Number()) {
+			c.fail(path+"."+string(nf.Name()), "reuses reserved field number %d", nf.Number())
+		}
+	}
+}
+
+func (c *compatChecker) field(path string, old, new protoreflect.FieldDescriptor) {
+	if old.Name() != new.Name() {
+		c.fail(path, "field %d renamed to %s", old.Number(), new.Name())
+	}
+	if old.Cardinality() != new.Cardinality() {
+		c.fail(path, "cardinality changed from %s to %s", old.Cardinality(), new.Cardinality())
+	}
+	if old.Kind() != new.Kind() {
+		c.fail(path, "type changed from %s to %s", old.Kind(), new.Kind())
+		return
+	}
+	switch old.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		c.message(path, old.Message(), new.Message())
+	case protoreflect.EnumKind:
+		c.enum(path, old.Enum(), new.Enum())
+	}
+}
+
+func (c *compatChecker) enum(path string, old, new protoreflect.EnumDescriptor) {
+	oldValues := old.Values()
+	for i := 0; i < oldValues.Len(); i++ {
+		ov := oldValues.Get(i)
+		nv := new.Values().ByNumber(ov.Number())
+		switch {
+		case nv == nil && !new.ReservedRanges().Has(ov.Number()):
+			c.fail(path, "enum value %s (%d) removed without reserving its number", ov.Name(), ov.Number())
+		case nv != nil && nv.Name() != ov.Name():
+			c.fail(path, "enum value %d renamed from %s to %s", ov.Number(), ov.Name(), nv.Name())
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package eventschema
+
+import (
+	events "github.com/kubernetestest/ecommerce-platform/proto-go/events"
+)
+
+// Topics of the events exchanged between services
+const (
+	OrderCreatedTopic           = "orders.v1.order_created"
+	OrderStatusChangedTopic     = "orders.v1.order_status_changed"
+	StockReservedTopic          = "inventory.v1.stock_reserved"
+	StockReservationFailedTopic = "inventory.v1.stock_reservation_failed"
+	PaymentProcessedTopic       = "payments.v1.payment_processed"
+	PaymentRefundedTopic        = "payments.v1.payment_refunded"
+	PaymentReviewRequiredTopic  = "payments.v1.payment_review_required"
+)
+
+var contracts = newContracts()
+
+// Contracts returns the registry of every event schema version the services exchange.
+// A new version is added here before any producer publishes it, so consumers deployed
+// with it already decode both versions. Compatibility between versions, and with the
+// schemas of the last release in testdata, is checked by the package tests.
+func Contracts() *Registry { return contracts }
+
+func newContracts() *Registry {
+	r := NewRegistry()
+	Register[*events.OrderCreated](r, OrderCreatedTopic, 1)
+	Register[*events.OrderCreatedV2](r, OrderCreatedTopic, 2)
+	Register[*events.OrderStatusChanged](r, OrderStatusChangedTopic, 1)
+	Register[*events.StockReserved](r, StockReservedTopic, 1)
+	Register[*events.StockReservationFailed](r, StockReservationFailedTopic, 1)
+	Register[*events.PaymentProcessed](r, PaymentProcessedTopic, 1)
+	Register[*events.PaymentRefunded](r, PaymentRefundedTopic, 1)
+	Register[*events.PaymentReviewRequired](r, PaymentReviewRequiredTopic, 1)
+	return r
+}
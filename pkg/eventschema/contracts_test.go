@@ -0,0 +1,106 @@
+package eventschema
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+var updateRelease = flag.Bool("update-release", false, "record the current event schemas as released; only run on a release commit")
+
+// releasedSchemas holds the descriptors of the event protos of the last release, not of the
+// working tree; schemas added since then are not in it yet
+var releasedSchemas = filepath.Join("testdata", "released_events.binpb")
+
+func TestContractsCompatible(t *testing.T) {
+	if err := Contracts().Check(); err != nil {
+		t.Fatalf("incompatible contracts: %v", err)
+	}
+}
+
+// TestContractsCompatibleWithRelease compares every registered schema with the one released
+// under the same message name, so a version edited in place cannot break running consumers
+func TestContractsCompatibleWithRelease(t *testing.T) {
+	if *updateRelease {
+		recordRelease(t)
+		return
+	}
+	b, err := os.ReadFile(releasedSchemas)
+	if err != nil {
+		t.Fatalf("read released schemas: %v", err)
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(b, &set); err != nil {
+		t.Fatalf("decode released schemas: %v", err)
+	}
+	released, err := protodesc.NewFiles(&set)
+	if err != nil {
+		t.Fatalf("load released schemas: %v", err)
+	}
+
+	r := Contracts()
+	for _, topic := range r.Topics() {
+		for _, version := range r.Versions(topic) {
+			current := r.types[schemaKey{topic, version}].Descriptor()
+			d, err := released.FindDescriptorByName(current.FullName())
+			if err != nil {
+				continue // not released yet
+			}
+			old, ok := d.(protoreflect.MessageDescriptor)
+			if !ok {
+				t.Errorf("%s v%d: released %s is not a message", topic, version, current.FullName())
+				continue
+			}
+			if err := CheckCompatible(old, current); err != nil {
+				t.Errorf("%s v%d is incompatible with the release: %v", topic, version, err)
+			}
+		}
+	}
+}
+
+// recordRelease writes the files declaring the registered schemas to releasedSchemas
+func recordRelease(t *testing.T) {
+	t.Helper()
+	files := make(map[string]protoreflect.FileDescriptor)
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if _, ok := files[fd.Path()]; ok {
+			return
+		}
+		files[fd.Path()] = fd
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+	}
+	for _, mt := range Contracts().types {
+		add(mt.Descriptor().ParentFile())
+	}
+
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, p := range paths {
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(files[p]))
+	}
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(set)
+	if err != nil {
+		t.Fatalf("encode released schemas: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(releasedSchemas), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(releasedSchemas, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
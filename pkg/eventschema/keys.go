@@ -0,0 +1,59 @@
+package eventschema
+
+import (
+	"github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
+	events "github.com/kubernetestest/ecommerce-platform/proto-go/events"
+	"google.golang.org/protobuf/proto"
+)
+
+// IdempotencyKey identifies the business fact an event reports, so consumers skip it when it
+// is delivered or published again; empty for events without one
+func IdempotencyKey(evt proto.Message) string {
+	var name, id string
+	switch e := evt.(type) {
+	case *events.OrderCreated:
+		name, id = "OrderCreated", e.GetOrderId()
+	case *events.OrderCreatedV2:
+		name, id = "OrderCreated", e.GetOrderId()
+	case *events.OrderStatusChanged:
+		if e.GetOrderId() != "" {
+			name, id = "OrderStatusChanged", e.GetOrderId()+":"+e.GetNewStatus()
+		}
+	case *events.StockReserved:
+		name, id = "StockReserved", e.GetOrderId()
+	case *events.StockReservationFailed:
+		name, id = "StockReservationFailed", e.GetOrderId()
+	case *events.PaymentProcessed:
+		// an order may see several payment attempts
+		name, id = "PaymentProcessed", paymentKey(e.GetOrderId(), e.GetPaymentId())
+	case *events.PaymentReviewRequired:
+		if e.GetOrderId() != "" {
+			name, id = "PaymentReviewRequired", e.GetOrderId()+"/"+e.GetPaymentId()
+		}
+	}
+	if id == "" {
+		return ""
+	}
+	return name + ":" + id
+}
+
+func paymentKey(orderID, paymentID string) string {
+	if paymentID == "" {
+		return orderID
+	}
+	return orderID + "/" + paymentID
+}
+
+// KeyFunc is the idempotency key function of consumers of the contract events: it decodes
+// messages with the contracts and keys them by IdempotencyKey, falling back to
+// kafkaclient.DefaultKeyFunc for events without a key or that do not decode
+func KeyFunc(msg *kafkaclient.Message) string {
+	evt, err := kafkaclient.DecodeEvent(contracts, msg)
+	if err != nil {
+		return kafkaclient.DefaultKeyFunc(msg)
+	}
+	if key := IdempotencyKey(evt); key != "" {
+		return key
+	}
+	return kafkaclient.DefaultKeyFunc(msg)
+}
@@ -0,0 +1,121 @@
+package eventschema
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ErrUnknownSchema is returned for a topic and version, or message type, that is not registered
+var ErrUnknownSchema = errors.New("unknown event schema")
+
+type schemaKey struct {
+	topic   string
+	version int
+}
+
+type typeKey struct {
+	topic string
+	name  protoreflect.FullName
+}
+
+// Registry maps topic and schema version to the protobuf message type of its events.
+// It implements kafkaclient.Schemas. Registries are built once at startup and are
+// safe for concurrent reads afterwards.
+type Registry struct {
+	types    map[schemaKey]protoreflect.MessageType
+	versions map[typeKey]int
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		types:    make(map[schemaKey]protoreflect.MessageType),
+		versions: make(map[typeKey]int),
+	}
+}
+
+// Register records T as schema version of events on topic. Registering a version or a
+// type twice for one topic is a programming error and panics.
+func Register[T proto.Message](r *Registry, topic string, version int) *Registry {
+	var zero T
+	mt := zero.ProtoReflect().Type()
+	name := mt.Descriptor().FullName()
+	if version < 1 {
+		panic(fmt.Sprintf("eventschema: %s on %s: version must be positive", name, topic))
+	}
+	if prev, ok := r.types[schemaKey{topic, version}]; ok {
+		panic(fmt.Sprintf("eventschema: %s v%d already registered as %s", topic, version, prev.Descriptor().FullName()))
+	}
+	if prev, ok := r.versions[typeKey{topic, name}]; ok {
+		panic(fmt.Sprintf("eventschema: %s already registered on %s as v%d", name, topic, prev))
+	}
+	r.types[schemaKey{topic, version}] = mt
+	r.versions[typeKey{topic, name}] = version
+	return r
+}
+
+// New returns an empty message of the type registered for topic and version
+func (r *Registry) New(topic string, version int) (proto.Message, error) {
+	mt, ok := r.types[schemaKey{topic, version}]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s v%d", ErrUnknownSchema, topic, version)
+	}
+	return mt.New().Interface(), nil
+}
+
+// Version returns the schema version evt's type is registered as on topic
+func (r *Registry) Version(topic string, evt proto.Message) (int, error) {
+	name := evt.ProtoReflect().Descriptor().FullName()
+	version, ok := r.versions[typeKey{topic, name}]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s on %s", ErrUnknownSchema, name, topic)
+	}
+	return version, nil
+}
+
+// Topics returns the registered topics in order
+func (r *Registry) Topics() []string {
+	seen := make(map[string]bool)
+	var topics []string
+	for k := range r.types {
+		if !seen[k.topic] {
+			seen[k.topic] = true
+			topics = append(topics, k.topic)
+		}
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// Versions returns the registered schema versions of topic in ascending order
+func (r *Registry) Versions(topic string) []int {
+	var versions []int
+	for k := range r.types {
+		if k.topic == topic {
+			versions = append(versions, k.version)
+		}
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// Check verifies that every schema version of a topic is compatible with the previous one,
+// so consumers of either version can decode events of the other
+func (r *Registry) Check() error {
+	var errs []error
+	for _, topic := range r.Topics() {
+		versions := r.Versions(topic)
+		for i := 1; i < len(versions); i++ {
+			prev := r.types[schemaKey{topic, versions[i-1]}].Descriptor()
+			next := r.types[schemaKey{topic, versions[i]}].Descriptor()
+			if err := CheckCompatible(prev, next); err != nil {
+				errs = append(errs, fmt.Errorf("%s v%d -> v%d: %w", topic, versions[i-1], versions[i], err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,46 @@
+package eventschema
+
+import (
+	"fmt"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
+	events "github.com/kubernetestest/ecommerce-platform/proto-go/events"
+	"google.golang.org/protobuf/proto"
+)
+
+// As returns evt as T, or a permanent error when a topic carries an event type the
+// consumer does not handle
+func As[T proto.Message](evt proto.Message) (T, error) {
+	t, ok := evt.(T)
+	if !ok {
+		return t, kafkaclient.Permanent(fmt.Errorf("%w: unexpected event %s", ErrUnknownSchema, proto.MessageName(evt)))
+	}
+	return t, nil
+}
+
+// UpgradeOrderCreated converts a version 1 OrderCreated to version 2; item prices and
+// the shipping address, which version 1 does not carry, are left empty
+func UpgradeOrderCreated(v1 *events.OrderCreated) *events.OrderCreatedV2 {
+	v2 := &events.OrderCreatedV2{
+		OrderId:     v1.GetOrderId(),
+		UserId:      v1.GetUserId(),
+		TotalAmount: v1.GetTotalAmount(),
+		Currency:    v1.GetCurrency(),
+		OccurredAt:  v1.GetOccurredAt(),
+	}
+	for _, it := range v1.GetItems() {
+		v2.Items = append(v2.Items, &events.OrderLine{ProductId: it.GetProductId(), Quantity: it.GetQuantity()})
+	}
+	return v2
+}
+
+// OrderCreatedV2Of returns an OrderCreated event of any registered version as version 2
+func OrderCreatedV2Of(evt proto.Message) (*events.OrderCreatedV2, error) {
+	switch evt := evt.(type) {
+	case *events.OrderCreatedV2:
+		return evt, nil
+	case *events.OrderCreated:
+		return UpgradeOrderCreated(evt), nil
+	}
+	return As[*events.OrderCreatedV2](evt)
+}
@@ -0,0 +1,45 @@
+package exchange
+
+import (
+	"fmt"
+	"time"
+)
+
+// Rate sources
+const (
+	SourceNone = "none"
+	SourceFile = "file"
+	SourceHTTP = "http"
+)
+
+// Config selects and tunes the rate provider of a service
+type Config struct {
+	Source  string        // none, file or http
+	File    string        // rates file, file source
+	URL     string        // rates service base URL, http source
+	Timeout time.Duration // http source
+	Refresh time.Duration // how long the http source caches rates
+	MaxAge  time.Duration // rates published longer ago are rejected; 0 accepts any age
+}
+
+// NewProvider builds the configured provider; the none source returns nil, meaning a single
+// currency deployment
+func NewProvider(cfg Config) (Provider, error) {
+	switch cfg.Source {
+	case "", SourceNone:
+		return nil, nil
+	case SourceFile:
+		p, err := NewFileProvider(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		return p.WithMaxAge(cfg.MaxAge), nil
+	case SourceHTTP:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("exchange: http source needs a URL")
+		}
+		return NewHTTPProvider(cfg.URL, cfg.Timeout).WithRefreshInterval(cfg.Refresh).WithMaxAge(cfg.MaxAge), nil
+	default:
+		return nil, fmt.Errorf("exchange: unknown rates source %q", cfg.Source)
+	}
+}
@@ -0,0 +1,90 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileProvider serves rates from a JSON file in the Table format. The file is read again
+// whenever its modification time changes, so rates can be updated without a restart.
+type FileProvider struct {
+	path   string
+	maxAge time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	table   *Table
+	modTime time.Time
+}
+
+// NewFileProvider loads the rates file; it fails if the file is missing or invalid
+func NewFileProvider(path string) (*FileProvider, error) {
+	p := &FileProvider{path: path, now: time.Now}
+	if _, err := p.current(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// WithMaxAge rejects rates published longer ago than d
+func (p *FileProvider) WithMaxAge(d time.Duration) *FileProvider {
+	p.maxAge = d
+	return p
+}
+
+func (p *FileProvider) Rate(_ context.Context, from, to string) (Rate, error) {
+	t, err := p.current()
+	if err != nil {
+		return Rate{}, err
+	}
+	if err := t.checkFresh(p.maxAge, p.now()); err != nil {
+		return Rate{}, err
+	}
+	return t.Rate(from, to)
+}
+
+// current returns the loaded table, reloading the file if it changed. A file that became
+// unreadable or invalid keeps the last good rates.
+func (p *FileProvider) current() (*Table, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		if p.table != nil {
+			return p.table, nil
+		}
+		return nil, fmt.Errorf("exchange: rates file: %w", err)
+	}
+	if p.table != nil && info.ModTime().Equal(p.modTime) {
+		return p.table, nil
+	}
+	t, err := readTable(p.path)
+	if err != nil {
+		if p.table != nil {
+			return p.table, nil
+		}
+		return nil, err
+	}
+	p.table, p.modTime = t, info.ModTime()
+	return t, nil
+}
+
+func readTable(path string) (*Table, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("exchange: rates file: %w", err)
+	}
+	var t Table
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return nil, fmt.Errorf("exchange: rates file %s: %w", path, err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,110 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTPProvider fetches rates from a rates service (GET {baseURL}/rates returning a Table) and
+// caches them for the refresh interval. When a refresh fails the cached rates are used until
+// they exceed the max age.
+type HTTPProvider struct {
+	baseURL string
+	client  *http.Client
+	refresh time.Duration
+	maxAge  time.Duration
+	now     func() time.Time
+
+	mu        sync.Mutex
+	table     *Table
+	fetchedAt time.Time
+}
+
+func NewHTTPProvider(baseURL string, timeout time.Duration) *HTTPProvider {
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	return &HTTPProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+		refresh: 5 * time.Minute,
+		now:     time.Now,
+	}
+}
+
+// WithRefreshInterval sets how long fetched rates are cached
+func (p *HTTPProvider) WithRefreshInterval(d time.Duration) *HTTPProvider {
+	if d > 0 {
+		p.refresh = d
+	}
+	return p
+}
+
+// WithMaxAge rejects rates published longer ago than d
+func (p *HTTPProvider) WithMaxAge(d time.Duration) *HTTPProvider {
+	p.maxAge = d
+	return p
+}
+
+func (p *HTTPProvider) Rate(ctx context.Context, from, to string) (Rate, error) {
+	t, err := p.current(ctx)
+	if err != nil {
+		return Rate{}, err
+	}
+	if err := t.checkFresh(p.maxAge, p.now()); err != nil {
+		return Rate{}, err
+	}
+	return t.Rate(from, to)
+}
+
+func (p *HTTPProvider) current(ctx context.Context) (*Table, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	if p.table != nil && now.Sub(p.fetchedAt) < p.refresh {
+		return p.table, nil
+	}
+	t, err := p.fetch(ctx)
+	if err != nil {
+		if p.table != nil {
+			return p.table, nil
+		}
+		return nil, err
+	}
+	p.table, p.fetchedAt = t, now
+	return t, nil
+}
+
+func (p *HTTPProvider) fetch(ctx context.Context) (*Table, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/rates", nil)
+	if err != nil {
+		return nil, fmt.Errorf("exchange: build request: %w", err)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("exchange: fetch rates: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("exchange: read rates: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("exchange: fetch rates: http %d", resp.StatusCode)
+	}
+	var t Table
+	if err := json.Unmarshal(body, &t); err != nil {
+		return nil, fmt.Errorf("exchange: decode rates: %w", err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,97 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
+)
+
+var (
+	// ErrUnsupportedCurrency is returned for currencies the rates do not cover
+	ErrUnsupportedCurrency = errors.New("exchange: unsupported currency")
+	// ErrStaleRates is returned when the rates were published longer ago than allowed
+	ErrStaleRates = errors.New("exchange: rates are stale")
+)
+
+// Rate converts amounts From one currency To another: amount in To = amount in From * Value
+type Rate struct {
+	From  string
+	To    string
+	Value float64
+	// AsOf is when the rate was published by its source
+	AsOf time.Time
+}
+
+// Provider looks up exchange rates between ISO 4217 currencies
+type Provider interface {
+	Rate(ctx context.Context, from, to string) (Rate, error)
+}
+
+// Table is a set of rates against a base currency, as published at AsOf. It is the format of
+// rate files and of the rates stub service.
+type Table struct {
+	Base string    `json:"base"`
+	AsOf time.Time `json:"as_of"`
+	// Rates are units of the currency per unit of Base
+	Rates map[string]float64 `json:"rates"`
+}
+
+// Validate checks the table is usable
+func (t *Table) Validate() error {
+	if len(t.Base) != 3 {
+		return fmt.Errorf("exchange: invalid base currency %q", t.Base)
+	}
+	if t.AsOf.IsZero() {
+		return errors.New("exchange: rates have no as_of timestamp")
+	}
+	for cur, v := range t.Rates {
+		if len(cur) != 3 || !(v > 0) || math.IsInf(v, 0) {
+			return fmt.Errorf("exchange: invalid rate %s=%v", cur, v)
+		}
+	}
+	return nil
+}
+
+// Rate returns the rate between two currencies, crossing through the base currency
+func (t *Table) Rate(from, to string) (Rate, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	f, ok := t.unitsPerBase(from)
+	if !ok {
+		return Rate{}, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, from)
+	}
+	x, ok := t.unitsPerBase(to)
+	if !ok {
+		return Rate{}, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, to)
+	}
+	return Rate{From: from, To: to, Value: x / f, AsOf: t.AsOf}, nil
+}
+
+func (t *Table) unitsPerBase(currency string) (float64, bool) {
+	if strings.EqualFold(currency, t.Base) {
+		return 1, true
+	}
+	v, ok := t.Rates[currency]
+	return v, ok && v > 0
+}
+
+// checkFresh rejects tables published more than maxAge before now; 0 disables the check
+func (t *Table) checkFresh(maxAge time.Duration, now time.Time) error {
+	if maxAge > 0 && now.Sub(t.AsOf) > maxAge {
+		return fmt.Errorf("%w: published %s", ErrStaleRates, t.AsOf.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Convert converts an amount in r.From to r.To with the currencies' ISO 4217 minor units,
+// rounding half away from zero
+func Convert(m money.Money, r Rate) (money.Money, error) {
+	if !strings.EqualFold(m.Currency, r.From) {
+		return money.Money{}, fmt.Errorf("%w: rate is for %s, amount in %s", money.ErrCurrencyMismatch, r.From, m.Currency)
+	}
+	return m.Convert(r.To, r.Value)
+}
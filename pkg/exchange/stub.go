@@ -0,0 +1,50 @@
+package exchange
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// DefaultStubRates are the USD based rates the stub serves when no rates file is given
+func DefaultStubRates() map[string]float64 {
+	return map[string]float64{
+		"EUR": 0.92,
+		"GBP": 0.79,
+		"JPY": 149.5,
+		"CAD": 1.36,
+		"AUD": 1.52,
+		"CHF": 0.88,
+	}
+}
+
+// StubServer is a local stand-in for a rates service, serving GET /rates for HTTPProvider.
+// Rates without a fixed publication time are reported as published at the start of the hour.
+type StubServer struct {
+	table Table
+	now   func() time.Time
+}
+
+// NewStubServer serves the given table; a zero AsOf follows the clock
+func NewStubServer(table Table) *StubServer {
+	if table.Base == "" {
+		table.Base = "USD"
+	}
+	if table.Rates == nil {
+		table.Rates = DefaultStubRates()
+	}
+	return &StubServer{table: table, now: time.Now}
+}
+
+func (s *StubServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rates", func(w http.ResponseWriter, _ *http.Request) {
+		t := s.table
+		if t.AsOf.IsZero() {
+			t.AsOf = s.now().UTC().Truncate(time.Hour)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(t)
+	})
+	return mux
+}
@@ -0,0 +1,39 @@
+package kafkaclient
+
+import "errors"
+
+// ErrKafkaUnavailable is returned by KafkaBus in builds without cgo, which the Kafka client needs
+var ErrKafkaUnavailable = errors.New("kafka transport requires cgo; use MemoryBus")
+
+// Bus is a message transport with Kafka semantics: topics split into partitions by key,
+// and every consumer group receives each message once, through one of its members.
+// KafkaBus is used in services; MemoryBus runs whole event flows inside one process.
+type Bus interface {
+	// NewPublisher creates a publisher sending to the bus
+	NewPublisher(config PublisherConfig) (Publisher, error)
+	// NewConsumer creates a consumer reading from the bus as a member of config.GroupID
+	NewConsumer(config ConsumerConfig) (*Consumer, error)
+}
+
+// KafkaBus is the Bus backed by a Kafka cluster. The client library needs cgo; without it
+// NewPublisher and NewConsumer return ErrKafkaUnavailable.
+type KafkaBus struct {
+	bootstrapServers string
+}
+
+// NewKafkaBus creates a bus connecting publishers and consumers to bootstrapServers
+func NewKafkaBus(bootstrapServers string) *KafkaBus {
+	return &KafkaBus{bootstrapServers: bootstrapServers}
+}
+
+// NewPublisher creates a KafkaPublisher
+func (b *KafkaBus) NewPublisher(config PublisherConfig) (Publisher, error) {
+	config.BootstrapServers = b.bootstrapServers
+	return newKafkaPublisher(config)
+}
+
+// NewConsumer creates a Kafka consumer
+func (b *KafkaBus) NewConsumer(config ConsumerConfig) (*Consumer, error) {
+	config.BootstrapServers = b.bootstrapServers
+	return NewConsumer(config)
+}
@@ -2,20 +2,31 @@ package kafkaclient
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
 	"sync"
 	"time"
 
-	"github.com/confluentinc/confluent-kafka-go/kafka"
 	"github.com/kubernetestest/ecommerce-platform/pkg/logger"
+	"google.golang.org/protobuf/proto"
 )
 
-// Consumer wraps a confluent-kafka consumer with optimized worker pool
+// Consumer runs handlers for messages of a consumer group on an optimized worker pool.
+// The transport is Kafka (NewConsumer) or an in-process MemoryBus.
 type Consumer struct {
-	c       *kafka.Consumer
+	t       consumerTransport
 	log     logger.Logger
 	perMsgT time.Duration
 	workers int
 	buffer  int
+	groupID string
+	idem    *idempotency
+	retry   RetryPolicy
+	commitT time.Duration
+	dlq     DeadLetterPublisher
+	offsets *offsetTracker
+	orderFn KeyFunc
 }
 
 // ConsumerConfig holds consumer configuration
@@ -26,10 +37,24 @@ type ConsumerConfig struct {
 	Workers          int
 	BufferSize       int
 	HandleTimeout    time.Duration
+	// CommitInterval is how often offsets of handled messages are committed
+	CommitInterval time.Duration
+	// Retry controls handler retries before a message is dead-lettered
+	Retry RetryPolicy
 }
 
-// NewConsumer creates a new consumer with optimized config
+// NewConsumer creates a new Kafka consumer with optimized config
 func NewConsumer(config ConsumerConfig) (*Consumer, error) {
+	config = config.withDefaults()
+	t, err := newKafkaTransport(config)
+	if err != nil {
+		return nil, err
+	}
+	return newConsumer(config, t), nil
+}
+
+// withDefaults applies defaults to zero config values
+func (config ConsumerConfig) withDefaults() ConsumerConfig {
 	if config.AutoOffsetReset == "" {
 		config.AutoOffsetReset = "earliest"
 	}
@@ -39,24 +64,60 @@ func NewConsumer(config ConsumerConfig) (*Consumer, error) {
 	if config.BufferSize <= 0 {
 		config.BufferSize = 128
 	}
-
-	c, err := kafka.NewConsumer(&kafka.ConfigMap{
-		"bootstrap.servers":       config.BootstrapServers,
-		"group.id":                config.GroupID,
-		"auto.offset.reset":       config.AutoOffsetReset,
-		"enable.auto.commit":      true,
-		"auto.commit.interval.ms": 1000,
-	})
-	if err != nil {
-		return nil, err
+	if config.CommitInterval <= 0 {
+		config.CommitInterval = time.Second
 	}
+	return config
+}
 
+func newConsumer(config ConsumerConfig, t consumerTransport) *Consumer {
 	return &Consumer{
-		c:       c,
+		t:       t,
 		perMsgT: config.HandleTimeout,
 		workers: config.Workers,
 		buffer:  config.BufferSize,
-	}, nil
+		groupID: config.GroupID,
+		retry:   config.Retry.withDefaults(),
+		commitT: config.CommitInterval,
+		offsets: newOffsetTracker(),
+	}
+}
+
+// WithIdempotency skips messages whose key was already processed by this consumer group.
+// keyFn defaults to DefaultKeyFunc; lease bounds how long a crashed handler blocks redelivery.
+func (c *Consumer) WithIdempotency(store ProcessedStore, keyFn KeyFunc, lease time.Duration) *Consumer {
+	if keyFn == nil {
+		keyFn = DefaultKeyFunc
+	}
+	if lease <= 0 {
+		lease = 5 * time.Minute
+	}
+	c.idem = &idempotency{store: store, keyFn: keyFn, lease: lease, scope: c.groupID}
+	return c
+}
+
+// WithDeadLetter routes messages that still fail after all retries to "<topic>.dlq".
+// Without it such messages are retried until they succeed: they are never committed, and
+// block the messages after them with the same ordering key.
+func (c *Consumer) WithDeadLetter(p DeadLetterPublisher) *Consumer {
+	c.dlq = p
+	return c
+}
+
+// WithOrderingKey sets how messages are sharded to workers; messages with the same key
+// are handled one at a time in arrival order. Empty keys fall back to DefaultOrderingKey.
+func (c *Consumer) WithOrderingKey(fn KeyFunc) *Consumer {
+	c.orderFn = fn
+	return c
+}
+
+// DefaultOrderingKey uses the Kafka message key, or the partition for unkeyed messages
+// so they keep the partition order
+func DefaultOrderingKey(msg *Message) string {
+	if len(msg.Key) > 0 {
+		return string(msg.Key)
+	}
+	return fmt.Sprintf("%s/%d", msg.Topic, msg.Partition)
 }
 
 // WithLogger sets logger for consumer
@@ -67,87 +128,124 @@ func (c *Consumer) WithLogger(l logger.Logger) *Consumer {
 
 // Close terminates consumer gracefully
 func (c *Consumer) Close() error {
-	return c.c.Close()
+	return c.t.close()
 }
 
 // RunValueLoop runs optimized worker-pool loop for value-only processing
 func (c *Consumer) RunValueLoop(ctx context.Context, topics []string, handle func(context.Context, []byte) error) error {
 	defer c.Close()
 
-	if err := c.c.SubscribeTopics(topics, nil); err != nil {
+	if err := c.t.subscribe(topics, c.revoked); err != nil {
 		if c.log != nil {
 			c.log.Error("failed to subscribe to topics", "error", err, "topics", topics)
 		}
 		return err
 	}
 
-	return c.runLoop(ctx, func(ctx context.Context, msg *kafka.Message) error {
+	return c.runLoop(ctx, func(ctx context.Context, msg *Message) error {
 		return handle(ctx, msg.Value)
 	})
 }
 
-// RunMetaLoop runs optimized worker-pool loop with metadata
-func (c *Consumer) RunMetaLoop(ctx context.Context, topics []string, handle func(context.Context, string, int32, kafka.Offset, []byte) error) error {
+// RunEventLoop runs the worker-pool loop decoding each message into the event type
+// registered in schemas for its topic and schema version, so handlers can serve
+// several versions of an event side by side
+func (c *Consumer) RunEventLoop(ctx context.Context, topics []string, schemas Schemas, handle func(context.Context, proto.Message) error) error {
 	defer c.Close()
 
-	if err := c.c.SubscribeTopics(topics, nil); err != nil {
+	if err := c.t.subscribe(topics, c.revoked); err != nil {
 		if c.log != nil {
 			c.log.Error("failed to subscribe to topics", "error", err, "topics", topics)
 		}
 		return err
 	}
 
-	return c.runLoop(ctx, func(ctx context.Context, msg *kafka.Message) error {
-		topic := ""
-		if msg.TopicPartition.Topic != nil {
-			topic = *msg.TopicPartition.Topic
+	return c.runLoop(ctx, func(ctx context.Context, msg *Message) error {
+		evt, err := DecodeEvent(schemas, msg)
+		if err != nil {
+			return err
 		}
-		return handle(ctx, topic, msg.TopicPartition.Partition, msg.TopicPartition.Offset, msg.Value)
+		return handle(ctx, evt)
 	})
 }
 
-// runLoop is the unified worker pool implementation
-func (c *Consumer) runLoop(ctx context.Context, handle func(context.Context, *kafka.Message) error) error {
-	type workItem struct {
-		msg *kafka.Message
+// RunMetaLoop runs optimized worker-pool loop with metadata
+func (c *Consumer) RunMetaLoop(ctx context.Context, topics []string, handle func(context.Context, string, int32, int64, []byte) error) error {
+	defer c.Close()
+
+	if err := c.t.subscribe(topics, c.revoked); err != nil {
+		if c.log != nil {
+			c.log.Error("failed to subscribe to topics", "error", err, "topics", topics)
+		}
+		return err
 	}
 
-	workCh := make(chan workItem, c.buffer)
+	return c.runLoop(ctx, func(ctx context.Context, msg *Message) error {
+		return handle(ctx, msg.Topic, msg.Partition, msg.Offset, msg.Value)
+	})
+}
+
+// runLoop is the unified worker pool implementation. Messages are sharded to workers by
+// ordering key, so one key is processed in order while different keys run in parallel.
+// A full worker queue blocks reading (backpressure) instead of dropping messages; offsets
+// are committed periodically up to the last message whose handling finished.
+func (c *Consumer) runLoop(ctx context.Context, handle func(context.Context, *Message) error) error {
+	queueSize := c.buffer / c.workers
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	workChs := make([]chan *job, c.workers)
 	var wg sync.WaitGroup
 
-	// Start workers
-	for i := 0; i < c.workers; i++ {
+	// Start workers, each with its own queue
+	for i := range workChs {
+		workChs[i] = make(chan *job, queueSize)
 		wg.Add(1)
-		go func(workerID int) {
+		go func(workerID int, workCh <-chan *job) {
 			defer wg.Done()
 			for {
 				select {
 				case <-ctx.Done():
 					return
-				case item, ok := <-workCh:
+				case j, ok := <-workCh:
 					if !ok {
 						return
 					}
-					c.processMessage(ctx, item.msg, handle, workerID)
+					// messages queued before their partition was revoked are skipped
+					if !c.offsets.start(j) {
+						continue
+					}
+					c.processMessage(j, handle, workerID)
+					c.offsets.finish(j)
 				}
 			}
-		}(i)
+		}(i, workChs[i])
 	}
 
+	shutdown := func() error {
+		for _, ch := range workChs {
+			close(ch)
+		}
+		wg.Wait()
+		c.commit()
+		return nil
+	}
+
+	commitTicker := time.NewTicker(c.commitT)
+	defer commitTicker.Stop()
+
 	// Message reading loop
 	for {
 		select {
 		case <-ctx.Done():
-			close(workCh)
-			wg.Wait()
-			return nil
+			return shutdown()
+		case <-commitTicker.C:
+			c.commit()
 		default:
-			msg, err := c.c.ReadMessage(100 * time.Millisecond)
+			msg, err := c.t.read(100 * time.Millisecond)
 			if err != nil {
-				if kerr, ok := err.(kafka.Error); !ok || kerr.Code() != kafka.ErrTimedOut {
-					if c.log != nil {
-						c.log.Warn("kafka read error", "error", err)
-					}
+				if c.log != nil {
+					c.log.Warn("kafka read error", "error", err)
 				}
 				continue
 			}
@@ -155,53 +253,182 @@ func (c *Consumer) runLoop(ctx context.Context, handle func(context.Context, *ka
 				continue
 			}
 
+			j := c.offsets.add(ctx, msg)
 			select {
-			case workCh <- workItem{msg: msg}:
+			case workChs[c.shard(msg)] <- j:
 			case <-ctx.Done():
-				close(workCh)
-				wg.Wait()
-				return nil
-			default:
-				if c.log != nil {
-					c.log.Warn("dropping message due to full buffer", "topic", *msg.TopicPartition.Topic)
-				}
+				return shutdown()
 			}
 		}
 	}
 }
 
-// processMessage handles individual message with timeout and error handling
-func (c *Consumer) processMessage(ctx context.Context, msg *kafka.Message, handle func(context.Context, *kafka.Message) error, workerID int) {
-	handleCtx := ctx
-	var cancel func()
-
-	if c.perMsgT > 0 {
-		handleCtx, cancel = context.WithTimeout(ctx, c.perMsgT)
-		defer cancel()
+// shard picks the worker for a message by hashing its ordering key
+func (c *Consumer) shard(m *Message) int {
+	key := ""
+	if c.orderFn != nil {
+		key = c.orderFn(m)
 	}
+	if key == "" {
+		key = DefaultOrderingKey(m)
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(c.workers))
+}
 
-	if err := handle(handleCtx, msg); err != nil {
+// processMessage handles a message with retries and marks it done once it was handled,
+// skipped as duplicate or dead-lettered. Messages interrupted by shutdown or a revoke of
+// their partition stay uncommitted.
+func (c *Consumer) processMessage(j *job, handle func(context.Context, *Message) error, workerID int) {
+	ctx, m := j.part.ctx, j.msg
+	attempts, err := c.handleWithRetry(ctx, m, handle, workerID)
+	for err != nil {
+		if ctx.Err() != nil {
+			return
+		}
 		if c.log != nil {
-			topic := ""
-			if msg.TopicPartition.Topic != nil {
-				topic = *msg.TopicPartition.Topic
-			}
 			c.log.Error("message processing failed",
 				"error", err,
-				"topic", topic,
-				"partition", msg.TopicPartition.Partition,
-				"offset", msg.TopicPartition.Offset,
+				"topic", m.Topic,
+				"partition", m.Partition,
+				"offset", m.Offset,
+				"attempts", attempts,
 				"worker_id", workerID)
 		}
-	} else if c.log != nil {
-		topic := ""
-		if msg.TopicPartition.Topic != nil {
-			topic = *msg.TopicPartition.Topic
+		if c.dlq != nil {
+			if !c.deadLetter(ctx, m, attempts, err) {
+				return
+			}
+			break
+		}
+		// Without a dead-letter topic a failed message is never committed; retry it after a pause
+		if c.log != nil {
+			c.log.Error("no dead-letter publisher, retrying failed message", "topic", m.Topic, "partition", m.Partition, "offset", m.Offset)
 		}
+		if !sleep(ctx, c.retry.MaxBackoff) {
+			return
+		}
+		var more int
+		more, err = c.handleWithRetry(ctx, m, handle, workerID)
+		attempts += more
+	}
+	if err == nil && c.log != nil {
 		c.log.Debug("message processed successfully",
-			"topic", topic,
-			"partition", msg.TopicPartition.Partition,
-			"offset", msg.TopicPartition.Offset,
+			"topic", m.Topic,
+			"partition", m.Partition,
+			"offset", m.Offset,
 			"worker_id", workerID)
 	}
+	c.offsets.done(j)
+}
+
+// handleWithRetry runs the handler until it succeeds, fails permanently or runs out of attempts
+func (c *Consumer) handleWithRetry(ctx context.Context, m *Message, handle func(context.Context, *Message) error, workerID int) (int, error) {
+	for attempt := 1; ; attempt++ {
+		err := c.handleOnce(ctx, m, handle, workerID)
+		if err == nil || IsPermanent(err) || attempt >= c.retry.MaxAttempts || ctx.Err() != nil {
+			return attempt, err
+		}
+		delay := c.retry.backoff(attempt)
+		if c.log != nil {
+			c.log.Warn("message processing failed, retrying",
+				"error", err,
+				"topic", m.Topic,
+				"offset", m.Offset,
+				"attempt", attempt,
+				"backoff", delay)
+		}
+		if !sleep(ctx, delay) {
+			return attempt, err
+		}
+	}
+}
+
+// sleep waits for d and reports false if ctx ended first
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// handleOnce runs one handler attempt with timeout and idempotency; duplicates count as handled
+func (c *Consumer) handleOnce(ctx context.Context, m *Message, handle func(context.Context, *Message) error, workerID int) error {
+	handleCtx := ctx
+	if env, ok := EnvelopeFromHeaders(m.Headers); ok {
+		handleCtx = ContextWithEnvelope(handleCtx, env)
+	}
+	if c.perMsgT > 0 {
+		var cancel func()
+		handleCtx, cancel = context.WithTimeout(handleCtx, c.perMsgT)
+		defer cancel()
+	}
+
+	run := func(ctx context.Context) error { return handle(ctx, m) }
+	var err error
+	if c.idem != nil {
+		err = c.idem.do(handleCtx, m, run)
+	} else {
+		err = run(handleCtx)
+	}
+
+	var dup errDuplicate
+	if errors.As(err, &dup) {
+		if c.log != nil {
+			c.log.Debug("duplicate message skipped", "key", dup.key, "worker_id", workerID)
+		}
+		return nil
+	}
+	return err
+}
+
+// deadLetter writes a failed message to its dead-letter topic, waiting for the broker ack and
+// retrying failed writes with backoff. It reports whether the message may be committed, false
+// only when ctx ended before the write succeeded.
+func (c *Consumer) deadLetter(ctx context.Context, m *Message, attempts int, cause error) bool {
+	dl := deadLetterMessage(m, c.groupID, attempts, cause, time.Now())
+	for attempt := 1; ; attempt++ {
+		err := c.dlq.PublishSync(ctx, dl)
+		if err == nil {
+			break
+		}
+		if c.log != nil {
+			c.log.Error("dead-letter publish failed, message left uncommitted", "error", err, "topic", dl.Topic, "offset", m.Offset, "attempt", attempt)
+		}
+		if !sleep(ctx, c.retry.backoff(attempt)) {
+			return false
+		}
+	}
+	if c.log != nil {
+		c.log.Warn("message dead-lettered", "topic", dl.Topic, "offset", m.Offset, "attempts", attempts)
+	}
+	return true
+}
+
+// commit commits offsets of finished messages
+func (c *Consumer) commit() {
+	offsets := c.offsets.committable()
+	if len(offsets) == 0 {
+		return
+	}
+	if err := c.t.commit(offsets); err != nil {
+		if c.log != nil {
+			c.log.Warn("offset commit failed", "error", err)
+		}
+		return
+	}
+	c.offsets.committed(offsets)
+}
+
+// revoked stops work of partitions moving to another group member and commits what finished
+// before acknowledging the revoke; skipped and cancelled messages are redelivered to the new owner
+func (c *Consumer) revoked(partitions []partitionKey) {
+	c.offsets.revoke(partitions)
+	c.commit()
+	c.offsets.forget(partitions)
 }
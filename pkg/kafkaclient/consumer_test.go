@@ -0,0 +1,157 @@
+package kafkaclient
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConsumerRevokeStopsQueuedWork(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tr := &scriptedTransport{revokeDone: make(chan struct{})}
+	for o := int64(0); o < 3; o++ {
+		tr.push(&Message{Topic: "orders", Partition: 0, Offset: o})
+	}
+	c := newConsumer(ConsumerConfig{Workers: 1, BufferSize: 8, CommitInterval: time.Hour}.withDefaults(), tr)
+
+	started := make(chan struct{})
+	var mu sync.Mutex
+	var handled []partitionOffset
+	handle := func(ctx context.Context, _ string, partition int32, offset int64, _ []byte) error {
+		if partition == 0 && offset == 0 {
+			close(started)
+			<-ctx.Done() // a slow handler, interrupted by the revoke
+			return ctx.Err()
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, partitionOffset{partitionKey: partitionKey{"orders", partition}, offset: offset})
+		return nil
+	}
+	loopDone := make(chan error, 1)
+	go func() { loopDone <- c.RunMetaLoop(ctx, []string{"orders"}, handle) }()
+
+	// revoke while offset 0 is handled and offsets 1 and 2 are queued behind it
+	wait(ctx, t, "first message started", func() bool {
+		select {
+		case <-started:
+			return c.offsets.dispatched(partitionKey{"orders", 0}) == 3
+		default:
+			return false
+		}
+	})
+	tr.revokeNext([]partitionKey{{"orders", 0}})
+	select {
+	case <-tr.revokeDone:
+	case <-ctx.Done():
+		t.Fatal("revoke was not acknowledged")
+	}
+	if got := tr.committed(); len(got) != 0 {
+		t.Errorf("committed %v on revoke, want nothing", got)
+	}
+
+	// a message of a partition still owned runs after the skipped ones on the same worker
+	tr.push(&Message{Topic: "orders", Partition: 1, Offset: 0})
+	wait(ctx, t, "message of orders/1 handled", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(handled) > 0
+	})
+	cancel()
+	<-loopDone
+
+	want := []partitionOffset{{partitionKey: partitionKey{"orders", 1}, offset: 0}}
+	if !reflect.DeepEqual(handled, want) {
+		t.Errorf("handled %v, want only %v", handled, want)
+	}
+	if got := tr.committed(); !reflect.DeepEqual(got, []partitionOffset{{partitionKey: partitionKey{"orders", 1}, offset: 1}}) {
+		t.Errorf("committed %v, want orders/1 at 1", got)
+	}
+}
+
+func wait(ctx context.Context, t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for !cond() {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for %s", what)
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+// dispatched returns the number of unfinished messages of a partition
+func (t *offsetTracker) dispatched(key partitionKey) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if p, ok := t.parts[key]; ok {
+		return len(p.inflight)
+	}
+	return 0
+}
+
+// scriptedTransport hands out pushed messages and revokes partitions on request
+type scriptedTransport struct {
+	mu         sync.Mutex
+	pending    []*Message
+	revoke     []partitionKey
+	onRevoke   func([]partitionKey)
+	revokeDone chan struct{}
+	commits    []partitionOffset
+}
+
+func (s *scriptedTransport) push(m *Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, m)
+}
+
+func (s *scriptedTransport) revokeNext(keys []partitionKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revoke = keys
+}
+
+func (s *scriptedTransport) committed() []partitionOffset {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]partitionOffset(nil), s.commits...)
+}
+
+func (s *scriptedTransport) subscribe(_ []string, onRevoke func([]partitionKey)) error {
+	s.onRevoke = onRevoke
+	return nil
+}
+
+func (s *scriptedTransport) read(time.Duration) (*Message, error) {
+	s.mu.Lock()
+	if keys := s.revoke; keys != nil {
+		s.revoke = nil
+		s.mu.Unlock()
+		s.onRevoke(keys)
+		close(s.revokeDone)
+		return nil, nil
+	}
+	if len(s.pending) > 0 {
+		m := s.pending[0]
+		s.pending = s.pending[1:]
+		s.mu.Unlock()
+		return m, nil
+	}
+	s.mu.Unlock()
+	time.Sleep(time.Millisecond)
+	return nil, nil
+}
+
+func (s *scriptedTransport) commit(offsets []partitionOffset) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commits = append(s.commits, offsets...)
+	return nil
+}
+
+func (s *scriptedTransport) close() error { return nil }
@@ -0,0 +1,49 @@
+package kafkaclient
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// DeadLetterSuffix is appended to the source topic to name its dead-letter topic
+const DeadLetterSuffix = ".dlq"
+
+// Headers describing why a message was dead-lettered
+const (
+	HeaderDLQError         = "dlq_error"
+	HeaderDLQOriginalTopic = "dlq_original_topic"
+	HeaderDLQPartition     = "dlq_original_partition"
+	HeaderDLQOffset        = "dlq_original_offset"
+	HeaderDLQAttempts      = "dlq_attempts"
+	HeaderDLQConsumerGroup = "dlq_consumer_group"
+	HeaderDLQFailedAt      = "dlq_failed_at"
+)
+
+// DeadLetterPublisher receives messages whose handler failed for good. The source offset is
+// committed once PublishSync returns, so it must only return after the broker acknowledged
+// the write.
+type DeadLetterPublisher interface {
+	PublishSync(ctx context.Context, msg *Message) error
+}
+
+// deadLetterMessage copies msg to its dead-letter topic, keeping key and headers and adding failure details
+func deadLetterMessage(msg *Message, group string, attempts int, cause error, now time.Time) *Message {
+	headers := make(map[string]string, len(msg.Headers)+7)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[HeaderDLQError] = cause.Error()
+	headers[HeaderDLQOriginalTopic] = msg.Topic
+	headers[HeaderDLQPartition] = strconv.FormatInt(int64(msg.Partition), 10)
+	headers[HeaderDLQOffset] = strconv.FormatInt(msg.Offset, 10)
+	headers[HeaderDLQAttempts] = strconv.Itoa(attempts)
+	headers[HeaderDLQConsumerGroup] = group
+	headers[HeaderDLQFailedAt] = now.UTC().Format(time.RFC3339)
+	return &Message{
+		Topic:   msg.Topic + DeadLetterSuffix,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+}
@@ -0,0 +1,121 @@
+package kafkaclient
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+)
+
+// Envelope headers carried by every published event
+const (
+	HeaderEventID       = "event_id"
+	HeaderEventType     = "event_type"
+	HeaderSchemaVersion = "schema_version"
+	HeaderCorrelationID = "correlation_id"
+	HeaderCausationID   = "causation_id"
+	HeaderProducer      = "producer"
+	HeaderOccurredAt    = "occurred_at"
+)
+
+// DefaultSchemaVersion is the schema version of events that do not set one
+const DefaultSchemaVersion = 1
+
+// Envelope describes an event independently of its payload. CorrelationID is shared by
+// all events of one business flow; CausationID is the ID of the event that caused this one.
+type Envelope struct {
+	EventID       string
+	EventType     string
+	SchemaVersion int
+	CorrelationID string
+	CausationID   string
+	Producer      string
+	OccurredAt    time.Time
+}
+
+type envelopeCtxKey struct{}
+
+// ContextWithEnvelope returns ctx carrying env as the event being handled
+func ContextWithEnvelope(ctx context.Context, env Envelope) context.Context {
+	return context.WithValue(ctx, envelopeCtxKey{}, env)
+}
+
+// EnvelopeFromContext returns the envelope of the event being handled, if any
+func EnvelopeFromContext(ctx context.Context) (Envelope, bool) {
+	env, ok := ctx.Value(envelopeCtxKey{}).(Envelope)
+	return env, ok
+}
+
+// NewEnvelope creates an envelope for a new event. When ctx carries the event being handled,
+// the new event joins its correlation and records it as cause; otherwise it starts a new flow.
+func NewEnvelope(ctx context.Context, eventType, producer string) Envelope {
+	env := Envelope{
+		EventID:       uuid.New().String(),
+		EventType:     eventType,
+		SchemaVersion: DefaultSchemaVersion,
+		Producer:      producer,
+		OccurredAt:    time.Now().UTC(),
+	}
+	env.CorrelationID = env.EventID
+	if parent, ok := EnvelopeFromContext(ctx); ok {
+		env.CausationID = parent.EventID
+		if parent.CorrelationID != "" {
+			env.CorrelationID = parent.CorrelationID
+		}
+	}
+	return env
+}
+
+// Headers encodes the envelope as message headers
+func (e Envelope) Headers() map[string]string {
+	h := map[string]string{
+		HeaderEventID:       e.EventID,
+		HeaderEventType:     e.EventType,
+		HeaderSchemaVersion: strconv.Itoa(e.SchemaVersion),
+		HeaderCorrelationID: e.CorrelationID,
+		HeaderProducer:      e.Producer,
+		HeaderOccurredAt:    e.OccurredAt.UTC().Format(time.RFC3339Nano),
+	}
+	if e.CausationID != "" {
+		h[HeaderCausationID] = e.CausationID
+	}
+	return h
+}
+
+// EnvelopeFromHeaders decodes an envelope; ok is false for messages published without one
+func EnvelopeFromHeaders(h map[string]string) (env Envelope, ok bool) {
+	env.EventID = h[HeaderEventID]
+	if env.EventID == "" {
+		return Envelope{}, false
+	}
+	env.EventType = h[HeaderEventType]
+	env.SchemaVersion = DefaultSchemaVersion
+	if v, err := strconv.Atoi(h[HeaderSchemaVersion]); err == nil {
+		env.SchemaVersion = v
+	}
+	env.CorrelationID = h[HeaderCorrelationID]
+	env.CausationID = h[HeaderCausationID]
+	env.Producer = h[HeaderProducer]
+	if t, err := time.Parse(time.RFC3339Nano, h[HeaderOccurredAt]); err == nil {
+		env.OccurredAt = t
+	}
+	return env, true
+}
+
+// EventMessage builds a message keyed by aggregateID with env as headers
+func EventMessage(topic, aggregateID string, env Envelope, value []byte) *Message {
+	return &Message{Topic: topic, Key: []byte(aggregateID), Value: value, Headers: env.Headers()}
+}
+
+// EncodeEvent marshals evt into a message keyed by aggregateID, with a new envelope
+// typed by the protobuf message name and derived from the event handled in ctx
+func EncodeEvent(ctx context.Context, topic, aggregateID, producer string, evt proto.Message) (*Message, error) {
+	value, err := proto.Marshal(evt)
+	if err != nil {
+		return nil, err
+	}
+	env := NewEnvelope(ctx, string(proto.MessageName(evt)), producer)
+	return EventMessage(topic, aggregateID, env, value), nil
+}
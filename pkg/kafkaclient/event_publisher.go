@@ -0,0 +1,112 @@
+package kafkaclient
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// EventPublisher publishes protobuf events to the topic routed for their message type
+type EventPublisher interface {
+	// Publish queues evt for delivery
+	Publish(ctx context.Context, evt proto.Message) error
+	// PublishSync publishes evt and waits for the broker ack
+	PublishSync(ctx context.Context, evt proto.Message) error
+}
+
+// EventRoute is where events of one message type go and how they are keyed
+type EventRoute struct {
+	Topic string
+	key   func(proto.Message) string
+}
+
+// EventRoutes maps protobuf message types to topics and aggregate keys
+type EventRoutes struct {
+	routes map[protoreflect.FullName]EventRoute
+}
+
+// NewEventRoutes creates an empty routing table
+func NewEventRoutes() *EventRoutes {
+	return &EventRoutes{routes: make(map[protoreflect.FullName]EventRoute)}
+}
+
+// Route sends events of type T to topic, keyed by the aggregate ID returned by key
+func Route[T proto.Message](r *EventRoutes, topic string, key func(T) string) *EventRoutes {
+	var zero T
+	r.routes[zero.ProtoReflect().Descriptor().FullName()] = EventRoute{
+		Topic: topic,
+		key:   func(m proto.Message) string { return key(m.(T)) },
+	}
+	return r
+}
+
+// Lookup returns the route and aggregate key of evt
+func (r *EventRoutes) Lookup(evt proto.Message) (EventRoute, string, error) {
+	name := evt.ProtoReflect().Descriptor().FullName()
+	route, ok := r.routes[name]
+	if !ok {
+		return EventRoute{}, "", fmt.Errorf("no topic routed for event %s", name)
+	}
+	return route, route.key(evt), nil
+}
+
+// ProtoEventPublisher implements EventPublisher on top of a Publisher
+type ProtoEventPublisher struct {
+	base     Publisher
+	producer string
+	routes   *EventRoutes
+	schemas  Schemas
+}
+
+// NewProtoEventPublisher creates an event publisher recording producer in every envelope
+func NewProtoEventPublisher(base Publisher, producer string, routes *EventRoutes) *ProtoEventPublisher {
+	return &ProtoEventPublisher{base: base, producer: producer, routes: routes}
+}
+
+// WithSchemas stamps each event with the schema version registered for its type and topic;
+// without schemas every event is published as DefaultSchemaVersion
+func (p *ProtoEventPublisher) WithSchemas(s Schemas) *ProtoEventPublisher {
+	p.schemas = s
+	return p
+}
+
+// Publish marshals evt with an envelope and queues it on its routed topic
+func (p *ProtoEventPublisher) Publish(ctx context.Context, evt proto.Message) error {
+	msg, err := p.encode(ctx, evt)
+	if err != nil {
+		return err
+	}
+	return p.base.PublishMessage(ctx, msg)
+}
+
+// PublishSync marshals evt with an envelope and waits until the broker acknowledged it
+func (p *ProtoEventPublisher) PublishSync(ctx context.Context, evt proto.Message) error {
+	msg, err := p.encode(ctx, evt)
+	if err != nil {
+		return err
+	}
+	return p.base.PublishSync(ctx, msg)
+}
+
+func (p *ProtoEventPublisher) encode(ctx context.Context, evt proto.Message) (*Message, error) {
+	route, key, err := p.routes.Lookup(evt)
+	if err != nil {
+		return nil, err
+	}
+	if p.schemas == nil {
+		return EncodeEvent(ctx, route.Topic, key, p.producer, evt)
+	}
+	version, err := p.schemas.Version(route.Topic, evt)
+	if err != nil {
+		return nil, err
+	}
+	value, err := proto.Marshal(evt)
+	if err != nil {
+		return nil, err
+	}
+	env := NewEnvelope(ctx, string(proto.MessageName(evt)), p.producer)
+	env.SchemaVersion = version
+	return EventMessage(route.Topic, key, env, value), nil
+}
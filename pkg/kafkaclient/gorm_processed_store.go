@@ -0,0 +1,69 @@
+package kafkaclient
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	processedStatusProcessing = "PROCESSING"
+	processedStatusDone       = "DONE"
+)
+
+// ProcessedMessage is a row of the processed-message ledger
+type ProcessedMessage struct {
+	Key         string    `gorm:"primaryKey;type:varchar(512)"`
+	Status      string    `gorm:"type:varchar(16);not null"`
+	LockedUntil time.Time `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"not null"`
+	ProcessedAt *time.Time
+}
+
+func (ProcessedMessage) TableName() string { return "processed_messages" }
+
+// GormProcessedStore implements ProcessedStore on Postgres
+type GormProcessedStore struct {
+	db  *gorm.DB
+	now func() time.Time
+}
+
+// NewGormProcessedStore creates a ledger on the given database
+func NewGormProcessedStore(db *gorm.DB) *GormProcessedStore {
+	return &GormProcessedStore{db: db, now: time.Now}
+}
+
+// AutoMigrate creates the ledger table
+func (s *GormProcessedStore) AutoMigrate() error {
+	return s.db.AutoMigrate(&ProcessedMessage{})
+}
+
+// Begin inserts a claim, or takes over a claim whose lease expired; completed keys are never claimed again
+func (s *GormProcessedStore) Begin(ctx context.Context, key string, lease time.Duration) (bool, error) {
+	now := s.now()
+	res := s.db.WithContext(ctx).Exec(`
+INSERT INTO processed_messages (key, status, locked_until, created_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (key) DO UPDATE SET locked_until = EXCLUDED.locked_until
+WHERE processed_messages.status = ? AND processed_messages.locked_until < ?`,
+		key, processedStatusProcessing, now.Add(lease), now,
+		processedStatusProcessing, now)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (s *GormProcessedStore) Complete(ctx context.Context, key string) error {
+	now := s.now()
+	return s.db.WithContext(ctx).Model(&ProcessedMessage{}).
+		Where("key = ?", key).
+		Updates(map[string]interface{}{"status": processedStatusDone, "processed_at": now}).Error
+}
+
+func (s *GormProcessedStore) Abort(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).
+		Where("key = ? AND status = ?", key, processedStatusProcessing).
+		Delete(&ProcessedMessage{}).Error
+}
@@ -0,0 +1,76 @@
+package kafkaclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Message is a transport-neutral message as published to and consumed from a Bus
+type Message struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   map[string]string
+}
+
+// KeyFunc derives the idempotency key of a message; an empty key disables deduplication for it
+type KeyFunc func(msg *Message) string
+
+// DefaultKeyFunc uses the envelope event ID, falling back to the message position,
+// which still catches redelivery of the same offset after a rebalance or restart
+func DefaultKeyFunc(msg *Message) string {
+	if id := msg.Headers[HeaderEventID]; id != "" {
+		return id
+	}
+	return fmt.Sprintf("%s/%d/%d", msg.Topic, msg.Partition, msg.Offset)
+}
+
+// ProcessedStore is a ledger of processed message keys shared by all consumer replicas
+type ProcessedStore interface {
+	// Begin claims key for processing for at most lease. It returns false when the key
+	// was already processed or another consumer holds an unexpired claim.
+	Begin(ctx context.Context, key string, lease time.Duration) (bool, error)
+	// Complete marks key as processed for good
+	Complete(ctx context.Context, key string) error
+	// Abort drops the claim so a redelivery can process the message again
+	Abort(ctx context.Context, key string) error
+}
+
+// idempotency wraps handlers so each key is processed at most once per consumer group
+type idempotency struct {
+	store ProcessedStore
+	keyFn KeyFunc
+	lease time.Duration
+	scope string
+}
+
+// errDuplicate marks a message skipped because its key was already processed
+type errDuplicate struct{ key string }
+
+func (d errDuplicate) Error() string { return "duplicate message " + d.key }
+
+// do runs fn unless msg was already processed; fn errors abort the claim so the message can be retried
+func (i *idempotency) do(ctx context.Context, msg *Message, fn func(context.Context) error) error {
+	key := i.keyFn(msg)
+	if key == "" {
+		return fn(ctx)
+	}
+	key = i.scope + "|" + key
+	ok, err := i.store.Begin(ctx, key, i.lease)
+	if err != nil {
+		return fmt.Errorf("idempotency begin %s: %w", key, err)
+	}
+	if !ok {
+		return errDuplicate{key: key}
+	}
+	if err := fn(ctx); err != nil {
+		if aerr := i.store.Abort(context.WithoutCancel(ctx), key); aerr != nil {
+			return fmt.Errorf("%w (idempotency abort failed: %v)", err, aerr)
+		}
+		return err
+	}
+	return i.store.Complete(context.WithoutCancel(ctx), key)
+}
@@ -0,0 +1,13 @@
+//go:build !cgo
+
+package kafkaclient
+
+// newKafkaPublisher reports that the Kafka transport is not compiled in
+func newKafkaPublisher(PublisherConfig) (Publisher, error) {
+	return nil, ErrKafkaUnavailable
+}
+
+// newKafkaTransport reports that the Kafka transport is not compiled in
+func newKafkaTransport(ConsumerConfig) (consumerTransport, error) {
+	return nil, ErrKafkaUnavailable
+}
@@ -0,0 +1,91 @@
+//go:build cgo
+
+package kafkaclient
+
+import (
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+)
+
+// kafkaTransport reads from Kafka through confluent-kafka-go
+type kafkaTransport struct {
+	c *kafka.Consumer
+}
+
+func newKafkaTransport(config ConsumerConfig) (*kafkaTransport, error) {
+	c, err := kafka.NewConsumer(&kafka.ConfigMap{
+		"bootstrap.servers":  config.BootstrapServers,
+		"group.id":           config.GroupID,
+		"auto.offset.reset":  config.AutoOffsetReset,
+		"enable.auto.commit": false, // offsets are committed after handlers succeed
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &kafkaTransport{c: c}, nil
+}
+
+func (t *kafkaTransport) subscribe(topics []string, onRevoke func([]partitionKey)) error {
+	return t.c.SubscribeTopics(topics, func(_ *kafka.Consumer, ev kafka.Event) error {
+		if e, ok := ev.(kafka.RevokedPartitions); ok {
+			keys := make([]partitionKey, 0, len(e.Partitions))
+			for _, tp := range e.Partitions {
+				keys = append(keys, partitionKey{topic: topicOf(tp), partition: tp.Partition})
+			}
+			onRevoke(keys)
+		}
+		return nil
+	})
+}
+
+func (t *kafkaTransport) read(timeout time.Duration) (*Message, error) {
+	msg, err := t.c.ReadMessage(timeout)
+	if err != nil {
+		if kerr, ok := err.(kafka.Error); ok && kerr.Code() == kafka.ErrTimedOut {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if msg == nil {
+		return nil, nil
+	}
+	return toMessage(msg), nil
+}
+
+func (t *kafkaTransport) commit(offsets []partitionOffset) error {
+	tps := make([]kafka.TopicPartition, 0, len(offsets))
+	for _, po := range offsets {
+		topic := po.topic
+		tps = append(tps, kafka.TopicPartition{Topic: &topic, Partition: po.partition, Offset: kafka.Offset(po.offset)})
+	}
+	_, err := t.c.CommitOffsets(tps)
+	return err
+}
+
+func (t *kafkaTransport) close() error {
+	return t.c.Close()
+}
+
+// toMessage converts a confluent message to the transport-neutral Message
+func toMessage(msg *kafka.Message) *Message {
+	out := &Message{
+		Topic:     topicOf(msg.TopicPartition),
+		Partition: msg.TopicPartition.Partition,
+		Offset:    int64(msg.TopicPartition.Offset),
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Headers:   make(map[string]string, len(msg.Headers)),
+	}
+	for _, h := range msg.Headers {
+		out.Headers[h.Key] = string(h.Value)
+	}
+	return out
+}
+
+func topicOf(tp kafka.TopicPartition) string {
+	if tp.Topic == nil {
+		return ""
+	}
+	return *tp.Topic
+}
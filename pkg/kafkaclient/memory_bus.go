@@ -0,0 +1,316 @@
+package kafkaclient
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/logger"
+)
+
+// MemoryBus is an in-process Bus with the consumer-group semantics of Kafka. Each topic has
+// a fixed number of partitions; keyed messages always land on the same partition. Partitions
+// are spread over the members of a group, and a partition that changes owner is consumed
+// from the group's committed offset, so uncommitted messages are redelivered.
+type MemoryBus struct {
+	mu         sync.Mutex
+	partitions int
+	topics     map[string]*memoryTopic
+	groups     map[string]*memoryGroup
+	changed    chan struct{}
+}
+
+type memoryTopic struct {
+	logs [][]*Message
+	next int // round-robin partition for unkeyed messages
+}
+
+type memoryGroup struct {
+	committed map[partitionKey]int64
+	members   []*memoryMember
+}
+
+// NewMemoryBus creates a bus whose topics have the given number of partitions (default 4)
+func NewMemoryBus(partitions int) *MemoryBus {
+	if partitions <= 0 {
+		partitions = 4
+	}
+	return &MemoryBus{
+		partitions: partitions,
+		topics:     make(map[string]*memoryTopic),
+		groups:     make(map[string]*memoryGroup),
+		changed:    make(chan struct{}),
+	}
+}
+
+// NewPublisher creates a publisher appending to the bus
+func (b *MemoryBus) NewPublisher(PublisherConfig) (Publisher, error) {
+	return &memoryBusPublisher{bus: b}, nil
+}
+
+// NewConsumer creates a consumer that joins config.GroupID when it starts running
+func (b *MemoryBus) NewConsumer(config ConsumerConfig) (*Consumer, error) {
+	config = config.withDefaults()
+	m := &memoryMember{bus: b, group: config.GroupID, reset: config.AutoOffsetReset, positions: make(map[partitionKey]int64)}
+	return newConsumer(config, m), nil
+}
+
+// Messages returns the messages published to topic, ordered by partition and offset
+func (b *MemoryBus) Messages(topic string) []*Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []*Message
+	if t, ok := b.topics[topic]; ok {
+		for _, log := range t.logs {
+			out = append(out, log...)
+		}
+	}
+	return out
+}
+
+// append stores msg on its partition and wakes up waiting consumers
+func (b *MemoryBus) append(msg *Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t := b.topic(msg.Topic)
+	partition := t.next % len(t.logs)
+	if len(msg.Key) > 0 {
+		h := fnv.New32a()
+		_, _ = h.Write(msg.Key)
+		partition = int(h.Sum32() % uint32(len(t.logs)))
+	} else {
+		t.next++
+	}
+	stored := &Message{
+		Topic:     msg.Topic,
+		Partition: int32(partition),
+		Offset:    int64(len(t.logs[partition])),
+		Key:       append([]byte(nil), msg.Key...),
+		Value:     append([]byte(nil), msg.Value...),
+		Headers:   make(map[string]string, len(msg.Headers)),
+	}
+	for k, v := range msg.Headers {
+		stored.Headers[k] = v
+	}
+	t.logs[partition] = append(t.logs[partition], stored)
+	b.notify()
+}
+
+// topic returns the topic, creating it on first use; b.mu must be held
+func (b *MemoryBus) topic(name string) *memoryTopic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &memoryTopic{logs: make([][]*Message, b.partitions)}
+		b.topics[name] = t
+	}
+	return t
+}
+
+// notify wakes up all readers waiting for a change; b.mu must be held
+func (b *MemoryBus) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// rebalance spreads the partitions of the group's topics over its members; b.mu must be held
+func (b *MemoryBus) rebalance(g *memoryGroup) {
+	owners := make(map[partitionKey]*memoryMember)
+	var topics []string
+	seen := make(map[string]bool)
+	for _, m := range g.members {
+		for _, name := range m.topics {
+			if !seen[name] {
+				seen[name] = true
+				topics = append(topics, name)
+			}
+		}
+	}
+	sort.Strings(topics)
+	for _, name := range topics {
+		var subscribers []*memoryMember
+		for _, m := range g.members {
+			if m.subscribed(name) {
+				subscribers = append(subscribers, m)
+			}
+		}
+		for p := range b.topic(name).logs {
+			owners[partitionKey{topic: name, partition: int32(p)}] = subscribers[p%len(subscribers)]
+		}
+	}
+
+	for _, m := range g.members {
+		for key := range m.positions {
+			if owners[key] != m {
+				delete(m.positions, key)
+				m.revoked = append(m.revoked, key)
+			}
+		}
+	}
+	for key, m := range owners {
+		if _, ok := m.positions[key]; ok {
+			continue
+		}
+		if off, ok := g.committed[key]; ok {
+			m.positions[key] = off
+		} else if m.reset == "latest" {
+			m.positions[key] = int64(len(b.topics[key.topic].logs[key.partition]))
+		} else {
+			m.positions[key] = 0
+		}
+	}
+	b.notify()
+}
+
+// memoryMember is the consumerTransport of a MemoryBus consumer
+type memoryMember struct {
+	bus       *MemoryBus
+	group     string
+	reset     string
+	topics    []string
+	positions map[partitionKey]int64 // assigned partitions and their next offset
+	revoked   []partitionKey
+	onRevoke  func([]partitionKey)
+	joined    bool
+}
+
+func (m *memoryMember) subscribed(topic string) bool {
+	for _, t := range m.topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *memoryMember) subscribe(topics []string, onRevoke func([]partitionKey)) error {
+	b := m.bus
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if m.joined {
+		return errors.New("consumer already subscribed")
+	}
+	m.topics = append([]string(nil), topics...)
+	m.onRevoke = onRevoke
+	m.joined = true
+	g, ok := b.groups[m.group]
+	if !ok {
+		g = &memoryGroup{committed: make(map[partitionKey]int64)}
+		b.groups[m.group] = g
+	}
+	g.members = append(g.members, m)
+	b.rebalance(g)
+	return nil
+}
+
+func (m *memoryMember) read(timeout time.Duration) (*Message, error) {
+	b := m.bus
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		b.mu.Lock()
+		if revoked := m.revoked; len(revoked) > 0 {
+			m.revoked = nil
+			b.mu.Unlock()
+			m.onRevoke(revoked)
+			continue
+		}
+		if msg := m.next(); msg != nil {
+			b.mu.Unlock()
+			return msg, nil
+		}
+		changed := b.changed
+		b.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			return nil, nil
+		}
+	}
+}
+
+// next returns an unread message of an assigned partition; b.mu must be held.
+// Map iteration order is random, which spreads reads over partitions.
+func (m *memoryMember) next() *Message {
+	for key, pos := range m.positions {
+		log := m.bus.topics[key.topic].logs[key.partition]
+		if pos < int64(len(log)) {
+			m.positions[key]++
+			out := *log[pos]
+			return &out
+		}
+	}
+	return nil
+}
+
+func (m *memoryMember) commit(offsets []partitionOffset) error {
+	b := m.bus
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	g, ok := b.groups[m.group]
+	if !ok {
+		return errors.New("consumer not subscribed")
+	}
+	for _, po := range offsets {
+		if po.offset > g.committed[po.partitionKey] {
+			g.committed[po.partitionKey] = po.offset
+		}
+	}
+	return nil
+}
+
+func (m *memoryMember) close() error {
+	b := m.bus
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !m.joined {
+		return nil
+	}
+	m.joined = false
+	g := b.groups[m.group]
+	for i, member := range g.members {
+		if member == m {
+			g.members = append(g.members[:i], g.members[i+1:]...)
+			break
+		}
+	}
+	m.positions = make(map[partitionKey]int64)
+	m.revoked = nil
+	b.rebalance(g)
+	return nil
+}
+
+// memoryBusPublisher implements Publisher on a MemoryBus; appends are acknowledged immediately
+type memoryBusPublisher struct {
+	bus *MemoryBus
+	log logger.Logger
+}
+
+func (p *memoryBusPublisher) Publish(ctx context.Context, topic string, value []byte) error {
+	return p.PublishMessage(ctx, &Message{Topic: topic, Value: value})
+}
+
+func (p *memoryBusPublisher) PublishMessage(ctx context.Context, msg *Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p.bus.append(msg)
+	if p.log != nil {
+		p.log.Debug("message appended to memory bus", "topic", msg.Topic, "size", len(msg.Value))
+	}
+	return nil
+}
+
+func (p *memoryBusPublisher) PublishSync(ctx context.Context, msg *Message) error {
+	return p.PublishMessage(ctx, msg)
+}
+
+func (p *memoryBusPublisher) WithLogger(l logger.Logger) Publisher {
+	p.log = l
+	return p
+}
+
+func (p *memoryBusPublisher) Close() error { return nil }
@@ -0,0 +1,58 @@
+package kafkaclient
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryClaim struct {
+	done        bool
+	lockedUntil time.Time
+}
+
+// MemoryProcessedStore implements ProcessedStore in memory for tests and single-replica runs
+type MemoryProcessedStore struct {
+	mu     sync.Mutex
+	claims map[string]memoryClaim
+	now    func() time.Time
+}
+
+// NewMemoryProcessedStore creates an empty in-memory ledger
+func NewMemoryProcessedStore() *MemoryProcessedStore {
+	return &MemoryProcessedStore{claims: make(map[string]memoryClaim), now: time.Now}
+}
+
+func (m *MemoryProcessedStore) Begin(ctx context.Context, key string, lease time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	if c, ok := m.claims[key]; ok && (c.done || c.lockedUntil.After(now)) {
+		return false, nil
+	}
+	m.claims[key] = memoryClaim{lockedUntil: now.Add(lease)}
+	return true, nil
+}
+
+func (m *MemoryProcessedStore) Complete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.claims[key] = memoryClaim{done: true}
+	return nil
+}
+
+func (m *MemoryProcessedStore) Abort(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok := m.claims[key]; ok && !c.done {
+		delete(m.claims, key)
+	}
+	return nil
+}
+
+// Processed reports whether key was completed
+func (m *MemoryProcessedStore) Processed(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.claims[key].done
+}
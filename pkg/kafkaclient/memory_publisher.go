@@ -0,0 +1,86 @@
+package kafkaclient
+
+import (
+	"context"
+	"sync"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/logger"
+)
+
+// PublishedMessage is a message recorded by MemoryPublisher
+type PublishedMessage struct {
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+}
+
+// MemoryPublisher implements Publisher in memory for tests and local runs
+type MemoryPublisher struct {
+	mu       sync.Mutex
+	messages []PublishedMessage
+	err      error
+	log      logger.Logger
+}
+
+// NewMemoryPublisher creates an empty in-memory publisher
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+// WithLogger sets logger for publisher
+func (m *MemoryPublisher) WithLogger(l logger.Logger) Publisher {
+	m.log = l
+	return m
+}
+
+// FailWith makes subsequent Publish calls return err; nil restores normal behaviour
+func (m *MemoryPublisher) FailWith(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+}
+
+// Publish records the message unless a failure is configured
+func (m *MemoryPublisher) Publish(ctx context.Context, topic string, value []byte) error {
+	return m.PublishMessage(ctx, &Message{Topic: topic, Value: value})
+}
+
+// PublishMessage records the message with key and headers unless a failure is configured
+func (m *MemoryPublisher) PublishMessage(ctx context.Context, msg *Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	rec := PublishedMessage{Topic: msg.Topic, Key: append([]byte(nil), msg.Key...), Value: append([]byte(nil), msg.Value...)}
+	if len(msg.Headers) > 0 {
+		rec.Headers = make(map[string]string, len(msg.Headers))
+		for k, v := range msg.Headers {
+			rec.Headers[k] = v
+		}
+	}
+	m.messages = append(m.messages, rec)
+	if m.log != nil {
+		m.log.Debug("message recorded in memory", "topic", msg.Topic, "size", len(msg.Value))
+	}
+	return nil
+}
+
+// PublishSync records the message like PublishMessage; the memory "broker" acknowledges immediately
+func (m *MemoryPublisher) PublishSync(ctx context.Context, msg *Message) error {
+	return m.PublishMessage(ctx, msg)
+}
+
+// Messages returns a copy of all recorded messages in publish order
+func (m *MemoryPublisher) Messages() []PublishedMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]PublishedMessage(nil), m.messages...)
+}
+
+// Close is a no-op
+func (m *MemoryPublisher) Close() error { return nil }
@@ -0,0 +1,159 @@
+package kafkaclient
+
+import (
+	"context"
+	"sync"
+)
+
+type partitionKey struct {
+	topic     string
+	partition int32
+}
+
+// partitionOffset is the next offset of a partition the group should consume
+type partitionOffset struct {
+	partitionKey
+	offset int64
+}
+
+// noOffset marks a partition without a committable offset yet
+const noOffset = int64(-1)
+
+// partitionOffsets tracks dispatched offsets of one assignment of a partition; handlers may
+// finish out of order, so only the offset after the longest finished prefix is safe to commit.
+// A partition assigned again after a revoke gets a new partitionOffsets.
+type partitionOffsets struct {
+	inflight  []int64
+	done      map[int64]bool
+	next      int64
+	committed int64
+
+	// ctx of the handlers, cancelled when the partition is revoked
+	ctx     context.Context
+	cancel  context.CancelFunc
+	revoked bool
+	running sync.WaitGroup
+}
+
+// job is a message handed to a worker, tied to the assignment of its partition
+type job struct {
+	msg  *Message
+	part *partitionOffsets
+}
+
+// offsetTracker computes committable offsets of messages handled by the worker pool
+type offsetTracker struct {
+	mu    sync.Mutex
+	parts map[partitionKey]*partitionOffsets
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{parts: make(map[partitionKey]*partitionOffsets)}
+}
+
+// add records a message handed to a worker; its handlers run in a context derived from ctx
+func (t *offsetTracker) add(ctx context.Context, m *Message) *job {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := keyOf(m)
+	p, ok := t.parts[key]
+	if !ok {
+		p = &partitionOffsets{done: make(map[int64]bool), next: noOffset, committed: noOffset}
+		p.ctx, p.cancel = context.WithCancel(ctx)
+		t.parts[key] = p
+	}
+	p.inflight = append(p.inflight, m.Offset)
+	return &job{msg: m, part: p}
+}
+
+// start reports whether a worker may handle j, false once its partition was revoked.
+// Every started job must be finished.
+func (t *offsetTracker) start(j *job) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.parts[keyOf(j.msg)] != j.part || j.part.revoked {
+		return false
+	}
+	j.part.running.Add(1)
+	return true
+}
+
+// finish records that a worker stopped handling j
+func (t *offsetTracker) finish(j *job) {
+	j.part.running.Done()
+}
+
+// done records a finished message and advances the committable offset past finished messages
+func (t *offsetTracker) done(j *job) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p := j.part
+	if t.parts[keyOf(j.msg)] != p {
+		return // the partition was revoked meanwhile, possibly assigned again
+	}
+	p.done[j.msg.Offset] = true
+	for len(p.inflight) > 0 && p.done[p.inflight[0]] {
+		delete(p.done, p.inflight[0])
+		p.next = p.inflight[0] + 1
+		p.inflight = p.inflight[1:]
+	}
+}
+
+// committable returns offsets that advanced since the last successful commit
+func (t *offsetTracker) committable() []partitionOffset {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var out []partitionOffset
+	for key, p := range t.parts {
+		if p.next == noOffset || p.next == p.committed {
+			continue
+		}
+		out = append(out, partitionOffset{partitionKey: key, offset: p.next})
+	}
+	return out
+}
+
+// committed records offsets acknowledged by the broker
+func (t *offsetTracker) committed(offsets []partitionOffset) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, po := range offsets {
+		if p, ok := t.parts[po.partitionKey]; ok && po.offset > p.committed {
+			p.committed = po.offset
+		}
+	}
+}
+
+// revoke cancels the handlers of partitions and waits until they stopped; queued messages
+// of those partitions are no longer started
+func (t *offsetTracker) revoke(partitions []partitionKey) {
+	t.mu.Lock()
+	var stopping []*partitionOffsets
+	for _, key := range partitions {
+		if p, ok := t.parts[key]; ok {
+			p.revoked = true
+			p.cancel()
+			stopping = append(stopping, p)
+		}
+	}
+	t.mu.Unlock()
+	for _, p := range stopping {
+		p.running.Wait()
+	}
+}
+
+// forget drops state of revoked partitions; their unfinished messages are redelivered to the new owner
+func (t *offsetTracker) forget(partitions []partitionKey) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, key := range partitions {
+		if p, ok := t.parts[key]; ok {
+			p.cancel()
+			delete(t.parts, key)
+		}
+	}
+}
+
+func keyOf(m *Message) partitionKey {
+	return partitionKey{topic: m.Topic, partition: m.Partition}
+}
@@ -0,0 +1,106 @@
+package kafkaclient
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestOffsetTrackerCommitsContiguousPrefix(t *testing.T) {
+	msg := func(partition int32, offset int64) *Message {
+		return &Message{Topic: "orders", Partition: partition, Offset: offset}
+	}
+
+	tests := []struct {
+		name       string
+		dispatched []int64
+		finished   []int64
+		want       int64 // committable offset, noOffset for none
+	}{
+		{name: "nothing finished", dispatched: []int64{10, 11, 12}, want: noOffset},
+		{name: "in order", dispatched: []int64{10, 11, 12}, finished: []int64{10, 11, 12}, want: 13},
+		{name: "gap holds back later offsets", dispatched: []int64{10, 11, 12}, finished: []int64{11, 12}, want: noOffset},
+		{name: "prefix before a gap", dispatched: []int64{10, 11, 12, 13}, finished: []int64{10, 12, 13}, want: 11},
+		{name: "gap filled last", dispatched: []int64{10, 11, 12}, finished: []int64{12, 11, 10}, want: 13},
+		{name: "compacted offsets", dispatched: []int64{10, 14, 20}, finished: []int64{14, 10}, want: 15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newOffsetTracker()
+			jobs := make(map[int64]*job)
+			for _, o := range tt.dispatched {
+				jobs[o] = tr.add(context.Background(), msg(0, o))
+			}
+			for _, o := range tt.finished {
+				tr.done(jobs[o])
+			}
+			got := tr.committable()
+			if tt.want == noOffset {
+				if len(got) != 0 {
+					t.Errorf("committable = %v, want none", got)
+				}
+				return
+			}
+			want := []partitionOffset{{partitionKey: partitionKey{"orders", 0}, offset: tt.want}}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("committable = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestOffsetTrackerCommittedAndForget(t *testing.T) {
+	tr := newOffsetTracker()
+	jobs := make(map[int32][]*job)
+	for p := int32(0); p < 2; p++ {
+		for o := int64(0); o < 3; o++ {
+			jobs[p] = append(jobs[p], tr.add(context.Background(), &Message{Topic: "orders", Partition: p, Offset: o}))
+		}
+	}
+	tr.done(jobs[0][0])
+	tr.done(jobs[1][0])
+	tr.done(jobs[1][1])
+
+	got := tr.committable()
+	sort.Slice(got, func(i, j int) bool { return got[i].partition < got[j].partition })
+	want := []partitionOffset{
+		{partitionKey: partitionKey{"orders", 0}, offset: 1},
+		{partitionKey: partitionKey{"orders", 1}, offset: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("committable = %v, want %v", got, want)
+	}
+
+	// acknowledged offsets are not committed again until they advance
+	tr.committed(got)
+	if again := tr.committable(); len(again) != 0 {
+		t.Errorf("committable after commit = %v, want none", again)
+	}
+	// a stale acknowledgement does not move the committed offset back
+	tr.committed([]partitionOffset{{partitionKey: partitionKey{"orders", 1}, offset: 1}})
+	if again := tr.committable(); len(again) != 0 {
+		t.Errorf("committable after stale ack = %v, want none", again)
+	}
+	tr.done(jobs[0][1])
+	if got := tr.committable(); len(got) != 1 || got[0].offset != 2 {
+		t.Errorf("committable after progress = %v, want orders/0 at 2", got)
+	}
+
+	// messages of revoked partitions finishing late are ignored, also once the partition is
+	// assigned again and its messages are redelivered
+	tr.revoke([]partitionKey{{"orders", 0}})
+	if tr.start(jobs[0][2]) {
+		t.Error("started a message of a revoked partition")
+	}
+	tr.forget([]partitionKey{{"orders", 0}})
+	redelivered := tr.add(context.Background(), &Message{Topic: "orders", Partition: 0, Offset: 2})
+	tr.done(jobs[0][2])
+	if got := tr.committable(); len(got) != 0 {
+		t.Errorf("committable after stale completion = %v, want none", got)
+	}
+	tr.done(redelivered)
+	if got := tr.committable(); len(got) != 1 || got[0].offset != 3 {
+		t.Errorf("committable after redelivery = %v, want orders/0 at 3", got)
+	}
+}
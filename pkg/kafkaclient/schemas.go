@@ -0,0 +1,31 @@
+package kafkaclient
+
+import (
+	"google.golang.org/protobuf/proto"
+)
+
+// Schemas resolves the protobuf message types of versioned events
+type Schemas interface {
+	// Version returns the schema version events of evt's type are published with on topic
+	Version(topic string, evt proto.Message) (int, error)
+	// New returns an empty message of the type registered for topic and version
+	New(topic string, version int) (proto.Message, error)
+}
+
+// DecodeEvent unmarshals msg into the message type registered for its topic and the
+// schema version in its envelope; messages without an envelope are DefaultSchemaVersion.
+// Unknown versions and malformed payloads are permanent failures.
+func DecodeEvent(schemas Schemas, msg *Message) (proto.Message, error) {
+	version := DefaultSchemaVersion
+	if env, ok := EnvelopeFromHeaders(msg.Headers); ok {
+		version = env.SchemaVersion
+	}
+	evt, err := schemas.New(msg.Topic, version)
+	if err != nil {
+		return nil, Permanent(err)
+	}
+	if err := proto.Unmarshal(msg.Value, evt); err != nil {
+		return nil, Permanent(err)
+	}
+	return evt, nil
+}
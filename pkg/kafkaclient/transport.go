@@ -0,0 +1,15 @@
+package kafkaclient
+
+import "time"
+
+// consumerTransport is the broker side of a Consumer: group membership, reads and commits
+type consumerTransport interface {
+	// subscribe joins the group for topics; onRevoke is called from read before
+	// partitions move to another member
+	subscribe(topics []string, onRevoke func([]partitionKey)) error
+	// read returns the next message, or nil when none arrived within timeout
+	read(timeout time.Duration) (*Message, error)
+	// commit stores the next offsets the group should consume
+	commit(offsets []partitionOffset) error
+	close() error
+}
@@ -0,0 +1,55 @@
+package money
+
+import (
+	"fmt"
+	"math"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Convert converts m to another currency at rate (units of to per unit of m's currency),
+// scaling between the currencies' minor units and rounding half away from zero
+func (m Money) Convert(to string, rate float64) (Money, error) {
+	c, ok := LookupCurrency(to)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, to)
+	}
+	if !(rate > 0) || math.IsInf(rate, 0) {
+		return Money{}, fmt.Errorf("money: invalid rate %s/%s %v", m.Currency, c.Code, rate)
+	}
+	v := math.Round(float64(m.Amount) * rate * math.Pow10(c.Exponent-Exponent(m.Currency)))
+	if math.IsNaN(v) || v >= math.MaxInt64 || v <= math.MinInt64 {
+		return Money{}, fmt.Errorf("%w: %s in %s", ErrOverflow, m, c.Code)
+	}
+	return Money{Amount: int64(v), Currency: c.Code}, nil
+}
+
+// PB is satisfied by the generated Money messages of every proto package
+type PB interface {
+	GetAmount() int64
+	GetCurrency() string
+}
+
+// FromProto reads a proto Money message; nil gives zero Money. The currency is not checked,
+// use New for amounts from untrusted input.
+func FromProto(p PB) Money {
+	if p == nil {
+		return Money{}
+	}
+	return Money{Amount: p.GetAmount(), Currency: p.GetCurrency()}
+}
+
+// ToProto builds a proto Money message of any proto package, e.g. ToProto[orderpb.Money](m).
+// The message must have the amount (int64) and currency (string) fields.
+func ToProto[T any, P interface {
+	*T
+	proto.Message
+}](m Money) P {
+	p := P(new(T))
+	msg := p.ProtoReflect()
+	fields := msg.Descriptor().Fields()
+	msg.Set(fields.ByName("amount"), protoreflect.ValueOfInt64(m.Amount))
+	msg.Set(fields.ByName("currency"), protoreflect.ValueOfString(m.Currency))
+	return p
+}
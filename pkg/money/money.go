@@ -0,0 +1,282 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrUnknownCurrency is returned for codes that are not active ISO 4217 currencies
+	ErrUnknownCurrency = errors.New("money: unknown currency")
+	// ErrCurrencyMismatch is returned when combining amounts of different currencies
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	// ErrOverflow is returned when a result does not fit in int64 minor units
+	ErrOverflow = errors.New("money: amount overflow")
+)
+
+// Money is an amount in minor units of an ISO 4217 currency (cents for USD, yen for JPY,
+// fils for BHD). It is a value: operations return new values and never round.
+type Money struct {
+	Amount   int64  `json:"amount"`   // minor units
+	Currency string `json:"currency"` // ISO 4217
+}
+
+// New returns an amount in minor units of a known ISO 4217 currency
+func New(amount int64, currency string) (Money, error) {
+	c, ok := LookupCurrency(strings.TrimSpace(currency))
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+	return Money{Amount: amount, Currency: c.Code}, nil
+}
+
+// Zero returns no money in a currency
+func Zero(currency string) (Money, error) { return New(0, currency) }
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool { return m.Amount == 0 }
+
+// IsNegative reports whether the amount is below zero
+func (m Money) IsNegative() bool { return m.Amount < 0 }
+
+// IsPositive reports whether the amount is above zero
+func (m Money) IsPositive() bool { return m.Amount > 0 }
+
+// SameCurrency reports whether both amounts are in the same currency
+func (m Money) SameCurrency(o Money) bool { return strings.EqualFold(m.Currency, o.Currency) }
+
+// Add returns m + o
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.checkCurrency(o); err != nil {
+		return Money{}, err
+	}
+	sum, ok := addInt64(m.Amount, o.Amount)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrOverflow, m, o)
+	}
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+// Sub returns m - o
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.checkCurrency(o); err != nil {
+		return Money{}, err
+	}
+	if o.Amount == math.MinInt64 {
+		return Money{}, fmt.Errorf("%w: %s - %s", ErrOverflow, m, o)
+	}
+	diff, ok := addInt64(m.Amount, -o.Amount)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %s - %s", ErrOverflow, m, o)
+	}
+	return Money{Amount: diff, Currency: m.Currency}, nil
+}
+
+// Mul returns m * n, e.g. a unit price times a quantity
+func (m Money) Mul(n int64) (Money, error) {
+	p, ok := mulInt64(m.Amount, n)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %s * %d", ErrOverflow, m, n)
+	}
+	return Money{Amount: p, Currency: m.Currency}, nil
+}
+
+// Ratio returns m * num / den rounded half away from zero, e.g. a tax rate in parts per million
+// of a price; 0 <= num <= den
+func (m Money) Ratio(num, den int64) (Money, error) {
+	if den <= 0 || num < 0 || num > den {
+		return Money{}, fmt.Errorf("money: invalid ratio %d/%d", num, den)
+	}
+	v, err := mulDivRound(m.Amount, num, den)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: v, Currency: m.Currency}, nil
+}
+
+// Neg returns -m
+func (m Money) Neg() (Money, error) {
+	if m.Amount == math.MinInt64 {
+		return Money{}, fmt.Errorf("%w: -%s", ErrOverflow, m)
+	}
+	return Money{Amount: -m.Amount, Currency: m.Currency}, nil
+}
+
+// Cmp compares m and o: -1 if m < o, 0 if equal, 1 if m > o
+func (m Money) Cmp(o Money) (int, error) {
+	if err := m.checkCurrency(o); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Sum adds amounts of one currency; the sum of no amounts is zero in currency
+func Sum(currency string, amounts ...Money) (Money, error) {
+	total := Money{Currency: strings.ToUpper(currency)}
+	for _, a := range amounts {
+		var err error
+		if total, err = total.Add(a); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// Allocate splits m by the given non-negative ratios without losing minor units: every part
+// gets its share rounded down and the remainder is handed out one minor unit at a time to the
+// first parts. Allocate(100, 1, 1, 1) gives 34, 33, 33.
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	if len(ratios) == 0 {
+		return nil, errors.New("money: allocate needs at least one ratio")
+	}
+	var total int64
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, fmt.Errorf("money: negative ratio %d", r)
+		}
+		var ok bool
+		if total, ok = addInt64(total, r); !ok {
+			return nil, fmt.Errorf("%w: ratios", ErrOverflow)
+		}
+	}
+	if total == 0 {
+		return nil, errors.New("money: ratios sum to zero")
+	}
+
+	parts := make([]Money, len(ratios))
+	remainder := m.Amount
+	for i, r := range ratios {
+		share, err := mulDiv(m.Amount, r, total)
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = Money{Amount: share, Currency: m.Currency}
+		remainder -= share
+	}
+	step := int64(1)
+	if remainder < 0 {
+		step = -1
+	}
+	for i := 0; remainder != 0; i = (i + 1) % len(parts) {
+		if ratios[i] == 0 {
+			continue
+		}
+		parts[i].Amount += step
+		remainder -= step
+	}
+	return parts, nil
+}
+
+// Split divides m into n parts that differ by at most one minor unit
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("money: cannot split into %d parts", n)
+	}
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+// Major formats the amount in major units with the currency's exponent, e.g. "12.34" for
+// 1234 USD, "1234" for 1234 JPY and "1.234" for 1234 BHD
+func (m Money) Major() string {
+	exp := Exponent(m.Currency)
+	neg := m.Amount < 0
+	// Via uint64 so MinInt64 formats too
+	abs := uint64(m.Amount)
+	if neg {
+		abs = -abs
+	}
+	digits := strconv.FormatUint(abs, 10)
+	if exp > 0 {
+		if len(digits) <= exp {
+			digits = strings.Repeat("0", exp-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+	}
+	if neg {
+		return "-" + digits
+	}
+	return digits
+}
+
+// String formats the amount as "12.34 USD"
+func (m Money) String() string {
+	return m.Major() + " " + m.Currency
+}
+
+func (m Money) checkCurrency(o Money) error {
+	if !m.SameCurrency(o) {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return nil
+}
+
+func addInt64(a, b int64) (int64, bool) {
+	s := a + b
+	if (b > 0 && s < a) || (b < 0 && s > a) {
+		return 0, false
+	}
+	return s, true
+}
+
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	p := a * b
+	if (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) || p/b != a {
+		return 0, false
+	}
+	return p, true
+}
+
+// mulDiv returns a*b/c rounded toward zero; c > 0, b <= c
+func mulDiv(a, b, c int64) (int64, error) {
+	if p, ok := mulInt64(a, b); ok {
+		return p / c, nil
+	}
+	// a*b overflows but the result fits since b <= c: divide first and carry the remainder
+	q, r := a/c, a%c
+	hi, ok := mulInt64(q, b)
+	if !ok {
+		return 0, fmt.Errorf("%w: allocation", ErrOverflow)
+	}
+	lo, ok := mulInt64(r, b)
+	if !ok {
+		return 0, fmt.Errorf("%w: allocation", ErrOverflow)
+	}
+	return hi + lo/c, nil
+}
+
+// mulDivRound returns a*b/c rounded half away from zero, for 0 <= b <= c
+func mulDivRound(a, b, c int64) (int64, error) {
+	q, r := a/c, a%c
+	hi, ok := mulInt64(q, b)
+	if !ok {
+		return 0, fmt.Errorf("%w: ratio", ErrOverflow)
+	}
+	lo, ok := mulInt64(r, b)
+	if !ok {
+		return 0, fmt.Errorf("%w: ratio", ErrOverflow)
+	}
+	v, frac := hi+lo/c, lo%c
+	switch {
+	case frac > 0 && frac >= c-frac:
+		v++
+	case frac < 0 && -frac >= c+frac:
+		v--
+	}
+	return v, nil
+}
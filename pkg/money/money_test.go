@@ -0,0 +1,115 @@
+package money
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestRatio(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		num, den int64
+		want     int64
+		wantErr  bool
+	}{
+		{name: "whole", amount: 1000, num: 1, den: 1, want: 1000},
+		{name: "half", amount: 1000, num: 1, den: 2, want: 500},
+		{name: "zero", amount: 1000, num: 0, den: 3, want: 0},
+		{name: "half rounds up", amount: 5, num: 1, den: 2, want: 3},
+		{name: "below half rounds down", amount: 1, num: 1, den: 3, want: 0},
+		{name: "above half rounds up", amount: 2, num: 1, den: 3, want: 1},
+		{name: "negative half rounds away from zero", amount: -5, num: 1, den: 2, want: -3},
+		{name: "negative below half", amount: -1, num: 1, den: 3, want: 0},
+		{name: "tax rate in ppm", amount: 1999, num: 190000, den: 1000000, want: 380},
+		{name: "largest amount", amount: math.MaxInt64, num: 999999, den: 1000000, want: 9223362813482738952},
+		{name: "zero denominator", amount: 100, num: 0, den: 0, wantErr: true},
+		{name: "negative numerator", amount: 100, num: -1, den: 2, wantErr: true},
+		{name: "above one", amount: 100, num: 3, den: 2, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Money{Amount: tt.amount, Currency: "USD"}.Ratio(tt.num, tt.den)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Ratio(%d, %d) = %v, want error", tt.num, tt.den, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Ratio(%d, %d): %v", tt.num, tt.den, err)
+			}
+			if got.Amount != tt.want || got.Currency != "USD" {
+				t.Errorf("Ratio(%d, %d) = %v, want %d USD", tt.num, tt.den, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  int64
+		ratios  []int64
+		want    []int64
+		wantErr bool
+	}{
+		{name: "even", amount: 100, ratios: []int64{1, 1}, want: []int64{50, 50}},
+		{name: "remainder to the first parts", amount: 100, ratios: []int64{1, 1, 1}, want: []int64{34, 33, 33}},
+		{name: "weighted", amount: 1000, ratios: []int64{70, 20, 10}, want: []int64{700, 200, 100}},
+		{name: "weighted with remainder", amount: 101, ratios: []int64{2, 1}, want: []int64{68, 33}},
+		{name: "zero ratio gets nothing", amount: 5, ratios: []int64{0, 1, 1}, want: []int64{0, 3, 2}},
+		{name: "fewer units than parts", amount: 2, ratios: []int64{1, 1, 1}, want: []int64{1, 1, 0}},
+		{name: "negative", amount: -100, ratios: []int64{1, 1, 1}, want: []int64{-34, -33, -33}},
+		{name: "zero amount", amount: 0, ratios: []int64{1, 2}, want: []int64{0, 0}},
+		{name: "largest amount", amount: math.MaxInt64, ratios: []int64{1, 1}, want: []int64{math.MaxInt64/2 + 1, math.MaxInt64 / 2}},
+		{name: "no ratios", amount: 100, wantErr: true},
+		{name: "negative ratio", amount: 100, ratios: []int64{2, -1}, wantErr: true},
+		{name: "ratios sum to zero", amount: 100, ratios: []int64{0, 0}, wantErr: true},
+		{name: "ratio sum overflows", amount: 100, ratios: []int64{math.MaxInt64, 1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := Money{Amount: tt.amount, Currency: "EUR"}.Allocate(tt.ratios...)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Allocate(%v) = %v, want error", tt.ratios, parts)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Allocate(%v): %v", tt.ratios, err)
+			}
+			got := make([]int64, len(parts))
+			for i, p := range parts {
+				if p.Currency != "EUR" {
+					t.Errorf("part %d currency = %s, want EUR", i, p.Currency)
+				}
+				got[i] = p.Amount
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Allocate(%v) = %v, want %v", tt.ratios, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitKeepsEveryMinorUnit(t *testing.T) {
+	for _, amount := range []int64{0, 1, 7, 100, 1001, -1001} {
+		for n := 1; n <= 7; n++ {
+			parts, err := Money{Amount: amount, Currency: "JPY"}.Split(n)
+			if err != nil {
+				t.Fatalf("Split(%d) of %d: %v", n, amount, err)
+			}
+			var sum, lo, hi int64 = 0, math.MaxInt64, math.MinInt64
+			for _, p := range parts {
+				sum += p.Amount
+				lo, hi = min(lo, p.Amount), max(hi, p.Amount)
+			}
+			if sum != amount || hi-lo > 1 {
+				t.Errorf("Split(%d) of %d = %v: sum %d, spread %d", n, amount, parts, sum, hi-lo)
+			}
+		}
+	}
+}
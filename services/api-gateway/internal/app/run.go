@@ -139,6 +139,7 @@ func Run(ctx context.Context, cfg *config.Config, logger *zap.Logger) error {
 			orders := protected.Group("/orders")
 			{
 				orders.POST("", orderHandler.CreateOrder)
+				orders.POST("/shipping-quotes", orderHandler.QuoteShipping)
 				orders.GET("", orderHandler.GetUserOrders)
 				orders.GET("/:id", orderHandler.GetOrder)
 			}
@@ -147,6 +148,10 @@ func Run(ctx context.Context, cfg *config.Config, logger *zap.Logger) error {
 			{
 				payments.POST("", paymentHandler.ProcessPayment)
 				payments.GET("/:id", paymentHandler.GetPayment)
+				payments.POST("/:id/refunds", paymentHandler.RefundPayment)
+				payments.GET("/methods", paymentHandler.ListPaymentMethods)
+				payments.POST("/methods", paymentHandler.AddPaymentMethod)
+				payments.DELETE("/methods/:token", paymentHandler.DeletePaymentMethod)
 			}
 		}
 
@@ -158,7 +163,6 @@ func Run(ctx context.Context, cfg *config.Config, logger *zap.Logger) error {
 			inventory.GET("/categories", inventoryHandler.GetCategories)
 		}
 
-		api.GET("/payments/methods", paymentHandler.GetPaymentMethods)
 		api.GET("/payments/test-cards", paymentHandler.GetTestCards)
 	}
 
@@ -3,6 +3,7 @@ package clients
 import (
 	"context"
 
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
 	"github.com/kubernetestest/ecommerce-platform/services/api-gateway/pkg/conversion"
 	"github.com/kubernetestest/ecommerce-platform/services/api-gateway/pkg/grpc"
 	"github.com/kubernetestest/ecommerce-platform/services/api-gateway/pkg/types"
@@ -13,8 +14,9 @@ import (
 
 type InventoryClient interface {
 	Close() error
-	GetProducts(ctx context.Context, categoryID string, page, limit int32, search string) ([]*Product, int32, error)
-	GetProduct(ctx context.Context, productID string) (*Product, error)
+	// currency is the ISO 4217 code to show prices in; empty shows catalog prices
+	GetProducts(ctx context.Context, categoryID string, page, limit int32, search, currency string) ([]*Product, int32, error)
+	GetProduct(ctx context.Context, productID, currency string) (*Product, error)
 	GetCategories(ctx context.Context, activeOnly bool) ([]*Category, error)
 	CheckStock(ctx context.Context, req *StockCheckRequest) (*StockCheckResponse, error)
 }
@@ -27,17 +29,19 @@ type inventoryClient struct {
 // ---------------- Inventory Models ----------------
 
 type Product struct {
-	ID            string      `json:"id"`
-	Name          string      `json:"name"`
-	Description   string      `json:"description"`
-	Price         types.Money `json:"price"`
-	CategoryID    string      `json:"category_id"`
-	CategoryName  string      `json:"category_name"`
-	StockQuantity int32       `json:"stock_quantity"`
-	ImageURL      string      `json:"image_url"`
-	IsActive      bool        `json:"is_active"`
-	CreatedAt     string      `json:"created_at"`
-	UpdatedAt     string      `json:"updated_at"`
+	ID            string              `json:"id"`
+	Name          string              `json:"name"`
+	Description   string              `json:"description"`
+	Price         money.Money         `json:"price"`
+	CategoryID    string              `json:"category_id"`
+	CategoryName  string              `json:"category_name"`
+	StockQuantity int32               `json:"stock_quantity"`
+	ImageURL      string              `json:"image_url"`
+	IsActive      bool                `json:"is_active"`
+	CreatedAt     string              `json:"created_at"`
+	UpdatedAt     string              `json:"updated_at"`
+	CatalogPrice  *money.Money        `json:"catalog_price,omitempty"` // set when price was converted
+	ExchangeRate  *types.ExchangeRate `json:"exchange_rate,omitempty"` // set when price was converted
 }
 
 type Category struct {
@@ -83,13 +87,14 @@ func NewInventoryClient(address string) (InventoryClient, error) {
 
 // ---------------- Inventory Methods ----------------
 
-func (c *inventoryClient) GetProducts(ctx context.Context, categoryID string, page, limit int32, search string) ([]*Product, int32, error) {
+func (c *inventoryClient) GetProducts(ctx context.Context, categoryID string, page, limit int32, search, currency string) ([]*Product, int32, error) {
 	resp, err := grpc.WithTimeoutResult(ctx, func(ctx context.Context) (*invpb.GetProductsResponse, error) {
 		return c.client.GetProducts(ctx, &invpb.GetProductsRequest{
 			CategoryId: categoryID,
 			Page:       page,
 			Limit:      limit,
 			Search:     search,
+			Currency:   currency,
 		})
 	})
 	if err != nil {
@@ -103,9 +108,9 @@ func (c *inventoryClient) GetProducts(ctx context.Context, categoryID string, pa
 	return out, resp.GetTotal(), nil
 }
 
-func (c *inventoryClient) GetProduct(ctx context.Context, productID string) (*Product, error) {
+func (c *inventoryClient) GetProduct(ctx context.Context, productID, currency string) (*Product, error) {
 	resp, err := grpc.WithTimeoutResult(ctx, func(ctx context.Context) (*invpb.GetProductResponse, error) {
-		return c.client.GetProduct(ctx, &invpb.GetProductRequest{Id: productID})
+		return c.client.GetProduct(ctx, &invpb.GetProductRequest{Id: productID, Currency: currency})
 	})
 	if err != nil {
 		return nil, err
@@ -155,11 +160,11 @@ func mapProductFromPB(p *invpb.Product) *Product {
 	if p == nil {
 		return nil
 	}
-	return &Product{
+	out := &Product{
 		ID:            p.Id,
 		Name:          p.Name,
 		Description:   p.Description,
-		Price:         conversion.MoneyFromPB(p.Price),
+		Price:         money.FromProto(p.Price),
 		CategoryID:    p.CategoryId,
 		CategoryName:  p.CategoryName,
 		StockQuantity: p.StockQuantity,
@@ -167,7 +172,13 @@ func mapProductFromPB(p *invpb.Product) *Product {
 		IsActive:      p.IsActive,
 		CreatedAt:     grpc.FormatTimestamp(p.CreatedAt),
 		UpdatedAt:     grpc.FormatTimestamp(p.UpdatedAt),
+		ExchangeRate:  conversion.ExchangeRateFromPB(p.ExchangeRate),
 	}
+	if p.CatalogPrice != nil {
+		catalog := money.FromProto(p.CatalogPrice)
+		out.CatalogPrice = &catalog
+	}
+	return out
 }
 
 func mapCategoryFromPB(c *invpb.Category) *Category {
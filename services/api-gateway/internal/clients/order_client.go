@@ -3,6 +3,8 @@ package clients
 import (
 	"context"
 
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
+	"github.com/kubernetestest/ecommerce-platform/services/api-gateway/pkg/conversion"
 	"github.com/kubernetestest/ecommerce-platform/services/api-gateway/pkg/grpc"
 	"github.com/kubernetestest/ecommerce-platform/services/api-gateway/pkg/types"
 	orderpb "github.com/kubernetestest/ecommerce-platform/proto-go/order"
@@ -15,6 +17,7 @@ type OrderClient interface {
 	CreateOrder(ctx context.Context, req *CreateOrderRequest) (*Order, error)
 	GetOrder(ctx context.Context, orderID, userID string) (*Order, error)
 	GetUserOrders(ctx context.Context, userID string, page, limit int32) ([]*Order, error)
+	QuoteShipping(ctx context.Context, req *QuoteShippingRequest) ([]ShippingQuote, error)
 }
 
 type orderClient struct {
@@ -25,30 +28,93 @@ type orderClient struct {
 // ---------------- Order Models ----------------
 
 type Order struct {
-	ID              string      `json:"id"`
-	UserID          string      `json:"user_id"`
-	Status          string      `json:"status"`
-	Items           []OrderItem `json:"items"`
-	TotalAmount     types.Money `json:"total_amount"`
-	ShippingAddress string      `json:"shipping_address"`
-	CreatedAt       string      `json:"created_at"`
-	UpdatedAt       string      `json:"updated_at"`
+	ID                 string             `json:"id"`
+	UserID             string             `json:"user_id"`
+	Status             string             `json:"status"`
+	Items              []OrderItem        `json:"items"`
+	Subtotal           money.Money        `json:"subtotal"`   // items net of tax
+	TaxAmount          money.Money        `json:"tax_amount"` // inclusive and exclusive taxes
+	ShippingCost       money.Money        `json:"shipping_cost"`
+	TotalAmount        money.Money        `json:"total_amount"`     // subtotal plus tax and shipping
+	ShippingAddress    string             `json:"shipping_address"` // one-line form of Shipping
+	ShippingCountry    string             `json:"shipping_country,omitempty"`
+	ShippingRegion     string             `json:"shipping_region,omitempty"`
+	Shipping           *Address           `json:"shipping,omitempty"`
+	ShippingMethod     string             `json:"shipping_method,omitempty"`
+	CreatedAt          string             `json:"created_at"`
+	UpdatedAt          string             `json:"updated_at"`
+	CancellationReason string             `json:"cancellation_reason,omitempty"`
+	ShortProducts      []ProductShortfall `json:"short_products,omitempty"`
+	PaymentMethod      string             `json:"payment_method,omitempty"`
+}
+
+type ProductShortfall struct {
+	ProductID         string `json:"product_id"`
+	RequestedQuantity int32  `json:"requested_quantity"`
+	AvailableQuantity int32  `json:"available_quantity"`
 }
 
 type OrderItem struct {
-	ID          string      `json:"id"`
-	ProductID   string      `json:"product_id"`
-	ProductName string      `json:"product_name"`
-	Quantity    int32       `json:"quantity"`
-	Price       types.Money `json:"price"`
-	Total       types.Money `json:"total"`
+	ID           string              `json:"id"`
+	ProductID    string              `json:"product_id"`
+	ProductName  string              `json:"product_name"`
+	Quantity     int32               `json:"quantity"`
+	Price        money.Money         `json:"price"`
+	Total        money.Money         `json:"total"`
+	CatalogPrice *money.Money        `json:"catalog_price,omitempty"` // set when price was converted
+	ExchangeRate *types.ExchangeRate `json:"exchange_rate,omitempty"` // rate used at checkout
+	TaxCategory  string              `json:"tax_category,omitempty"`
+	TaxLines     []TaxLine           `json:"tax_lines,omitempty"`
+}
+
+// TaxLine is a tax charged on an order item; inclusive taxes are contained in the item total
+type TaxLine struct {
+	Name         string      `json:"name"`
+	Jurisdiction string      `json:"jurisdiction"` // country, or country-region (US-CA)
+	Rate         float64     `json:"rate"`         // 0.19 for 19%
+	Amount       money.Money `json:"amount"`
+	Inclusive    bool        `json:"inclusive"`
+}
+
+// Address is a postal address; region is an ISO 3166-2 subdivision without the country prefix
+type Address struct {
+	Recipient  string `json:"recipient"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country"` // ISO 3166-1 alpha-2
+}
+
+type ShippingQuote struct {
+	Method  string      `json:"method"` // STANDARD, EXPRESS
+	Name    string      `json:"name"`
+	Cost    money.Money `json:"cost"`
+	MinDays int32       `json:"min_days"` // delivery estimate in business days
+	MaxDays int32       `json:"max_days"`
 }
 
 type CreateOrderRequest struct {
-	UserID          string             `json:"user_id"`
-	Items           []OrderItemRequest `json:"items"`
-	ShippingAddress string             `json:"shipping_address"`
-	Currency        string             `json:"currency"`
+	UserID             string             `json:"user_id"`
+	Items              []OrderItemRequest `json:"items"`
+	Shipping           *Address           `json:"shipping,omitempty"`
+	ShippingMethod     string             `json:"shipping_method,omitempty"`      // one of the quoted methods, empty: STANDARD
+	Currency           string             `json:"currency,omitempty"`             // ISO 4217 presentment currency
+	PaymentMethod      string             `json:"payment_method"`                 // CREDIT_CARD or DEBIT_CARD
+	PaymentMethodToken string             `json:"payment_method_token,omitempty"` // saved card in the payment-service vault
+
+	// Deprecated: one-line address of older clients, sent when Shipping is nil. Removed in
+	// the next release with the shipping_address request field.
+	LegacyShippingAddress string `json:"shipping_address,omitempty"`
+	LegacyShippingCountry string `json:"shipping_country,omitempty"` // Deprecated: use Shipping.Country
+	LegacyShippingRegion  string `json:"shipping_region,omitempty"`  // Deprecated: use Shipping.Region
+}
+
+type QuoteShippingRequest struct {
+	Shipping Address            `json:"shipping"`
+	Items    []OrderItemRequest `json:"items"`
+	Currency string             `json:"currency,omitempty"` // ISO 4217 currency of the quotes
 }
 
 type OrderItemRequest struct {
@@ -72,14 +138,19 @@ func NewOrderClient(address string) (OrderClient, error) {
 // ---------------- Order Methods ----------------
 
 func (c *orderClient) CreateOrder(ctx context.Context, req *CreateOrderRequest) (*Order, error) {
-	items := make([]*orderpb.OrderItemRequest, len(req.Items))
-	for i, it := range req.Items {
-		items[i] = &orderpb.OrderItemRequest{ProductId: it.ProductID, Quantity: it.Quantity}
-	}
 	grpcReq := &orderpb.CreateOrderRequest{
-		UserId:          req.UserID,
-		Items:           items,
-		ShippingAddress: req.ShippingAddress,
+		UserId:         req.UserID,
+		Items:          mapItemRequestsToPB(req.Items),
+		ShippingMethod: req.ShippingMethod,
+		Payment:        &orderpb.PaymentSelection{Method: req.PaymentMethod, PaymentMethodToken: req.PaymentMethodToken},
+		Currency:       req.Currency,
+	}
+	if req.Shipping != nil {
+		grpcReq.Shipping = mapAddressToPB(*req.Shipping)
+	} else {
+		grpcReq.ShippingAddress = req.LegacyShippingAddress
+		grpcReq.ShippingCountry = req.LegacyShippingCountry
+		grpcReq.ShippingRegion = req.LegacyShippingRegion
 	}
 
 	resp, err := grpc.WithTimeoutResult(ctx, func(ctx context.Context) (*orderpb.CreateOrderResponse, error) {
@@ -118,27 +189,96 @@ func (c *orderClient) GetUserOrders(ctx context.Context, userID string, page, li
 	return out, nil
 }
 
+func (c *orderClient) QuoteShipping(ctx context.Context, req *QuoteShippingRequest) ([]ShippingQuote, error) {
+	resp, err := grpc.WithTimeoutResult(ctx, func(ctx context.Context) (*orderpb.QuoteShippingResponse, error) {
+		return c.client.QuoteShipping(ctx, &orderpb.QuoteShippingRequest{
+			Shipping: mapAddressToPB(req.Shipping),
+			Items:    mapItemRequestsToPB(req.Items),
+			Currency: req.Currency,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]ShippingQuote, 0, len(resp.GetQuotes()))
+	for _, q := range resp.GetQuotes() {
+		out = append(out, ShippingQuote{
+			Method:  q.Method,
+			Name:    q.Name,
+			Cost:    money.FromProto(q.Cost),
+			MinDays: q.MinDays,
+			MaxDays: q.MaxDays,
+		})
+	}
+	return out, nil
+}
+
 // ---------------- Mapping Helpers ----------------
 
-func mapMoneyFromPB(m *orderpb.Money) types.Money {
-	if m == nil {
-		return types.Money{}
+func mapItemRequestsToPB(in []OrderItemRequest) []*orderpb.OrderItemRequest {
+	items := make([]*orderpb.OrderItemRequest, len(in))
+	for i, it := range in {
+		items[i] = &orderpb.OrderItemRequest{ProductId: it.ProductID, Quantity: it.Quantity}
+	}
+	return items
+}
+
+func mapAddressToPB(a Address) *orderpb.Address {
+	return &orderpb.Address{
+		Recipient:  a.Recipient,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+}
+
+func mapAddressFromPB(a *orderpb.Address) *Address {
+	if a == nil {
+		return nil
+	}
+	return &Address{
+		Recipient:  a.Recipient,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
 	}
-	return types.Money{Amount: m.GetAmount(), Currency: m.GetCurrency()}
 }
 
 func mapOrderItemFromPB(it *orderpb.OrderItem) OrderItem {
 	if it == nil {
 		return OrderItem{}
 	}
-	return OrderItem{
-		ID:          it.Id,
-		ProductID:   it.ProductId,
-		ProductName: it.ProductName,
-		Quantity:    it.Quantity,
-		Price:       mapMoneyFromPB(it.Price),
-		Total:       mapMoneyFromPB(it.Total),
+	out := OrderItem{
+		ID:           it.Id,
+		ProductID:    it.ProductId,
+		ProductName:  it.ProductName,
+		Quantity:     it.Quantity,
+		Price:        money.FromProto(it.Price),
+		Total:        money.FromProto(it.Total),
+		ExchangeRate: conversion.ExchangeRateFromPB(it.ExchangeRate),
+		TaxCategory:  it.TaxCategory,
+	}
+	if it.CatalogPrice != nil {
+		catalog := money.FromProto(it.CatalogPrice)
+		out.CatalogPrice = &catalog
 	}
+	for _, t := range it.TaxLines {
+		out.TaxLines = append(out.TaxLines, TaxLine{
+			Name:         t.Name,
+			Jurisdiction: t.Jurisdiction,
+			Rate:         t.Rate,
+			Amount:       money.FromProto(t.Amount),
+			Inclusive:    t.Inclusive,
+		})
+	}
+	return out
 }
 
 func mapOrderFromPB(o *orderpb.Order) *Order {
@@ -149,15 +289,33 @@ func mapOrderFromPB(o *orderpb.Order) *Order {
 	for i, it := range o.Items {
 		items[i] = mapOrderItemFromPB(it)
 	}
+	var shortProducts []ProductShortfall
+	for _, sf := range o.ShortProducts {
+		shortProducts = append(shortProducts, ProductShortfall{
+			ProductID:         sf.ProductId,
+			RequestedQuantity: sf.RequestedQuantity,
+			AvailableQuantity: sf.AvailableQuantity,
+		})
+	}
 	return &Order{
-		ID:              o.Id,
-		UserID:          o.UserId,
-		Status:          mapStatusFromPB(o.Status),
-		Items:           items,
-		TotalAmount:     mapMoneyFromPB(o.TotalAmount),
-		ShippingAddress: o.ShippingAddress,
-		CreatedAt:       grpc.FormatTimestamp(o.CreatedAt),
-		UpdatedAt:       grpc.FormatTimestamp(o.UpdatedAt),
+		ID:                 o.Id,
+		UserID:             o.UserId,
+		Status:             mapStatusFromPB(o.Status),
+		Items:              items,
+		Subtotal:           money.FromProto(o.Subtotal),
+		TaxAmount:          money.FromProto(o.TaxAmount),
+		ShippingCost:       money.FromProto(o.ShippingCost),
+		TotalAmount:        money.FromProto(o.TotalAmount),
+		ShippingAddress:    o.ShippingAddress,
+		ShippingCountry:    o.ShippingCountry,
+		ShippingRegion:     o.ShippingRegion,
+		Shipping:           mapAddressFromPB(o.Shipping),
+		ShippingMethod:     o.ShippingMethod,
+		CreatedAt:          grpc.FormatTimestamp(o.CreatedAt),
+		UpdatedAt:          grpc.FormatTimestamp(o.UpdatedAt),
+		CancellationReason: o.CancellationReason,
+		ShortProducts:      shortProducts,
+		PaymentMethod:      o.PaymentMethod,
 	}
 }
 
@@ -167,6 +325,8 @@ func mapStatusFromPB(s orderpb.OrderStatus) string {
 		return "PENDING"
 	case orderpb.OrderStatus_CONFIRMED:
 		return "CONFIRMED"
+	case orderpb.OrderStatus_PAYMENT_REVIEW:
+		return "PAYMENT_REVIEW"
 	case orderpb.OrderStatus_PROCESSING:
 		return "PROCESSING"
 	case orderpb.OrderStatus_SHIPPED:
@@ -3,15 +3,19 @@ package clients
 import (
 	"context"
 
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
 	paymentpb "github.com/kubernetestest/ecommerce-platform/proto-go/payment"
 	"github.com/kubernetestest/ecommerce-platform/services/api-gateway/pkg/grpc"
-	"github.com/kubernetestest/ecommerce-platform/services/api-gateway/pkg/types"
 )
 
 type PaymentClient interface {
 	Close() error
 	ProcessPayment(ctx context.Context, req *ProcessPaymentRequest) (*PaymentResponse, error)
 	GetPayment(ctx context.Context, paymentID string) (*Payment, error)
+	RefundPayment(ctx context.Context, req *RefundPaymentRequest) (*RefundResponse, error)
+	AddPaymentMethod(ctx context.Context, req *AddPaymentMethodRequest) (*SavedPaymentMethod, error)
+	ListPaymentMethods(ctx context.Context, userID string) ([]*SavedPaymentMethod, error)
+	DeletePaymentMethod(ctx context.Context, userID, token string) error
 }
 
 type paymentClient struct {
@@ -20,31 +24,77 @@ type paymentClient struct {
 }
 
 type Payment struct {
+	ID             string      `json:"id"`
+	OrderID        string      `json:"order_id"`
+	UserID         string      `json:"user_id"`
+	Amount         money.Money `json:"amount"`
+	Status         string      `json:"status"`
+	Method         string      `json:"method"`
+	TransactionID  string      `json:"transaction_id"`
+	RefundedAmount money.Money `json:"refunded_amount"`
+	RiskDecision   string      `json:"risk_decision,omitempty"` // ALLOW, REVIEW, DENY; empty until evaluated
+	RiskReasons    []string    `json:"risk_reasons,omitempty"`
+	CreatedAt      string      `json:"created_at"`
+	UpdatedAt      string      `json:"updated_at"`
+}
+
+type Refund struct {
 	ID            string      `json:"id"`
-	OrderID       string      `json:"order_id"`
-	UserID        string      `json:"user_id"`
-	Amount        types.Money `json:"amount"`
+	PaymentID     string      `json:"payment_id"`
+	Amount        money.Money `json:"amount"`
+	Reason        string      `json:"reason"`
 	Status        string      `json:"status"`
-	Method        string      `json:"method"`
 	TransactionID string      `json:"transaction_id"`
 	CreatedAt     string      `json:"created_at"`
-	UpdatedAt     string      `json:"updated_at"`
+}
+
+type RefundPaymentRequest struct {
+	PaymentID string       `json:"payment_id"`
+	Amount    *money.Money `json:"amount,omitempty"` // nil refunds the remaining amount
+	Reason    string       `json:"reason"`
+}
+
+type RefundResponse struct {
+	Payment *Payment `json:"payment"`
+	Refund  *Refund  `json:"refund"`
+	Success bool     `json:"success"`
+	Message string   `json:"message"`
 }
 
 type ProcessPaymentRequest struct {
-	OrderID string         `json:"order_id"`
-	UserID  string         `json:"user_id"`
-	Amount  types.Money    `json:"amount"`
-	Method  string         `json:"method"`
-	Details PaymentDetails `json:"details"`
+	OrderID            string      `json:"order_id"`
+	UserID             string      `json:"user_id"`
+	Amount             money.Money `json:"amount"`
+	Method             string      `json:"method"`
+	PaymentMethodToken string      `json:"payment_method_token"`
+}
+
+// SavedPaymentMethod is a card saved in the payment-service vault; only display details are exposed
+type SavedPaymentMethod struct {
+	Token          string `json:"token"`
+	Method         string `json:"method"`
+	Brand          string `json:"brand"`
+	Last4          string `json:"last4"`
+	ExpiryMonth    int32  `json:"expiry_month"`
+	ExpiryYear     int32  `json:"expiry_year"`
+	CardHolder     string `json:"card_holder"`
+	BillingCountry string `json:"billing_country,omitempty"`
+	CreatedAt      string `json:"created_at"`
+}
+
+type AddPaymentMethodRequest struct {
+	UserID string         `json:"user_id"`
+	Method string         `json:"method"`
+	Card   PaymentDetails `json:"card"`
 }
 
 type PaymentDetails struct {
-	CardNumber  string `json:"card_number"`
-	CardHolder  string `json:"card_holder"`
-	ExpiryMonth string `json:"expiry_month"`
-	ExpiryYear  string `json:"expiry_year"`
-	CVV         string `json:"cvv"`
+	CardNumber     string `json:"card_number"`
+	CardHolder     string `json:"card_holder"`
+	ExpiryMonth    string `json:"expiry_month"`
+	ExpiryYear     string `json:"expiry_year"`
+	CVV            string `json:"cvv"`
+	BillingCountry string `json:"billing_country,omitempty"`
 }
 
 type PaymentResponse struct {
@@ -66,17 +116,11 @@ func NewPaymentClient(address string) (PaymentClient, error) {
 
 func (c *paymentClient) ProcessPayment(ctx context.Context, req *ProcessPaymentRequest) (*PaymentResponse, error) {
 	grpcReq := &paymentpb.ProcessPaymentRequest{
-		OrderId: req.OrderID,
-		UserId:  req.UserID,
-		Amount:  &paymentpb.Money{Amount: req.Amount.Amount, Currency: req.Amount.Currency},
-		Method:  mapMethodToEnum(req.Method),
-		Details: &paymentpb.PaymentDetails{
-			CardNumber:  req.Details.CardNumber,
-			CardHolder:  req.Details.CardHolder,
-			ExpiryMonth: req.Details.ExpiryMonth,
-			ExpiryYear:  req.Details.ExpiryYear,
-			Cvv:         req.Details.CVV,
-		},
+		OrderId:            req.OrderID,
+		UserId:             req.UserID,
+		Amount:             money.ToProto[paymentpb.Money](req.Amount),
+		Method:             mapMethodToEnum(req.Method),
+		PaymentMethodToken: req.PaymentMethodToken,
 	}
 
 	resp, err := grpc.WithTimeoutResult(ctx, func(ctx context.Context) (*paymentpb.ProcessPaymentResponse, error) {
@@ -102,6 +146,86 @@ func (c *paymentClient) GetPayment(ctx context.Context, paymentID string) (*Paym
 	return mapPaymentFromPB(resp.Payment), nil
 }
 
+func (c *paymentClient) RefundPayment(ctx context.Context, req *RefundPaymentRequest) (*RefundResponse, error) {
+	grpcReq := &paymentpb.RefundPaymentRequest{PaymentId: req.PaymentID, Reason: req.Reason}
+	if req.Amount != nil {
+		grpcReq.Amount = money.ToProto[paymentpb.Money](*req.Amount)
+	}
+
+	resp, err := grpc.WithTimeoutResult(ctx, func(ctx context.Context) (*paymentpb.RefundPaymentResponse, error) {
+		return c.client.RefundPayment(ctx, grpcReq)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RefundResponse{
+		Payment: mapPaymentFromPB(resp.Payment),
+		Refund:  mapRefundFromPB(resp.Refund),
+		Success: resp.Success,
+		Message: resp.Message,
+	}, nil
+}
+
+func (c *paymentClient) AddPaymentMethod(ctx context.Context, req *AddPaymentMethodRequest) (*SavedPaymentMethod, error) {
+	grpcReq := &paymentpb.AddPaymentMethodRequest{
+		UserId: req.UserID,
+		Method: mapMethodToEnum(req.Method),
+		Card: &paymentpb.PaymentDetails{
+			CardNumber:     req.Card.CardNumber,
+			CardHolder:     req.Card.CardHolder,
+			ExpiryMonth:    req.Card.ExpiryMonth,
+			ExpiryYear:     req.Card.ExpiryYear,
+			Cvv:            req.Card.CVV,
+			BillingCountry: req.Card.BillingCountry,
+		},
+	}
+	resp, err := grpc.WithTimeoutResult(ctx, func(ctx context.Context) (*paymentpb.AddPaymentMethodResponse, error) {
+		return c.client.AddPaymentMethod(ctx, grpcReq)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mapSavedMethodFromPB(resp.PaymentMethod), nil
+}
+
+func (c *paymentClient) ListPaymentMethods(ctx context.Context, userID string) ([]*SavedPaymentMethod, error) {
+	resp, err := grpc.WithTimeoutResult(ctx, func(ctx context.Context) (*paymentpb.ListPaymentMethodsResponse, error) {
+		return c.client.ListPaymentMethods(ctx, &paymentpb.ListPaymentMethodsRequest{UserId: userID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	methods := make([]*SavedPaymentMethod, 0, len(resp.PaymentMethods))
+	for _, m := range resp.PaymentMethods {
+		methods = append(methods, mapSavedMethodFromPB(m))
+	}
+	return methods, nil
+}
+
+func (c *paymentClient) DeletePaymentMethod(ctx context.Context, userID, token string) error {
+	_, err := grpc.WithTimeoutResult(ctx, func(ctx context.Context) (*paymentpb.DeletePaymentMethodResponse, error) {
+		return c.client.DeletePaymentMethod(ctx, &paymentpb.DeletePaymentMethodRequest{UserId: userID, Token: token})
+	})
+	return err
+}
+
+func mapSavedMethodFromPB(m *paymentpb.SavedPaymentMethod) *SavedPaymentMethod {
+	if m == nil {
+		return nil
+	}
+	return &SavedPaymentMethod{
+		Token:          m.Token,
+		Method:         mapMethodFromEnum(m.Method),
+		Brand:          m.Brand,
+		Last4:          m.Last4,
+		ExpiryMonth:    m.ExpiryMonth,
+		ExpiryYear:     m.ExpiryYear,
+		CardHolder:     m.CardHolder,
+		BillingCountry: m.BillingCountry,
+		CreatedAt:      grpc.FormatTimestamp(m.CreatedAt),
+	}
+}
+
 func mapMethodToEnum(method string) paymentpb.PaymentMethod {
 	switch method {
 	case "CREDIT_CARD", "credit_card":
@@ -140,28 +264,76 @@ func mapStatusFromEnum(status paymentpb.PaymentStatus) string {
 		return "FAILED"
 	case paymentpb.PaymentStatus_PAYMENT_PROCESSING:
 		return "PROCESSING"
+	case paymentpb.PaymentStatus_PAYMENT_AUTHORIZED:
+		return "AUTHORIZED"
+	case paymentpb.PaymentStatus_PAYMENT_VOIDED:
+		return "VOIDED"
+	case paymentpb.PaymentStatus_PAYMENT_REFUNDED:
+		return "REFUNDED"
+	case paymentpb.PaymentStatus_PAYMENT_IN_REVIEW:
+		return "IN_REVIEW"
+	case paymentpb.PaymentStatus_PAYMENT_PARTIALLY_REFUNDED:
+		return "PARTIALLY_REFUNDED"
 	default:
 		return "PENDING"
 	}
 }
 
+func mapRiskDecisionFromEnum(d paymentpb.RiskDecision) string {
+	switch d {
+	case paymentpb.RiskDecision_RISK_ALLOW:
+		return "ALLOW"
+	case paymentpb.RiskDecision_RISK_REVIEW:
+		return "REVIEW"
+	case paymentpb.RiskDecision_RISK_DENY:
+		return "DENY"
+	default:
+		return ""
+	}
+}
+
 func mapPaymentFromPB(p *paymentpb.Payment) *Payment {
 	if p == nil {
 		return nil
 	}
-	amt := types.Money{}
-	if p.Amount != nil {
-		amt = types.Money{Amount: p.Amount.Amount, Currency: p.Amount.Currency}
-	}
 	return &Payment{
-		ID:            p.Id,
-		OrderID:       p.OrderId,
-		UserID:        p.UserId,
-		Amount:        amt,
-		Status:        mapStatusFromEnum(p.Status),
-		Method:        mapMethodFromEnum(p.Method),
-		TransactionID: p.TransactionId,
-		CreatedAt:     grpc.FormatTimestamp(p.CreatedAt),
-		UpdatedAt:     grpc.FormatTimestamp(p.UpdatedAt),
+		ID:             p.Id,
+		OrderID:        p.OrderId,
+		UserID:         p.UserId,
+		Amount:         money.FromProto(p.Amount),
+		Status:         mapStatusFromEnum(p.Status),
+		Method:         mapMethodFromEnum(p.Method),
+		TransactionID:  p.TransactionId,
+		RefundedAmount: money.FromProto(p.RefundedAmount),
+		RiskDecision:   mapRiskDecisionFromEnum(p.RiskDecision),
+		RiskReasons:    p.RiskReasons,
+		CreatedAt:      grpc.FormatTimestamp(p.CreatedAt),
+		UpdatedAt:      grpc.FormatTimestamp(p.UpdatedAt),
+	}
+}
+
+func mapRefundStatusFromEnum(status paymentpb.RefundStatus) string {
+	switch status {
+	case paymentpb.RefundStatus_REFUND_SUCCEEDED:
+		return "SUCCEEDED"
+	case paymentpb.RefundStatus_REFUND_FAILED:
+		return "FAILED"
+	default:
+		return "PENDING"
+	}
+}
+
+func mapRefundFromPB(r *paymentpb.Refund) *Refund {
+	if r == nil {
+		return nil
+	}
+	return &Refund{
+		ID:            r.Id,
+		PaymentID:     r.PaymentId,
+		Amount:        money.FromProto(r.Amount),
+		Reason:        r.Reason,
+		Status:        mapRefundStatusFromEnum(r.Status),
+		TransactionID: r.TransactionId,
+		CreatedAt:     grpc.FormatTimestamp(r.CreatedAt),
 	}
 }
@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/kubernetestest/ecommerce-platform/services/api-gateway/internal/clients"
 	"github.com/kubernetestest/ecommerce-platform/services/api-gateway/pkg/http"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type InventoryHandler struct {
@@ -19,10 +24,14 @@ func NewInventoryHandler(inventoryClient clients.InventoryClient) *InventoryHand
 func (h *InventoryHandler) GetProducts(c *gin.Context) {
 	categoryID := c.Query("category_id")
 	search := c.Query("search")
+	currency, ok := queryCurrency(c)
+	if !ok {
+		return
+	}
 	page, limit := http.GetPageLimit(c, 1, 20, 100)
-	products, total, err := h.inventoryClient.GetProducts(c.Request.Context(), categoryID, page, limit, search)
+	products, total, err := h.inventoryClient.GetProducts(c.Request.Context(), categoryID, page, limit, search, currency)
 	if err != nil {
-		http.HandleInventoryClientError(c, err, "get products")
+		http.HandleInventoryClientError(c, inventoryClientErr(err), "get products")
 		return
 	}
 	http.RespondSuccess(c, gin.H{"products": products, "page": page, "limit": limit, "total": total}, "Products retrieved successfully")
@@ -34,9 +43,13 @@ func (h *InventoryHandler) GetProduct(c *gin.Context) {
 		http.RespondBadRequest(c, "Product ID is required")
 		return
 	}
-	product, err := h.inventoryClient.GetProduct(c.Request.Context(), productID)
+	currency, ok := queryCurrency(c)
+	if !ok {
+		return
+	}
+	product, err := h.inventoryClient.GetProduct(c.Request.Context(), productID, currency)
 	if err != nil {
-		http.HandleInventoryClientError(c, err, "get product")
+		http.HandleInventoryClientError(c, inventoryClientErr(err), "get product")
 		return
 	}
 	http.RespondSuccess(c, gin.H{"product": product}, "Product retrieved successfully")
@@ -51,3 +64,26 @@ func (h *InventoryHandler) GetCategories(c *gin.Context) {
 	}
 	http.RespondSuccess(c, gin.H{"categories": categories}, "Categories retrieved successfully")
 }
+
+// queryCurrency reads the optional ?currency= the catalog is viewed in; it responds 400 for
+// values that are not ISO 4217 codes
+func queryCurrency(c *gin.Context) (string, bool) {
+	currency := strings.ToUpper(strings.TrimSpace(c.Query("currency")))
+	if currency == "" {
+		return "", true
+	}
+	if len(currency) != 3 || strings.Trim(currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		http.RespondBadRequest(c, "Currency must be an ISO 4217 code")
+		return "", false
+	}
+	return currency, true
+}
+
+// inventoryClientErr maps inventory-service gRPC statuses to gateway inventory errors; the only
+// invalid argument of a catalog read is a currency without exchange rate
+func inventoryClientErr(err error) error {
+	if status.Code(err) == codes.InvalidArgument {
+		return fmt.Errorf("%w: %v", http.ErrUnsupportedCurrency, err)
+	}
+	return err
+}
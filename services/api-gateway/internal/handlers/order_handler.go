@@ -34,6 +34,22 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 	// Set user ID from JWT context
 	req.UserID = userID
 
+	// Card orders are charged with a saved card; refuse tokens the user does not have up front
+	if req.PaymentMethodToken != "" && !h.HandlePaymentClientOperation(c, func() error {
+		methods, err := h.paymentClient.ListPaymentMethods(c.Request.Context(), userID)
+		if err != nil {
+			return paymentClientErr(err)
+		}
+		for _, m := range methods {
+			if m.Token == req.PaymentMethodToken {
+				return nil
+			}
+		}
+		return http.ErrPaymentMethodNotFound
+	}, "check payment method") {
+		return
+	}
+
 	// Check stock availability
 	var stockResponse *clients.StockCheckResponse
 	if !h.HandleInventoryClientOperation(c, func() error {
@@ -58,6 +74,22 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 	}
 }
 
+func (h *OrderHandler) QuoteShipping(c *gin.Context) {
+	var req http.QuoteShippingRequest
+	if !http.ValidateRequest(c, &req) {
+		return
+	}
+
+	var quotes []clients.ShippingQuote
+	if h.HandleOrderClientOperation(c, func() error {
+		var err error
+		quotes, err = h.orderClient.QuoteShipping(c.Request.Context(), req.ToClientRequest())
+		return err
+	}, "quote shipping") {
+		http.RespondSuccess(c, gin.H{"quotes": quotes}, "Shipping quotes retrieved successfully")
+	}
+}
+
 func (h *OrderHandler) GetUserOrders(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
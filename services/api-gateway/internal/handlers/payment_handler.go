@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"fmt"
+
 	"github.com/kubernetestest/ecommerce-platform/services/api-gateway/internal/clients"
 	"github.com/kubernetestest/ecommerce-platform/services/api-gateway/internal/middleware"
 	"github.com/kubernetestest/ecommerce-platform/services/api-gateway/pkg/http"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type PaymentHandler struct {
@@ -33,19 +37,18 @@ func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 	// Set user ID from JWT context
 	req.UserID = userID
 
-	if h.HandlePaymentClientOperation(c, func() error {
+	h.HandlePaymentClientOperation(c, func() error {
 		response, err := h.paymentClient.ProcessPayment(c.Request.Context(), req.ToClientRequest())
 		if err != nil {
-			return err
+			return paymentMethodClientErr(err)
 		}
 		if !response.Success {
 			http.RespondError(c, 402, "Payment failed")
 			return nil // Not an error, just business logic
 		}
-		return nil
-	}, "process payment") {
 		http.RespondSuccess(c, gin.H{"message": "Payment processed successfully"}, "Payment processed successfully")
-	}
+		return nil
+	}, "process payment")
 }
 
 // GetPayment retrieves payment information
@@ -80,14 +83,134 @@ func (h *PaymentHandler) GetPayment(c *gin.Context) {
 	}
 }
 
-// GetPaymentMethods returns available payment methods (mock data)
-func (h *PaymentHandler) GetPaymentMethods(c *gin.Context) {
-	methods := []map[string]interface{}{
-		{"id": "credit_card", "name": "Credit Card", "description": "Pay with Visa, MasterCard, or American Express", "enabled": true},
-		{"id": "debit_card", "name": "Debit Card", "description": "Pay directly from your bank account", "enabled": true},
-		{"id": "paypal", "name": "PayPal", "description": "Pay with your PayPal account", "enabled": false},
+// RefundPayment refunds a payment of the authenticated user fully or partially
+func (h *PaymentHandler) RefundPayment(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		http.RespondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	paymentID, ok := h.RequireParam(c, "id")
+	if !ok {
+		return // Error response already sent by RequireParam
+	}
+
+	var req http.RefundPaymentRequest
+	if !http.ValidateRequest(c, &req) {
+		return
+	}
+
+	h.HandlePaymentClientOperation(c, func() error {
+		// Verify the payment belongs to the authenticated user before refunding
+		payment, err := h.paymentClient.GetPayment(c.Request.Context(), paymentID)
+		if err != nil {
+			return paymentClientErr(err)
+		}
+		if payment.UserID != userID {
+			http.RespondForbidden(c, "Access denied: payment does not belong to user")
+			return nil
+		}
+
+		response, err := h.paymentClient.RefundPayment(c.Request.Context(), req.ToClientRequest(paymentID))
+		if err != nil {
+			return paymentClientErr(err)
+		}
+		if !response.Success {
+			http.RespondError(c, 402, "Refund declined: "+response.Message)
+			return nil // Not an error, just business logic
+		}
+
+		http.RespondCreated(c, gin.H{"refund": response.Refund, "payment": response.Payment}, "Refund processed successfully")
+		return nil
+	}, "refund payment")
+}
+
+// paymentClientErr maps payment-service gRPC statuses to gateway payment errors
+func paymentClientErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch status.Code(err) {
+	case codes.NotFound:
+		return fmt.Errorf("%w: %v", http.ErrPaymentNotFound, err)
+	case codes.InvalidArgument:
+		return fmt.Errorf("%w: %v", http.ErrInvalidPaymentData, err)
+	case codes.FailedPrecondition:
+		return fmt.Errorf("%w: %v", http.ErrPaymentRefundFailed, err)
+	default:
+		return err
+	}
+}
+
+// paymentMethodClientErr maps payment-service statuses of calls taking a saved method token;
+// an unknown token is a missing payment method rather than a missing payment
+func paymentMethodClientErr(err error) error {
+	if status.Code(err) == codes.NotFound {
+		return fmt.Errorf("%w: %v", http.ErrPaymentMethodNotFound, err)
+	}
+	return paymentClientErr(err)
+}
+
+// ListPaymentMethods returns the cards the authenticated user saved (brand, last4 and expiry only)
+func (h *PaymentHandler) ListPaymentMethods(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		http.RespondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	var methods []*clients.SavedPaymentMethod
+	if h.HandlePaymentClientOperation(c, func() error {
+		var err error
+		methods, err = h.paymentClient.ListPaymentMethods(c.Request.Context(), userID)
+		return paymentClientErr(err)
+	}, "list payment methods") {
+		http.RespondSuccess(c, gin.H{"payment_methods": methods}, "Payment methods retrieved successfully")
+	}
+}
+
+// AddPaymentMethod saves a card of the authenticated user in the payment vault and returns its token
+func (h *PaymentHandler) AddPaymentMethod(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		http.RespondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req http.AddPaymentMethodRequest
+	if !http.ValidateRequest(c, &req) {
+		return
+	}
+
+	var method *clients.SavedPaymentMethod
+	if h.HandlePaymentClientOperation(c, func() error {
+		var err error
+		method, err = h.paymentClient.AddPaymentMethod(c.Request.Context(), req.ToClientRequest(userID))
+		return paymentClientErr(err)
+	}, "add payment method") {
+		http.RespondCreated(c, gin.H{"payment_method": method}, "Payment method saved successfully")
+	}
+}
+
+// DeletePaymentMethod removes a saved card of the authenticated user
+func (h *PaymentHandler) DeletePaymentMethod(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		http.RespondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	token, ok := h.RequireParam(c, "token")
+	if !ok {
+		return // Error response already sent by RequireParam
+	}
+
+	if h.HandlePaymentClientOperation(c, func() error {
+		return paymentMethodClientErr(h.paymentClient.DeletePaymentMethod(c.Request.Context(), userID, token))
+	}, "delete payment method") {
+		http.RespondSuccess(c, gin.H{"token": token}, "Payment method deleted successfully")
 	}
-	http.RespondSuccess(c, gin.H{"methods": methods}, "Mock payment methods - for demo purposes only")
 }
 
 // GetTestCards returns test card numbers for demo
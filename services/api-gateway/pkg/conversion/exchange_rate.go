@@ -0,0 +1,30 @@
+package conversion
+
+import (
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/services/api-gateway/pkg/types"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// PBExchangeRate is a minimal interface satisfied by protobuf ExchangeRate messages.
+type PBExchangeRate interface {
+	GetFrom() string
+	GetTo() string
+	GetRate() float64
+	GetAsOf() *timestamppb.Timestamp
+}
+
+// ExchangeRateFromPB converts a protobuf ExchangeRate-like message to internal types.ExchangeRate.
+// Returns nil when r is nil or empty, i.e. the price was not converted.
+func ExchangeRateFromPB(r PBExchangeRate) *types.ExchangeRate {
+	if r == nil || r.GetFrom() == "" {
+		return nil
+	}
+	out := &types.ExchangeRate{From: r.GetFrom(), To: r.GetTo(), Rate: r.GetRate()}
+	if ts := r.GetAsOf(); ts != nil {
+		out.AsOf = ts.AsTime().Format(time.RFC3339)
+	}
+	return out
+}
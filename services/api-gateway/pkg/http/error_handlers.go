@@ -26,12 +26,13 @@ var (
 	ErrMissingUserID     = errors.New("user_id is required for order operations")
 
 	// Payment Domain Errors
-	ErrPaymentNotFound     = errors.New("payment not found")
-	ErrPaymentFailed       = errors.New("payment processing failed")
-	ErrPaymentRefundFailed = errors.New("payment refund failed")
-	ErrInvalidPaymentData  = errors.New("invalid payment data")
-	ErrInsufficientFunds   = errors.New("insufficient funds")
-	ErrCardDeclined        = errors.New("card declined")
+	ErrPaymentNotFound       = errors.New("payment not found")
+	ErrPaymentFailed         = errors.New("payment processing failed")
+	ErrPaymentRefundFailed   = errors.New("payment refund failed")
+	ErrInvalidPaymentData    = errors.New("invalid payment data")
+	ErrInsufficientFunds     = errors.New("insufficient funds")
+	ErrCardDeclined          = errors.New("card declined")
+	ErrPaymentMethodNotFound = errors.New("payment method not found")
 
 	// Inventory Domain Errors
 	ErrProductNotFound       = errors.New("product not found")
@@ -40,6 +41,7 @@ var (
 	ErrProductUpdateFailed   = errors.New("failed to update product")
 	ErrInvalidProductData    = errors.New("invalid product data")
 	ErrStockUpdateFailed     = errors.New("failed to update stock")
+	ErrUnsupportedCurrency   = errors.New("unsupported currency")
 )
 
 // ========== Error Handlers ==========
@@ -109,6 +111,10 @@ func HandlePaymentClientError(c *gin.Context, err error, operation string) {
 		RespondNotFound(c, "Payment not found")
 		return
 	}
+	if errors.Is(err, ErrPaymentMethodNotFound) {
+		RespondNotFound(c, "Payment method not found")
+		return
+	}
 	if errors.Is(err, ErrPaymentFailed) {
 		RespondError(c, 402, "Payment processing failed")
 		return
@@ -160,6 +166,10 @@ func HandleInventoryClientError(c *gin.Context, err error, operation string) {
 		RespondInternalError(c, "Failed to update stock")
 		return
 	}
+	if errors.Is(err, ErrUnsupportedCurrency) {
+		RespondBadRequest(c, "Prices are not available in the requested currency")
+		return
+	}
 
 	// Default case
 	RespondInternalError(c, "Failed to "+operation)
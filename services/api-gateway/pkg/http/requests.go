@@ -1,8 +1,10 @@
 package http
 
 import (
+	"strings"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
 	"github.com/kubernetestest/ecommerce-platform/services/api-gateway/internal/clients"
-	"github.com/kubernetestest/ecommerce-platform/services/api-gateway/pkg/types"
 )
 
 // ========== User Requests ==========
@@ -45,11 +47,21 @@ func (r *LoginRequest) ToClientRequest() *clients.LoginRequest {
 
 // CreateOrderRequest contains information for creating an order
 type CreateOrderRequest struct {
-	UserID          string             `json:"user_id" binding:"required" msg:"User ID is required"`
-	Items           []OrderItemRequest `json:"items" binding:"required,min=1,dive" msg:"At least one item is required"`
-	ShippingAddress string             `json:"shipping_address" binding:"required,min=10,max=200" msg:"Shipping address must be between 10 and 200 characters"`
-	PaymentDetails  PaymentDetails     `json:"payment_details" binding:"required" msg:"Payment details are required"`
-	PaymentMethod   string             `json:"payment_method" binding:"required,oneof=credit_card debit_card paypal" msg:"Payment method must be credit_card, debit_card, or paypal"`
+	UserID             string             `json:"user_id" binding:"required" msg:"User ID is required"`
+	Items              []OrderItemRequest `json:"items" binding:"required,min=1,dive" msg:"At least one item is required"`
+	Shipping           *AddressRequest    `json:"shipping" binding:"required_without=ShippingAddress,omitempty" msg:"Shipping address is required"`
+	ShippingMethod     string             `json:"shipping_method" binding:"omitempty,max=32" msg:"Shipping method must be one of the quoted methods"`
+	PaymentMethodToken string             `json:"payment_method_token" binding:"required" msg:"A saved payment method token is required"`
+	PaymentMethod      string             `json:"payment_method" binding:"required,oneof=credit_card debit_card" msg:"Payment method must be credit_card or debit_card"`
+	Currency           string             `json:"currency" binding:"omitempty,iso4217" msg:"Currency must be an ISO 4217 code"`
+
+	// Deprecated: one-line address of clients that do not send shipping yet, ignored when
+	// shipping is set. Removed in the next release.
+	ShippingAddress string `json:"shipping_address" binding:"omitempty,min=10,max=200" msg:"Shipping address must be between 10 and 200 characters"`
+	// Deprecated: use shipping.country
+	ShippingCountry string `json:"shipping_country" binding:"omitempty,iso3166_1_alpha2" msg:"Shipping country must be an ISO 3166-1 alpha-2 code"`
+	// Deprecated: use shipping.region
+	ShippingRegion string `json:"shipping_region" binding:"omitempty,excluded_without=ShippingCountry,max=6" msg:"Shipping region must be an ISO 3166-2 subdivision code and needs shipping_country"`
 }
 
 // ToClientRequest converts CreateOrderRequest to clients.CreateOrderRequest
@@ -59,11 +71,23 @@ func (r *CreateOrderRequest) ToClientRequest() *clients.CreateOrderRequest {
 		items[i] = *item.ToClientRequest()
 	}
 
-	return &clients.CreateOrderRequest{
-		UserID:          r.UserID,
-		Items:           items,
-		ShippingAddress: r.ShippingAddress,
+	req := &clients.CreateOrderRequest{
+		UserID:             r.UserID,
+		Items:              items,
+		ShippingMethod:     strings.ToUpper(r.ShippingMethod),
+		PaymentMethod:      strings.ToUpper(r.PaymentMethod),
+		PaymentMethodToken: r.PaymentMethodToken,
+		Currency:           strings.ToUpper(r.Currency),
+	}
+	if r.Shipping != nil {
+		addr := r.Shipping.ToClientRequest()
+		req.Shipping = &addr
+	} else {
+		req.LegacyShippingAddress = r.ShippingAddress
+		req.LegacyShippingCountry = strings.ToUpper(r.ShippingCountry)
+		req.LegacyShippingRegion = strings.ToUpper(r.ShippingRegion)
 	}
+	return req
 }
 
 // ToStockCheckRequest converts CreateOrderRequest to clients.StockCheckRequest
@@ -93,41 +117,120 @@ func (r *OrderItemRequest) ToClientRequest() *clients.OrderItemRequest {
 	}
 }
 
-// PaymentDetails contains payment information
+// AddressRequest contains the address an order ships to; the order service checks postal codes
+// and regions per country
+type AddressRequest struct {
+	Recipient  string `json:"recipient" binding:"required,min=2,max=100" msg:"Recipient must be between 2 and 100 characters"`
+	Line1      string `json:"line1" binding:"required,max=200" msg:"Address line is required and at most 200 characters"`
+	Line2      string `json:"line2" binding:"omitempty,max=200" msg:"Second address line must be at most 200 characters"`
+	City       string `json:"city" binding:"required,max=100" msg:"City is required and at most 100 characters"`
+	Region     string `json:"region" binding:"omitempty,max=6" msg:"Region must be an ISO 3166-2 subdivision code"`
+	PostalCode string `json:"postal_code" binding:"omitempty,max=16" msg:"Postal code must be at most 16 characters"`
+	Country    string `json:"country" binding:"required,iso3166_1_alpha2" msg:"Country must be an ISO 3166-1 alpha-2 code"`
+}
+
+// ToClientRequest converts AddressRequest to clients.Address
+func (r *AddressRequest) ToClientRequest() clients.Address {
+	return clients.Address{
+		Recipient:  r.Recipient,
+		Line1:      r.Line1,
+		Line2:      r.Line2,
+		City:       r.City,
+		Region:     strings.ToUpper(r.Region),
+		PostalCode: strings.ToUpper(r.PostalCode),
+		Country:    strings.ToUpper(r.Country),
+	}
+}
+
+// QuoteShippingRequest contains the cart and destination to quote shipping methods for
+type QuoteShippingRequest struct {
+	Shipping AddressRequest     `json:"shipping" binding:"required" msg:"Shipping address is required"`
+	Items    []OrderItemRequest `json:"items" binding:"required,min=1,dive" msg:"At least one item is required"`
+	Currency string             `json:"currency" binding:"omitempty,iso4217" msg:"Currency must be an ISO 4217 code"`
+}
+
+// ToClientRequest converts QuoteShippingRequest to clients.QuoteShippingRequest
+func (r *QuoteShippingRequest) ToClientRequest() *clients.QuoteShippingRequest {
+	items := make([]clients.OrderItemRequest, len(r.Items))
+	for i, item := range r.Items {
+		items[i] = *item.ToClientRequest()
+	}
+
+	return &clients.QuoteShippingRequest{
+		Shipping: r.Shipping.ToClientRequest(),
+		Items:    items,
+		Currency: strings.ToUpper(r.Currency),
+	}
+}
+
+// PaymentDetails contains the card a user saves as payment method
 type PaymentDetails struct {
 	CardNumber  string `json:"card_number" binding:"required,min=12,max=19" msg:"Card number must be between 12 and 19 digits"`
 	CardHolder  string `json:"card_holder" binding:"required,min=2,max=100" msg:"Card holder name must be between 2 and 100 characters"`
 	ExpiryMonth string `json:"expiry_month" binding:"required,len=2" msg:"Expiry month must be 2 digits (MM)"`
 	ExpiryYear  string `json:"expiry_year" binding:"required,len=4" msg:"Expiry year must be 4 digits (YYYY)"`
-	CVV         string `json:"cvv" binding:"required,len=3" msg:"CVV must be 3 digits"`
+	CVV         string `json:"cvv" binding:"required,min=3,max=4" msg:"CVV must be 3 or 4 digits"`
+	// BillingCountry is the ISO 3166-1 alpha-2 country of the card, optional
+	BillingCountry string `json:"billing_country" binding:"omitempty,iso3166_1_alpha2" msg:"Billing country must be an ISO 3166-1 alpha-2 code"`
 }
 
 // ========== Payment Requests ==========
 
 // ProcessPaymentRequest contains information for processing payment
 type ProcessPaymentRequest struct {
-	OrderID string         `json:"order_id" binding:"required,uuid" msg:"Valid order ID is required"`
-	UserID  string         `json:"user_id" binding:"required,uuid" msg:"Valid user ID is required"`
-	Amount  types.Money    `json:"amount" binding:"required" msg:"Payment amount is required"`
-	Details PaymentDetails `json:"details" binding:"required" msg:"Payment details are required"`
+	OrderID            string      `json:"order_id" binding:"required,uuid" msg:"Valid order ID is required"`
+	UserID             string      `json:"user_id" binding:"required,uuid" msg:"Valid user ID is required"`
+	Amount             money.Money `json:"amount" binding:"required" msg:"Payment amount is required"`
+	PaymentMethodToken string      `json:"payment_method_token" binding:"required" msg:"A saved payment method token is required"`
 }
 
 // ToClientRequest converts ProcessPaymentRequest to clients.ProcessPaymentRequest
 func (r *ProcessPaymentRequest) ToClientRequest() *clients.ProcessPaymentRequest {
 	return &clients.ProcessPaymentRequest{
-		OrderID: r.OrderID,
-		UserID:  r.UserID,
-		Amount:  r.Amount,
-		Details: clients.PaymentDetails{
-			CardNumber:  r.Details.CardNumber,
-			CardHolder:  r.Details.CardHolder,
-			ExpiryMonth: r.Details.ExpiryMonth,
-			ExpiryYear:  r.Details.ExpiryYear,
-			CVV:         r.Details.CVV,
+		OrderID:            r.OrderID,
+		UserID:             r.UserID,
+		Amount:             r.Amount,
+		PaymentMethodToken: r.PaymentMethodToken,
+	}
+}
+
+// AddPaymentMethodRequest contains a card to save in the payment vault
+type AddPaymentMethodRequest struct {
+	Method string         `json:"method" binding:"required,oneof=credit_card debit_card" msg:"Method must be credit_card or debit_card"`
+	Card   PaymentDetails `json:"card" binding:"required" msg:"Card details are required"`
+}
+
+// ToClientRequest converts AddPaymentMethodRequest to clients.AddPaymentMethodRequest
+func (r *AddPaymentMethodRequest) ToClientRequest(userID string) *clients.AddPaymentMethodRequest {
+	return &clients.AddPaymentMethodRequest{
+		UserID: userID,
+		Method: strings.ToUpper(r.Method),
+		Card: clients.PaymentDetails{
+			CardNumber:     r.Card.CardNumber,
+			CardHolder:     r.Card.CardHolder,
+			ExpiryMonth:    r.Card.ExpiryMonth,
+			ExpiryYear:     r.Card.ExpiryYear,
+			CVV:            r.Card.CVV,
+			BillingCountry: strings.ToUpper(r.Card.BillingCountry),
 		},
 	}
 }
 
+// RefundPaymentRequest contains information for refunding a payment
+type RefundPaymentRequest struct {
+	Amount *money.Money `json:"amount" binding:"omitempty" msg:"Refund amount is optional; omit to refund the remaining amount"`
+	Reason string       `json:"reason" binding:"required,min=3,max=500" msg:"Refund reason must be between 3 and 500 characters"`
+}
+
+// ToClientRequest converts RefundPaymentRequest to clients.RefundPaymentRequest
+func (r *RefundPaymentRequest) ToClientRequest(paymentID string) *clients.RefundPaymentRequest {
+	return &clients.RefundPaymentRequest{
+		PaymentID: paymentID,
+		Amount:    r.Amount,
+		Reason:    r.Reason,
+	}
+}
+
 // ========== Inventory Requests ==========
 
 // StockCheckRequest contains information for checking stock availability
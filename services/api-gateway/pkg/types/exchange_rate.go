@@ -0,0 +1,9 @@
+package types
+
+// ExchangeRate is the rate a price was converted with: amount in To = amount in From * Rate
+type ExchangeRate struct {
+	From string  `json:"from"`
+	To   string  `json:"to"`
+	Rate float64 `json:"rate"`
+	AsOf string  `json:"as_of"` // when the rate was published
+}
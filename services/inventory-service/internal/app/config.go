@@ -3,6 +3,10 @@ package app
 import (
 	"os"
 	"strconv"
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/exchange"
+	kafkaclient "github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
 )
 
 type Config struct {
@@ -13,17 +17,40 @@ type Config struct {
 	DBUser                string
 	DBPass                string
 	ReservationTTLSeconds int
+	// ReservationReviewTTLSeconds is how long reservations are held while a payment is in risk
+	// review; keep it above the order-service SAGA_REVIEW_TIMEOUT
+	ReservationReviewTTLSeconds int
+	// ReservationSweepIntervalSeconds controls how often expired reservations are released
+	ReservationSweepIntervalSeconds int
+	// KafkaIdempotentConsumers enables the processed-message ledger for Kafka consumers
+	KafkaIdempotentConsumers bool
+	// ExchangeRates lets the catalog be viewed in other currencies; source none only shows
+	// catalog prices
+	ExchangeRates exchange.Config
+	// Bus replaces the Kafka bus of KAFKA_BROKERS, e.g. with a MemoryBus in tests
+	Bus kafkaclient.Bus
 }
 
 func LoadConfigFromEnv() *Config {
 	return &Config{
-		Port:                  getEnv("PORT", "50053"),
-		DBHost:                getEnv("DB_HOST", "localhost"),
-		DBPort:                getEnv("DB_PORT", "5432"),
-		DBName:                getEnv("DB_NAME", "inventorydb"),
-		DBUser:                getEnv("DB_USER", "admin"),
-		DBPass:                getEnv("DB_PASSWORD", "password"),
-		ReservationTTLSeconds: getEnvInt("RESERVATION_TTL_SECONDS", 900),
+		Port:                            getEnv("PORT", "50053"),
+		DBHost:                          getEnv("DB_HOST", "localhost"),
+		DBPort:                          getEnv("DB_PORT", "5432"),
+		DBName:                          getEnv("DB_NAME", "inventorydb"),
+		DBUser:                          getEnv("DB_USER", "admin"),
+		DBPass:                          getEnv("DB_PASSWORD", "password"),
+		ReservationTTLSeconds:           getEnvInt("RESERVATION_TTL_SECONDS", 900),
+		ReservationReviewTTLSeconds:     getEnvInt("RESERVATION_REVIEW_TTL_SECONDS", 73*3600),
+		ReservationSweepIntervalSeconds: getEnvInt("RESERVATION_SWEEP_INTERVAL_SECONDS", 30),
+		KafkaIdempotentConsumers:        getEnv("KAFKA_IDEMPOTENT_CONSUMERS", "true") == "true",
+		ExchangeRates: exchange.Config{
+			Source:  getEnv("EXCHANGE_RATES_SOURCE", exchange.SourceNone),
+			File:    getEnv("EXCHANGE_RATES_FILE", ""),
+			URL:     getEnv("EXCHANGE_RATES_URL", "http://localhost:8092"),
+			Timeout: getEnvDuration("EXCHANGE_RATES_TIMEOUT", 3*time.Second),
+			Refresh: getEnvDuration("EXCHANGE_RATES_REFRESH", 5*time.Minute),
+			MaxAge:  getEnvDuration("EXCHANGE_RATES_MAX_AGE", 48*time.Hour),
+		},
 	}
 }
 
@@ -42,3 +69,12 @@ func getEnvInt(key string, def int) int {
 	}
 	return def
 }
+
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return def
+}
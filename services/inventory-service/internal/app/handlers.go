@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	kafkaclient "github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
+	"github.com/kubernetestest/ecommerce-platform/proto-go/events"
+	appsvc "github.com/kubernetestest/ecommerce-platform/services/inventory-service/internal/app/services"
+	con "github.com/kubernetestest/ecommerce-platform/services/inventory-service/internal/infra/kafka/consumer"
+	repoport "github.com/kubernetestest/ecommerce-platform/services/inventory-service/internal/ports/repository"
+
+	"go.uber.org/zap"
+)
+
+// orderCreatedHandler reserves the stock of new orders; the outcome is published by svc
+func orderCreatedHandler(svc *appsvc.InventoryService, log *zap.SugaredLogger) con.OrderCreatedHandlerFunc {
+	return func(ctx context.Context, evt *events.OrderCreatedV2) error {
+		items := make([]appsvc.StockCheckItem, 0, len(evt.Items))
+		for _, it := range evt.Items {
+			items = append(items, appsvc.StockCheckItem{ProductID: it.ProductId, Quantity: it.Quantity})
+		}
+		shortfalls, err := svc.ReserveStock(ctx, evt.OrderId, evt.UserId, items)
+		switch {
+		case errors.Is(err, repoport.ErrActiveReservationExists):
+			log.Infow("stock already reserved", "orderId", evt.OrderId)
+		case err != nil:
+			log.Errorw("failed to reserve stock", "orderId", evt.OrderId, "error", err)
+			return err
+		case len(shortfalls) > 0:
+			log.Infow("stock reservation rejected", "orderId", evt.OrderId, "shortfalls", shortfalls)
+		}
+		return nil
+	}
+}
+
+// paymentProcessedHandler commits the reservation of paid orders and releases the others.
+// A paid order without a reservation is dead-lettered right away: retrying cannot bring the
+// stock back.
+func paymentProcessedHandler(svc *appsvc.InventoryService, log *zap.SugaredLogger) con.PaymentProcessedHandlerFunc {
+	return func(ctx context.Context, evt *events.PaymentProcessed) error {
+		err := svc.FinalizeReservation(ctx, evt.OrderId, evt.Success)
+		switch {
+		case errors.Is(err, repoport.ErrNoActiveReservation):
+			log.Errorw("paid order has no stock reservation to commit", "orderId", evt.OrderId, "paymentId", evt.PaymentId)
+			return kafkaclient.Permanent(err)
+		case err != nil:
+			log.Errorw("failed to finalize reservation", "orderId", evt.OrderId, "success", evt.Success, "error", err)
+			return err
+		}
+		return nil
+	}
+}
+
+// paymentReviewRequiredHandler holds the reservation of orders whose payment is in risk review
+func paymentReviewRequiredHandler(svc *appsvc.InventoryService, log *zap.SugaredLogger) con.PaymentReviewRequiredHandlerFunc {
+	return func(ctx context.Context, evt *events.PaymentReviewRequired) error {
+		err := svc.HoldForReview(ctx, evt.OrderId)
+		switch {
+		case errors.Is(err, repoport.ErrNoActiveReservation):
+			log.Errorw("no stock reservation to hold for payment review", "orderId", evt.OrderId, "paymentId", evt.PaymentId)
+			return kafkaclient.Permanent(err)
+		case err != nil:
+			log.Errorw("failed to hold reservation for payment review", "orderId", evt.OrderId, "error", err)
+			return err
+		}
+		return nil
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"net"
 	"time"
 
+	"github.com/kubernetestest/ecommerce-platform/pkg/eventschema"
+	"github.com/kubernetestest/ecommerce-platform/pkg/exchange"
+	kafkaclient "github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
 	pkglogger "github.com/kubernetestest/ecommerce-platform/pkg/logger"
-	"github.com/kubernetestest/ecommerce-platform/proto-go/events"
 	invpb "github.com/kubernetestest/ecommerce-platform/proto-go/inventory"
 	appsvc "github.com/kubernetestest/ecommerce-platform/services/inventory-service/internal/app/services"
 	"github.com/kubernetestest/ecommerce-platform/services/inventory-service/internal/domain/models"
@@ -15,6 +17,7 @@ import (
 	con "github.com/kubernetestest/ecommerce-platform/services/inventory-service/internal/infra/kafka/consumer"
 	pub "github.com/kubernetestest/ecommerce-platform/services/inventory-service/internal/infra/kafka/publisher"
 	repo "github.com/kubernetestest/ecommerce-platform/services/inventory-service/internal/infra/repository"
+	"github.com/kubernetestest/ecommerce-platform/services/inventory-service/internal/infra/reservation"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -60,9 +63,9 @@ func Run(ctx context.Context, cfg *Config, logger *zap.Logger) error {
 
 		// Seed default catalog and stock: 3 of prod-1, 1 of prod-2 (Smart Watch), 2 of prod-3 (Mug)
 		seedProducts := []*models.Product{
-			{ID: "prod-1", Name: "Wireless Headphones", Description: "High-quality wireless headphones with noise cancellation", PriceMinor: 9999, Currency: "USD", CategoryID: "cat-1", CategoryName: "Electronics", ImageURL: "/images/headphones.jpg", IsActive: true},
-			{ID: "prod-2", Name: "Smart Watch", Description: "Fitness tracking smart watch with heart rate monitor", PriceMinor: 19999, Currency: "USD", CategoryID: "cat-1", CategoryName: "Electronics", ImageURL: "/images/smartwatch.jpg", IsActive: true},
-			{ID: "prod-3", Name: "Coffee Mug", Description: "Ceramic coffee mug with custom design", PriceMinor: 1599, Currency: "USD", CategoryID: "cat-2", CategoryName: "Home & Kitchen", ImageURL: "/images/mug.jpg", IsActive: true},
+			{ID: "prod-1", Name: "Wireless Headphones", Description: "High-quality wireless headphones with noise cancellation", PriceMinor: 9999, Currency: "USD", CategoryID: "cat-1", CategoryName: "Electronics", ImageURL: "/images/headphones.jpg", IsActive: true, WeightGrams: 450},
+			{ID: "prod-2", Name: "Smart Watch", Description: "Fitness tracking smart watch with heart rate monitor", PriceMinor: 19999, Currency: "USD", CategoryID: "cat-1", CategoryName: "Electronics", ImageURL: "/images/smartwatch.jpg", IsActive: true, WeightGrams: 200},
+			{ID: "prod-3", Name: "Coffee Mug", Description: "Ceramic coffee mug with custom design", PriceMinor: 1599, Currency: "USD", CategoryID: "cat-2", CategoryName: "Home & Kitchen", ImageURL: "/images/mug.jpg", IsActive: true, WeightGrams: 500},
 		}
 		for _, p := range seedProducts {
 			_ = db.Clauses(clause.OnConflict{UpdateAll: true}).Create(p).Error
@@ -77,45 +80,100 @@ func Run(ctx context.Context, cfg *Config, logger *zap.Logger) error {
 		}
 	}
 
-	svc := appsvc.NewInventoryService(gormRepo).WithReservationTTL(time.Duration(cfg.ReservationTTLSeconds) * time.Second)
+	svc := appsvc.NewInventoryService(gormRepo).
+		WithReservationTTL(time.Duration(cfg.ReservationTTLSeconds) * time.Second).
+		WithReviewHoldTTL(time.Duration(cfg.ReservationReviewTTLSeconds) * time.Second)
+
+	// Exchange rates let customers view the catalog in their preferred currency
+	rates, err := exchange.NewProvider(cfg.ExchangeRates)
+	if err != nil {
+		log.Errorw("failed to init exchange rates", "source", cfg.ExchangeRates.Source, "error", err)
+		return err
+	}
+	if rates != nil {
+		svc = svc.WithExchangeRates(rates)
+	}
+
+	// Sweeper releases stale reservations; safe to run on every replica
+	sweeper := reservation.NewSweeper(gormRepo, reservation.SweeperConfig{
+		Interval: time.Duration(cfg.ReservationSweepIntervalSeconds) * time.Second,
+	}).WithLogger(pkglogger.NewZapLogger(log))
+	go func() { _ = sweeper.Run(ctx) }()
+
+	// Message bus shared by Kafka producers and consumers
+	bus := cfg.Bus
+	if brokers := getEnv("KAFKA_BROKERS", "kafka:9092"); bus == nil && brokers != "" {
+		bus = kafkaclient.NewKafkaBus(brokers)
+	}
 
 	// Kafka producer for inventory events (best-effort)
-	if brokers := getEnv("KAFKA_BROKERS", "kafka:9092"); brokers != "" {
-		if p, err := pub.NewStockEventsPublisher(brokers, "inventory.v1.stock_reserved", "inventory.v1.stock_reservation_failed"); err == nil {
+	if bus != nil {
+		if p, err := pub.NewStockEventsPublisher(bus, eventschema.StockReservedTopic, eventschema.StockReservationFailedTopic); err == nil {
 			defer p.Close()
 			p = p.WithLogger(pkglogger.NewZapLogger(log))
 			svc = svc.WithPublisher(p)
 		}
 	}
 
-	// Kafka consumer for order created (best-effort)
-	if brokers := getEnv("KAFKA_BROKERS", "kafka:9092"); brokers != "" {
-		handler := con.OrderCreatedHandlerFunc(func(cctx context.Context, evt *events.OrderCreated) error {
-			items := make([]appsvc.StockCheckItem, 0, len(evt.Items))
-			for _, it := range evt.Items {
-				items = append(items, appsvc.StockCheckItem{ProductID: it.ProductId, Quantity: it.Quantity})
-			}
-			failed, rerr := svc.ReserveStock(cctx, evt.OrderId, evt.UserId, items)
-			if rerr != nil {
-				log.Errorw("failed to reserve stock", "orderId", evt.OrderId, "failedProducts", failed, "error", rerr)
+	// Processed-message ledger deduplicates redelivered Kafka events
+	var processed kafkaclient.ProcessedStore
+	if cfg.KafkaIdempotentConsumers {
+		ledger := kafkaclient.NewGormProcessedStore(db)
+		if getEnv("AUTO_MIGRATE", "") == "true" {
+			if err := ledger.AutoMigrate(); err != nil {
+				log.Errorw("failed to automigrate processed messages", "error", err)
+				return err
 			}
-			return nil
-		})
-		if cons, err := con.NewConsumer(brokers, "inventory-service", handler); err == nil {
+		}
+		processed = ledger
+	}
+
+	// Kafka consumer for order created (best-effort)
+	if bus != nil {
+		// Dead-letter publisher for events that keep failing
+		var dlq kafkaclient.DeadLetterPublisher
+		if p, err := bus.NewPublisher(kafkaclient.PublisherConfig{ClientID: "inventory-service-dlq"}); err == nil {
+			defer p.Close()
+			p.WithLogger(pkglogger.NewZapLogger(log))
+			dlq = p
+		} else {
+			log.Warnw("kafka dead-letter producer init failed", "error", err)
+		}
+
+		if cons, err := con.NewConsumer(bus, "inventory-service", orderCreatedHandler(svc, log)); err == nil {
 			defer cons.Close()
 			cons.WithLogger(pkglogger.NewZapLogger(log))
-			go cons.Run(ctx, []string{"orders.v1.order_created"})
+			if processed != nil {
+				cons.WithIdempotency(processed)
+			}
+			if dlq != nil {
+				cons.WithDeadLetter(dlq)
+			}
+			go cons.Run(ctx, []string{eventschema.OrderCreatedTopic})
 		}
 		// Consume payment outcomes to finalize or release reservations
-		if payCons, err := con.NewPaymentConsumer(brokers, "inventory-service", con.PaymentProcessedHandlerFunc(func(cctx context.Context, evt *events.PaymentProcessed) error {
-			if ferr := svc.FinalizeReservation(cctx, evt.OrderId, evt.Success); ferr != nil {
-				log.Errorw("failed to finalize reservation", "orderId", evt.OrderId, "success", evt.Success, "error", ferr)
-			}
-			return nil
-		})); err == nil {
+		if payCons, err := con.NewPaymentConsumer(bus, "inventory-service", paymentProcessedHandler(svc, log)); err == nil {
 			defer payCons.Close()
 			payCons.WithLogger(pkglogger.NewZapLogger(log))
-			go payCons.Run(ctx, []string{"payments.v1.payment_processed"})
+			if processed != nil {
+				payCons.WithIdempotency(processed)
+			}
+			if dlq != nil {
+				payCons.WithDeadLetter(dlq)
+			}
+			go payCons.Run(ctx, []string{eventschema.PaymentProcessedTopic})
+		}
+		// Hold reservations of payments in risk review until the review ends
+		if reviewCons, err := con.NewPaymentReviewRequiredConsumer(bus, "inventory-service-payment-review", paymentReviewRequiredHandler(svc, log)); err == nil {
+			defer reviewCons.Close()
+			reviewCons.WithLogger(pkglogger.NewZapLogger(log))
+			if processed != nil {
+				reviewCons.WithIdempotency(processed)
+			}
+			if dlq != nil {
+				reviewCons.WithDeadLetter(dlq)
+			}
+			go reviewCons.Run(ctx, []string{eventschema.PaymentReviewRequiredTopic})
 		}
 	}
 
@@ -3,40 +3,91 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"github.com/kubernetestest/ecommerce-platform/pkg/exchange"
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
 	"github.com/kubernetestest/ecommerce-platform/services/inventory-service/internal/domain/models"
 	invpub "github.com/kubernetestest/ecommerce-platform/services/inventory-service/internal/ports/events"
 	"github.com/kubernetestest/ecommerce-platform/services/inventory-service/internal/ports/repository"
-	"sync"
+	"strings"
 	"time"
 
 	events "github.com/kubernetestest/ecommerce-platform/proto-go/events"
-
-	"go.uber.org/multierr"
 )
 
+// DefaultReservationTTL is used when no positive TTL is configured
+const DefaultReservationTTL = 15 * time.Minute
+
+// DefaultReviewHoldTTL is how long reservations are held for a payment in risk review when no
+// positive TTL is configured; it outlasts the order saga's default review timeout of 72h
+const DefaultReviewHoldTTL = 73 * time.Hour
+
 type InventoryService struct {
 	repo repository.InventoryRepository
 	pub  invpub.Publisher
 
-	mu              sync.Mutex
-	reservedByOrder map[string][]StockCheckItem
-	reservationTTL  time.Duration
-	reservedTimers  map[string]*time.Timer
+	rates          exchange.Provider
+	reservationTTL time.Duration
+	reviewHoldTTL  time.Duration
 }
 
 func NewInventoryService(repo repository.InventoryRepository) *InventoryService {
-	return &InventoryService{repo: repo, reservedByOrder: make(map[string][]StockCheckItem), reservedTimers: make(map[string]*time.Timer)}
+	return &InventoryService{repo: repo, reservationTTL: DefaultReservationTTL, reviewHoldTTL: DefaultReviewHoldTTL}
 }
 
 // WithPublisher sets event publisher
 func (s *InventoryService) WithPublisher(p invpub.Publisher) *InventoryService { s.pub = p; return s }
 
-// WithReservationTTL sets TTL for automatic reservation expiration
+// WithReservationTTL sets TTL after which reservations are expired by the sweeper
 func (s *InventoryService) WithReservationTTL(d time.Duration) *InventoryService {
-	s.reservationTTL = d
+	if d > 0 {
+		s.reservationTTL = d
+	}
+	return s
+}
+
+// WithReviewHoldTTL sets how long reservations are held while the order's payment is in
+// risk review; it must outlast the order saga's review timeout
+func (s *InventoryService) WithReviewHoldTTL(d time.Duration) *InventoryService {
+	if d > 0 {
+		s.reviewHoldTTL = d
+	}
+	return s
+}
+
+// WithExchangeRates lets the catalog be shown in other currencies than it is priced in
+func (s *InventoryService) WithExchangeRates(p exchange.Provider) *InventoryService {
+	s.rates = p
 	return s
 }
 
+// DisplayPrice is a product price in the currency the customer views the catalog in
+type DisplayPrice struct {
+	money.Money
+	// Rate the catalog price was converted with; nil when shown in the catalog currency
+	Rate *exchange.Rate
+}
+
+// PriceIn returns the product price in currency; empty or the catalog currency returns the
+// catalog price. Currencies without a rate fail with exchange.ErrUnsupportedCurrency.
+func (s *InventoryService) PriceIn(ctx context.Context, p *models.Product, currency string) (DisplayPrice, error) {
+	if currency == "" || strings.EqualFold(currency, p.Currency) {
+		return DisplayPrice{Money: p.Price()}, nil
+	}
+	if s.rates == nil {
+		return DisplayPrice{}, fmt.Errorf("%w: %s", exchange.ErrUnsupportedCurrency, currency)
+	}
+	rate, err := s.rates.Rate(ctx, p.Currency, currency)
+	if err != nil {
+		return DisplayPrice{}, err
+	}
+	price, err := exchange.Convert(p.Price(), rate)
+	if err != nil {
+		return DisplayPrice{}, err
+	}
+	return DisplayPrice{Money: price, Rate: &rate}, nil
+}
+
 type StockCheckItem struct {
 	ProductID string
 	Quantity  int32
@@ -107,106 +158,78 @@ func (s *InventoryService) CheckStock(ctx context.Context, items []StockCheckIte
 	return results, allAvailable, nil
 }
 
-func (s *InventoryService) ReserveStock(ctx context.Context, orderID string, userID string, items []StockCheckItem) (failed []string, err error) {
-	// idempotency: prevent double reservation for same order
-	s.mu.Lock()
-	if _, exists := s.reservedByOrder[orderID]; exists {
-		s.mu.Unlock()
-		return nil, errors.New("order already has an active reservation")
+// ReserveStock reserves all items of the order or none of them; shortfalls lists the products that were short
+func (s *InventoryService) ReserveStock(ctx context.Context, orderID string, userID string, items []StockCheckItem) (shortfalls []models.StockShortfall, err error) {
+	if len(items) == 0 {
+		return nil, errors.New("no items to reserve")
 	}
-	s.mu.Unlock()
 
-	var failedProducts []string
-	successful := make([]StockCheckItem, 0, len(items))
-	var aggErr error
+	// one reservation per product; duplicate lines are merged
+	now := time.Now()
+	expiresAt := now.Add(s.reservationTTL)
+	byProduct := make(map[string]*models.Reservation, len(items))
+	reservations := make([]*models.Reservation, 0, len(items))
 	for _, it := range items {
-		if rerr := s.repo.Reserve(ctx, it.ProductID, it.Quantity); rerr != nil {
-			failedProducts = append(failedProducts, it.ProductID)
-			aggErr = multierr.Append(aggErr, rerr)
-			continue
+		if it.Quantity <= 0 {
+			return nil, fmt.Errorf("invalid quantity %d for product %s", it.Quantity, it.ProductID)
 		}
-		successful = append(successful, it)
-	}
-
-	// store only successfully reserved items for later commit/release
-	if len(successful) > 0 {
-		s.mu.Lock()
-		s.reservedByOrder[orderID] = successful
-		// schedule TTL expiration if configured
-		if s.reservationTTL > 0 {
-			if t, ok := s.reservedTimers[orderID]; ok {
-				t.Stop()
-			}
-			s.reservedTimers[orderID] = time.AfterFunc(s.reservationTTL, func() {
-				s.expireReservation(context.Background(), orderID)
-			})
+		if res, ok := byProduct[it.ProductID]; ok {
+			res.Quantity += it.Quantity
+			continue
 		}
-		s.mu.Unlock()
+		res := models.NewReservation(orderID, it.ProductID, it.Quantity, now, expiresAt)
+		byProduct[it.ProductID] = res
+		reservations = append(reservations, res)
+	}
+
+	shortfalls, err = s.repo.ReserveOrder(ctx, orderID, reservations)
+	if err != nil {
+		return nil, err
 	}
 
 	// publish result (best-effort)
 	if s.pub != nil {
-		if len(failedProducts) == 0 {
+		if len(shortfalls) == 0 {
 			_ = s.pub.PublishStockReserved(ctx, &events.StockReserved{OrderId: orderID, UserId: userID, OccurredAt: time.Now().Format(time.RFC3339)})
 		} else {
-			_ = s.pub.PublishStockReservationFailed(ctx, &events.StockReservationFailed{OrderId: orderID, UserId: userID, Reason: "insufficient stock", OccurredAt: time.Now().Format(time.RFC3339)})
+			_ = s.pub.PublishStockReservationFailed(ctx, &events.StockReservationFailed{OrderId: orderID, UserId: userID, Reason: "insufficient stock", OccurredAt: time.Now().Format(time.RFC3339), Shortfalls: toEventShortfalls(shortfalls)})
 		}
 	}
-	return failedProducts, aggErr
+	return shortfalls, nil
 }
 
-// FinalizeReservation commits (success=true) or releases (success=false) reserved stock for order
-func (s *InventoryService) FinalizeReservation(ctx context.Context, orderID string, success bool) error {
-	// get and delete reservation under lock
-	s.mu.Lock()
-	items := s.reservedByOrder[orderID]
-	delete(s.reservedByOrder, orderID)
-	if t, ok := s.reservedTimers[orderID]; ok {
-		t.Stop()
-		delete(s.reservedTimers, orderID)
-	}
-	s.mu.Unlock()
-	if len(items) == 0 {
-		return nil
-	}
-	var aggErr error
-	for _, it := range items {
-		if success {
-			if err := s.repo.Commit(ctx, it.ProductID, it.Quantity); err != nil {
-				aggErr = multierr.Append(aggErr, err)
-			}
-		} else {
-			if err := s.repo.Release(ctx, it.ProductID, it.Quantity); err != nil {
-				aggErr = multierr.Append(aggErr, err)
-			}
-		}
+func toEventShortfalls(shortfalls []models.StockShortfall) []*events.StockShortfall {
+	out := make([]*events.StockShortfall, 0, len(shortfalls))
+	for _, sf := range shortfalls {
+		out = append(out, &events.StockShortfall{ProductId: sf.ProductID, RequestedQuantity: sf.RequestedQuantity, AvailableQuantity: sf.AvailableQuantity})
 	}
-	return aggErr
+	return out
 }
 
-func (s *InventoryService) ReleaseStock(ctx context.Context, orderID string, items []StockCheckItem) error {
-	var aggErr error
-	for _, it := range items {
-		if err := s.repo.Release(ctx, it.ProductID, it.Quantity); err != nil {
-			aggErr = multierr.Append(aggErr, err)
-		}
+// FinalizeReservation commits (success=true) or releases (success=false) reserved stock for order;
+// committing fails with repository.ErrNoActiveReservation when the reservation is gone
+func (s *InventoryService) FinalizeReservation(ctx context.Context, orderID string, success bool) error {
+	if success {
+		return s.repo.CommitReservations(ctx, orderID, time.Now())
 	}
-	return aggErr
+	return s.repo.ReleaseReservations(ctx, orderID, nil, time.Now())
 }
 
-func (s *InventoryService) expireReservation(ctx context.Context, orderID string) {
-	// release only if still present (not finalized)
-	s.mu.Lock()
-	items, ok := s.reservedByOrder[orderID]
-	if ok {
-		delete(s.reservedByOrder, orderID)
-	}
-	delete(s.reservedTimers, orderID)
-	s.mu.Unlock()
-	if !ok || len(items) == 0 {
-		return
-	}
+// HoldForReview keeps the order's reservations from expiring while its payment is in risk
+// review, so an approval can still commit them
+func (s *InventoryService) HoldForReview(ctx context.Context, orderID string) error {
+	now := time.Now()
+	return s.repo.ExtendReservations(ctx, orderID, now.Add(s.reviewHoldTTL), now)
+}
+
+// ReleaseStock releases the order's active reservations for the given products
+func (s *InventoryService) ReleaseStock(ctx context.Context, orderID string, items []StockCheckItem) error {
+	productIDs := make([]string, 0, len(items))
 	for _, it := range items {
-		_ = s.repo.Release(ctx, it.ProductID, it.Quantity)
+		productIDs = append(productIDs, it.ProductID)
+	}
+	if len(productIDs) == 0 {
+		return nil
 	}
+	return s.repo.ReleaseReservations(ctx, orderID, productIDs, time.Now())
 }
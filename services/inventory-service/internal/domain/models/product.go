@@ -1,5 +1,7 @@
 package models
 
+import "github.com/kubernetestest/ecommerce-platform/pkg/money"
+
 type Product struct {
 	ID           string `gorm:"primaryKey;type:varchar(255)"`
 	Name         string `gorm:"not null;type:varchar(255)"`
@@ -10,4 +12,14 @@ type Product struct {
 	CategoryName string `gorm:"type:varchar(255)"`
 	ImageURL     string `gorm:"type:text"`
 	IsActive     bool   `gorm:"not null;default:true"`
+	// TaxCategory selects the tax rate orders charge for the product (e.g. food, books);
+	// empty means the standard rate
+	TaxCategory string `gorm:"type:varchar(32)"`
+	// WeightGrams is the shipping weight of one unit, packaging included
+	WeightGrams int32 `gorm:"not null;default:0"`
+}
+
+// Price returns the catalog price
+func (p *Product) Price() money.Money {
+	return money.Money{Amount: p.PriceMinor, Currency: p.Currency}
 }
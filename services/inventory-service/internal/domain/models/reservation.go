@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReservationState string
+
+const (
+	ReservationStateActive    ReservationState = "ACTIVE"
+	ReservationStateCommitted ReservationState = "COMMITTED"
+	ReservationStateReleased  ReservationState = "RELEASED"
+	ReservationStateExpired   ReservationState = "EXPIRED"
+)
+
+// Reservation - quantity of a product held for an order until it is committed, released or expires
+type Reservation struct {
+	ID        string           `gorm:"primaryKey;type:varchar(255)"`
+	OrderID   string           `gorm:"not null;type:varchar(255);index"`
+	ProductID string           `gorm:"not null;type:varchar(255)"`
+	Quantity  int32            `gorm:"not null"`
+	State     ReservationState `gorm:"type:varchar(20);not null;default:'ACTIVE';index:idx_reservations_due,priority:1"`
+	ExpiresAt time.Time        `gorm:"not null;index:idx_reservations_due,priority:2"`
+	CreatedAt time.Time        `gorm:"not null"`
+	UpdatedAt time.Time        `gorm:"not null"`
+}
+
+func (Reservation) TableName() string { return "reservations" }
+
+// NewReservation creates an active reservation that expires at expiresAt
+func NewReservation(orderID, productID string, qty int32, now, expiresAt time.Time) *Reservation {
+	return &Reservation{
+		ID:        uuid.New().String(),
+		OrderID:   orderID,
+		ProductID: productID,
+		Quantity:  qty,
+		State:     ReservationStateActive,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
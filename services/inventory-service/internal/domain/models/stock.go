@@ -5,3 +5,10 @@ type Stock struct {
 	AvailableQuantity int32  `gorm:"not null;default:0"`
 	ReservedQuantity  int32  `gorm:"not null;default:0"`
 }
+
+// StockShortfall describes a product that cannot cover the requested quantity
+type StockShortfall struct {
+	ProductID         string
+	RequestedQuantity int32
+	AvailableQuantity int32
+}
@@ -3,13 +3,17 @@ package grpc
 import (
 	"context"
 	"errors"
+	"github.com/kubernetestest/ecommerce-platform/pkg/exchange"
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
 	appsvc "github.com/kubernetestest/ecommerce-platform/services/inventory-service/internal/app/services"
 	"github.com/kubernetestest/ecommerce-platform/services/inventory-service/internal/domain/models"
+	"github.com/kubernetestest/ecommerce-platform/services/inventory-service/internal/ports/repository"
 	invpb "github.com/kubernetestest/ecommerce-platform/proto-go/inventory"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"gorm.io/gorm"
 )
 
@@ -50,12 +54,16 @@ func (s *PBInventoryServer) GetProducts(ctx context.Context, req *invpb.GetProdu
 		}
 	}
 	for _, p := range products {
+		price, err := s.svc.PriceIn(ctx, p, req.Currency)
+		if err != nil {
+			return nil, priceStatusErr(err)
+		}
 		if st, ok := stockMap[p.ID]; ok {
-			out = append(out, mapProductToPB(p, st.AvailableQuantity))
+			out = append(out, mapProductToPB(p, price, st.AvailableQuantity))
 			continue
 		}
 		// degrade to 0 if no stock or error during batch fetch
-		out = append(out, mapProductToPB(p, 0))
+		out = append(out, mapProductToPB(p, price, 0))
 	}
 	return &invpb.GetProductsResponse{Products: out, Total: int32(total)}, nil
 }
@@ -69,11 +77,15 @@ func (s *PBInventoryServer) GetProduct(ctx context.Context, req *invpb.GetProduc
 		}
 		return nil, status.Errorf(code, "failed to get product: %v", err)
 	}
+	price, err := s.svc.PriceIn(ctx, p, req.Currency)
+	if err != nil {
+		return nil, priceStatusErr(err)
+	}
 	q, err := s.svc.GetStockQuantity(ctx, p.ID)
 	if err != nil {
-		return &invpb.GetProductResponse{Product: mapProductToPB(p, 0)}, nil
+		return &invpb.GetProductResponse{Product: mapProductToPB(p, price, 0)}, nil
 	}
-	return &invpb.GetProductResponse{Product: mapProductToPB(p, q)}, nil
+	return &invpb.GetProductResponse{Product: mapProductToPB(p, price, q)}, nil
 }
 
 func (s *PBInventoryServer) CheckStock(ctx context.Context, req *invpb.CheckStockRequest) (*invpb.CheckStockResponse, error) {
@@ -97,14 +109,21 @@ func (s *PBInventoryServer) ReserveStock(ctx context.Context, req *invpb.Reserve
 	for _, it := range req.Items {
 		items = append(items, appsvc.StockCheckItem{ProductID: it.ProductId, Quantity: it.Quantity})
 	}
-	failed, err := s.svc.ReserveStock(ctx, req.OrderId, req.UserId, items)
+	shortfalls, err := s.svc.ReserveStock(ctx, req.OrderId, req.UserId, items)
+	if errors.Is(err, repository.ErrActiveReservationExists) {
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to reserve stock: %v", err)
 	}
+	failed := make([]string, 0, len(shortfalls))
+	for _, sf := range shortfalls {
+		failed = append(failed, sf.ProductID)
+	}
 	ok := len(failed) == 0
 	msg := "Reservation successful"
 	if !ok {
-		msg = "Reservation partial failure"
+		msg = "Insufficient stock; nothing was reserved"
 	}
 	return &invpb.ReserveStockResponse{Success: ok, Message: msg, FailedProducts: failed}, nil
 }
@@ -120,19 +139,35 @@ func (s *PBInventoryServer) ReleaseStock(ctx context.Context, req *invpb.Release
 	return &invpb.ReleaseStockResponse{Success: true, Message: "Released"}, nil
 }
 
+// priceStatusErr maps a failed price conversion: currencies without a rate are invalid, rates
+// that cannot be loaded are a temporary failure
+func priceStatusErr(err error) error {
+	if errors.Is(err, exchange.ErrUnsupportedCurrency) {
+		return status.Errorf(codes.InvalidArgument, "unsupported currency: %v", err)
+	}
+	return status.Errorf(codes.Unavailable, "failed to convert price: %v", err)
+}
+
 // mapping helpers
-func mapProductToPB(p *models.Product, stockQty int32) *invpb.Product {
-	return &invpb.Product{
+func mapProductToPB(p *models.Product, price appsvc.DisplayPrice, stockQty int32) *invpb.Product {
+	out := &invpb.Product{
 		Id:            p.ID,
 		Name:          p.Name,
 		Description:   p.Description,
-		Price:         &invpb.Money{Amount: p.PriceMinor, Currency: p.Currency},
+		Price:         money.ToProto[invpb.Money](price.Money),
 		CategoryId:    p.CategoryID,
 		CategoryName:  p.CategoryName,
 		StockQuantity: stockQty,
 		ImageUrl:      p.ImageURL,
 		IsActive:      p.IsActive,
+		TaxCategory:   p.TaxCategory,
+		WeightGrams:   p.WeightGrams,
+	}
+	if price.Rate != nil {
+		out.CatalogPrice = money.ToProto[invpb.Money](p.Price())
+		out.ExchangeRate = &invpb.ExchangeRate{From: price.Rate.From, To: price.Rate.To, Rate: price.Rate.Value, AsOf: timestamppb.New(price.Rate.AsOf)}
 	}
+	return out
 }
 
 func (s *PBInventoryServer) GetCategories(ctx context.Context, req *invpb.GetCategoriesRequest) (*invpb.GetCategoriesResponse, error) {
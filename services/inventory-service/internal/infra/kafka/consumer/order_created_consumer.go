@@ -3,6 +3,7 @@ package consumer
 import (
 	"context"
 
+	"github.com/kubernetestest/ecommerce-platform/pkg/eventschema"
 	kafkaclient "github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
 	"github.com/kubernetestest/ecommerce-platform/pkg/logger"
 
@@ -12,12 +13,12 @@ import (
 )
 
 type OrderCreatedHandler interface {
-	Handle(ctx context.Context, evt *events.OrderCreated) error
+	Handle(ctx context.Context, evt *events.OrderCreatedV2) error
 }
 
-type OrderCreatedHandlerFunc func(ctx context.Context, evt *events.OrderCreated) error
+type OrderCreatedHandlerFunc func(ctx context.Context, evt *events.OrderCreatedV2) error
 
-func (f OrderCreatedHandlerFunc) Handle(ctx context.Context, evt *events.OrderCreated) error {
+func (f OrderCreatedHandlerFunc) Handle(ctx context.Context, evt *events.OrderCreatedV2) error {
 	return f(ctx, evt)
 }
 
@@ -27,14 +28,13 @@ type Consumer struct {
 	l logger.Logger
 }
 
-func NewConsumer(bootstrapServers, groupID string, handler OrderCreatedHandler) (*Consumer, error) {
+func NewConsumer(bus kafkaclient.Bus, groupID string, handler OrderCreatedHandler) (*Consumer, error) {
 	config := kafkaclient.ConsumerConfig{
-		BootstrapServers: bootstrapServers,
-		GroupID:          groupID,
-		AutoOffsetReset:  "earliest",
+		GroupID:         groupID,
+		AutoOffsetReset: "earliest",
 	}
-	
-	c, err := kafkaclient.NewConsumer(config)
+
+	c, err := bus.NewConsumer(config)
 	if err != nil {
 		return nil, err
 	}
@@ -49,15 +49,25 @@ func (c *Consumer) WithLogger(l logger.Logger) *Consumer {
 
 func (c *Consumer) Close() error { return c.c.Close() }
 
+// WithDeadLetter routes events that keep failing to "<topic>.dlq"
+func (c *Consumer) WithDeadLetter(p kafkaclient.DeadLetterPublisher) *Consumer {
+	c.c.WithDeadLetter(p)
+	return c
+}
+
+// WithIdempotency skips redelivered OrderCreated events for orders already handled
+func (c *Consumer) WithIdempotency(store kafkaclient.ProcessedStore) *Consumer {
+	c.c.WithIdempotency(store, eventschema.KeyFunc, 0)
+	return c
+}
+
+// Run handles OrderCreated v1 and v2 side by side; v1 events are upgraded to v2
 func (c *Consumer) Run(ctx context.Context, topics []string) error {
-	return c.c.RunValueLoop(ctx, topics, func(hctx context.Context, value []byte) error {
-		var evt events.OrderCreated
-		if err := proto.Unmarshal(value, &evt); err != nil {
+	return c.c.RunEventLoop(ctx, topics, eventschema.Contracts(), func(hctx context.Context, msg proto.Message) error {
+		evt, err := eventschema.OrderCreatedV2Of(msg)
+		if err != nil {
 			return err
 		}
-		return c.h.Handle(hctx, &evt)
+		return c.h.Handle(hctx, evt)
 	})
 }
-
-
-
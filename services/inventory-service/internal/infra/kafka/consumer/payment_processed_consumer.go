@@ -3,6 +3,7 @@ package consumer
 import (
 	"context"
 
+	"github.com/kubernetestest/ecommerce-platform/pkg/eventschema"
 	kafkaclient "github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
 	"github.com/kubernetestest/ecommerce-platform/pkg/logger"
 
@@ -27,14 +28,13 @@ type PaymentConsumer struct {
 	l logger.Logger
 }
 
-func NewPaymentConsumer(bootstrapServers, groupID string, handler PaymentProcessedHandler) (*PaymentConsumer, error) {
+func NewPaymentConsumer(bus kafkaclient.Bus, groupID string, handler PaymentProcessedHandler) (*PaymentConsumer, error) {
 	config := kafkaclient.ConsumerConfig{
-		BootstrapServers: bootstrapServers,
-		GroupID:          groupID,
-		AutoOffsetReset:  "earliest",
+		GroupID:         groupID,
+		AutoOffsetReset: "earliest",
 	}
 
-	c, err := kafkaclient.NewConsumer(config)
+	c, err := bus.NewConsumer(config)
 	if err != nil {
 		return nil, err
 	}
@@ -49,12 +49,24 @@ func (c *PaymentConsumer) WithLogger(l logger.Logger) *PaymentConsumer {
 
 func (c *PaymentConsumer) Close() error { return c.c.Close() }
 
+// WithDeadLetter routes events that keep failing to "<topic>.dlq"
+func (c *PaymentConsumer) WithDeadLetter(p kafkaclient.DeadLetterPublisher) *PaymentConsumer {
+	c.c.WithDeadLetter(p)
+	return c
+}
+
+// WithIdempotency skips redelivered PaymentProcessed events for payments already handled
+func (c *PaymentConsumer) WithIdempotency(store kafkaclient.ProcessedStore) *PaymentConsumer {
+	c.c.WithIdempotency(store, eventschema.KeyFunc, 0)
+	return c
+}
+
 func (c *PaymentConsumer) Run(ctx context.Context, topics []string) error {
-	return c.c.RunValueLoop(ctx, topics, func(hctx context.Context, value []byte) error {
-		var evt events.PaymentProcessed
-		if err := proto.Unmarshal(value, &evt); err != nil {
+	return c.c.RunEventLoop(ctx, topics, eventschema.Contracts(), func(hctx context.Context, msg proto.Message) error {
+		evt, err := eventschema.As[*events.PaymentProcessed](msg)
+		if err != nil {
 			return err
 		}
-		return c.h.Handle(hctx, &evt)
+		return c.h.Handle(hctx, evt)
 	})
 }
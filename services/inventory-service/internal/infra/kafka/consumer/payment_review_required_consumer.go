@@ -0,0 +1,73 @@
+package consumer
+
+import (
+	"context"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/eventschema"
+	kafkaclient "github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
+	"github.com/kubernetestest/ecommerce-platform/pkg/logger"
+
+	events "github.com/kubernetestest/ecommerce-platform/proto-go/events"
+
+	"google.golang.org/protobuf/proto"
+)
+
+type PaymentReviewRequiredHandler interface {
+	Handle(ctx context.Context, evt *events.PaymentReviewRequired) error
+}
+
+type PaymentReviewRequiredHandlerFunc func(ctx context.Context, evt *events.PaymentReviewRequired) error
+
+func (f PaymentReviewRequiredHandlerFunc) Handle(ctx context.Context, evt *events.PaymentReviewRequired) error {
+	return f(ctx, evt)
+}
+
+// PaymentReviewRequiredConsumer reads payments the risk rules held for review
+type PaymentReviewRequiredConsumer struct {
+	c *kafkaclient.Consumer
+	h PaymentReviewRequiredHandler
+	l logger.Logger
+}
+
+func NewPaymentReviewRequiredConsumer(bus kafkaclient.Bus, groupID string, handler PaymentReviewRequiredHandler) (*PaymentReviewRequiredConsumer, error) {
+	config := kafkaclient.ConsumerConfig{
+		GroupID:         groupID,
+		AutoOffsetReset: "earliest",
+	}
+
+	c, err := bus.NewConsumer(config)
+	if err != nil {
+		return nil, err
+	}
+	return &PaymentReviewRequiredConsumer{c: c, h: handler}, nil
+}
+
+func (c *PaymentReviewRequiredConsumer) WithLogger(l logger.Logger) *PaymentReviewRequiredConsumer {
+	c.l = l
+	c.c.WithLogger(l)
+	return c
+}
+
+func (c *PaymentReviewRequiredConsumer) Close() error { return c.c.Close() }
+
+// WithDeadLetter routes events that keep failing to "<topic>.dlq"
+func (c *PaymentReviewRequiredConsumer) WithDeadLetter(p kafkaclient.DeadLetterPublisher) *PaymentReviewRequiredConsumer {
+	c.c.WithDeadLetter(p)
+	return c
+}
+
+// WithIdempotency skips redelivered PaymentReviewRequired events for payments already held
+func (c *PaymentReviewRequiredConsumer) WithIdempotency(store kafkaclient.ProcessedStore) *PaymentReviewRequiredConsumer {
+	c.c.WithIdempotency(store, eventschema.KeyFunc, 0)
+	return c
+}
+
+func (c *PaymentReviewRequiredConsumer) Run(ctx context.Context, topics []string) error {
+	return c.c.RunEventLoop(ctx, topics, eventschema.Contracts(), func(hctx context.Context, msg proto.Message) error {
+		evt, err := eventschema.As[*events.PaymentReviewRequired](msg)
+		if err != nil {
+			return err
+		}
+		return c.h.Handle(hctx, evt)
+	})
+}
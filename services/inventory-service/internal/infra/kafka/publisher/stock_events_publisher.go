@@ -3,59 +3,58 @@ package publisher
 import (
 	"context"
 
+	"github.com/kubernetestest/ecommerce-platform/pkg/eventschema"
 	kafkaclient "github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
 	"github.com/kubernetestest/ecommerce-platform/pkg/logger"
 
-	"google.golang.org/protobuf/proto"
-
 	events "github.com/kubernetestest/ecommerce-platform/proto-go/events"
 )
 
+// producerName is recorded in the envelope of published events
+const producerName = "inventory-service"
+
 type SugaredLogger = logger.Logger
 
 type Publisher = kafkaclient.Publisher
 
 type StockEventsPublisher struct {
-	base          Publisher
-	topicReserved string
-	topicFailed   string
+	base   Publisher
+	events kafkaclient.EventPublisher
 }
 
-func NewStockEventsPublisher(bootstrapServers, topicReserved, topicFailed string) (*StockEventsPublisher, error) {
+func NewStockEventsPublisher(bus kafkaclient.Bus, topicReserved, topicFailed string) (*StockEventsPublisher, error) {
 	config := kafkaclient.PublisherConfig{
-		BootstrapServers: bootstrapServers,
-		ClientID:         "inventory-service",
+		ClientID: producerName,
 	}
-	
-	kp, err := kafkaclient.NewKafkaPublisher(config)
+
+	kp, err := bus.NewPublisher(config)
 	if err != nil {
 		return nil, err
 	}
-	return &StockEventsPublisher{base: kp, topicReserved: topicReserved, topicFailed: topicFailed}, nil
+	events := kafkaclient.NewProtoEventPublisher(kp, producerName, Routes(topicReserved, topicFailed)).
+		WithSchemas(eventschema.Contracts())
+	return &StockEventsPublisher{base: kp, events: events}, nil
+}
+
+// Routes maps stock events to their topics, keyed by order ID
+func Routes(topicReserved, topicFailed string) *kafkaclient.EventRoutes {
+	r := kafkaclient.NewEventRoutes()
+	kafkaclient.Route(r, topicReserved, func(e *events.StockReserved) string { return e.GetOrderId() })
+	kafkaclient.Route(r, topicFailed, func(e *events.StockReservationFailed) string { return e.GetOrderId() })
+	return r
 }
 
 func (p *StockEventsPublisher) WithLogger(l SugaredLogger) *StockEventsPublisher {
-	p.base = p.base.WithLogger(l)
+	p.base.WithLogger(l)
 	return p
 }
 
 func (p *StockEventsPublisher) Close() error { return p.base.Close() }
 
 func (p *StockEventsPublisher) PublishStockReserved(ctx context.Context, evt *events.StockReserved) error {
-	bytes, err := proto.Marshal(evt)
-	if err != nil {
-		return err
-	}
-	return p.base.Publish(ctx, p.topicReserved, bytes)
+	return p.events.Publish(ctx, evt)
 }
 
 func (p *StockEventsPublisher) PublishStockReservationFailed(ctx context.Context, evt *events.StockReservationFailed) error {
-	bytes, err := proto.Marshal(evt)
-	if err != nil {
-		return err
-	}
-	return p.base.Publish(ctx, p.topicFailed, bytes)
+	return p.events.Publish(ctx, evt)
 }
-
-
-
@@ -2,13 +2,21 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/kubernetestest/ecommerce-platform/services/inventory-service/internal/domain/models"
+	repoport "github.com/kubernetestest/ecommerce-platform/services/inventory-service/internal/ports/repository"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// errInsufficientStock rolls back a multi-item reservation when any product is short
+var errInsufficientStock = errors.New("insufficient stock")
+
 type GormInventoryRepository struct{ db *gorm.DB }
 
 func NewGormInventoryRepository(db *gorm.DB) *GormInventoryRepository {
@@ -71,9 +79,189 @@ func (r *GormInventoryRepository) GetStocksByIDs(ctx context.Context, productIDs
 	return result, nil
 }
 
-func (r *GormInventoryRepository) Reserve(ctx context.Context, productID string, qty int32) error {
+func (r *GormInventoryRepository) ReserveOrder(ctx context.Context, orderID string, reservations []*models.Reservation) ([]models.StockShortfall, error) {
+	requested := make(map[string]int32, len(reservations))
+	productIDs := make([]string, 0, len(reservations))
+	for _, res := range reservations {
+		if _, ok := requested[res.ProductID]; !ok {
+			productIDs = append(productIDs, res.ProductID)
+		}
+		requested[res.ProductID] += res.Quantity
+	}
+	sort.Strings(productIDs)
+
+	var shortfalls []models.StockShortfall
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock stock rows in a deterministic order so concurrent orders cannot deadlock
+		var stocks []models.Stock
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id IN ?", productIDs).
+			Order("product_id ASC").
+			Find(&stocks).Error; err != nil {
+			return err
+		}
+
+		// Checked after locking: a duplicate request for the same order waits on the same rows
+		var active int64
+		if err := tx.Model(&models.Reservation{}).
+			Where("order_id = ? AND state = ?", orderID, models.ReservationStateActive).
+			Count(&active).Error; err != nil {
+			return err
+		}
+		if active > 0 {
+			return repoport.ErrActiveReservationExists
+		}
+
+		available := make(map[string]int32, len(stocks))
+		for _, st := range stocks {
+			available[st.ProductID] = st.AvailableQuantity
+		}
+		for _, id := range productIDs {
+			if available[id] < requested[id] {
+				shortfalls = append(shortfalls, models.StockShortfall{ProductID: id, RequestedQuantity: requested[id], AvailableQuantity: available[id]})
+			}
+		}
+		if len(shortfalls) > 0 {
+			return errInsufficientStock
+		}
+
+		for _, id := range productIDs {
+			if err := reserveStock(tx, id, requested[id]); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&reservations).Error
+	})
+	if errors.Is(err, errInsufficientStock) {
+		return shortfalls, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (r *GormInventoryRepository) CommitReservations(ctx context.Context, orderID string, now time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		reservations, err := lockActiveReservations(tx, orderID, nil)
+		if err != nil {
+			return err
+		}
+		if len(reservations) == 0 {
+			// a redelivered commit finds the reservations already committed
+			var committed int64
+			if err := tx.Model(&models.Reservation{}).
+				Where("order_id = ? AND state = ?", orderID, models.ReservationStateCommitted).
+				Count(&committed).Error; err != nil {
+				return err
+			}
+			if committed > 0 {
+				return nil
+			}
+			return repoport.ErrNoActiveReservation
+		}
+		for _, res := range reservations {
+			if err := commitStock(tx, res.ProductID, res.Quantity); err != nil {
+				return err
+			}
+		}
+		return setReservationState(tx, reservations, models.ReservationStateCommitted, now)
+	})
+}
+
+func (r *GormInventoryRepository) ExtendReservations(ctx context.Context, orderID string, expiresAt, now time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Locking waits for a sweeper that is expiring the same rows
+		reservations, err := lockActiveReservations(tx, orderID, nil)
+		if err != nil {
+			return err
+		}
+		if len(reservations) == 0 {
+			return repoport.ErrNoActiveReservation
+		}
+		ids := make([]string, 0, len(reservations))
+		for _, res := range reservations {
+			ids = append(ids, res.ID)
+		}
+		// never shorten a hold, so a redelivered extension is harmless
+		return tx.Model(&models.Reservation{}).
+			Where("id IN ? AND expires_at < ?", ids, expiresAt).
+			Updates(map[string]interface{}{"expires_at": expiresAt, "updated_at": now}).Error
+	})
+}
+
+func (r *GormInventoryRepository) ReleaseReservations(ctx context.Context, orderID string, productIDs []string, now time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		reservations, err := lockActiveReservations(tx, orderID, productIDs)
+		if err != nil {
+			return err
+		}
+		for _, res := range reservations {
+			if err := releaseStock(tx, res.ProductID, res.Quantity); err != nil {
+				return err
+			}
+		}
+		return setReservationState(tx, reservations, models.ReservationStateReleased, now)
+	})
+}
+
+func (r *GormInventoryRepository) ExpireReservations(ctx context.Context, now time.Time, limit int) ([]*models.Reservation, error) {
+	var expired []*models.Reservation
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// SKIP LOCKED lets several replicas sweep concurrently without blocking on each other
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("state = ? AND expires_at <= ?", models.ReservationStateActive, now).
+			Order("product_id ASC").
+			Limit(limit).
+			Find(&expired).Error; err != nil {
+			return err
+		}
+		for _, res := range expired {
+			if err := releaseStock(tx, res.ProductID, res.Quantity); err != nil {
+				return err
+			}
+		}
+		return setReservationState(tx, expired, models.ReservationStateExpired, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
+
+// lockActiveReservations locks the order's active reservations ordered by product to keep
+// stock row locks in a consistent order across transactions
+func lockActiveReservations(tx *gorm.DB, orderID string, productIDs []string) ([]*models.Reservation, error) {
+	var reservations []*models.Reservation
+	q := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND state = ?", orderID, models.ReservationStateActive)
+	if len(productIDs) > 0 {
+		q = q.Where("product_id IN ?", productIDs)
+	}
+	if err := q.Order("product_id ASC").Find(&reservations).Error; err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+func setReservationState(tx *gorm.DB, reservations []*models.Reservation, state models.ReservationState, now time.Time) error {
+	if len(reservations) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(reservations))
+	for _, res := range reservations {
+		res.State = state
+		res.UpdatedAt = now
+		ids = append(ids, res.ID)
+	}
+	return tx.Model(&models.Reservation{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{"state": state, "updated_at": now}).Error
+}
+
+func reserveStock(tx *gorm.DB, productID string, qty int32) error {
 	// Atomic update using a single SQL statement guarded by available quantity
-	res := r.db.WithContext(ctx).Model(&models.Stock{}).
+	res := tx.Model(&models.Stock{}).
 		Where("product_id = ? AND available_quantity >= ?", productID, qty).
 		Updates(map[string]interface{}{
 			"available_quantity": gorm.Expr("available_quantity - ?", qty),
@@ -88,8 +276,8 @@ func (r *GormInventoryRepository) Reserve(ctx context.Context, productID string,
 	return nil
 }
 
-func (r *GormInventoryRepository) Release(ctx context.Context, productID string, qty int32) error {
-	res := r.db.WithContext(ctx).Model(&models.Stock{}).
+func releaseStock(tx *gorm.DB, productID string, qty int32) error {
+	res := tx.Model(&models.Stock{}).
 		Where("product_id = ? AND reserved_quantity >= ?", productID, qty).
 		Updates(map[string]interface{}{
 			"available_quantity": gorm.Expr("available_quantity + ?", qty),
@@ -104,8 +292,8 @@ func (r *GormInventoryRepository) Release(ctx context.Context, productID string,
 	return nil
 }
 
-func (r *GormInventoryRepository) Commit(ctx context.Context, productID string, qty int32) error {
-	res := r.db.WithContext(ctx).Model(&models.Stock{}).
+func commitStock(tx *gorm.DB, productID string, qty int32) error {
+	res := tx.Model(&models.Stock{}).
 		Where("product_id = ? AND reserved_quantity >= ?", productID, qty).
 		UpdateColumn("reserved_quantity", gorm.Expr("reserved_quantity - ?", qty))
 	if res.Error != nil {
@@ -131,5 +319,5 @@ func (r *GormInventoryRepository) GetCategories(ctx context.Context, activeOnly
 
 // AutoMigrate creates tables
 func (r *GormInventoryRepository) AutoMigrate() error {
-	return r.db.AutoMigrate(&models.Product{}, &models.Stock{}, &models.Category{})
+	return r.db.AutoMigrate(&models.Product{}, &models.Stock{}, &models.Category{}, &models.Reservation{})
 }
@@ -0,0 +1,79 @@
+package reservation
+
+import (
+	"context"
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/logger"
+	"github.com/kubernetestest/ecommerce-platform/services/inventory-service/internal/ports/repository"
+)
+
+// SweeperConfig holds sweeper polling configuration
+type SweeperConfig struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+// Sweeper periodically returns expired reservations to available stock.
+// Rows are claimed with SKIP LOCKED so every replica can run a sweeper safely.
+type Sweeper struct {
+	repo repository.InventoryRepository
+	log  logger.Logger
+	cfg  SweeperConfig
+	now  func() time.Time
+}
+
+// NewSweeper creates a sweeper with defaults applied to zero config values
+func NewSweeper(repo repository.InventoryRepository, cfg SweeperConfig) *Sweeper {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	return &Sweeper{repo: repo, cfg: cfg, now: time.Now}
+}
+
+// WithLogger sets logger for sweeper
+func (s *Sweeper) WithLogger(l logger.Logger) *Sweeper {
+	s.log = l
+	return s
+}
+
+// Run sweeps expired reservations until ctx is cancelled
+func (s *Sweeper) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.SweepOnce(ctx); err != nil && s.log != nil {
+			s.log.Error("reservation sweep failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// SweepOnce expires batches of stale reservations until none are left and returns how many were expired
+func (s *Sweeper) SweepOnce(ctx context.Context) (int, error) {
+	total := 0
+	for ctx.Err() == nil {
+		expired, err := s.repo.ExpireReservations(ctx, s.now(), s.cfg.BatchSize)
+		if err != nil {
+			return total, err
+		}
+		for _, res := range expired {
+			if s.log != nil {
+				s.log.Info("reservation expired", "orderId", res.OrderID, "productId", res.ProductID, "quantity", res.Quantity)
+			}
+		}
+		total += len(expired)
+		if len(expired) < s.cfg.BatchSize {
+			break
+		}
+	}
+	return total, nil
+}
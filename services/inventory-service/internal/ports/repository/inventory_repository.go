@@ -2,9 +2,19 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
+
 	"github.com/kubernetestest/ecommerce-platform/services/inventory-service/internal/domain/models"
 )
 
+// ErrActiveReservationExists is returned when an order already holds active reservations
+var ErrActiveReservationExists = errors.New("order already has an active reservation")
+
+// ErrNoActiveReservation is returned when an order has no active reservations, e.g. because
+// they expired before the payment was approved
+var ErrNoActiveReservation = errors.New("order has no active reservation")
+
 type InventoryRepository interface {
 	GetProduct(ctx context.Context, id string) (*models.Product, error)
 	ListProducts(ctx context.Context, categoryID string, page, limit int, search string) ([]*models.Product, int32, error)
@@ -12,7 +22,20 @@ type InventoryRepository interface {
 
 	GetStock(ctx context.Context, productID string) (*models.Stock, error)
 	GetStocksByIDs(ctx context.Context, productIDs []string) (map[string]*models.Stock, error)
-	Reserve(ctx context.Context, productID string, qty int32) error
-	Release(ctx context.Context, productID string, qty int32) error
-	Commit(ctx context.Context, productID string, qty int32) error
+
+	// ReserveOrder reserves all items of an order in one transaction. Stock rows are locked in
+	// product ID order; if any product is short nothing is reserved and the shortfalls are returned
+	ReserveOrder(ctx context.Context, orderID string, reservations []*models.Reservation) ([]models.StockShortfall, error)
+	// CommitReservations consumes the order's active reservations. It fails with
+	// ErrNoActiveReservation unless the order holds active or already committed reservations
+	CommitReservations(ctx context.Context, orderID string, now time.Time) error
+	// ExtendReservations moves the expiry of the order's active reservations out to expiresAt;
+	// it fails with ErrNoActiveReservation when the order holds none
+	ExtendReservations(ctx context.Context, orderID string, expiresAt, now time.Time) error
+	// ReleaseReservations returns the order's active reservations to available stock;
+	// when productIDs is empty all of them are released
+	ReleaseReservations(ctx context.Context, orderID string, productIDs []string, now time.Time) error
+	// ExpireReservations releases up to limit active reservations past their expiry,
+	// skipping rows locked by other replicas
+	ExpireReservations(ctx context.Context, now time.Time, limit int) ([]*models.Reservation, error)
 }
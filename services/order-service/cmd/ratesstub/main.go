@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/exchange"
+
+	"go.uber.org/zap"
+)
+
+// Local stand-in for an exchange rates service; point order-service and inventory-service at
+// it with EXCHANGE_RATES_SOURCE=http and EXCHANGE_RATES_URL=http://localhost:8092.
+// RATES_STUB_FILE serves the rates of a file in the rates file format instead of the
+// built-in USD based rates.
+func main() {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+	log := logger.Sugar()
+
+	addr := getEnv("RATES_STUB_ADDR", ":8092")
+	var table exchange.Table
+	if path := os.Getenv("RATES_STUB_FILE"); path != "" {
+		raw, err := os.ReadFile(path)
+		if err == nil {
+			err = json.Unmarshal(raw, &table)
+		}
+		if err != nil {
+			log.Errorw("invalid RATES_STUB_FILE", "path", path, "error", err)
+			os.Exit(1)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	stub := exchange.NewStubServer(table)
+	server := &http.Server{Addr: addr, Handler: stub.Handler(), ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log.Infow("exchange rates stub listening", "addr", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Errorw("exchange rates stub failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func getEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
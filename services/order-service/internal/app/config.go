@@ -2,7 +2,11 @@ package app
 
 import (
 	"os"
+	"strconv"
 	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/exchange"
+	kafkaclient "github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
 )
 
 type Config struct {
@@ -16,6 +20,31 @@ type Config struct {
 	DefaultCurrency          string
 	InventoryProviderTimeout time.Duration
 	KafkaAutoOffsetReset     string
+	MetricsPort              string
+	OutboxPollInterval       time.Duration
+	OutboxBatchSize          int
+	PaymentServiceURL        string
+	SagaReserveTimeout       time.Duration
+	SagaPaymentTimeout       time.Duration
+	// SagaReviewTimeout is how long a payment held for risk review may wait for an admin
+	SagaReviewTimeout time.Duration
+	SagaPollInterval  time.Duration
+	// KafkaIdempotentConsumers enables the processed-message ledger for Kafka consumers
+	KafkaIdempotentConsumers bool
+	// ExchangeRates converts catalog prices for orders in another currency; source none keeps
+	// orders in the catalog currency
+	ExchangeRates exchange.Config
+	// TaxEngine selects how order taxes are calculated: table (rates by country, region and
+	// product tax category) or none; TaxRatesFile replaces the example rates of the table
+	TaxEngine    string
+	TaxRatesFile string
+	// ShippingEngine selects how shipping is priced: table (methods priced by weight and
+	// destination country) or none (free standard shipping); ShippingRatesFile replaces the
+	// example rates of the table
+	ShippingEngine    string
+	ShippingRatesFile string
+	// Bus replaces the Kafka bus of KAFKA_BROKERS, e.g. with a MemoryBus in tests
+	Bus kafkaclient.Bus
 }
 
 func LoadConfigFromEnv() *Config {
@@ -25,6 +54,18 @@ func LoadConfigFromEnv() *Config {
 			timeout = d
 		}
 	}
+	outboxPoll := time.Second
+	if v := os.Getenv("OUTBOX_POLL_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			outboxPoll = d
+		}
+	}
+	outboxBatch := 100
+	if v := os.Getenv("OUTBOX_BATCH_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			outboxBatch = n
+		}
+	}
 	return &Config{
 		Port:                     getEnv("PORT", "50052"),
 		DBHost:                   getEnv("DB_HOST", "localhost"),
@@ -36,6 +77,27 @@ func LoadConfigFromEnv() *Config {
 		DefaultCurrency:          getEnv("DEFAULT_CURRENCY", "USD"),
 		InventoryProviderTimeout: timeout,
 		KafkaAutoOffsetReset:     getEnv("KAFKA_AUTO_OFFSET_RESET", "earliest"),
+		MetricsPort:              getEnv("METRICS_PORT", "9095"),
+		OutboxPollInterval:       outboxPoll,
+		OutboxBatchSize:          outboxBatch,
+		PaymentServiceURL:        getEnv("PAYMENT_SERVICE_URL", "payment-service:50054"),
+		SagaReserveTimeout:       getEnvDuration("SAGA_RESERVE_TIMEOUT", 2*time.Minute),
+		SagaPaymentTimeout:       getEnvDuration("SAGA_PAYMENT_TIMEOUT", 5*time.Minute),
+		SagaReviewTimeout:        getEnvDuration("SAGA_REVIEW_TIMEOUT", 72*time.Hour),
+		SagaPollInterval:         getEnvDuration("SAGA_POLL_INTERVAL", 5*time.Second),
+		KafkaIdempotentConsumers: getEnv("KAFKA_IDEMPOTENT_CONSUMERS", "true") == "true",
+		ExchangeRates: exchange.Config{
+			Source:  getEnv("EXCHANGE_RATES_SOURCE", exchange.SourceNone),
+			File:    getEnv("EXCHANGE_RATES_FILE", ""),
+			URL:     getEnv("EXCHANGE_RATES_URL", "http://localhost:8092"),
+			Timeout: getEnvDuration("EXCHANGE_RATES_TIMEOUT", 3*time.Second),
+			Refresh: getEnvDuration("EXCHANGE_RATES_REFRESH", 5*time.Minute),
+			MaxAge:  getEnvDuration("EXCHANGE_RATES_MAX_AGE", 48*time.Hour),
+		},
+		TaxEngine:         getEnv("TAX_ENGINE", "table"),
+		TaxRatesFile:      getEnv("TAX_RATES_FILE", ""),
+		ShippingEngine:    getEnv("SHIPPING_ENGINE", "table"),
+		ShippingRatesFile: getEnv("SHIPPING_RATES_FILE", ""),
 	}
 }
 
@@ -45,3 +107,12 @@ func getEnv(key, def string) string {
 	}
 	return def
 }
+
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return def
+}
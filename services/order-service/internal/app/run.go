@@ -5,27 +5,35 @@ import (
 	"fmt"
 	"net"
 	"sync"
+	"time"
 
-	"github.com/kubernetestest/ecommerce-platform/proto-go/events"
 	invpb "github.com/kubernetestest/ecommerce-platform/proto-go/inventory"
+	paymentpb "github.com/kubernetestest/ecommerce-platform/proto-go/payment"
 	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/app/services"
-	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/domain/models"
 	clockimpl "github.com/kubernetestest/ecommerce-platform/services/order-service/internal/infra/clock"
+	compensationimpl "github.com/kubernetestest/ecommerce-platform/services/order-service/internal/infra/compensation"
 	ordergrpc "github.com/kubernetestest/ecommerce-platform/services/order-service/internal/infra/grpc"
-	con "github.com/kubernetestest/ecommerce-platform/services/order-service/internal/infra/kafka/consumer"
-	pub "github.com/kubernetestest/ecommerce-platform/services/order-service/internal/infra/kafka/publisher"
+	outboxrelay "github.com/kubernetestest/ecommerce-platform/services/order-service/internal/infra/outbox"
 	productinfoimpl "github.com/kubernetestest/ecommerce-platform/services/order-service/internal/infra/productinfo"
 	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/infra/repository"
+	shippingimpl "github.com/kubernetestest/ecommerce-platform/services/order-service/internal/infra/shipping"
+	taximpl "github.com/kubernetestest/ecommerce-platform/services/order-service/internal/infra/tax"
+	ordermetrics "github.com/kubernetestest/ecommerce-platform/services/order-service/internal/metrics"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/compensation"
 	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/productinfo"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/shipping"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/tax"
 
+	"github.com/kubernetestest/ecommerce-platform/pkg/exchange"
+	kafkaclient "github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
 	pkglogger "github.com/kubernetestest/ecommerce-platform/pkg/logger"
+	"github.com/kubernetestest/ecommerce-platform/pkg/metrics"
 	"go.uber.org/zap"
 	gogrpc "google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-
 )
 
 func Run(ctx context.Context, cfg *Config, logger *zap.Logger) error {
@@ -51,32 +59,39 @@ func Run(ctx context.Context, cfg *Config, logger *zap.Logger) error {
 		}
 	}
 
-	// Load brokers once
-	brokers := getEnv("KAFKA_BROKERS", "kafka:9092")
+	metricsInstance := ordermetrics.NewOrderMetrics()
+
+	// Load brokers once; without brokers there is no message bus
+	bus := cfg.Bus
+	if brokers := getEnv("KAFKA_BROKERS", "kafka:9092"); bus == nil && brokers != "" {
+		bus = kafkaclient.NewKafkaBus(brokers)
+	}
 
-	// Optional Kafka producer
-	prod, prodErr := createKafkaProducer(brokers, logger)
+	// Optional Kafka producer used by the outbox relay
+	prod, prodErr := createKafkaProducer(bus)
 	if prodErr != nil {
 		log.Warnw("kafka producer init failed", "error", prodErr)
 	}
 	if prod == nil {
-		log.Infow("kafka producer disabled or not configured")
+		log.Infow("kafka producer disabled or not configured; outbox messages stay pending")
 	}
 	if prod != nil {
-		_ = prod.WithLogger(pkglogger.NewZapLogger(log))
+		prod.WithLogger(pkglogger.NewZapLogger(log))
 		defer prod.Close()
 	}
 
-	// Optional Inventory provider
+	// Optional Inventory provider and stock release compensation
 	var (
-		provider productinfo.Provider
-		invConn  *gogrpc.ClientConn
+		provider     productinfo.Provider
+		stockRelease compensation.StockReleaser
+		invConn      *gogrpc.ClientConn
 	)
 	if cfg.InventoryServiceURL != "" {
 		if conn, err := gogrpc.DialContext(ctx, cfg.InventoryServiceURL, gogrpc.WithInsecure()); err == nil {
 			invConn = conn
 			invClient := invpb.NewInventoryServiceClient(conn)
 			provider = productinfoimpl.NewInventoryProvider(invClient, cfg.InventoryProviderTimeout)
+			stockRelease = compensationimpl.NewInventoryStockReleaser(invClient, cfg.InventoryProviderTimeout)
 		} else {
 			log.Warnw("inventory grpc dial failed", "url", cfg.InventoryServiceURL, "error", err)
 		}
@@ -84,39 +99,131 @@ func Run(ctx context.Context, cfg *Config, logger *zap.Logger) error {
 		log.Infow("inventory provider not configured")
 	}
 
+	// Optional Payment client for refund compensation
+	var (
+		refunder compensation.PaymentRefunder
+		payConn  *gogrpc.ClientConn
+	)
+	if cfg.PaymentServiceURL != "" {
+		if conn, err := gogrpc.DialContext(ctx, cfg.PaymentServiceURL, gogrpc.WithInsecure()); err == nil {
+			payConn = conn
+			refunder = compensationimpl.NewPaymentRefunder(paymentpb.NewPaymentServiceClient(conn), 0)
+		} else {
+			log.Warnw("payment grpc dial failed", "url", cfg.PaymentServiceURL, "error", err)
+		}
+	} else {
+		log.Infow("payment refunds not configured")
+	}
+
+	// Exchange rates let customers check out in another currency than the catalog
+	rates, err := exchange.NewProvider(cfg.ExchangeRates)
+	if err != nil {
+		log.Errorw("failed to init exchange rates", "source", cfg.ExchangeRates.Source, "error", err)
+		return err
+	}
+
+	// Taxes are charged by where the order ships to
+	taxes, err := newTaxCalculator(cfg)
+	if err != nil {
+		log.Errorw("failed to init tax engine", "engine", cfg.TaxEngine, "error", err)
+		return err
+	}
+
+	// Shipping is priced by method, weight and destination
+	quoter, err := newShippingQuoter(cfg)
+	if err != nil {
+		log.Errorw("failed to init shipping rates", "engine", cfg.ShippingEngine, "error", err)
+		return err
+	}
+
 	// Build service with the new constructor
 	orderService := services.NewOrderService(
 		orderRepo,
 		clockimpl.NewSystemClock(),
-		prod,
 		provider,
 		logger,
-	)
+	).WithReserveTimeout(cfg.SagaReserveTimeout)
+	if rates != nil {
+		orderService = orderService.WithExchangeRates(rates)
+	}
+	if taxes != nil {
+		orderService = orderService.WithTaxCalculator(taxes)
+	}
+	if quoter != nil {
+		orderService = orderService.WithShippingQuoter(quoter)
+	}
+
+	// Saga orchestrator owns the checkout flow: reserve stock → pay → commit
+	orchestrator := services.NewSagaOrchestrator(repository.NewGormSagaRepository(db), orderService, services.SagaConfig{
+		PaymentTimeout: cfg.SagaPaymentTimeout,
+		ReviewTimeout:  cfg.SagaReviewTimeout,
+		PollInterval:   cfg.SagaPollInterval,
+	}).WithStockReleaser(stockRelease).
+		WithPaymentRefunder(refunder).
+		WithClock(clockimpl.NewSystemClock()).
+		WithMetrics(metricsInstance).
+		WithLogger(logger)
 
-	// Optional Kafka consumer (payments)
 	var wg sync.WaitGroup
-	if brokers != "" {
-		if cons, err := con.NewConsumer(brokers, "order-service", cfg.KafkaAutoOffsetReset, con.PaymentProcessedHandlerFunc(func(cctx context.Context, evt *events.PaymentProcessed) error {
-			status := models.OrderStatusConfirmed
-			if !evt.Success {
-				status = models.OrderStatusCancelled
-			}
-			if _, err := orderService.UpdateOrderStatus(cctx, &services.UpdateOrderStatusRequest{OrderID: evt.OrderId, Status: status}); err != nil {
-				log.Warnw("update order status failed", "orderID", evt.OrderId, "status", status, "error", err)
+
+	// Saga timeouts and compensation retries
+	wg.Add(1)
+	go func() { defer wg.Done(); _ = orchestrator.Run(ctx) }()
+
+	// Outbox relay delivers events committed together with orders
+	if prod != nil {
+		relay := outboxrelay.NewRelay(repository.NewGormOutboxRepository(db), prod, outboxrelay.RelayConfig{
+			PollInterval: cfg.OutboxPollInterval,
+			BatchSize:    cfg.OutboxBatchSize,
+		}).WithLogger(pkglogger.NewZapLogger(log)).WithMetrics(metricsInstance).WithClock(clockimpl.NewSystemClock())
+		wg.Add(1)
+		go func() { defer wg.Done(); _ = relay.Run(ctx) }()
+	}
+
+	// Processed-message ledger deduplicates redelivered Kafka events
+	var processed kafkaclient.ProcessedStore
+	if cfg.KafkaIdempotentConsumers {
+		ledger := kafkaclient.NewGormProcessedStore(db)
+		if getEnv("AUTO_MIGRATE", "") == "true" {
+			if err := ledger.AutoMigrate(); err != nil {
+				log.Errorw("processed messages automigrate failed", "error", err)
+				return fmt.Errorf("automigrate processed messages: %w", err)
 			}
-			return nil
-		})); err == nil {
-			defer cons.Close()
-			cons.WithLogger(pkglogger.NewZapLogger(log))
-			wg.Add(1)
-			go func() { defer wg.Done(); cons.Run(ctx, []string{"payments.v1.payment_processed"}) }()
-		} else {
-			log.Warnw("kafka consumer init failed", "error", err)
 		}
+		processed = ledger
 	}
 
+	// Kafka consumers advancing the saga
+	if bus != nil {
+		closers := startSagaConsumers(ctx, &wg, bus, orchestrator, sagaConsumerOptions{
+			autoOffsetReset: cfg.KafkaAutoOffsetReset,
+			processed:       processed,
+			deadLetter:      prod,
+		}, log)
+		for _, c := range closers {
+			defer c.Close()
+		}
+	}
+
+	// Start metrics server
+	metricsServer := metrics.NewMetricsServer(":"+cfg.MetricsPort, logger)
+	go func() {
+		log.Infow("metrics server starting", "port", cfg.MetricsPort)
+		if err := metricsServer.Start(); err != nil {
+			log.Errorw("metrics server failed", "error", err)
+		}
+	}()
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			log.Errorw("metrics server shutdown failed", "error", err)
+		}
+	}()
+
 	server := gogrpc.NewServer()
-	ordergrpc.RegisterOrderPBServer(server, orderService, cfg.DefaultCurrency)
+	ordergrpc.RegisterOrderPBServer(server, orderService, orchestrator, cfg.DefaultCurrency)
+	ordergrpc.RegisterSagaAdminPBServer(server, orchestrator)
 
 	healthServer := health.NewServer()
 	grpc_health_v1.RegisterHealthServer(server, healthServer)
@@ -138,6 +245,9 @@ func Run(ctx context.Context, cfg *Config, logger *zap.Logger) error {
 		if invConn != nil {
 			_ = invConn.Close()
 		}
+		if payConn != nil {
+			_ = payConn.Close()
+		}
 	}
 
 	select {
@@ -152,6 +262,46 @@ func Run(ctx context.Context, cfg *Config, logger *zap.Logger) error {
 	}
 }
 
+// newTaxCalculator builds the tax engine selected by cfg.TaxEngine; "none" charges no tax.
+// The table engine uses the rates of cfg.TaxRatesFile, or example rates when it is not set.
+func newTaxCalculator(cfg *Config) (tax.Calculator, error) {
+	switch cfg.TaxEngine {
+	case "none":
+		return nil, nil
+	case "", "table":
+	default:
+		return nil, fmt.Errorf("unknown tax engine %q", cfg.TaxEngine)
+	}
+	table := taximpl.DefaultTable()
+	if cfg.TaxRatesFile != "" {
+		var err error
+		if table, err = taximpl.LoadTable(cfg.TaxRatesFile); err != nil {
+			return nil, err
+		}
+	}
+	return taximpl.NewTableCalculator(table)
+}
+
+// newShippingQuoter builds the shipping rates selected by cfg.ShippingEngine; "none" ships for free.
+// The table engine uses the rates of cfg.ShippingRatesFile, or example rates when it is not set.
+func newShippingQuoter(cfg *Config) (shipping.Quoter, error) {
+	switch cfg.ShippingEngine {
+	case "none":
+		return nil, nil
+	case "", "table":
+	default:
+		return nil, fmt.Errorf("unknown shipping engine %q", cfg.ShippingEngine)
+	}
+	table := shippingimpl.DefaultTable()
+	if cfg.ShippingRatesFile != "" {
+		var err error
+		if table, err = shippingimpl.LoadTable(cfg.ShippingRatesFile); err != nil {
+			return nil, err
+		}
+	}
+	return shippingimpl.NewTableQuoter(table)
+}
+
 func connectDB(cfg *Config) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPass, cfg.DBName)
@@ -171,11 +321,13 @@ func connectDB(cfg *Config) (*gorm.DB, error) {
 	return db, nil
 }
 
-func createKafkaProducer(brokers string, logger *zap.Logger) (*pub.OrderCreatedPublisher, error) {
-	if brokers == "" {
+func createKafkaProducer(bus kafkaclient.Bus) (kafkaclient.Publisher, error) {
+	if bus == nil {
 		return nil, nil
 	}
-	prod, err := pub.NewOrderCreatedPublisher(brokers, "orders.v1.order_created")
+	prod, err := bus.NewPublisher(kafkaclient.PublisherConfig{
+		ClientID: "order-service",
+	})
 	if err != nil {
 		return nil, err
 	}
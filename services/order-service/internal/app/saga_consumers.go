@@ -0,0 +1,140 @@
+package app
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/eventschema"
+	kafkaclient "github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
+	pkglogger "github.com/kubernetestest/ecommerce-platform/pkg/logger"
+	"github.com/kubernetestest/ecommerce-platform/proto-go/events"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/app/services"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/domain/models"
+	con "github.com/kubernetestest/ecommerce-platform/services/order-service/internal/infra/kafka/consumer"
+
+	"go.uber.org/zap"
+)
+
+// sagaConsumerOptions are the consumer settings shared by the saga consumers
+type sagaConsumerOptions struct {
+	autoOffsetReset string
+	processed       kafkaclient.ProcessedStore
+	deadLetter      kafkaclient.DeadLetterPublisher
+}
+
+// startSagaConsumers feeds inventory and payment events from bus to the orchestrator.
+// Consumers run on wg until ctx is cancelled; the returned closers stop them.
+func startSagaConsumers(ctx context.Context, wg *sync.WaitGroup, bus kafkaclient.Bus, orchestrator *services.SagaOrchestrator, opts sagaConsumerOptions, log *zap.SugaredLogger) []io.Closer {
+	var closers []io.Closer
+
+	// Complete or compensate sagas on payment outcomes
+	if cons, err := con.NewConsumer(bus, "order-service", opts.autoOffsetReset, paymentProcessedHandler(orchestrator, log)); err == nil {
+		closers = append(closers, cons)
+		cons.WithLogger(pkglogger.NewZapLogger(log))
+		if opts.processed != nil {
+			cons.WithIdempotency(opts.processed)
+		}
+		if opts.deadLetter != nil {
+			cons.WithDeadLetter(opts.deadLetter)
+		}
+		wg.Add(1)
+		go func() { defer wg.Done(); cons.Run(ctx, []string{eventschema.PaymentProcessedTopic}) }()
+	} else {
+		log.Warnw("kafka consumer init failed", "error", err)
+	}
+
+	// Hold orders whose payment is in risk review
+	if reviewCons, err := con.NewPaymentReviewRequiredConsumer(bus, "order-service-payment-review", opts.autoOffsetReset, paymentReviewRequiredHandler(orchestrator, log)); err == nil {
+		closers = append(closers, reviewCons)
+		reviewCons.WithLogger(pkglogger.NewZapLogger(log))
+		if opts.processed != nil {
+			reviewCons.WithIdempotency(opts.processed)
+		}
+		if opts.deadLetter != nil {
+			reviewCons.WithDeadLetter(opts.deadLetter)
+		}
+		wg.Add(1)
+		go func() { defer wg.Done(); reviewCons.Run(ctx, []string{eventschema.PaymentReviewRequiredTopic}) }()
+	} else {
+		log.Warnw("payment review consumer init failed", "error", err)
+	}
+
+	// Advance sagas whose stock was reserved
+	if reservedCons, err := con.NewStockReservedConsumer(bus, "order-service-stock-reserved", opts.autoOffsetReset, stockReservedHandler(orchestrator, log)); err == nil {
+		closers = append(closers, reservedCons)
+		reservedCons.WithLogger(pkglogger.NewZapLogger(log))
+		if opts.processed != nil {
+			reservedCons.WithIdempotency(opts.processed)
+		}
+		if opts.deadLetter != nil {
+			reservedCons.WithDeadLetter(opts.deadLetter)
+		}
+		wg.Add(1)
+		go func() { defer wg.Done(); reservedCons.Run(ctx, []string{eventschema.StockReservedTopic}) }()
+	} else {
+		log.Warnw("stock reserved consumer init failed", "error", err)
+	}
+
+	// Cancel orders whose stock could not be reserved
+	if stockCons, err := con.NewStockReservationFailedConsumer(bus, "order-service-stock", opts.autoOffsetReset, stockReservationFailedHandler(orchestrator, log)); err == nil {
+		closers = append(closers, stockCons)
+		stockCons.WithLogger(pkglogger.NewZapLogger(log))
+		if opts.processed != nil {
+			stockCons.WithIdempotency(opts.processed)
+		}
+		if opts.deadLetter != nil {
+			stockCons.WithDeadLetter(opts.deadLetter)
+		}
+		wg.Add(1)
+		go func() { defer wg.Done(); stockCons.Run(ctx, []string{eventschema.StockReservationFailedTopic}) }()
+	} else {
+		log.Warnw("stock reservation failed consumer init failed", "error", err)
+	}
+
+	return closers
+}
+
+func paymentProcessedHandler(orchestrator *services.SagaOrchestrator, log *zap.SugaredLogger) con.PaymentProcessedHandlerFunc {
+	return func(ctx context.Context, evt *events.PaymentProcessed) error {
+		if err := orchestrator.OnPaymentProcessed(ctx, evt.OrderId, evt.PaymentId, evt.Success, evt.Message); err != nil {
+			log.Warnw("saga payment step failed", "orderID", evt.OrderId, "success", evt.Success, "error", err)
+			return err
+		}
+		return nil
+	}
+}
+
+func paymentReviewRequiredHandler(orchestrator *services.SagaOrchestrator, log *zap.SugaredLogger) con.PaymentReviewRequiredHandlerFunc {
+	return func(ctx context.Context, evt *events.PaymentReviewRequired) error {
+		if err := orchestrator.OnPaymentReviewRequired(ctx, evt.OrderId, evt.PaymentId, evt.Reasons); err != nil {
+			log.Warnw("saga payment review step failed", "orderID", evt.OrderId, "paymentID", evt.PaymentId, "error", err)
+			return err
+		}
+		return nil
+	}
+}
+
+func stockReservedHandler(orchestrator *services.SagaOrchestrator, log *zap.SugaredLogger) con.StockReservedHandlerFunc {
+	return func(ctx context.Context, evt *events.StockReserved) error {
+		if err := orchestrator.OnStockReserved(ctx, evt.OrderId); err != nil {
+			log.Warnw("saga stock reserved step failed", "orderID", evt.OrderId, "error", err)
+			return err
+		}
+		return nil
+	}
+}
+
+func stockReservationFailedHandler(orchestrator *services.SagaOrchestrator, log *zap.SugaredLogger) con.StockReservationFailedHandlerFunc {
+	return func(ctx context.Context, evt *events.StockReservationFailed) error {
+		shortfalls := make([]models.OrderShortfall, 0, len(evt.Shortfalls))
+		for _, sf := range evt.Shortfalls {
+			shortfalls = append(shortfalls, models.OrderShortfall{ProductID: sf.ProductId, RequestedQuantity: sf.RequestedQuantity, AvailableQuantity: sf.AvailableQuantity})
+		}
+		if err := orchestrator.OnStockReservationFailed(ctx, evt.OrderId, evt.Reason, shortfalls); err != nil {
+			log.Warnw("saga stock reservation failed step failed", "orderID", evt.OrderId, "error", err)
+			return err
+		}
+		return nil
+	}
+}
@@ -0,0 +1,559 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/eventschema"
+	kafkaclient "github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
+	"github.com/kubernetestest/ecommerce-platform/proto-go/events"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/app/services"
+	derrors "github.com/kubernetestest/ecommerce-platform/services/order-service/internal/domain/errors"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/domain/models"
+	outboxrelay "github.com/kubernetestest/ecommerce-platform/services/order-service/internal/infra/outbox"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/outbox"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/repository"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/saga"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestCheckoutSaga runs orders through the saga over a MemoryBus: the outbox relay publishes
+// OrderCreated, stand-ins for inventory and payment answer on the bus, and the order-service
+// consumers and orchestrator settle the order.
+func TestCheckoutSaga(t *testing.T) {
+	tests := []struct {
+		name          string
+		productID     string
+		userID        string
+		wantStatus    models.OrderStatus
+		wantSaga      models.SagaState
+		wantReleased  bool
+		wantPaymentID bool
+	}{
+		{name: "paid", productID: "prod-1", userID: "user-1", wantStatus: models.OrderStatusConfirmed, wantSaga: models.SagaStateCompleted, wantPaymentID: true},
+		{name: "out of stock", productID: "sold-out", userID: "user-1", wantStatus: models.OrderStatusCancelled, wantSaga: models.SagaStateFailed},
+		{name: "payment declined", productID: "prod-1", userID: "declined", wantStatus: models.OrderStatusCancelled, wantSaga: models.SagaStateFailed, wantReleased: true},
+		{name: "payment review", productID: "prod-1", userID: "risky", wantStatus: models.OrderStatusPaymentReview, wantSaga: models.SagaStatePaymentReview, wantPaymentID: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			h := startSagaHarness(ctx, t)
+			order, err := h.orders.CreateOrder(ctx, &services.CreateOrderRequest{
+				UserID:             tt.userID,
+				Items:              []services.OrderItemRequest{{ProductID: tt.productID, ProductName: "Widget", Quantity: 2, Price: 1500}},
+				Shipping:           models.Address{Recipient: "Jane Doe", Line1: "1 Main St", City: "Berlin", PostalCode: "10115", Country: "DE"},
+				Currency:           "EUR",
+				PaymentMethod:      models.PaymentMethodCreditCard,
+				PaymentMethodToken: "pm_test",
+			})
+			if err != nil {
+				t.Fatalf("CreateOrder: %v", err)
+			}
+
+			s := h.waitForSaga(ctx, t, order.ID, tt.wantSaga)
+			if got := h.store.order(order.ID).Status; got != tt.wantStatus {
+				t.Errorf("order status = %s, want %s", got, tt.wantStatus)
+			}
+			if got := s.PaymentID != ""; got != tt.wantPaymentID {
+				t.Errorf("saga payment ID = %q, want set %v", s.PaymentID, tt.wantPaymentID)
+			}
+			if got := h.stock.released(order.ID); got != tt.wantReleased {
+				t.Errorf("stock released = %v, want %v", got, tt.wantReleased)
+			}
+		})
+	}
+}
+
+// TestCancelDuringCheckout cancels orders whose checkout is still running: the saga releases
+// the stock and gives back the payment, also when the payment succeeds after the cancellation.
+func TestCancelDuringCheckout(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID string
+		state  models.SagaState
+	}{
+		{name: "while paying", userID: "slow", state: models.SagaStateProcessingPayment},
+		{name: "in payment review", userID: "risky", state: models.SagaStatePaymentReview},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			h := startSagaHarness(ctx, t)
+			order, err := h.orders.CreateOrder(ctx, &services.CreateOrderRequest{
+				UserID:             tt.userID,
+				Items:              []services.OrderItemRequest{{ProductID: "prod-1", ProductName: "Widget", Quantity: 2, Price: 1500}},
+				Shipping:           models.Address{Recipient: "Jane Doe", Line1: "1 Main St", City: "Berlin", PostalCode: "10115", Country: "DE"},
+				Currency:           "EUR",
+				PaymentMethod:      models.PaymentMethodCreditCard,
+				PaymentMethodToken: "pm_test",
+			})
+			if err != nil {
+				t.Fatalf("CreateOrder: %v", err)
+			}
+			h.waitForSaga(ctx, t, order.ID, tt.state)
+
+			_, err = h.orchestrator.UpdateOrderStatus(ctx, &services.UpdateOrderStatusRequest{OrderID: order.ID, Status: models.OrderStatusProcessing})
+			if !errors.Is(err, derrors.ErrCheckoutInProgress) {
+				t.Errorf("UpdateOrderStatus during checkout: %v, want %v", err, derrors.ErrCheckoutInProgress)
+			}
+			if _, err := h.orchestrator.CancelOrder(ctx, order.ID, tt.userID); err != nil {
+				t.Fatalf("CancelOrder: %v", err)
+			}
+			close(h.payments.gate)
+
+			paymentID := "pay-" + order.ID
+			for !h.payments.refunded(paymentID) {
+				select {
+				case <-ctx.Done():
+					t.Fatalf("payment %s was not refunded", paymentID)
+				case <-time.After(5 * time.Millisecond):
+				}
+			}
+			h.waitForSaga(ctx, t, order.ID, models.SagaStateFailed)
+			if got := h.store.order(order.ID).Status; got != models.OrderStatusCancelled {
+				t.Errorf("order status = %s, want %s", got, models.OrderStatusCancelled)
+			}
+			if !h.stock.released(order.ID) {
+				t.Error("stock was not released")
+			}
+		})
+	}
+}
+
+// sagaHarness is order-service wired to a MemoryBus
+type sagaHarness struct {
+	store        *memoryStore
+	orders       *services.OrderService
+	orchestrator *services.SagaOrchestrator
+	stock        *recordingStockReleaser
+	payments     *recordingPaymentRefunder
+}
+
+func startSagaHarness(ctx context.Context, t *testing.T) *sagaHarness {
+	t.Helper()
+	bus := kafkaclient.NewMemoryBus(2)
+	log := zap.NewNop()
+	store := newMemoryStore()
+	stock := &recordingStockReleaser{orders: make(map[string]bool)}
+	payments := &recordingPaymentRefunder{payments: make(map[string]bool), gate: make(chan struct{})}
+
+	orders := services.NewOrderService(store, nil, nil, log)
+	orchestrator := services.NewSagaOrchestrator(memorySagas{store}, orders, services.SagaConfig{
+		PollInterval: 10 * time.Millisecond,
+		BaseBackoff:  10 * time.Millisecond,
+	}).WithStockReleaser(stock).WithPaymentRefunder(payments).WithLogger(log)
+
+	prod, err := bus.NewPublisher(kafkaclient.PublisherConfig{ClientID: "order-service"})
+	if err != nil {
+		t.Fatalf("publisher: %v", err)
+	}
+	relay := outboxrelay.NewRelay(store, prod, outboxrelay.RelayConfig{PollInterval: 10 * time.Millisecond})
+
+	var wg sync.WaitGroup
+	runCtx, stop := context.WithCancel(ctx)
+	t.Cleanup(func() {
+		stop()
+		wg.Wait()
+	})
+	wg.Add(2)
+	go func() { defer wg.Done(); _ = relay.Run(runCtx) }()
+	go func() { defer wg.Done(); _ = orchestrator.Run(runCtx) }()
+	closers := startSagaConsumers(runCtx, &wg, bus, orchestrator, sagaConsumerOptions{autoOffsetReset: "earliest", deadLetter: prod}, log.Sugar())
+	t.Cleanup(func() {
+		for _, c := range closers {
+			_ = c.Close()
+		}
+	})
+	startParticipants(runCtx, t, &wg, bus, payments.gate)
+
+	return &sagaHarness{store: store, orders: orders, orchestrator: orchestrator, stock: stock, payments: payments}
+}
+
+func (h *sagaHarness) waitForSaga(ctx context.Context, t *testing.T, orderID string, want models.SagaState) *models.OrderSaga {
+	t.Helper()
+	for {
+		s, err := h.orchestrator.GetSaga(ctx, orderID)
+		if err == nil && s.State == want {
+			return s
+		}
+		select {
+		case <-ctx.Done():
+			state := models.SagaState("")
+			if s != nil {
+				state = s.State
+			}
+			t.Fatalf("saga of %s is %s, want %s", orderID, state, want)
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+// startParticipants answers on the bus like inventory-service and payment-service: product
+// "sold-out" cannot be reserved, user "declined" is declined, user "risky" is held for review and
+// the payment of user "slow" succeeds once gate is closed
+func startParticipants(ctx context.Context, t *testing.T, wg *sync.WaitGroup, bus kafkaclient.Bus, gate <-chan struct{}) {
+	t.Helper()
+	base, err := bus.NewPublisher(kafkaclient.PublisherConfig{})
+	if err != nil {
+		t.Fatalf("participant publisher: %v", err)
+	}
+	routes := kafkaclient.NewEventRoutes()
+	kafkaclient.Route(routes, eventschema.StockReservedTopic, (*events.StockReserved).GetOrderId)
+	kafkaclient.Route(routes, eventschema.StockReservationFailedTopic, (*events.StockReservationFailed).GetOrderId)
+	kafkaclient.Route(routes, eventschema.PaymentProcessedTopic, (*events.PaymentProcessed).GetOrderId)
+	kafkaclient.Route(routes, eventschema.PaymentReviewRequiredTopic, (*events.PaymentReviewRequired).GetOrderId)
+	pub := kafkaclient.NewProtoEventPublisher(base, "saga-test", routes).WithSchemas(eventschema.Contracts())
+
+	inventory := func(ctx context.Context, msg proto.Message) error {
+		evt, err := eventschema.As[*events.OrderCreatedV2](msg)
+		if err != nil {
+			return err
+		}
+		for _, it := range evt.Items {
+			if it.ProductId == "sold-out" {
+				return pub.PublishSync(ctx, &events.StockReservationFailed{
+					OrderId:    evt.OrderId,
+					UserId:     evt.UserId,
+					Reason:     "insufficient stock",
+					Shortfalls: []*events.StockShortfall{{ProductId: it.ProductId, RequestedQuantity: it.Quantity}},
+				})
+			}
+		}
+		return pub.PublishSync(ctx, &events.StockReserved{OrderId: evt.OrderId, UserId: evt.UserId})
+	}
+	payment := func(ctx context.Context, msg proto.Message) error {
+		evt, err := eventschema.As[*events.StockReserved](msg)
+		if err != nil {
+			return err
+		}
+		switch evt.UserId {
+		case "declined":
+			return pub.PublishSync(ctx, &events.PaymentProcessed{OrderId: evt.OrderId, PaymentId: "pay-" + evt.OrderId, Message: "card declined"})
+		case "risky":
+			return pub.PublishSync(ctx, &events.PaymentReviewRequired{OrderId: evt.OrderId, PaymentId: "pay-" + evt.OrderId, UserId: evt.UserId, Reasons: []string{"velocity"}})
+		case "slow":
+			select {
+			case <-gate:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return pub.PublishSync(ctx, &events.PaymentProcessed{OrderId: evt.OrderId, PaymentId: "pay-" + evt.OrderId, Success: true})
+	}
+
+	for group, p := range map[string]struct {
+		topic  string
+		handle func(context.Context, proto.Message) error
+	}{
+		"inventory-service": {eventschema.OrderCreatedTopic, inventory},
+		"payment-service":   {eventschema.StockReservedTopic, payment},
+	} {
+		c, err := bus.NewConsumer(kafkaclient.ConsumerConfig{GroupID: group})
+		if err != nil {
+			t.Fatalf("%s consumer: %v", group, err)
+		}
+		wg.Add(1)
+		go func() { defer wg.Done(); _ = c.RunEventLoop(ctx, []string{p.topic}, eventschema.Contracts(), p.handle) }()
+	}
+}
+
+type recordingStockReleaser struct {
+	mu     sync.Mutex
+	orders map[string]bool
+}
+
+func (r *recordingStockReleaser) ReleaseStock(_ context.Context, orderID string, _ []models.OrderItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.orders[orderID] = true
+	return nil
+}
+
+func (r *recordingStockReleaser) released(orderID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.orders[orderID]
+}
+
+type recordingPaymentRefunder struct {
+	mu       sync.Mutex
+	payments map[string]bool
+	// gate holds back the payment of user "slow"
+	gate chan struct{}
+}
+
+func (r *recordingPaymentRefunder) RefundPayment(_ context.Context, paymentID, _ string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.payments[paymentID] = true
+	return nil
+}
+
+func (r *recordingPaymentRefunder) refunded(paymentID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.payments[paymentID]
+}
+
+// memoryStore keeps orders, sagas and outbox messages in memory; one lock stands in for the
+// database transactions of the gorm repositories and another for the saga row locks
+type memoryStore struct {
+	mu      sync.Mutex
+	sagaMu  sync.Mutex
+	orders  map[string]models.Order
+	sagas   map[string]models.OrderSaga
+	outbox  map[string]models.OutboxMessage
+	numbers map[string]int64
+}
+
+// memorySagas is the saga.Store view of a memoryStore
+type memorySagas struct{ *memoryStore }
+
+var (
+	_ repository.OrderRepository = (*memoryStore)(nil)
+	_ outbox.Store               = (*memoryStore)(nil)
+	_ saga.Store                 = memorySagas{}
+)
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		orders:  make(map[string]models.Order),
+		sagas:   make(map[string]models.OrderSaga),
+		outbox:  make(map[string]models.OutboxMessage),
+		numbers: make(map[string]int64),
+	}
+}
+
+func (m *memoryStore) order(id string) models.Order {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.orders[id]
+}
+
+func (m *memoryStore) Create(_ context.Context, order *models.Order) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.orders[order.ID] = *order
+	return nil
+}
+
+func (m *memoryStore) CreateWithOutbox(_ context.Context, order *models.Order, s *models.OrderSaga, msgs ...*models.OutboxMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.orders[order.ID] = *order
+	s.ClearNewEntries()
+	m.sagas[s.OrderID] = *s
+	for _, msg := range msgs {
+		m.outbox[msg.ID] = *msg
+	}
+	return nil
+}
+
+func (m *memoryStore) GetByID(_ context.Context, id string) (*models.Order, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	o, ok := m.orders[id]
+	if !ok {
+		return nil, derrors.ErrOrderNotFound
+	}
+	return &o, nil
+}
+
+func (m *memoryStore) GetByUserID(_ context.Context, userID string, _, _ int) ([]*models.Order, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*models.Order
+	for _, o := range m.orders {
+		if o.UserID == userID {
+			o := o
+			out = append(out, &o)
+		}
+	}
+	return out, int64(len(out)), nil
+}
+
+func (m *memoryStore) Update(_ context.Context, order *models.Order) error {
+	return m.UpdateWithOutbox(context.Background(), order)
+}
+
+func (m *memoryStore) UpdateWithOutbox(_ context.Context, order *models.Order, msgs ...*models.OutboxMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.orders[order.ID] = *order
+	for _, msg := range msgs {
+		m.outbox[msg.ID] = *msg
+	}
+	return nil
+}
+
+func (m *memoryStore) Modify(_ context.Context, id string, fn func(*models.Order) ([]*models.OutboxMessage, error)) (*models.Order, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	o, ok := m.orders[id]
+	if !ok {
+		return nil, derrors.ErrOrderNotFound
+	}
+	msgs, err := fn(&o)
+	if err != nil {
+		return nil, err
+	}
+	m.orders[id] = o
+	for _, msg := range msgs {
+		m.outbox[msg.ID] = *msg
+	}
+	return &o, nil
+}
+
+func (m *memoryStore) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.orders, id)
+	return nil
+}
+
+func (m *memoryStore) GetByStatus(_ context.Context, status models.OrderStatus) ([]*models.Order, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*models.Order
+	for _, o := range m.orders {
+		if o.Status == status {
+			o := o
+			out = append(out, &o)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryStore) NextOrderNumber(_ context.Context, userID string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.numbers[userID]++
+	return m.numbers[userID], nil
+}
+
+func (m memorySagas) Get(_ context.Context, orderID string) (*models.OrderSaga, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sagas[orderID]
+	if !ok {
+		return nil, derrors.ErrSagaNotFound
+	}
+	return &s, nil
+}
+
+func (m memorySagas) Modify(ctx context.Context, orderID string, fn func(context.Context, *models.OrderSaga) error) (*models.OrderSaga, error) {
+	m.sagaMu.Lock()
+	defer m.sagaMu.Unlock()
+	m.mu.Lock()
+	s, ok := m.sagas[orderID]
+	m.mu.Unlock()
+	if !ok {
+		return nil, derrors.ErrSagaNotFound
+	}
+	// fn may update the order, so the store lock is not held while it runs
+	if err := fn(ctx, &s); err != nil {
+		return nil, err
+	}
+	s.ClearNewEntries()
+	m.mu.Lock()
+	m.sagas[orderID] = s
+	m.mu.Unlock()
+	return &s, nil
+}
+
+func (m memorySagas) ClaimDue(_ context.Context, now time.Time, limit int, lease time.Duration) ([]*models.OrderSaga, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var due []*models.OrderSaga
+	for id, s := range m.sagas {
+		if len(due) == limit || s.IsTerminal() || s.StepDeadline == nil || s.StepDeadline.After(now) {
+			continue
+		}
+		claimed := s
+		due = append(due, &claimed)
+		leased := now.Add(lease)
+		s.StepDeadline = &leased
+		m.sagas[id] = s
+	}
+	return due, nil
+}
+
+func (m memorySagas) List(_ context.Context, filter saga.ListFilter) ([]*models.OrderSaga, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*models.OrderSaga
+	for _, s := range m.sagas {
+		s := s
+		out = append(out, &s)
+	}
+	return out, nil
+}
+
+func (m *memoryStore) ClaimPending(_ context.Context, limit int, now time.Time, lease time.Duration) ([]*models.OutboxMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var due []*models.OutboxMessage
+	for id, msg := range m.outbox {
+		if msg.Status != models.OutboxStatusPending || msg.NextAttemptAt.After(now) {
+			continue
+		}
+		claimed := msg
+		due = append(due, &claimed)
+		msg.NextAttemptAt = now.Add(lease)
+		m.outbox[id] = msg
+	}
+	// Relay in creation order, as the gorm repository does
+	sort.Slice(due, func(i, j int) bool { return due[i].CreatedAt.Before(due[j].CreatedAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (m *memoryStore) MarkSent(_ context.Context, id string, sentAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	msg := m.outbox[id]
+	msg.Status, msg.SentAt = models.OutboxStatusSent, &sentAt
+	m.outbox[id] = msg
+	return nil
+}
+
+func (m *memoryStore) MarkFailed(_ context.Context, id string, reason string, nextAttemptAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	msg := m.outbox[id]
+	msg.Attempts++
+	msg.LastError, msg.NextAttemptAt = reason, nextAttemptAt
+	m.outbox[id] = msg
+	return nil
+}
+
+func (m *memoryStore) Stats(_ context.Context) (outbox.Stats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var st outbox.Stats
+	for _, msg := range m.outbox {
+		if msg.Status != models.OutboxStatusPending {
+			continue
+		}
+		st.Pending++
+		if st.OldestPendingAt.IsZero() || msg.CreatedAt.Before(st.OldestPendingAt) {
+			st.OldestPendingAt = msg.CreatedAt
+		}
+	}
+	return st, nil
+}
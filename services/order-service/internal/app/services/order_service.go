@@ -2,33 +2,72 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/kubernetestest/ecommerce-platform/pkg/eventschema"
+	"github.com/kubernetestest/ecommerce-platform/pkg/exchange"
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
 	"github.com/kubernetestest/ecommerce-platform/proto-go/events"
 	derrors "github.com/kubernetestest/ecommerce-platform/services/order-service/internal/domain/errors"
 	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/domain/models"
 	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/clock"
 	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/productinfo"
-	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/publisher"
 	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/repository"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/shipping"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/tax"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
 )
 
+// OrderCreatedTopic is the Kafka topic OrderCreated events are relayed to
+const OrderCreatedTopic = eventschema.OrderCreatedTopic
+
+// OrderStatusChangedTopic is the Kafka topic OrderStatusChanged events are relayed to
+const OrderStatusChangedTopic = eventschema.OrderStatusChangedTopic
+
+// DefaultReserveTimeout bounds how long a new order waits for stock reservation
+const DefaultReserveTimeout = 2 * time.Minute
+
 type OrderService struct {
-	orderRepo repository.OrderRepository
-	clock     clock.Clock
-	pub       publisher.EventPublisher
-	products  productinfo.Provider
-	logger    *zap.SugaredLogger
+	orderRepo      repository.OrderRepository
+	clock          clock.Clock
+	products       productinfo.Provider
+	rates          exchange.Provider
+	taxes          tax.Calculator
+	shipping       shipping.Quoter
+	logger         *zap.SugaredLogger
+	reserveTimeout time.Duration
 }
 
 type CreateOrderRequest struct {
-	UserID          string
-	Items           []OrderItemRequest
-	ShippingAddress string
-	Currency        string
+	UserID   string
+	Items    []OrderItemRequest
+	Shipping models.Address
+	// ShippingMethod is one of the methods quoted for the address, empty for STANDARD
+	ShippingMethod models.ShippingMethod
+	// Currency is the ISO 4217 presentment currency the order is priced and charged in
+	Currency      string
+	PaymentMethod models.PaymentMethod
+	// PaymentMethodToken is the saved card to charge
+	PaymentMethodToken string
+	// LegacyShipping marks a Shipping mapped from the one-line shipping_address of older
+	// clients: Line1 holds the line and only country and region may be set.
+	//
+	// Deprecated: remove with the shipping_address field of CreateOrderRequest.
+	LegacyShipping bool
+}
+
+// QuoteShippingRequest asks what shipping the items cost to an address
+type QuoteShippingRequest struct {
+	Shipping models.Address
+	Items    []OrderItemRequest
+	// Currency is the ISO 4217 currency of the quotes
+	Currency string
 }
 
 type OrderItemRequest struct {
@@ -44,14 +83,42 @@ type UpdateOrderStatusRequest struct {
 }
 
 // NewOrderService creates a fully configured service instance
-func NewOrderService(orderRepo repository.OrderRepository, c clock.Clock, p publisher.EventPublisher, prod productinfo.Provider, l *zap.Logger) *OrderService {
-	s := &OrderService{orderRepo: orderRepo, clock: c, pub: p, products: prod}
+func NewOrderService(orderRepo repository.OrderRepository, c clock.Clock, prod productinfo.Provider, l *zap.Logger) *OrderService {
+	s := &OrderService{orderRepo: orderRepo, clock: c, products: prod, reserveTimeout: DefaultReserveTimeout}
 	if l != nil {
 		s.logger = l.Sugar()
 	}
 	return s
 }
 
+// WithExchangeRates lets orders be placed in another currency than the catalog; catalog prices
+// are converted at checkout and the rate used is recorded on each item
+func (s *OrderService) WithExchangeRates(p exchange.Provider) *OrderService {
+	s.rates = p
+	return s
+}
+
+// WithTaxCalculator charges the taxes due where orders ship to; without it orders are not taxed
+func (s *OrderService) WithTaxCalculator(c tax.Calculator) *OrderService {
+	s.taxes = c
+	return s
+}
+
+// WithShippingQuoter charges shipping by the methods and rates it quotes; without it shipping
+// is free
+func (s *OrderService) WithShippingQuoter(q shipping.Quoter) *OrderService {
+	s.shipping = q
+	return s
+}
+
+// WithReserveTimeout sets the stock reservation step timeout of new order sagas
+func (s *OrderService) WithReserveTimeout(d time.Duration) *OrderService {
+	if d > 0 {
+		s.reserveTimeout = d
+	}
+	return s
+}
+
 func (s *OrderService) CreateOrder(ctx context.Context, req *CreateOrderRequest) (*models.Order, error) {
 	if req.UserID == "" {
 		return nil, fmt.Errorf("user ID is required")
@@ -59,8 +126,9 @@ func (s *OrderService) CreateOrder(ctx context.Context, req *CreateOrderRequest)
 	if len(req.Items) == 0 {
 		return nil, fmt.Errorf("order must contain at least one item")
 	}
-	if req.ShippingAddress == "" {
-		return nil, fmt.Errorf("shipping address is required")
+	currency, ok := money.LookupCurrency(strings.TrimSpace(req.Currency))
+	if !ok {
+		return nil, fmt.Errorf("%w: currency must be an ISO 4217 code", derrors.ErrInvalidArgument)
 	}
 
 	// Determine next sequential number per user and generate secure order ID
@@ -72,47 +140,99 @@ func (s *OrderService) CreateOrder(ctx context.Context, req *CreateOrderRequest)
 	// Generate secure order ID (ORD- + UUID) instead of exposing user ID
 	orderID := "ORD-" + uuid.New().String()
 
-	order := &models.Order{ID: orderID, UserID: req.UserID, Number: num, Status: models.OrderStatusPending, ShippingAddress: req.ShippingAddress, Items: make([]models.OrderItem, 0, len(req.Items)), Currency: req.Currency}
+	order := &models.Order{ID: orderID, UserID: req.UserID, Number: num, Status: models.OrderStatusPending, Items: make([]models.OrderItem, 0, len(req.Items)), Currency: currency.Code}
+	if err := order.ChoosePayment(req.PaymentMethod, req.PaymentMethodToken); err != nil {
+		return nil, fmt.Errorf("%w: %v", derrors.ErrInvalidArgument, err)
+	}
+	setShipping := order.SetShippingAddress
+	if req.LegacyShipping {
+		setShipping = order.SetLegacyShippingAddress
+	}
+	if err := setShipping(req.Shipping); err != nil {
+		return nil, fmt.Errorf("%w: %v", derrors.ErrInvalidArgument, err)
+	}
 	now := s.now()
 	order.CreatedAt, order.UpdatedAt = now, now
 
 	for _, item := range req.Items {
 		name := item.ProductName
-		price := item.Price
-		currency := req.Currency
+		price := money.Money{Amount: item.Price, Currency: order.Currency}
+		var details models.ItemDetails
 		if s.products != nil {
-			if info, err := s.products.GetProduct(ctx, item.ProductID); err == nil {
-				name, price, currency = info.Name, info.Price, info.Currency
-			} else if s.logger != nil {
-				s.logger.Warnw("product lookup failed; using payload values", "productID", item.ProductID, "error", err)
+			info, err := s.lookupProduct(ctx, item.ProductID)
+			if err != nil {
+				return nil, err
 			}
+			name, price = info.Name, info.Price
+			details = models.ItemDetails{TaxCategory: info.TaxCategory, WeightGrams: info.WeightGrams}
 		}
-		if err := order.AddItem(item.ProductID, name, item.Quantity, price, currency); err != nil {
+		if !strings.EqualFold(price.Currency, order.Currency) && s.rates != nil {
+			conv, converted, err := s.convertPrice(ctx, price, order.Currency)
+			if err != nil {
+				return nil, fmt.Errorf("failed to price item %s in %s: %w", item.ProductID, order.Currency, err)
+			}
+			if err := order.AddConvertedItem(item.ProductID, name, item.Quantity, converted, details, conv); err != nil {
+				return nil, fmt.Errorf("failed to add item %s: %w", item.ProductID, err)
+			}
+			continue
+		}
+		if err := order.AddItem(item.ProductID, name, item.Quantity, price, details); err != nil {
 			return nil, fmt.Errorf("failed to add item %s: %w", item.ProductID, err)
 		}
 	}
+	if err := s.applyTax(ctx, order); err != nil {
+		return nil, fmt.Errorf("failed to calculate tax: %w", err)
+	}
+	method := models.ShippingMethod(strings.ToUpper(strings.TrimSpace(string(req.ShippingMethod))))
+	if method == "" {
+		method = models.ShippingMethodStandard
+	}
+	if err := s.applyShipping(ctx, order, method); err != nil {
+		return nil, fmt.Errorf("failed to price shipping: %w", err)
+	}
 
-	if err := s.orderRepo.Create(ctx, order); err != nil {
+	// OrderCreated is stored in the outbox within the order transaction and relayed asynchronously
+	msg, err := s.orderCreatedMessage(order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OrderCreated event: %w", err)
+	}
+	// The checkout saga starts together with the order
+	saga := models.NewOrderSaga(order.ID, now, now.Add(s.reserveTimeout))
+	if err := s.orderRepo.CreateWithOutbox(ctx, order, saga, msg); err != nil {
 		return nil, fmt.Errorf("failed to create order: %w", err)
 	}
+	return order, nil
+}
 
-	// Publish OrderCreated (best-effort)
-	if s.pub != nil {
-		evt := &events.OrderCreated{
-			OrderId:     order.ID,
-			UserId:      order.UserID,
-			TotalAmount: order.TotalAmount,
-			Currency:    order.Currency,
-			OccurredAt:  s.now().Format(time.RFC3339),
+// QuoteShipping returns the shipping methods available to the address with what they cost for
+// the items in the requested currency, cheapest first
+func (s *OrderService) QuoteShipping(ctx context.Context, req *QuoteShippingRequest) ([]shipping.Quote, error) {
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("%w: at least one item is required", derrors.ErrInvalidArgument)
+	}
+	addr := req.Shipping.Normalize()
+	if err := addr.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", derrors.ErrInvalidArgument, err)
+	}
+	currency, ok := money.LookupCurrency(strings.TrimSpace(req.Currency))
+	if !ok {
+		return nil, fmt.Errorf("%w: currency must be an ISO 4217 code", derrors.ErrInvalidArgument)
+	}
+	var weight int64
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity must be positive", derrors.ErrInvalidArgument)
 		}
-		for _, it := range order.Items {
-			evt.Items = append(evt.Items, &events.OrderItem{ProductId: it.ProductID, Quantity: it.Quantity})
+		if s.products == nil {
+			continue
 		}
-		if err := s.pub.PublishOrderCreated(ctx, evt); err != nil && s.logger != nil {
-			s.logger.Errorw("failed to publish OrderCreated", "orderID", order.ID, "error", err)
+		info, err := s.lookupProduct(ctx, item.ProductID)
+		if err != nil {
+			return nil, err
 		}
+		weight += int64(info.WeightGrams) * int64(item.Quantity)
 	}
-	return order, nil
+	return s.quoteShipping(ctx, addr, weight, currency.Code)
 }
 
 func (s *OrderService) GetOrder(ctx context.Context, orderID, userID string) (*models.Order, error) {
@@ -126,6 +246,15 @@ func (s *OrderService) GetOrder(ctx context.Context, orderID, userID string) (*m
 	return order, nil
 }
 
+// GetOrderPayment returns an order for charging it; no ownership check, internal callers only
+func (s *OrderService) GetOrderPayment(ctx context.Context, orderID string) (*models.Order, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", derrors.ErrOrderNotFound, err)
+	}
+	return order, nil
+}
+
 func (s *OrderService) GetUserOrders(ctx context.Context, userID string, page, limit int) ([]*models.Order, int64, error) {
 	if page <= 0 {
 		page = 1
@@ -170,12 +299,96 @@ func (s *OrderService) CancelOrder(ctx context.Context, orderID, userID string)
 	return updated, nil
 }
 
-func (s *OrderService) AddItemToOrder(ctx context.Context, orderID, userID, productID, productName string, quantity int32, price int64, currency string) (*models.Order, error) {
+// ConfirmOrder confirms a paid order; confirming an already confirmed order is a no-op
+func (s *OrderService) ConfirmOrder(ctx context.Context, orderID string) (*models.Order, error) {
+	var updated *models.Order
+	err := s.modifyOrder(ctx, orderID, "", func(order *models.Order) error {
+		updated = order
+		if order.Status == models.OrderStatusConfirmed {
+			return nil
+		}
+		if err := order.UpdateStatus(models.OrderStatusConfirmed); err != nil {
+			return fmt.Errorf("failed to confirm order: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// HoldOrderForPaymentReview parks a pending order while its payment is in risk review.
+// Orders no longer pending (already held, or cancelled meanwhile) are left as they are.
+func (s *OrderService) HoldOrderForPaymentReview(ctx context.Context, orderID string) (*models.Order, error) {
+	var updated *models.Order
+	err := s.modifyOrder(ctx, orderID, "", func(order *models.Order) error {
+		updated = order
+		if order.Status != models.OrderStatusPending {
+			return nil
+		}
+		if err := order.UpdateStatus(models.OrderStatusPaymentReview); err != nil {
+			return fmt.Errorf("failed to hold order for payment review: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// CancelOrderWithReason cancels an order on behalf of the system; cancelling twice is a no-op
+func (s *OrderService) CancelOrderWithReason(ctx context.Context, orderID, reason string) (*models.Order, error) {
+	var updated *models.Order
+	err := s.modifyOrder(ctx, orderID, "", func(order *models.Order) error {
+		updated = order
+		if order.Status == models.OrderStatusCancelled {
+			return nil
+		}
+		if err := order.CancelWithReason(reason); err != nil {
+			return fmt.Errorf("failed to cancel order: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// CancelOrderForStockShortage cancels a pending order whose stock could not be reserved,
+// recording the reason and the short products. Redelivered events for a cancelled order are no-ops.
+func (s *OrderService) CancelOrderForStockShortage(ctx context.Context, orderID, reason string, shortfalls []models.OrderShortfall) (*models.Order, error) {
+	if reason == "" {
+		reason = "stock reservation failed"
+	}
+	var updated *models.Order
+	err := s.modifyOrder(ctx, orderID, "", func(order *models.Order) error {
+		updated = order
+		if order.Status == models.OrderStatusCancelled {
+			return nil
+		}
+		if err := order.CancelForStockShortage(reason, shortfalls); err != nil {
+			return fmt.Errorf("failed to cancel order: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *OrderService) AddItemToOrder(ctx context.Context, orderID, userID, productID, productName string, quantity int32, price money.Money, details models.ItemDetails) (*models.Order, error) {
 	var updated *models.Order
 	err := s.modifyOrder(ctx, orderID, userID, func(order *models.Order) error {
-		if err := order.AddItem(productID, productName, quantity, price, currency); err != nil {
+		if err := order.AddItem(productID, productName, quantity, price, details); err != nil {
 			return fmt.Errorf("failed to add item: %w", err)
 		}
+		if err := s.repriceOrder(ctx, order); err != nil {
+			return err
+		}
 		updated = order
 		return nil
 	})
@@ -191,6 +404,9 @@ func (s *OrderService) RemoveItemFromOrder(ctx context.Context, orderID, userID,
 		if err := order.RemoveItem(productID); err != nil {
 			return fmt.Errorf("failed to remove item: %w", err)
 		}
+		if err := s.repriceOrder(ctx, order); err != nil {
+			return err
+		}
 		updated = order
 		return nil
 	})
@@ -201,6 +417,125 @@ func (s *OrderService) RemoveItemFromOrder(ctx context.Context, orderID, userID,
 }
 
 // helpers
+
+// convertPrice converts a catalog unit price to the order currency. Currencies the rates do not
+// cover are invalid arguments; rates that cannot be loaded are not.
+func (s *OrderService) convertPrice(ctx context.Context, price money.Money, to string) (models.PriceConversion, money.Money, error) {
+	rate, err := s.rates.Rate(ctx, price.Currency, to)
+	if errors.Is(err, exchange.ErrUnsupportedCurrency) {
+		return models.PriceConversion{}, money.Money{}, fmt.Errorf("%w: %v", derrors.ErrInvalidArgument, err)
+	}
+	if err != nil {
+		return models.PriceConversion{}, money.Money{}, err
+	}
+	converted, err := exchange.Convert(price, rate)
+	if err != nil {
+		return models.PriceConversion{}, money.Money{}, fmt.Errorf("%w: %v", derrors.ErrInvalidArgument, err)
+	}
+	conv := models.PriceConversion{CatalogPrice: price, Rate: rate.Value, RateAsOf: rate.AsOf}
+	return conv, converted, nil
+}
+
+// repriceOrder recalculates tax and shipping after the items of an order changed
+func (s *OrderService) repriceOrder(ctx context.Context, order *models.Order) error {
+	if err := s.applyTax(ctx, order); err != nil {
+		return fmt.Errorf("failed to calculate tax: %w", err)
+	}
+	if order.ShippingMethod == "" {
+		return nil
+	}
+	if err := s.applyShipping(ctx, order, order.ShippingMethod); err != nil {
+		return fmt.Errorf("failed to price shipping: %w", err)
+	}
+	return nil
+}
+
+// lookupProduct returns the catalog details of a product. Orders are priced, taxed and
+// shipped by them, so a failed lookup fails the order, as does a product without a weight
+// when shipping is charged by weight.
+func (s *OrderService) lookupProduct(ctx context.Context, productID string) (*productinfo.ProductInfo, error) {
+	info, err := s.products.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up product %s: %w", productID, err)
+	}
+	if s.shipping != nil && info.WeightGrams <= 0 {
+		return nil, fmt.Errorf("product %s has no shipping weight", productID)
+	}
+	return info, nil
+}
+
+// applyShipping charges the shipping method for the weight of the order and its address
+func (s *OrderService) applyShipping(ctx context.Context, order *models.Order, method models.ShippingMethod) error {
+	quotes, err := s.quoteShipping(ctx, order.Shipping, order.WeightGrams(), order.Currency)
+	if err != nil {
+		return err
+	}
+	for _, q := range quotes {
+		if strings.EqualFold(q.Method, string(method)) {
+			return order.ChooseShipping(models.ShippingMethod(q.Method), q.Cost)
+		}
+	}
+	return fmt.Errorf("%w: shipping method %s is not available to %s", derrors.ErrInvalidArgument, method, order.Shipping.Country)
+}
+
+// quoteShipping quotes the shipping methods to an address in currency, converting the costs
+// of rate tables in another currency. Without a quoter standard shipping is free.
+func (s *OrderService) quoteShipping(ctx context.Context, addr models.Address, weightGrams int64, currency string) ([]shipping.Quote, error) {
+	if s.shipping == nil {
+		return []shipping.Quote{{Method: string(models.ShippingMethodStandard), Name: "Standard", Cost: money.Money{Currency: currency}}}, nil
+	}
+	quotes, err := s.shipping.Quote(ctx, shipping.Request{
+		Destination: shipping.Destination{Country: addr.Country, Region: addr.Region, PostalCode: addr.PostalCode},
+		WeightGrams: weightGrams,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range quotes {
+		if strings.EqualFold(quotes[i].Cost.Currency, currency) {
+			continue
+		}
+		if s.rates == nil {
+			return nil, fmt.Errorf("shipping is priced in %s and no exchange rates are configured for %s", quotes[i].Cost.Currency, currency)
+		}
+		_, converted, err := s.convertPrice(ctx, quotes[i].Cost, currency)
+		if err != nil {
+			return nil, err
+		}
+		quotes[i].Cost = converted
+	}
+	sort.SliceStable(quotes, func(i, j int) bool { return quotes[i].Cost.Amount < quotes[j].Cost.Amount })
+	return quotes, nil
+}
+
+// applyTax charges the taxes due on the order items where the order ships to
+func (s *OrderService) applyTax(ctx context.Context, order *models.Order) error {
+	if s.taxes == nil {
+		return nil
+	}
+	req := tax.Request{Address: tax.Address{Country: order.Shipping.Country, Region: order.Shipping.Region}}
+	for _, it := range order.Items {
+		req.Items = append(req.Items, tax.Item{ID: it.ID, Category: it.TaxCategory, Amount: it.LineTotal()})
+	}
+	lines, err := s.taxes.Calculate(ctx, req)
+	if err != nil {
+		return err
+	}
+	taxes := make([]models.OrderItemTax, 0, len(lines))
+	for _, l := range lines {
+		taxes = append(taxes, models.OrderItemTax{
+			OrderItemID:  l.ItemID,
+			Name:         l.Name,
+			Jurisdiction: l.Jurisdiction,
+			Rate:         l.Rate,
+			Amount:       l.Amount.Amount,
+			Currency:     l.Amount.Currency,
+			Inclusive:    l.Inclusive,
+		})
+	}
+	return order.ApplyTax(taxes)
+}
+
 func (s *OrderService) now() time.Time {
 	if s.clock != nil {
 		return s.clock.Now()
@@ -208,21 +543,67 @@ func (s *OrderService) now() time.Time {
 	return time.Now()
 }
 
-// modifyOrder centralizes retrieval, optional ownership check, timestamp update, and persistence
-func (s *OrderService) modifyOrder(ctx context.Context, orderID, userID string, modifyFunc func(*models.Order) error) error {
-	order, err := s.orderRepo.GetByID(ctx, orderID)
-	if err != nil {
-		return fmt.Errorf("%w: %v", derrors.ErrOrderNotFound, err)
+func (s *OrderService) orderCreatedMessage(order *models.Order) (*models.OutboxMessage, error) {
+	evt := &events.OrderCreatedV2{
+		OrderId:         order.ID,
+		UserId:          order.UserID,
+		TotalAmount:     order.TotalAmount,
+		Currency:        order.Currency,
+		OccurredAt:      order.CreatedAt.Format(time.RFC3339),
+		ShippingAddress: order.ShippingAddress,
 	}
-	if userID != "" && order.UserID != userID {
-		return derrors.ErrOrderAccessDenied
+	for _, it := range order.Items {
+		evt.Items = append(evt.Items, &events.OrderLine{ProductId: it.ProductID, Quantity: it.Quantity, UnitPrice: it.Price})
 	}
-	if err := modifyFunc(order); err != nil {
-		return err
+	return outboxMessage(order.ID, OrderCreatedTopic, evt, order.CreatedAt)
+}
+
+// orderStatusChangedMessage lets payment-service capture or release the order payment
+func (s *OrderService) orderStatusChangedMessage(order *models.Order, oldStatus models.OrderStatus) (*models.OutboxMessage, error) {
+	evt := &events.OrderStatusChanged{
+		OrderId:    order.ID,
+		UserId:     order.UserID,
+		OldStatus:  string(oldStatus),
+		NewStatus:  string(order.Status),
+		Reason:     order.CancellationReason,
+		OccurredAt: order.UpdatedAt.Format(time.RFC3339),
 	}
-	order.UpdatedAt = s.now()
-	if err := s.orderRepo.Update(ctx, order); err != nil {
-		return fmt.Errorf("failed to save order: %w", err)
+	return outboxMessage(order.ID, OrderStatusChangedTopic, evt, order.UpdatedAt)
+}
+
+func outboxMessage(aggregateID, topic string, evt proto.Message, now time.Time) (*models.OutboxMessage, error) {
+	version, err := eventschema.Contracts().Version(topic, evt)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	payload, err := proto.Marshal(evt)
+	if err != nil {
+		return nil, err
+	}
+	eventType := string(evt.ProtoReflect().Descriptor().FullName())
+	return models.NewOutboxMessage(aggregateID, eventType, version, topic, payload, now), nil
+}
+
+// modifyOrder centralizes retrieval under a row lock, optional ownership check, timestamp update, and persistence.
+// A status change is published as OrderStatusChanged through the outbox.
+func (s *OrderService) modifyOrder(ctx context.Context, orderID, userID string, modifyFunc func(*models.Order) error) error {
+	_, err := s.orderRepo.Modify(ctx, orderID, func(order *models.Order) ([]*models.OutboxMessage, error) {
+		if userID != "" && order.UserID != userID {
+			return nil, derrors.ErrOrderAccessDenied
+		}
+		oldStatus := order.Status
+		if err := modifyFunc(order); err != nil {
+			return nil, err
+		}
+		order.UpdatedAt = s.now()
+		if order.Status == oldStatus {
+			return nil, nil
+		}
+		msg, err := s.orderStatusChangedMessage(order, oldStatus)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build OrderStatusChanged event: %w", err)
+		}
+		return []*models.OutboxMessage{msg}, nil
+	})
+	return err
 }
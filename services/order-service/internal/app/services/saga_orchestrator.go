@@ -0,0 +1,411 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	derrors "github.com/kubernetestest/ecommerce-platform/services/order-service/internal/domain/errors"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/domain/models"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/metrics"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/clock"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/compensation"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/saga"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// SagaConfig holds step timeouts and compensation retry settings of the checkout saga
+type SagaConfig struct {
+	PaymentTimeout time.Duration
+	// ReviewTimeout is how long a payment may wait in risk review before the order is cancelled
+	ReviewTimeout time.Duration
+	PollInterval  time.Duration
+	BatchSize     int
+	Lease         time.Duration
+	BaseBackoff   time.Duration
+	MaxBackoff    time.Duration
+}
+
+// SagaOrchestrator owns the checkout flow of each order: reserve stock → pay → commit.
+// Events from inventory and payment advance the saga; timed out steps and late
+// successes are compensated by releasing stock and refunding payments.
+type SagaOrchestrator struct {
+	sagas    saga.Store
+	orders   *OrderService
+	stock    compensation.StockReleaser
+	payments compensation.PaymentRefunder
+	clock    clock.Clock
+	metrics  metrics.OrderMetrics
+	logger   *zap.SugaredLogger
+	cfg      SagaConfig
+}
+
+// NewSagaOrchestrator creates an orchestrator with defaults applied to zero config values
+func NewSagaOrchestrator(sagas saga.Store, orders *OrderService, cfg SagaConfig) *SagaOrchestrator {
+	if cfg.PaymentTimeout <= 0 {
+		cfg.PaymentTimeout = 5 * time.Minute
+	}
+	if cfg.ReviewTimeout <= 0 {
+		cfg.ReviewTimeout = 72 * time.Hour
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = 30 * time.Second
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = 5 * time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 10 * time.Minute
+	}
+	return &SagaOrchestrator{sagas: sagas, orders: orders, cfg: cfg}
+}
+
+// WithStockReleaser sets the compensation used to release reserved stock
+func (o *SagaOrchestrator) WithStockReleaser(r compensation.StockReleaser) *SagaOrchestrator {
+	o.stock = r
+	return o
+}
+
+// WithPaymentRefunder sets the compensation used to refund payments
+func (o *SagaOrchestrator) WithPaymentRefunder(r compensation.PaymentRefunder) *SagaOrchestrator {
+	o.payments = r
+	return o
+}
+
+// WithClock sets time source for orchestrator
+func (o *SagaOrchestrator) WithClock(c clock.Clock) *SagaOrchestrator {
+	o.clock = c
+	return o
+}
+
+// WithMetrics sets metrics sink for orchestrator
+func (o *SagaOrchestrator) WithMetrics(m metrics.OrderMetrics) *SagaOrchestrator {
+	o.metrics = m
+	return o
+}
+
+// WithLogger sets logger for orchestrator
+func (o *SagaOrchestrator) WithLogger(l *zap.Logger) *SagaOrchestrator {
+	if l != nil {
+		o.logger = l.Sugar()
+	}
+	return o
+}
+
+// OnStockReserved advances the saga to payment
+func (o *SagaOrchestrator) OnStockReserved(ctx context.Context, orderID string) error {
+	now := o.now()
+	return o.apply(ctx, orderID, models.SagaEventStockReserved, func(_ context.Context, s *models.OrderSaga) error {
+		return s.StockReserved(now, now.Add(o.cfg.PaymentTimeout))
+	})
+}
+
+// OnStockReservationFailed fails the saga and cancels the order with the short products
+func (o *SagaOrchestrator) OnStockReservationFailed(ctx context.Context, orderID, reason string, shortfalls []models.OrderShortfall) error {
+	return o.apply(ctx, orderID, models.SagaEventStockReservationFailed, func(ctx context.Context, s *models.OrderSaga) error {
+		if err := s.StockReservationFailed(reason, o.now()); err != nil {
+			return err
+		}
+		_, err := o.orders.CancelOrderForStockShortage(ctx, orderID, reason, shortfalls)
+		return err
+	})
+}
+
+// OnPaymentProcessed completes the saga on success and compensates on failure
+func (o *SagaOrchestrator) OnPaymentProcessed(ctx context.Context, orderID, paymentID string, success bool, message string) error {
+	if !success {
+		reason := "payment failed"
+		if message != "" {
+			reason += ": " + message
+		}
+		return o.apply(ctx, orderID, models.SagaEventPaymentFailed, func(ctx context.Context, s *models.OrderSaga) error {
+			if err := s.PaymentFailed(reason, o.now()); err != nil {
+				return err
+			}
+			_, err := o.orders.CancelOrderWithReason(ctx, orderID, reason)
+			return err
+		})
+	}
+	return o.apply(ctx, orderID, models.SagaEventPaymentSucceeded, func(ctx context.Context, s *models.OrderSaga) error {
+		if err := s.PaymentSucceeded(paymentID, o.now()); err != nil {
+			return err
+		}
+		if s.State != models.SagaStateCompleted {
+			return nil // late payment, refund is scheduled
+		}
+		_, err := o.orders.ConfirmOrder(ctx, orderID)
+		return err
+	})
+}
+
+// OnPaymentReviewRequired holds the order while its payment is in risk review; the review
+// outcome arrives as PaymentProcessed
+func (o *SagaOrchestrator) OnPaymentReviewRequired(ctx context.Context, orderID, paymentID string, reasons []string) error {
+	now := o.now()
+	return o.apply(ctx, orderID, models.SagaEventPaymentReviewRequired, func(ctx context.Context, s *models.OrderSaga) error {
+		if err := s.PaymentReviewRequired(paymentID, strings.Join(reasons, "; "), now, now.Add(o.cfg.ReviewTimeout)); err != nil {
+			return err
+		}
+		_, err := o.orders.HoldOrderForPaymentReview(ctx, orderID)
+		return err
+	})
+}
+
+// CancelOrder cancels an order for its user, or for an admin when userID is empty. A checkout
+// still in progress is compensated: reserved stock is released and a payment held for review
+// is voided. Orders whose checkout finished are only cancelled.
+func (o *SagaOrchestrator) CancelOrder(ctx context.Context, orderID, userID string) (*models.Order, error) {
+	reason := "cancelled by admin"
+	if userID != "" {
+		reason = "cancelled by user"
+	}
+	var cancelled *models.Order
+	err := o.modify(ctx, orderID, models.SagaEventCancelled, func(ctx context.Context, s *models.OrderSaga) error {
+		var err error
+		if cancelled, err = o.orders.CancelOrder(ctx, orderID, userID); err != nil {
+			return err
+		}
+		err = s.Cancel(reason, o.now())
+		if errors.Is(err, models.ErrInvalidSagaTransition) {
+			return nil // nothing left to compensate
+		}
+		return err
+	})
+	if errors.Is(err, derrors.ErrSagaNotFound) {
+		// orders placed before the saga was introduced
+		return o.orders.CancelOrder(ctx, orderID, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cancelled, nil
+}
+
+// UpdateOrderStatus changes the status of an order for an admin. Cancellations are compensated
+// as in CancelOrder; other changes are refused until the checkout finished.
+func (o *SagaOrchestrator) UpdateOrderStatus(ctx context.Context, req *UpdateOrderStatusRequest) (*models.Order, error) {
+	if req.Status == models.OrderStatusCancelled {
+		return o.CancelOrder(ctx, req.OrderID, "")
+	}
+	var updated *models.Order
+	err := o.modify(ctx, req.OrderID, "STATUS_CHANGE", func(ctx context.Context, s *models.OrderSaga) error {
+		if !s.IsTerminal() {
+			return fmt.Errorf("%w: checkout of order %s is %s", derrors.ErrCheckoutInProgress, req.OrderID, s.State)
+		}
+		var err error
+		updated, err = o.orders.UpdateOrderStatus(ctx, req)
+		return err
+	})
+	if errors.Is(err, derrors.ErrSagaNotFound) {
+		return o.orders.UpdateOrderStatus(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Run times out stuck steps and retries compensation until ctx is cancelled
+func (o *SagaOrchestrator) Run(ctx context.Context) error {
+	ticker := time.NewTicker(o.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := o.ProcessDue(ctx)
+			if err != nil && o.logger != nil {
+				o.logger.Errorw("saga pass failed", "error", err)
+			}
+			// Keep draining while full batches come back
+			if err != nil || n < o.cfg.BatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue handles one batch of sagas whose step deadline passed and returns its size
+func (o *SagaOrchestrator) ProcessDue(ctx context.Context) (int, error) {
+	due, err := o.sagas.ClaimDue(ctx, o.now(), o.cfg.BatchSize, o.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+	for _, s := range due {
+		var perr error
+		if s.State == models.SagaStateCompensating {
+			perr = o.compensate(ctx, s)
+		} else {
+			perr = o.timeOut(ctx, s)
+		}
+		if perr != nil && o.logger != nil {
+			o.logger.Warnw("saga step failed", "orderID", s.OrderID, "state", s.State, "error", perr)
+		}
+	}
+	return len(due), nil
+}
+
+// GetSaga returns the saga of an order with its log
+func (o *SagaOrchestrator) GetSaga(ctx context.Context, orderID string) (*models.OrderSaga, error) {
+	return o.sagas.Get(ctx, orderID)
+}
+
+// ListSagas returns sagas matching filter; stuckOnly selects unfinished sagas past their step deadline
+func (o *SagaOrchestrator) ListSagas(ctx context.Context, states []models.SagaState, stuckOnly bool, limit int) ([]*models.OrderSaga, error) {
+	filter := saga.ListFilter{States: states, Limit: limit}
+	if stuckOnly {
+		now := o.now()
+		filter.StuckAt = &now
+	}
+	return o.sagas.List(ctx, filter)
+}
+
+func (o *SagaOrchestrator) timeOut(ctx context.Context, claimed *models.OrderSaga) error {
+	return o.apply(ctx, claimed.OrderID, models.SagaEventTimedOut, func(ctx context.Context, s *models.OrderSaga) error {
+		if s.State != claimed.State {
+			return models.ErrInvalidSagaTransition // the step completed after it was claimed
+		}
+		if err := s.TimeOut(o.now()); err != nil {
+			return err
+		}
+		_, err := o.orders.CancelOrderWithReason(ctx, claimed.OrderID, s.FailureReason)
+		return err
+	})
+}
+
+// compensate runs pending compensations outside the saga lock and records their outcome
+func (o *SagaOrchestrator) compensate(ctx context.Context, claimed *models.OrderSaga) error {
+	var (
+		released, refunded bool
+		errs               error
+	)
+	if claimed.PendingStockRelease {
+		if err := o.releaseStock(ctx, claimed.OrderID); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("release stock: %w", err))
+		} else {
+			released = true
+		}
+	}
+	if claimed.PendingRefund {
+		if err := o.refundPayment(ctx, claimed); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("refund payment %s: %w", claimed.PaymentID, err))
+		} else {
+			refunded = true
+		}
+	}
+
+	return o.apply(ctx, claimed.OrderID, "COMPENSATION", func(_ context.Context, s *models.OrderSaga) error {
+		if s.State != models.SagaStateCompensating {
+			return models.ErrInvalidSagaTransition
+		}
+		now := o.now()
+		if released {
+			s.StockReleased(now)
+		}
+		if refunded {
+			s.PaymentRefunded(now)
+		}
+		if errs != nil {
+			s.CompensationFailed(errs, now.Add(o.backoff(s.CompensationAttempts)), now)
+			if o.metrics != nil {
+				o.metrics.SagaCompensationFailed()
+			}
+		}
+		return nil
+	})
+}
+
+func (o *SagaOrchestrator) releaseStock(ctx context.Context, orderID string) error {
+	if o.stock == nil {
+		return errors.New("stock releaser not configured")
+	}
+	order, err := o.orders.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	return o.stock.ReleaseStock(ctx, orderID, order.Items)
+}
+
+func (o *SagaOrchestrator) refundPayment(ctx context.Context, s *models.OrderSaga) error {
+	if o.payments == nil {
+		return errors.New("payment refunder not configured")
+	}
+	reason := "order cancelled"
+	if s.FailureReason != "" {
+		reason += ": " + s.FailureReason
+	}
+	return o.payments.RefundPayment(ctx, s.PaymentID, reason)
+}
+
+// apply runs a saga transition under the saga lock; events that do not apply to the
+// current state (redeliveries, stale timeouts) are ignored
+func (o *SagaOrchestrator) apply(ctx context.Context, orderID, event string, fn func(context.Context, *models.OrderSaga) error) error {
+	err := o.modify(ctx, orderID, event, fn)
+	switch {
+	case errors.Is(err, models.ErrInvalidSagaTransition):
+		if o.logger != nil {
+			o.logger.Debugw("saga event ignored", "orderID", orderID, "event", event)
+		}
+		return nil
+	case errors.Is(err, derrors.ErrSagaNotFound):
+		if o.logger != nil {
+			o.logger.Warnw("saga not found", "orderID", orderID, "event", event)
+		}
+		return nil
+	}
+	return err
+}
+
+// modify runs fn under the saga lock; order changes made with the context passed to fn
+// are saved in the same transaction as the saga
+func (o *SagaOrchestrator) modify(ctx context.Context, orderID, event string, fn func(context.Context, *models.OrderSaga) error) error {
+	var before models.SagaState
+	s, err := o.sagas.Modify(ctx, orderID, func(ctx context.Context, s *models.OrderSaga) error {
+		before = s.State
+		return fn(ctx, s)
+	})
+	if err != nil {
+		return err
+	}
+	if s.State != before {
+		if o.metrics != nil {
+			o.metrics.SagaTransition(string(s.State))
+		}
+		if o.logger != nil {
+			o.logger.Infow("saga transition", "orderID", orderID, "event", event, "from", before, "to", s.State)
+		}
+	}
+	return nil
+}
+
+func (o *SagaOrchestrator) backoff(attempts int32) time.Duration {
+	d := o.cfg.BaseBackoff
+	for i := int32(0); i < attempts && d < o.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > o.cfg.MaxBackoff {
+		d = o.cfg.MaxBackoff
+	}
+	return d
+}
+
+func (o *SagaOrchestrator) now() time.Time {
+	if o.clock != nil {
+		return o.clock.Now()
+	}
+	return time.Now()
+}
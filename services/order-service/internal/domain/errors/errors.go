@@ -3,7 +3,9 @@ package errors
 import "errors"
 
 var (
-	ErrOrderNotFound     = errors.New("order not found")
-	ErrOrderAccessDenied = errors.New("access denied")
-	ErrInvalidArgument   = errors.New("invalid argument")
+	ErrOrderNotFound      = errors.New("order not found")
+	ErrOrderAccessDenied  = errors.New("access denied")
+	ErrInvalidArgument    = errors.New("invalid argument")
+	ErrSagaNotFound       = errors.New("saga not found")
+	ErrCheckoutInProgress = errors.New("checkout in progress")
 )
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Address - postal address an order ships to, stored on the order with the shipping_ prefix
+type Address struct {
+	Recipient  string `gorm:"type:varchar(255)"`
+	Line1      string `gorm:"type:varchar(255)"`
+	Line2      string `gorm:"type:varchar(255)"`
+	City       string `gorm:"type:varchar(128)"`
+	Region     string `gorm:"type:varchar(3)"` // ISO 3166-2 subdivision without the country prefix (CA for US-CA)
+	PostalCode string `gorm:"type:varchar(16)"`
+	Country    string `gorm:"type:varchar(2)"` // ISO 3166-1 alpha-2, checked by the payment risk rules
+}
+
+// addressFormat - what a country requires of its addresses
+type addressFormat struct {
+	postalCode *regexp.Regexp // nil: the country has no postal codes checked
+	// regions lists the valid subdivisions when a region is required
+	regions map[string]bool
+}
+
+func regionSet(codes ...string) map[string]bool {
+	out := make(map[string]bool, len(codes))
+	for _, c := range codes {
+		out[c] = true
+	}
+	return out
+}
+
+// addressFormats - countries whose addresses are checked beyond the required fields
+var addressFormats = map[string]addressFormat{
+	"US": {
+		postalCode: regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+		regions: regionSet("AL", "AK", "AZ", "AR", "CA", "CO", "CT", "DE", "DC", "FL", "GA", "HI", "ID", "IL", "IN",
+			"IA", "KS", "KY", "LA", "ME", "MD", "MA", "MI", "MN", "MS", "MO", "MT", "NE", "NV", "NH", "NJ", "NM", "NY",
+			"NC", "ND", "OH", "OK", "OR", "PA", "RI", "SC", "SD", "TN", "TX", "UT", "VT", "VA", "WA", "WV", "WI", "WY"),
+	},
+	"CA": {
+		postalCode: regexp.MustCompile(`^[A-Z]\d[A-Z] ?\d[A-Z]\d$`),
+		regions:    regionSet("AB", "BC", "MB", "NB", "NL", "NS", "NT", "NU", "ON", "PE", "QC", "SK", "YT"),
+	},
+	"AU": {
+		postalCode: regexp.MustCompile(`^\d{4}$`),
+		regions:    regionSet("ACT", "NSW", "NT", "QLD", "SA", "TAS", "VIC", "WA"),
+	},
+	"GB": {postalCode: regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`)},
+	"DE": {postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"FR": {postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"NL": {postalCode: regexp.MustCompile(`^\d{4} ?[A-Z]{2}$`)},
+	"JP": {postalCode: regexp.MustCompile(`^\d{3}-?\d{4}$`)},
+}
+
+// Normalize trims the fields and upper-cases the codes; a region given with its country
+// prefix (US-CA) loses the prefix
+func (a Address) Normalize() Address {
+	a.Recipient = strings.TrimSpace(a.Recipient)
+	a.Line1 = strings.TrimSpace(a.Line1)
+	a.Line2 = strings.TrimSpace(a.Line2)
+	a.City = strings.TrimSpace(a.City)
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+	a.Region = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(a.Region)), a.Country+"-")
+	a.PostalCode = strings.ToUpper(strings.TrimSpace(a.PostalCode))
+	return a
+}
+
+// Validate checks a normalized address: recipient, first line, city and country are required,
+// postal code and region are checked for the countries that use them
+func (a Address) Validate() error {
+	if a.Recipient == "" {
+		return errors.New("shipping recipient is required")
+	}
+	if a.Line1 == "" {
+		return errors.New("shipping address line is required")
+	}
+	if a.City == "" {
+		return errors.New("shipping city is required")
+	}
+	if err := a.validateCodes(); err != nil {
+		return err
+	}
+	f, ok := addressFormats[a.Country]
+	if !ok {
+		return nil
+	}
+	if f.postalCode != nil && !f.postalCode.MatchString(a.PostalCode) {
+		return fmt.Errorf("invalid postal code %q for %s", a.PostalCode, a.Country)
+	}
+	if f.regions != nil && !f.regions[a.Region] {
+		return fmt.Errorf("invalid or missing region %q for %s", a.Region, a.Country)
+	}
+	return nil
+}
+
+// validateCodes checks the form of the country, region and postal code
+func (a Address) validateCodes() error {
+	if len(a.Country) != 2 || a.Country[0] < 'A' || a.Country[0] > 'Z' || a.Country[1] < 'A' || a.Country[1] > 'Z' {
+		return errors.New("shipping country must be an ISO 3166-1 alpha-2 code")
+	}
+	if len(a.Region) > 3 || strings.IndexFunc(a.Region, func(c rune) bool { return (c < 'A' || c > 'Z') && (c < '0' || c > '9') }) >= 0 {
+		return errors.New("shipping region must be an ISO 3166-2 subdivision code")
+	}
+	if len(a.PostalCode) > 16 {
+		return errors.New("shipping postal code is too long")
+	}
+	return nil
+}
+
+// Format returns the address on one line, e.g. "Jane Doe, 1 Main St, Springfield, IL 62701, US"
+func (a Address) Format() string {
+	parts := []string{a.Recipient, a.Line1, a.Line2, a.City, strings.TrimSpace(a.Region + " " + a.PostalCode), a.Country}
+	out := parts[:0]
+	for _, p := range parts {
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, ", ")
+}
@@ -0,0 +1,68 @@
+package models
+
+import "testing"
+
+func TestAddressValidate(t *testing.T) {
+	valid := Address{Recipient: "Jane Doe", Line1: "1 Main St", City: "Springfield", Region: "IL", PostalCode: "62701", Country: "US"}
+	with := func(fn func(*Address)) Address {
+		a := valid
+		fn(&a)
+		return a
+	}
+
+	tests := []struct {
+		name    string
+		addr    Address
+		wantErr bool
+	}{
+		{name: "valid US", addr: valid},
+		{name: "ZIP+4", addr: with(func(a *Address) { a.PostalCode = "62701-1234" })},
+		{name: "region with country prefix", addr: with(func(a *Address) { a.Region = "us-il" })},
+		{name: "country without checked format", addr: Address{Recipient: "Jane Doe", Line1: "Calle 1", City: "Lima", Country: "pe"}},
+		{name: "GB postcode", addr: Address{Recipient: "Jane Doe", Line1: "1 High St", City: "London", PostalCode: "sw1a 1aa", Country: "GB"}},
+		{name: "CA postal code without space", addr: Address{Recipient: "Jane Doe", Line1: "1 Rue", City: "Montreal", Region: "QC", PostalCode: "H2X1Y4", Country: "CA"}},
+		{name: "missing recipient", addr: with(func(a *Address) { a.Recipient = " " }), wantErr: true},
+		{name: "missing line", addr: with(func(a *Address) { a.Line1 = "" }), wantErr: true},
+		{name: "missing city", addr: with(func(a *Address) { a.City = "" }), wantErr: true},
+		{name: "country not alpha-2", addr: with(func(a *Address) { a.Country = "USA" }), wantErr: true},
+		{name: "bad ZIP", addr: with(func(a *Address) { a.PostalCode = "6270" }), wantErr: true},
+		{name: "missing required region", addr: with(func(a *Address) { a.Region = "" }), wantErr: true},
+		{name: "unknown region", addr: with(func(a *Address) { a.Region = "XX" }), wantErr: true},
+		{name: "malformed region", addr: Address{Recipient: "Jane Doe", Line1: "Calle 1", City: "Lima", Region: "LI-M", Country: "PE"}, wantErr: true},
+		{name: "postal code too long", addr: Address{Recipient: "Jane Doe", Line1: "Calle 1", City: "Lima", PostalCode: "12345678901234567", Country: "PE"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.addr.Normalize().Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetLegacyShippingAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    Address
+		wantErr bool
+	}{
+		{name: "line only", addr: Address{Line1: "1 Main St, Springfield"}},
+		{name: "with country and region", addr: Address{Line1: "1 Main St, Springfield", Region: "il", Country: "us"}},
+		{name: "too short", addr: Address{Line1: "1 Main"}, wantErr: true},
+		{name: "region without country", addr: Address{Line1: "1 Main St, Springfield", Region: "IL"}, wantErr: true},
+		{name: "bad country", addr: Address{Line1: "1 Main St, Springfield", Country: "USA"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var o Order
+			err := o.SetLegacyShippingAddress(tt.addr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetLegacyShippingAddress() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && o.ShippingAddress != tt.addr.Line1 {
+				t.Errorf("ShippingAddress = %q, want %q", o.ShippingAddress, tt.addr.Line1)
+			}
+		})
+	}
+}
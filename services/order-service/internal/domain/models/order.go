@@ -2,23 +2,44 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
 	"gorm.io/gorm"
 )
 
 // Order - Aggregate Root
 type Order struct {
-	ID              string      `gorm:"primaryKey;type:varchar(255)"`
-	UserID          string      `gorm:"not null;type:varchar(255);index:idx_user_number,unique"`
-	Number          int64       `gorm:"not null;default:0;index:idx_user_number,unique"`
-	Status          OrderStatus `gorm:"type:varchar(20);not null;default:'PENDING'"`
-	Items           []OrderItem `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE"`
-	TotalAmount     int64       `gorm:"type:bigint;not null"`
-	Currency        string      `gorm:"type:varchar(3);not null;default:'USD'"`
-	ShippingAddress string      `gorm:"type:text;not null"`
-	CreatedAt       time.Time   `gorm:"autoCreateTime"`
-	UpdatedAt       time.Time   `gorm:"autoUpdateTime"`
+	ID                 string           `gorm:"primaryKey;type:varchar(255)"`
+	UserID             string           `gorm:"not null;type:varchar(255);index:idx_user_number,unique"`
+	Number             int64            `gorm:"not null;default:0;index:idx_user_number,unique"`
+	Status             OrderStatus      `gorm:"type:varchar(20);not null;default:'PENDING'"`
+	Items              []OrderItem      `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE"`
+	SubtotalAmount     int64            `gorm:"type:bigint;not null;default:0"` // items net of tax
+	TaxAmount          int64            `gorm:"type:bigint;not null;default:0"`
+	ShippingCostAmount int64            `gorm:"type:bigint;not null;default:0"`
+	TotalAmount        int64            `gorm:"type:bigint;not null"` // subtotal plus tax and shipping, what is charged
+	Currency           string           `gorm:"type:varchar(3);not null;default:'USD'"`
+	ShippingAddress    string           `gorm:"type:text;not null"` // one-line form of Shipping
+	Shipping           Address          `gorm:"embedded;embeddedPrefix:shipping_"`
+	ShippingMethod     ShippingMethod   `gorm:"type:varchar(32)"`
+	CancellationReason string           `gorm:"type:text"`
+	ShortProducts      []OrderShortfall `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE"`
+	PaymentMethod      PaymentMethod    `gorm:"type:varchar(32);not null;default:'CREDIT_CARD'"`
+	PaymentMethodToken string           `gorm:"type:varchar(64)"` // saved card in the payment-service vault
+	CreatedAt          time.Time        `gorm:"autoCreateTime"`
+	UpdatedAt          time.Time        `gorm:"autoUpdateTime"`
+}
+
+// OrderShortfall - product of the order that inventory could not reserve
+type OrderShortfall struct {
+	ID                string `gorm:"primaryKey;type:varchar(255)"`
+	OrderID           string `gorm:"not null;type:varchar(255);index"`
+	ProductID         string `gorm:"not null;type:varchar(255)"`
+	RequestedQuantity int32  `gorm:"not null"`
+	AvailableQuantity int32  `gorm:"not null"`
 }
 
 // OrderItem - Entity within Order Aggregate
@@ -31,6 +52,42 @@ type OrderItem struct {
 	Price       int64  `gorm:"type:bigint;not null"`
 	Total       int64  `gorm:"type:bigint;not null"`
 	Currency    string `gorm:"type:varchar(3);not null;default:'USD'"`
+	// Catalog price and the rate it was converted with, set when the product is priced in
+	// another currency than the order
+	CatalogPrice    int64   `gorm:"type:bigint"` // minor units of CatalogCurrency
+	CatalogCurrency string  `gorm:"type:varchar(3)"`
+	ExchangeRate    float64 `gorm:"type:double precision"`
+	RateAsOf        *time.Time
+	// TaxCategory of the product (empty for the standard rate) and the taxes charged on the item
+	TaxCategory string         `gorm:"type:varchar(32)"`
+	TaxLines    []OrderItemTax `gorm:"foreignKey:OrderItemID;constraint:OnDelete:CASCADE"`
+	WeightGrams int32          `gorm:"not null;default:0"` // shipping weight of one unit
+}
+
+// ItemDetails - product attributes copied onto an order item
+type ItemDetails struct {
+	TaxCategory string // empty for the standard rate
+	WeightGrams int32  // shipping weight of one unit, 0 if unknown
+}
+
+// OrderItemTax - tax charged on an order item. Inclusive taxes are contained in the item total,
+// exclusive ones are charged on top of it.
+type OrderItemTax struct {
+	ID           string  `gorm:"primaryKey;type:varchar(255)"`
+	OrderItemID  string  `gorm:"not null;type:varchar(255);index"`
+	Name         string  `gorm:"not null;type:varchar(64)"`
+	Jurisdiction string  `gorm:"not null;type:varchar(16)"` // country, or country-region (US-CA)
+	Rate         float64 `gorm:"type:double precision;not null"`
+	Amount       int64   `gorm:"type:bigint;not null"`
+	Currency     string  `gorm:"type:varchar(3);not null"`
+	Inclusive    bool    `gorm:"not null;default:false"`
+}
+
+// PriceConversion - how a catalog price was converted to the order currency
+type PriceConversion struct {
+	CatalogPrice money.Money
+	Rate         float64
+	RateAsOf     time.Time
 }
 
 type OrderStatus string
@@ -42,32 +99,78 @@ const (
 	OrderStatusShipped    OrderStatus = "SHIPPED"
 	OrderStatusDelivered  OrderStatus = "DELIVERED"
 	OrderStatusCancelled  OrderStatus = "CANCELLED"
+
+	// OrderStatusPaymentReview - the payment is held for risk review until an admin decides
+	OrderStatusPaymentReview OrderStatus = "PAYMENT_REVIEW"
+)
+
+// PaymentMethod - how the user pays for the order, chosen at checkout
+type PaymentMethod string
+
+const (
+	PaymentMethodCreditCard   PaymentMethod = "CREDIT_CARD"
+	PaymentMethodDebitCard    PaymentMethod = "DEBIT_CARD"
+	PaymentMethodPayPal       PaymentMethod = "PAYPAL"
+	PaymentMethodBankTransfer PaymentMethod = "BANK_TRANSFER"
+)
+
+// ShippingMethod - how the order is shipped, one of the methods quoted for its address
+type ShippingMethod string
+
+const (
+	ShippingMethodStandard ShippingMethod = "STANDARD"
+	ShippingMethodExpress  ShippingMethod = "EXPRESS"
 )
 
 // TableName sets the table name
-func (Order) TableName() string     { return "orders" }
-func (OrderItem) TableName() string { return "order_items" }
+func (Order) TableName() string          { return "orders" }
+func (OrderItem) TableName() string      { return "order_items" }
+func (OrderItemTax) TableName() string   { return "order_item_taxes" }
+func (OrderShortfall) TableName() string { return "order_shortfalls" }
 
 // Domain methods for Order Aggregate
 
 // AddItem adds item to order
-// AddItem adds item to order
-func (o *Order) AddItem(productID, productName string, quantity int32, price int64, currency string) error {
+func (o *Order) AddItem(productID, productName string, quantity int32, price money.Money, details ItemDetails) error {
+	return o.addItem(productID, productName, quantity, price, details, nil)
+}
+
+// AddConvertedItem adds an item whose catalog price was converted to the order currency,
+// recording the rate used
+func (o *Order) AddConvertedItem(productID, productName string, quantity int32, price money.Money, details ItemDetails, conv PriceConversion) error {
+	if conv.CatalogPrice.Currency == "" || !(conv.Rate > 0) {
+		return errors.New("price conversion needs the catalog currency and a positive rate")
+	}
+	return o.addItem(productID, productName, quantity, price, details, &conv)
+}
+
+func (o *Order) addItem(productID, productName string, quantity int32, price money.Money, details ItemDetails, conv *PriceConversion) error {
 	if o.Status == OrderStatusCancelled {
 		return errors.New("cannot add items to cancelled order")
 	}
 	if quantity <= 0 {
 		return errors.New("quantity must be positive")
 	}
-	if price < 0 {
+	if price.IsNegative() {
 		return errors.New("price cannot be negative")
 	}
+	if details.WeightGrams < 0 {
+		return errors.New("weight cannot be negative")
+	}
+	price, err := money.New(price.Amount, price.Currency)
+	if err != nil {
+		return err
+	}
 	if o.Currency == "" {
-		o.Currency = currency
+		o.Currency = price.Currency
 	}
-	if o.Currency != currency {
+	if o.Currency != price.Currency {
 		return errors.New("mixed currencies are not supported in a single order")
 	}
+	total, err := price.Mul(int64(quantity))
+	if err != nil {
+		return err
+	}
 
 	item := OrderItem{
 		ID:          generateOrderItemID(o.ID, productID),
@@ -75,12 +178,22 @@ func (o *Order) AddItem(productID, productName string, quantity int32, price int
 		ProductID:   productID,
 		ProductName: productName,
 		Quantity:    quantity,
-		Price:       price,
-		Total:       int64(quantity) * price,
-		Currency:    currency,
+		Price:       price.Amount,
+		Total:       total.Amount,
+		Currency:    price.Currency,
+		TaxCategory: details.TaxCategory,
+		WeightGrams: details.WeightGrams,
+	}
+	if conv != nil {
+		asOf := conv.RateAsOf
+		item.CatalogPrice, item.CatalogCurrency = conv.CatalogPrice.Amount, conv.CatalogPrice.Currency
+		item.ExchangeRate, item.RateAsOf = conv.Rate, &asOf
 	}
 	o.Items = append(o.Items, item)
-	o.recalculateTotal()
+	if err := o.recalculateTotal(); err != nil {
+		o.Items = o.Items[:len(o.Items)-1]
+		return err
+	}
 	return nil
 }
 
@@ -92,13 +205,133 @@ func (o *Order) RemoveItem(productID string) error {
 	for i, item := range o.Items {
 		if item.ProductID == productID {
 			o.Items = append(o.Items[:i], o.Items[i+1:]...)
-			o.recalculateTotal()
-			return nil
+			return o.recalculateTotal()
 		}
 	}
 	return errors.New("item not found")
 }
 
+// ChoosePayment records the payment method the order is charged with: a card saved in the
+// payment-service vault, referenced by its token. PayPal and bank transfers cannot be charged
+// by the payment processors and are refused.
+func (o *Order) ChoosePayment(method PaymentMethod, paymentMethodToken string) error {
+	switch method {
+	case PaymentMethodCreditCard, PaymentMethodDebitCard:
+		if paymentMethodToken == "" {
+			return errors.New("payment method token is required for card payments")
+		}
+	case PaymentMethodPayPal, PaymentMethodBankTransfer:
+		return fmt.Errorf("payment method %s is not supported; pay by card", method)
+	default:
+		return errors.New("unknown payment method")
+	}
+	o.PaymentMethod = method
+	o.PaymentMethodToken = paymentMethodToken
+	return nil
+}
+
+// SetShippingAddress validates and records where the order ships to
+func (o *Order) SetShippingAddress(addr Address) error {
+	addr = addr.Normalize()
+	if err := addr.Validate(); err != nil {
+		return err
+	}
+	o.Shipping = addr
+	o.ShippingAddress = addr.Format()
+	return nil
+}
+
+// SetLegacyShippingAddress records the one-line address of clients that do not send a
+// structured one yet: addr.Line1 holds the line, country and region are optional.
+//
+// Deprecated: remove with the shipping_address field of CreateOrderRequest.
+func (o *Order) SetLegacyShippingAddress(addr Address) error {
+	addr = addr.Normalize()
+	if len(addr.Line1) < 10 || len(addr.Line1) > 200 {
+		return errors.New("shipping address must be between 10 and 200 characters")
+	}
+	if addr.Country == "" {
+		if addr.Region != "" {
+			return errors.New("shipping region needs a shipping country")
+		}
+	} else if err := addr.validateCodes(); err != nil {
+		return err
+	}
+	o.Shipping = addr
+	o.ShippingAddress = addr.Line1
+	return nil
+}
+
+// ChooseShipping records the shipping method and what it costs for the order
+func (o *Order) ChooseShipping(method ShippingMethod, cost money.Money) error {
+	if method == "" {
+		return errors.New("shipping method is required")
+	}
+	if cost.IsNegative() {
+		return errors.New("shipping cost cannot be negative")
+	}
+	if !strings.EqualFold(cost.Currency, o.Currency) {
+		return errors.New("shipping cost currency differs from the order currency")
+	}
+	previousMethod, previousCost := o.ShippingMethod, o.ShippingCostAmount
+	o.ShippingMethod, o.ShippingCostAmount = method, cost.Amount
+	if err := o.recalculateTotal(); err != nil {
+		o.ShippingMethod, o.ShippingCostAmount = previousMethod, previousCost
+		return err
+	}
+	return nil
+}
+
+// ApplyTax replaces the tax lines of the items with lines, matched by OrderItemID, and
+// recalculates the subtotal, tax and total
+func (o *Order) ApplyTax(lines []OrderItemTax) error {
+	byItem := make(map[string][]OrderItemTax, len(o.Items))
+	for _, l := range lines {
+		if !strings.EqualFold(l.Currency, o.Currency) {
+			return errors.New("tax currency differs from the order currency")
+		}
+		if l.Amount < 0 {
+			return errors.New("tax amount cannot be negative")
+		}
+		byItem[l.OrderItemID] = append(byItem[l.OrderItemID], l)
+	}
+	for id := range byItem {
+		if o.item(id) == nil {
+			return fmt.Errorf("tax for unknown order item %s", id)
+		}
+	}
+	for _, item := range o.Items {
+		var included int64
+		for _, l := range byItem[item.ID] {
+			if l.Inclusive {
+				included += l.Amount
+			}
+		}
+		if included > item.Total {
+			return fmt.Errorf("tax included in item %s exceeds its total", item.ID)
+		}
+	}
+
+	previous := make([][]OrderItemTax, len(o.Items))
+	for i := range o.Items {
+		item := &o.Items[i]
+		previous[i] = item.TaxLines
+		item.TaxLines = byItem[item.ID]
+		for j := range item.TaxLines {
+			item.TaxLines[j].ID = fmt.Sprintf("%s-tax-%d", item.ID, j+1)
+			item.TaxLines[j].OrderItemID = item.ID
+			item.TaxLines[j].Currency = o.Currency
+		}
+	}
+	if err := o.recalculateTotal(); err != nil {
+		for i := range o.Items {
+			o.Items[i].TaxLines = previous[i]
+		}
+		return err
+	}
+	return nil
+}
+
 // UpdateStatus updates order status
 func (o *Order) UpdateStatus(status OrderStatus) error {
 	if !o.canTransitionTo(status) {
@@ -120,6 +353,31 @@ func (o *Order) Cancel() error {
 	return nil
 }
 
+// CancelWithReason cancels the order on behalf of the system and records why
+func (o *Order) CancelWithReason(reason string) error {
+	if err := o.Cancel(); err != nil {
+		return err
+	}
+	o.CancellationReason = reason
+	return nil
+}
+
+// CancelForStockShortage cancels a pending order whose stock could not be reserved
+func (o *Order) CancelForStockShortage(reason string, shortfalls []OrderShortfall) error {
+	if o.Status != OrderStatusPending {
+		return errors.New("only pending orders can be cancelled for stock shortage")
+	}
+	o.Status = OrderStatusCancelled
+	o.CancellationReason = reason
+	o.ShortProducts = make([]OrderShortfall, 0, len(shortfalls))
+	for _, sf := range shortfalls {
+		sf.ID = generateOrderItemID(o.ID, sf.ProductID)
+		sf.OrderID = o.ID
+		o.ShortProducts = append(o.ShortProducts, sf)
+	}
+	return nil
+}
+
 // GetItemCount returns total item count
 func (o *Order) GetItemCount() int32 {
 	var total int32
@@ -134,23 +392,111 @@ func (o *Order) IsModifiable() bool {
 	return o.Status == OrderStatusPending || o.Status == OrderStatusConfirmed
 }
 
-// Private methods
-func (o *Order) recalculateTotal() {
+// Subtotal returns the items net of tax
+func (o *Order) Subtotal() money.Money {
+	return money.Money{Amount: o.SubtotalAmount, Currency: o.Currency}
+}
+
+// Tax returns the tax of all items, inclusive and exclusive
+func (o *Order) Tax() money.Money {
+	return money.Money{Amount: o.TaxAmount, Currency: o.Currency}
+}
+
+// ShippingCost returns what the chosen shipping method costs
+func (o *Order) ShippingCost() money.Money {
+	return money.Money{Amount: o.ShippingCostAmount, Currency: o.Currency}
+}
+
+// WeightGrams returns the shipping weight of all items
+func (o *Order) WeightGrams() int64 {
 	var total int64
 	for _, item := range o.Items {
-		total += item.Total
+		total += int64(item.WeightGrams) * int64(item.Quantity)
+	}
+	return total
+}
+
+// Total returns the order total: subtotal plus tax and shipping
+func (o *Order) Total() money.Money {
+	return money.Money{Amount: o.TotalAmount, Currency: o.Currency}
+}
+
+// UnitPrice returns the price of one unit of the item
+func (i OrderItem) UnitPrice() money.Money {
+	return money.Money{Amount: i.Price, Currency: i.Currency}
+}
+
+// LineTotal returns the unit price times the quantity
+func (i OrderItem) LineTotal() money.Money {
+	return money.Money{Amount: i.Total, Currency: i.Currency}
+}
+
+// TaxAmount returns the tax charged
+func (t OrderItemTax) TaxAmount() money.Money {
+	return money.Money{Amount: t.Amount, Currency: t.Currency}
+}
+
+// Private methods
+
+// recalculateTotal sums the items into the subtotal (net of the taxes they include), the tax
+// lines into the tax and both with the shipping cost into the total
+func (o *Order) recalculateTotal() error {
+	lines := make([]money.Money, 0, len(o.Items))
+	var taxes, included []money.Money
+	for _, item := range o.Items {
+		lines = append(lines, item.LineTotal())
+		for _, t := range item.TaxLines {
+			taxes = append(taxes, t.TaxAmount())
+			if t.Inclusive {
+				included = append(included, t.TaxAmount())
+			}
+		}
+	}
+	gross, err := money.Sum(o.Currency, lines...)
+	if err != nil {
+		return err
+	}
+	tax, err := money.Sum(o.Currency, taxes...)
+	if err != nil {
+		return err
+	}
+	inclusive, err := money.Sum(o.Currency, included...)
+	if err != nil {
+		return err
+	}
+	subtotal, err := gross.Sub(inclusive)
+	if err != nil {
+		return err
+	}
+	total, err := subtotal.Add(tax)
+	if err != nil {
+		return err
+	}
+	if total, err = total.Add(o.ShippingCost()); err != nil {
+		return err
 	}
-	o.TotalAmount = total
+	o.SubtotalAmount, o.TaxAmount, o.TotalAmount = subtotal.Amount, tax.Amount, total.Amount
+	return nil
+}
+
+func (o *Order) item(id string) *OrderItem {
+	for i := range o.Items {
+		if o.Items[i].ID == id {
+			return &o.Items[i]
+		}
+	}
+	return nil
 }
 
 func (o *Order) canTransitionTo(newStatus OrderStatus) bool {
 	transitions := map[OrderStatus][]OrderStatus{
-		OrderStatusPending:    {OrderStatusConfirmed, OrderStatusCancelled},
-		OrderStatusConfirmed:  {OrderStatusProcessing, OrderStatusCancelled},
-		OrderStatusProcessing: {OrderStatusShipped, OrderStatusCancelled},
-		OrderStatusShipped:    {OrderStatusDelivered},
-		OrderStatusDelivered:  {},
-		OrderStatusCancelled:  {},
+		OrderStatusPending:       {OrderStatusConfirmed, OrderStatusPaymentReview, OrderStatusCancelled},
+		OrderStatusPaymentReview: {OrderStatusConfirmed, OrderStatusCancelled},
+		OrderStatusConfirmed:     {OrderStatusProcessing, OrderStatusCancelled},
+		OrderStatusProcessing:    {OrderStatusShipped, OrderStatusCancelled},
+		OrderStatusShipped:       {OrderStatusDelivered},
+		OrderStatusDelivered:     {},
+		OrderStatusCancelled:     {},
 	}
 	allowed, ok := transitions[o.Status]
 	if !ok {
@@ -172,11 +518,10 @@ func (o *Order) BeforeCreate(tx *gorm.DB) error {
 	if o.ShippingAddress == "" {
 		return errors.New("shipping address is required")
 	}
-	o.recalculateTotal()
-	return nil
+	return o.recalculateTotal()
 }
 
-func (o *Order) BeforeUpdate(tx *gorm.DB) error { o.recalculateTotal(); return nil }
+func (o *Order) BeforeUpdate(tx *gorm.DB) error { return o.recalculateTotal() }
 
 // Helper functions
 func generateOrderItemID(orderID, productID string) string {
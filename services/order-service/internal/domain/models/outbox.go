@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending OutboxStatus = "PENDING"
+	OutboxStatusSent    OutboxStatus = "SENT"
+)
+
+// OutboxMessage - domain event stored in the same transaction as the aggregate it describes
+type OutboxMessage struct {
+	ID            string       `gorm:"primaryKey;type:varchar(255)"`
+	AggregateID   string       `gorm:"not null;type:varchar(255);index"`
+	EventType     string       `gorm:"not null;type:varchar(255)"`
+	SchemaVersion int          `gorm:"not null;default:1"`
+	Topic         string       `gorm:"not null;type:varchar(255)"`
+	Payload       []byte       `gorm:"type:bytea;not null"`
+	Status        OutboxStatus `gorm:"type:varchar(20);not null;default:'PENDING';index:idx_outbox_due,priority:1"`
+	Attempts      int32        `gorm:"not null;default:0"`
+	LastError     string       `gorm:"type:text"`
+	NextAttemptAt time.Time    `gorm:"not null;index:idx_outbox_due,priority:2"`
+	CreatedAt     time.Time    `gorm:"not null"`
+	SentAt        *time.Time
+}
+
+func (OutboxMessage) TableName() string { return "outbox_messages" }
+
+// NewOutboxMessage creates a pending message that is due immediately
+func NewOutboxMessage(aggregateID, eventType string, schemaVersion int, topic string, payload []byte, now time.Time) *OutboxMessage {
+	return &OutboxMessage{
+		ID:            uuid.New().String(),
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		SchemaVersion: schemaVersion,
+		Topic:         topic,
+		Payload:       payload,
+		Status:        OutboxStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+}
@@ -0,0 +1,255 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SagaState string
+
+const (
+	SagaStateReservingStock    SagaState = "RESERVING_STOCK"
+	SagaStateProcessingPayment SagaState = "PROCESSING_PAYMENT"
+	SagaStatePaymentReview     SagaState = "PAYMENT_REVIEW"
+	SagaStateCompensating      SagaState = "COMPENSATING"
+	SagaStateCompleted         SagaState = "COMPLETED"
+	SagaStateFailed            SagaState = "FAILED"
+)
+
+// Saga events recorded in the saga log
+const (
+	SagaEventStarted                = "STARTED"
+	SagaEventStockReserved          = "STOCK_RESERVED"
+	SagaEventStockReservationFailed = "STOCK_RESERVATION_FAILED"
+	SagaEventPaymentSucceeded       = "PAYMENT_SUCCEEDED"
+	SagaEventPaymentFailed          = "PAYMENT_FAILED"
+	SagaEventPaymentReviewRequired  = "PAYMENT_REVIEW_REQUIRED"
+	SagaEventTimedOut               = "TIMED_OUT"
+	SagaEventCancelled              = "CANCELLED"
+	SagaEventStockReleased          = "STOCK_RELEASED"
+	SagaEventPaymentRefunded        = "PAYMENT_REFUNDED"
+	SagaEventCompensationFailed     = "COMPENSATION_FAILED"
+)
+
+var ErrInvalidSagaTransition = errors.New("invalid saga transition")
+
+// OrderSaga - state of the checkout flow (reserve stock → pay → commit) for one order.
+// StepDeadline is when the current step times out, or when compensation is retried.
+type OrderSaga struct {
+	OrderID              string         `gorm:"primaryKey;type:varchar(255)"`
+	State                SagaState      `gorm:"type:varchar(32);not null;index:idx_sagas_due,priority:1"`
+	StepDeadline         *time.Time     `gorm:"index:idx_sagas_due,priority:2"`
+	PaymentID            string         `gorm:"type:varchar(255)"`
+	PendingStockRelease  bool           `gorm:"not null;default:false"`
+	PendingRefund        bool           `gorm:"not null;default:false"`
+	CompensationAttempts int32          `gorm:"not null;default:0"`
+	FailureReason        string         `gorm:"type:text"`
+	LastError            string         `gorm:"type:text"`
+	Log                  []SagaLogEntry `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE"`
+	CreatedAt            time.Time      `gorm:"not null"`
+	UpdatedAt            time.Time      `gorm:"not null"`
+
+	// newEntries are log entries appended since the saga was loaded
+	newEntries []SagaLogEntry `gorm:"-"`
+}
+
+// SagaLogEntry - append-only record of a saga transition
+type SagaLogEntry struct {
+	ID        string    `gorm:"primaryKey;type:varchar(255)"`
+	OrderID   string    `gorm:"not null;type:varchar(255);index"`
+	Event     string    `gorm:"type:varchar(64);not null"`
+	FromState SagaState `gorm:"type:varchar(32)"`
+	ToState   SagaState `gorm:"type:varchar(32);not null"`
+	Detail    string    `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+func (OrderSaga) TableName() string    { return "order_sagas" }
+func (SagaLogEntry) TableName() string { return "order_saga_log" }
+
+// NewOrderSaga starts a saga waiting for stock reservation until reserveDeadline
+func NewOrderSaga(orderID string, now, reserveDeadline time.Time) *OrderSaga {
+	s := &OrderSaga{OrderID: orderID, State: SagaStateReservingStock, StepDeadline: &reserveDeadline, CreatedAt: now, UpdatedAt: now}
+	s.appendEntry(SagaEventStarted, "", "", now)
+	return s
+}
+
+// IsTerminal reports whether the saga has finished
+func (s *OrderSaga) IsTerminal() bool {
+	return s.State == SagaStateCompleted || s.State == SagaStateFailed
+}
+
+// NewEntries returns log entries appended since the saga was loaded
+func (s *OrderSaga) NewEntries() []SagaLogEntry { return s.newEntries }
+
+// ClearNewEntries marks appended log entries as persisted
+func (s *OrderSaga) ClearNewEntries() {
+	s.Log = append(s.Log, s.newEntries...)
+	s.newEntries = nil
+}
+
+// StockReserved moves the saga to payment, or schedules a release if the saga already gave up
+func (s *OrderSaga) StockReserved(now, paymentDeadline time.Time) error {
+	switch s.State {
+	case SagaStateReservingStock:
+		s.transition(SagaStateProcessingPayment, SagaEventStockReserved, "", now)
+		s.StepDeadline = &paymentDeadline
+		return nil
+	case SagaStateCompensating, SagaStateFailed:
+		// late reservation for an order that was already cancelled
+		s.PendingStockRelease = true
+		s.compensate(SagaEventStockReserved, "late reservation will be released", now)
+		return nil
+	}
+	return ErrInvalidSagaTransition
+}
+
+// StockReservationFailed fails the saga; nothing was reserved so nothing is compensated
+func (s *OrderSaga) StockReservationFailed(reason string, now time.Time) error {
+	if s.State != SagaStateReservingStock {
+		return ErrInvalidSagaTransition
+	}
+	s.FailureReason = reason
+	s.StepDeadline = nil
+	s.transition(SagaStateFailed, SagaEventStockReservationFailed, reason, now)
+	return nil
+}
+
+// PaymentReviewRequired waits for the risk review of the payment until reviewDeadline
+func (s *OrderSaga) PaymentReviewRequired(paymentID, detail string, now, reviewDeadline time.Time) error {
+	if s.State != SagaStateReservingStock && s.State != SagaStateProcessingPayment {
+		return ErrInvalidSagaTransition
+	}
+	s.PaymentID = paymentID
+	s.StepDeadline = &reviewDeadline
+	s.transition(SagaStatePaymentReview, SagaEventPaymentReviewRequired, detail, now)
+	return nil
+}
+
+// PaymentSucceeded completes the saga, or schedules a refund if the saga already gave up
+func (s *OrderSaga) PaymentSucceeded(paymentID string, now time.Time) error {
+	if s.State == SagaStateFailed && s.PaymentID == paymentID {
+		// this payment was already refunded
+		return ErrInvalidSagaTransition
+	}
+	s.PaymentID = paymentID
+	switch s.State {
+	// payment implies the stock was reserved, even if that event has not arrived yet
+	case SagaStateReservingStock, SagaStateProcessingPayment, SagaStatePaymentReview:
+		s.StepDeadline = nil
+		s.transition(SagaStateCompleted, SagaEventPaymentSucceeded, "", now)
+		return nil
+	case SagaStateCompensating, SagaStateFailed:
+		// late payment for an order that was already cancelled
+		s.PendingRefund = true
+		s.compensate(SagaEventPaymentSucceeded, "late payment will be refunded", now)
+		return nil
+	}
+	return ErrInvalidSagaTransition
+}
+
+// PaymentFailed releases the reserved stock
+func (s *OrderSaga) PaymentFailed(reason string, now time.Time) error {
+	if s.State != SagaStateReservingStock && s.State != SagaStateProcessingPayment && s.State != SagaStatePaymentReview {
+		return ErrInvalidSagaTransition
+	}
+	s.FailureReason = reason
+	s.PendingStockRelease = true
+	s.compensate(SagaEventPaymentFailed, reason, now)
+	return nil
+}
+
+// TimeOut abandons the current step and compensates whatever may have been done
+func (s *OrderSaga) TimeOut(now time.Time) error {
+	switch s.State {
+	case SagaStateReservingStock:
+		s.FailureReason = "stock reservation timed out"
+	case SagaStateProcessingPayment:
+		s.FailureReason = "payment timed out"
+	case SagaStatePaymentReview:
+		s.FailureReason = "payment review timed out"
+	default:
+		return ErrInvalidSagaTransition
+	}
+	// a reservation may still land after the timeout; releasing an unknown order is a no-op
+	s.PendingStockRelease = true
+	s.compensate(SagaEventTimedOut, s.FailureReason, now)
+	return nil
+}
+
+// Cancel abandons the checkout of an order cancelled by its user or an admin. Stock is released,
+// and a payment held for review is voided; a payment already succeeded means the saga completed.
+func (s *OrderSaga) Cancel(reason string, now time.Time) error {
+	switch s.State {
+	case SagaStateReservingStock, SagaStateProcessingPayment:
+	case SagaStatePaymentReview:
+		s.PendingRefund = true
+	default:
+		return ErrInvalidSagaTransition
+	}
+	s.FailureReason = reason
+	// a reservation may still be in flight; releasing an unknown order is a no-op
+	s.PendingStockRelease = true
+	s.compensate(SagaEventCancelled, reason, now)
+	return nil
+}
+
+// StockReleased records a completed stock release
+func (s *OrderSaga) StockReleased(now time.Time) {
+	s.PendingStockRelease = false
+	s.finishCompensation(SagaEventStockReleased, now)
+}
+
+// PaymentRefunded records a completed refund
+func (s *OrderSaga) PaymentRefunded(now time.Time) {
+	s.PendingRefund = false
+	s.finishCompensation(SagaEventPaymentRefunded, now)
+}
+
+// CompensationFailed records a failed compensation attempt and schedules a retry
+func (s *OrderSaga) CompensationFailed(err error, retryAt, now time.Time) {
+	s.CompensationAttempts++
+	s.LastError = err.Error()
+	s.StepDeadline = &retryAt
+	s.record(SagaEventCompensationFailed, err.Error(), now)
+}
+
+func (s *OrderSaga) compensate(event, detail string, now time.Time) {
+	s.StepDeadline = &now
+	s.transition(SagaStateCompensating, event, detail, now)
+}
+
+func (s *OrderSaga) finishCompensation(event string, now time.Time) {
+	if s.PendingStockRelease || s.PendingRefund {
+		s.record(event, "", now)
+		return
+	}
+	s.StepDeadline = nil
+	s.LastError = ""
+	s.transition(SagaStateFailed, event, "", now)
+}
+
+func (s *OrderSaga) transition(to SagaState, event, detail string, now time.Time) {
+	from := s.State
+	s.State = to
+	s.appendEntry(event, from, detail, now)
+}
+
+func (s *OrderSaga) record(event, detail string, now time.Time) {
+	s.appendEntry(event, s.State, detail, now)
+}
+
+func (s *OrderSaga) appendEntry(event string, from SagaState, detail string, now time.Time) {
+	s.UpdatedAt = now
+	s.newEntries = append(s.newEntries, SagaLogEntry{
+		ID:        uuid.New().String(),
+		OrderID:   s.OrderID,
+		Event:     event,
+		FromState: from,
+		ToState:   s.State,
+		Detail:    detail,
+		CreatedAt: now,
+	})
+}
@@ -0,0 +1,43 @@
+package compensation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	invpb "github.com/kubernetestest/ecommerce-platform/proto-go/inventory"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/domain/models"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/compensation"
+)
+
+// InventoryStockReleaser implements compensation.StockReleaser using inventory-service gRPC client.
+type InventoryStockReleaser struct {
+	client  invpb.InventoryServiceClient
+	timeout time.Duration
+}
+
+func NewInventoryStockReleaser(client invpb.InventoryServiceClient, timeout time.Duration) compensation.StockReleaser {
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	return &InventoryStockReleaser{client: client, timeout: timeout}
+}
+
+// ReleaseStock releases the order's reservations; orders without active reservations are a no-op
+func (r *InventoryStockReleaser) ReleaseStock(ctx context.Context, orderID string, items []models.OrderItem) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	req := &invpb.ReleaseStockRequest{OrderId: orderID, Items: make([]*invpb.StockReservationItem, 0, len(items))}
+	for _, it := range items {
+		req.Items = append(req.Items, &invpb.StockReservationItem{ProductId: it.ProductID, Quantity: it.Quantity})
+	}
+	resp, err := r.client.ReleaseStock(ctx, req)
+	if err != nil {
+		return fmt.Errorf("inventory release stock %s: %w", orderID, err)
+	}
+	if !resp.GetSuccess() {
+		return fmt.Errorf("inventory release stock %s: %s", orderID, resp.GetMessage())
+	}
+	return nil
+}
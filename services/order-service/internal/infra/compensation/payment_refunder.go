@@ -0,0 +1,57 @@
+package compensation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	paymentpb "github.com/kubernetestest/ecommerce-platform/proto-go/payment"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/compensation"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PaymentRefunder implements compensation.PaymentRefunder using payment-service gRPC client.
+type PaymentRefunder struct {
+	client  paymentpb.PaymentServiceClient
+	timeout time.Duration
+}
+
+func NewPaymentRefunder(client paymentpb.PaymentServiceClient, timeout time.Duration) compensation.PaymentRefunder {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &PaymentRefunder{client: client, timeout: timeout}
+}
+
+// RefundPayment voids the payment if it is only authorized, otherwise refunds the remaining
+// amount. A payment that is not refundable any more (already refunded or declined) counts as compensated.
+func (r *PaymentRefunder) RefundPayment(ctx context.Context, paymentID, reason string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	vresp, err := r.client.VoidPayment(ctx, &paymentpb.VoidPaymentRequest{PaymentId: paymentID, Reason: reason})
+	switch {
+	case status.Code(err) == codes.FailedPrecondition:
+		// not an open authorization; refund whatever was captured
+	case err != nil:
+		return fmt.Errorf("payment void %s: %w", paymentID, err)
+	case !vresp.GetSuccess():
+		return fmt.Errorf("payment void %s declined: %s", paymentID, vresp.GetMessage())
+	default:
+		return nil
+	}
+
+	resp, err := r.client.RefundPayment(ctx, &paymentpb.RefundPaymentRequest{PaymentId: paymentID, Reason: reason})
+	if status.Code(err) == codes.FailedPrecondition {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("payment refund %s: %w", paymentID, err)
+	}
+	if !resp.GetSuccess() {
+		return fmt.Errorf("payment refund %s declined: %s", paymentID, resp.GetMessage())
+	}
+	return nil
+}
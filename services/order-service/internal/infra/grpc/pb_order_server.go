@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
 	appsvc "github.com/kubernetestest/ecommerce-platform/services/order-service/internal/app/services"
 	derrors "github.com/kubernetestest/ecommerce-platform/services/order-service/internal/domain/errors"
 	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/domain/models"
@@ -17,52 +18,85 @@ import (
 type PBOrderServer struct {
 	orderpb.UnimplementedOrderServiceServer
 	svc             *appsvc.OrderService
+	sagas           *appsvc.SagaOrchestrator
 	defaultCurrency string
 }
 
-func NewPBOrderServer(svc *appsvc.OrderService, defaultCurrency string) *PBOrderServer {
-	return &PBOrderServer{svc: svc, defaultCurrency: defaultCurrency}
+// NewPBOrderServer creates the server; cancellations and status changes go through sagas
+func NewPBOrderServer(svc *appsvc.OrderService, sagas *appsvc.SagaOrchestrator, defaultCurrency string) *PBOrderServer {
+	return &PBOrderServer{svc: svc, sagas: sagas, defaultCurrency: defaultCurrency}
 }
 
 func (s *PBOrderServer) CreateOrder(ctx context.Context, req *orderpb.CreateOrderRequest) (*orderpb.CreateOrderResponse, error) {
 	// Validate basics
-	if req.UserId == "" || req.ShippingAddress == "" {
-		return nil, status.Error(codes.InvalidArgument, "user_id and shipping_address are required")
+	if req.UserId == "" || (req.Shipping == nil && req.ShippingAddress == "") {
+		return nil, status.Error(codes.InvalidArgument, "user_id and shipping are required")
 	}
-	if len(req.Items) == 0 {
-		return nil, status.Error(codes.InvalidArgument, "items are required")
+	items, err := mapItemRequests(req.Items)
+	if err != nil {
+		return nil, err
 	}
-	// Per-item validation
-	for _, it := range req.Items {
-		if it.ProductId == "" {
-			return nil, status.Error(codes.InvalidArgument, "item.product_id is required")
-		}
-		if it.Quantity <= 0 {
-			return nil, status.Error(codes.InvalidArgument, "item.quantity must be positive")
-		}
+	if req.GetPayment().GetMethod() == "" {
+		return nil, status.Error(codes.InvalidArgument, "payment.method is required")
 	}
-	// Map proto -> app request; product name/price not present in proto
-	items := make([]appsvc.OrderItemRequest, 0, len(req.Items))
-	for _, it := range req.Items {
-		items = append(items, appsvc.OrderItemRequest{
-			ProductID:   it.ProductId,
-			ProductName: "",
-			Quantity:    it.Quantity,
-			Price:       0,
-		})
+	currency := req.Currency
+	if currency == "" {
+		currency = s.defaultCurrency
 	}
-	order, err := s.svc.CreateOrder(ctx, &appsvc.CreateOrderRequest{
-		UserID:          req.UserId,
-		Items:           items,
-		ShippingAddress: req.ShippingAddress,
-		Currency:        s.defaultCurrency,
-	})
+	appReq := &appsvc.CreateOrderRequest{
+		UserID:             req.UserId,
+		Items:              items,
+		Shipping:           mapAddressFromPB(req.Shipping),
+		ShippingMethod:     models.ShippingMethod(req.ShippingMethod),
+		Currency:           currency,
+		PaymentMethod:      models.PaymentMethod(req.Payment.Method),
+		PaymentMethodToken: req.Payment.PaymentMethodToken,
+	}
+	if req.Shipping == nil {
+		// older clients only send the one-line address
+		appReq.Shipping = models.Address{Line1: req.ShippingAddress, Region: req.ShippingRegion, Country: req.ShippingCountry}
+		appReq.LegacyShipping = true
+	}
+	order, err := s.svc.CreateOrder(ctx, appReq)
 	if err != nil {
 		return nil, toStatusErr(err)
 	}
 	return &orderpb.CreateOrderResponse{Order: mapOrderToPB(order), Message: "Order created"}, nil
 }
 
+func (s *PBOrderServer) QuoteShipping(ctx context.Context, req *orderpb.QuoteShippingRequest) (*orderpb.QuoteShippingResponse, error) {
+	if req.Shipping == nil {
+		return nil, status.Error(codes.InvalidArgument, "shipping is required")
+	}
+	items, err := mapItemRequests(req.Items)
+	if err != nil {
+		return nil, err
+	}
+	currency := req.Currency
+	if currency == "" {
+		currency = s.defaultCurrency
+	}
+	quotes, err := s.svc.QuoteShipping(ctx, &appsvc.QuoteShippingRequest{
+		Shipping: mapAddressFromPB(req.Shipping),
+		Items:    items,
+		Currency: currency,
+	})
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+	resp := &orderpb.QuoteShippingResponse{Quotes: make([]*orderpb.ShippingQuote, 0, len(quotes))}
+	for _, q := range quotes {
+		resp.Quotes = append(resp.Quotes, &orderpb.ShippingQuote{
+			Method:  q.Method,
+			Name:    q.Name,
+			Cost:    money.ToProto[orderpb.Money](q.Cost),
+			MinDays: int32(q.MinDays),
+			MaxDays: int32(q.MaxDays),
+		})
+	}
+	return resp, nil
+}
+
 func (s *PBOrderServer) GetOrder(ctx context.Context, req *orderpb.GetOrderRequest) (*orderpb.GetOrderResponse, error) {
 	ord, err := s.svc.GetOrder(ctx, req.Id, req.UserId)
 	if err != nil {
@@ -101,7 +135,7 @@ func (s *PBOrderServer) UpdateOrderStatus(ctx context.Context, req *orderpb.Upda
 	default:
 		return nil, status.Error(codes.InvalidArgument, "unknown order status")
 	}
-	ord, err := s.svc.UpdateOrderStatus(ctx, &appsvc.UpdateOrderStatusRequest{OrderID: req.Id, Status: st})
+	ord, err := s.sagas.UpdateOrderStatus(ctx, &appsvc.UpdateOrderStatusRequest{OrderID: req.Id, Status: st})
 	if err != nil {
 		return nil, toStatusErr(err)
 	}
@@ -109,35 +143,132 @@ func (s *PBOrderServer) UpdateOrderStatus(ctx context.Context, req *orderpb.Upda
 }
 
 func (s *PBOrderServer) CancelOrder(ctx context.Context, req *orderpb.CancelOrderRequest) (*orderpb.CancelOrderResponse, error) {
-	ord, err := s.svc.CancelOrder(ctx, req.Id, req.UserId)
+	ord, err := s.sagas.CancelOrder(ctx, req.Id, req.UserId)
 	if err != nil {
 		return nil, toStatusErr(err)
 	}
 	return &orderpb.CancelOrderResponse{Order: mapOrderToPB(ord), Message: "Order cancelled"}, nil
 }
 
+func (s *PBOrderServer) GetOrderPayment(ctx context.Context, req *orderpb.GetOrderPaymentRequest) (*orderpb.GetOrderPaymentResponse, error) {
+	if req.OrderId == "" {
+		return nil, status.Error(codes.InvalidArgument, "order_id is required")
+	}
+	ord, err := s.svc.GetOrderPayment(ctx, req.OrderId)
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+	return &orderpb.GetOrderPaymentResponse{
+		OrderId:         ord.ID,
+		UserId:          ord.UserID,
+		Amount:          money.ToProto[orderpb.Money](ord.Total()),
+		Payment:         &orderpb.PaymentSelection{Method: string(ord.PaymentMethod), PaymentMethodToken: ord.PaymentMethodToken},
+		Status:          mapStatusToPB(ord.Status),
+		ShippingCountry: ord.Shipping.Country,
+	}, nil
+}
+
 // Mapping helpers
+
+// mapItemRequests validates the requested items; product name and price come from inventory
+func mapItemRequests(in []*orderpb.OrderItemRequest) ([]appsvc.OrderItemRequest, error) {
+	if len(in) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "items are required")
+	}
+	items := make([]appsvc.OrderItemRequest, 0, len(in))
+	for _, it := range in {
+		if it.ProductId == "" {
+			return nil, status.Error(codes.InvalidArgument, "item.product_id is required")
+		}
+		if it.Quantity <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "item.quantity must be positive")
+		}
+		items = append(items, appsvc.OrderItemRequest{ProductID: it.ProductId, Quantity: it.Quantity})
+	}
+	return items, nil
+}
+
+func mapAddressFromPB(a *orderpb.Address) models.Address {
+	return models.Address{
+		Recipient:  a.GetRecipient(),
+		Line1:      a.GetLine1(),
+		Line2:      a.GetLine2(),
+		City:       a.GetCity(),
+		Region:     a.GetRegion(),
+		PostalCode: a.GetPostalCode(),
+		Country:    a.GetCountry(),
+	}
+}
+
+func mapAddressToPB(a models.Address) *orderpb.Address {
+	return &orderpb.Address{
+		Recipient:  a.Recipient,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+}
+
 func mapOrderToPB(o *models.Order) *orderpb.Order {
 	items := make([]*orderpb.OrderItem, 0, len(o.Items))
 	for _, it := range o.Items {
-		items = append(items, &orderpb.OrderItem{
+		item := &orderpb.OrderItem{
 			Id:          it.ID,
 			ProductId:   it.ProductID,
 			ProductName: it.ProductName,
 			Quantity:    it.Quantity,
-			Price:       &orderpb.Money{Amount: it.Price, Currency: it.Currency},
-			Total:       &orderpb.Money{Amount: it.Total, Currency: it.Currency},
+			Price:       money.ToProto[orderpb.Money](it.UnitPrice()),
+			Total:       money.ToProto[orderpb.Money](it.LineTotal()),
+			TaxCategory: it.TaxCategory,
+		}
+		for _, t := range it.TaxLines {
+			item.TaxLines = append(item.TaxLines, &orderpb.TaxLine{
+				Name:         t.Name,
+				Jurisdiction: t.Jurisdiction,
+				Rate:         t.Rate,
+				Amount:       money.ToProto[orderpb.Money](t.TaxAmount()),
+				Inclusive:    t.Inclusive,
+			})
+		}
+		if it.CatalogCurrency != "" {
+			item.CatalogPrice = money.ToProto[orderpb.Money](money.Money{Amount: it.CatalogPrice, Currency: it.CatalogCurrency})
+			item.ExchangeRate = &orderpb.ExchangeRate{From: it.CatalogCurrency, To: it.Currency, Rate: it.ExchangeRate}
+			if it.RateAsOf != nil {
+				item.ExchangeRate.AsOf = timestamppb.New(*it.RateAsOf)
+			}
+		}
+		items = append(items, item)
+	}
+	shortProducts := make([]*orderpb.ProductShortfall, 0, len(o.ShortProducts))
+	for _, sf := range o.ShortProducts {
+		shortProducts = append(shortProducts, &orderpb.ProductShortfall{
+			ProductId:         sf.ProductID,
+			RequestedQuantity: sf.RequestedQuantity,
+			AvailableQuantity: sf.AvailableQuantity,
 		})
 	}
 	return &orderpb.Order{
-		Id:              o.ID,
-		UserId:          o.UserID,
-		Status:          mapStatusToPB(o.Status),
-		Items:           items,
-		TotalAmount:     &orderpb.Money{Amount: o.TotalAmount, Currency: o.Currency},
-		ShippingAddress: o.ShippingAddress,
-		CreatedAt:       timestamppb.New(o.CreatedAt),
-		UpdatedAt:       timestamppb.New(o.UpdatedAt),
+		Id:                 o.ID,
+		UserId:             o.UserID,
+		Status:             mapStatusToPB(o.Status),
+		Items:              items,
+		Subtotal:           money.ToProto[orderpb.Money](o.Subtotal()),
+		TaxAmount:          money.ToProto[orderpb.Money](o.Tax()),
+		TotalAmount:        money.ToProto[orderpb.Money](o.Total()),
+		ShippingAddress:    o.ShippingAddress,
+		ShippingCountry:    o.Shipping.Country,
+		ShippingRegion:     o.Shipping.Region,
+		Shipping:           mapAddressToPB(o.Shipping),
+		ShippingMethod:     string(o.ShippingMethod),
+		ShippingCost:       money.ToProto[orderpb.Money](o.ShippingCost()),
+		CreatedAt:          timestamppb.New(o.CreatedAt),
+		UpdatedAt:          timestamppb.New(o.UpdatedAt),
+		CancellationReason: o.CancellationReason,
+		ShortProducts:      shortProducts,
+		PaymentMethod:      string(o.PaymentMethod),
 	}
 }
 
@@ -147,6 +278,8 @@ func mapStatusToPB(s models.OrderStatus) orderpb.OrderStatus {
 		return orderpb.OrderStatus_PENDING
 	case models.OrderStatusConfirmed:
 		return orderpb.OrderStatus_CONFIRMED
+	case models.OrderStatusPaymentReview:
+		return orderpb.OrderStatus_PAYMENT_REVIEW
 	case models.OrderStatusProcessing:
 		return orderpb.OrderStatus_PROCESSING
 	case models.OrderStatusShipped:
@@ -163,12 +296,14 @@ func mapStatusToPB(s models.OrderStatus) orderpb.OrderStatus {
 // toStatusErr maps domain/service errors to gRPC statuses
 func toStatusErr(err error) error {
 	switch {
-	case errors.Is(err, derrors.ErrOrderNotFound):
+	case errors.Is(err, derrors.ErrOrderNotFound), errors.Is(err, derrors.ErrSagaNotFound):
 		return status.Error(codes.NotFound, err.Error())
 	case errors.Is(err, derrors.ErrOrderAccessDenied):
 		return status.Error(codes.PermissionDenied, err.Error())
 	case errors.Is(err, derrors.ErrInvalidArgument):
 		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, derrors.ErrCheckoutInProgress):
+		return status.Error(codes.FailedPrecondition, err.Error())
 	default:
 		return status.Error(codes.Internal, err.Error())
 	}
@@ -0,0 +1,81 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	appsvc "github.com/kubernetestest/ecommerce-platform/services/order-service/internal/app/services"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/domain/models"
+	orderpb "github.com/kubernetestest/ecommerce-platform/services/order-service/internal/pb/order"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// PBSagaAdminServer reports where checkout sagas are and why they are stuck
+type PBSagaAdminServer struct {
+	orderpb.UnimplementedOrderSagaAdminServiceServer
+	orchestrator *appsvc.SagaOrchestrator
+}
+
+func NewPBSagaAdminServer(orchestrator *appsvc.SagaOrchestrator) *PBSagaAdminServer {
+	return &PBSagaAdminServer{orchestrator: orchestrator}
+}
+
+func (s *PBSagaAdminServer) GetSaga(ctx context.Context, req *orderpb.GetSagaRequest) (*orderpb.GetSagaResponse, error) {
+	if req.OrderId == "" {
+		return nil, status.Error(codes.InvalidArgument, "order_id is required")
+	}
+	sg, err := s.orchestrator.GetSaga(ctx, req.OrderId)
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+	return &orderpb.GetSagaResponse{Saga: mapSagaToPB(sg, time.Now())}, nil
+}
+
+func (s *PBSagaAdminServer) ListSagas(ctx context.Context, req *orderpb.ListSagasRequest) (*orderpb.ListSagasResponse, error) {
+	states := make([]models.SagaState, 0, len(req.States))
+	for _, st := range req.States {
+		states = append(states, models.SagaState(st))
+	}
+	sagas, err := s.orchestrator.ListSagas(ctx, states, req.StuckOnly, int(req.Limit))
+	if err != nil {
+		return nil, toStatusErr(err)
+	}
+	now := time.Now()
+	out := make([]*orderpb.Saga, 0, len(sagas))
+	for _, sg := range sagas {
+		out = append(out, mapSagaToPB(sg, now))
+	}
+	return &orderpb.ListSagasResponse{Sagas: out}, nil
+}
+
+func mapSagaToPB(sg *models.OrderSaga, now time.Time) *orderpb.Saga {
+	out := &orderpb.Saga{
+		OrderId:              sg.OrderID,
+		State:                string(sg.State),
+		PaymentId:            sg.PaymentID,
+		PendingStockRelease:  sg.PendingStockRelease,
+		PendingRefund:        sg.PendingRefund,
+		CompensationAttempts: sg.CompensationAttempts,
+		FailureReason:        sg.FailureReason,
+		LastError:            sg.LastError,
+		CreatedAt:            timestamppb.New(sg.CreatedAt),
+		UpdatedAt:            timestamppb.New(sg.UpdatedAt),
+	}
+	if sg.StepDeadline != nil {
+		out.StepDeadline = timestamppb.New(*sg.StepDeadline)
+		out.Overdue = !sg.IsTerminal() && !sg.StepDeadline.After(now)
+	}
+	for _, e := range sg.Log {
+		out.Log = append(out.Log, &orderpb.SagaLogEntry{
+			Event:     e.Event,
+			FromState: string(e.FromState),
+			ToState:   string(e.ToState),
+			Detail:    e.Detail,
+			CreatedAt: timestamppb.New(e.CreatedAt),
+		})
+	}
+	return out
+}
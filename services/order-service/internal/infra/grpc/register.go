@@ -8,6 +8,11 @@ import (
 )
 
 // RegisterOrderPBServer registers the protobuf server implementation
-func RegisterOrderPBServer(server *gogrpc.Server, svc *appsvc.OrderService, defaultCurrency string) {
-	orderpb.RegisterOrderServiceServer(server, NewPBOrderServer(svc, defaultCurrency))
+func RegisterOrderPBServer(server *gogrpc.Server, svc *appsvc.OrderService, orchestrator *appsvc.SagaOrchestrator, defaultCurrency string) {
+	orderpb.RegisterOrderServiceServer(server, NewPBOrderServer(svc, orchestrator, defaultCurrency))
+}
+
+// RegisterSagaAdminPBServer registers the saga admin protobuf server implementation
+func RegisterSagaAdminPBServer(server *gogrpc.Server, orchestrator *appsvc.SagaOrchestrator) {
+	orderpb.RegisterOrderSagaAdminServiceServer(server, NewPBSagaAdminServer(orchestrator))
 }
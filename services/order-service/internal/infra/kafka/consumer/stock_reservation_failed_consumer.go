@@ -0,0 +1,72 @@
+package consumer
+
+import (
+	"context"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/eventschema"
+	kafkaclient "github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
+	"github.com/kubernetestest/ecommerce-platform/pkg/logger"
+	events "github.com/kubernetestest/ecommerce-platform/proto-go/events"
+
+	"google.golang.org/protobuf/proto"
+)
+
+type StockReservationFailedHandler interface {
+	Handle(ctx context.Context, evt *events.StockReservationFailed) error
+}
+
+type StockReservationFailedHandlerFunc func(ctx context.Context, evt *events.StockReservationFailed) error
+
+func (f StockReservationFailedHandlerFunc) Handle(ctx context.Context, evt *events.StockReservationFailed) error {
+	return f(ctx, evt)
+}
+
+// StockReservationFailedConsumer reads inventory reservation failures
+type StockReservationFailedConsumer struct {
+	c   *kafkaclient.Consumer
+	h   StockReservationFailedHandler
+	log logger.Logger
+}
+
+func NewStockReservationFailedConsumer(bus kafkaclient.Bus, groupID, autoOffsetReset string, handler StockReservationFailedHandler) (*StockReservationFailedConsumer, error) {
+	config := kafkaclient.ConsumerConfig{
+		GroupID:         groupID,
+		AutoOffsetReset: autoOffsetReset,
+	}
+
+	c, err := bus.NewConsumer(config)
+	if err != nil {
+		return nil, err
+	}
+	return &StockReservationFailedConsumer{c: c, h: handler}, nil
+}
+
+func (c *StockReservationFailedConsumer) WithLogger(l logger.Logger) *StockReservationFailedConsumer {
+	c.log = l
+	c.c.WithLogger(l)
+	return c
+}
+
+func (c *StockReservationFailedConsumer) Close() error { return c.c.Close() }
+
+// WithDeadLetter routes events that keep failing to "<topic>.dlq"
+func (c *StockReservationFailedConsumer) WithDeadLetter(p kafkaclient.DeadLetterPublisher) *StockReservationFailedConsumer {
+	c.c.WithDeadLetter(p)
+	return c
+}
+
+// WithIdempotency skips redelivered StockReservationFailed events for reservation failures of orders already handled
+func (c *StockReservationFailedConsumer) WithIdempotency(store kafkaclient.ProcessedStore) *StockReservationFailedConsumer {
+	c.c.WithIdempotency(store, eventschema.KeyFunc, 0)
+	return c
+}
+
+func (c *StockReservationFailedConsumer) Run(ctx context.Context, topics []string) error {
+	return c.c.RunEventLoop(ctx, topics, eventschema.Contracts(), func(hctx context.Context, msg proto.Message) error {
+		evt, err := eventschema.As[*events.StockReservationFailed](msg)
+		if err != nil {
+			return err
+		}
+		return c.h.Handle(hctx, evt)
+	})
+}
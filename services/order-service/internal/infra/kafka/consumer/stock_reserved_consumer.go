@@ -0,0 +1,72 @@
+package consumer
+
+import (
+	"context"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/eventschema"
+	kafkaclient "github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
+	"github.com/kubernetestest/ecommerce-platform/pkg/logger"
+	events "github.com/kubernetestest/ecommerce-platform/proto-go/events"
+
+	"google.golang.org/protobuf/proto"
+)
+
+type StockReservedHandler interface {
+	Handle(ctx context.Context, evt *events.StockReserved) error
+}
+
+type StockReservedHandlerFunc func(ctx context.Context, evt *events.StockReserved) error
+
+func (f StockReservedHandlerFunc) Handle(ctx context.Context, evt *events.StockReserved) error {
+	return f(ctx, evt)
+}
+
+// StockReservedConsumer reads successful inventory reservations
+type StockReservedConsumer struct {
+	c   *kafkaclient.Consumer
+	h   StockReservedHandler
+	log logger.Logger
+}
+
+func NewStockReservedConsumer(bus kafkaclient.Bus, groupID, autoOffsetReset string, handler StockReservedHandler) (*StockReservedConsumer, error) {
+	config := kafkaclient.ConsumerConfig{
+		GroupID:         groupID,
+		AutoOffsetReset: autoOffsetReset,
+	}
+
+	c, err := bus.NewConsumer(config)
+	if err != nil {
+		return nil, err
+	}
+	return &StockReservedConsumer{c: c, h: handler}, nil
+}
+
+func (c *StockReservedConsumer) WithLogger(l logger.Logger) *StockReservedConsumer {
+	c.log = l
+	c.c.WithLogger(l)
+	return c
+}
+
+func (c *StockReservedConsumer) Close() error { return c.c.Close() }
+
+// WithDeadLetter routes events that keep failing to "<topic>.dlq"
+func (c *StockReservedConsumer) WithDeadLetter(p kafkaclient.DeadLetterPublisher) *StockReservedConsumer {
+	c.c.WithDeadLetter(p)
+	return c
+}
+
+// WithIdempotency skips redelivered StockReserved events for stock reservations of orders already handled
+func (c *StockReservedConsumer) WithIdempotency(store kafkaclient.ProcessedStore) *StockReservedConsumer {
+	c.c.WithIdempotency(store, eventschema.KeyFunc, 0)
+	return c
+}
+
+func (c *StockReservedConsumer) Run(ctx context.Context, topics []string) error {
+	return c.c.RunEventLoop(ctx, topics, eventschema.Contracts(), func(hctx context.Context, msg proto.Message) error {
+		evt, err := eventschema.As[*events.StockReserved](msg)
+		if err != nil {
+			return err
+		}
+		return c.h.Handle(hctx, evt)
+	})
+}
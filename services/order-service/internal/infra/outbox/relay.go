@@ -0,0 +1,196 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	kafkaclient "github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
+	"github.com/kubernetestest/ecommerce-platform/pkg/logger"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/domain/models"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/metrics"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/clock"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/outbox"
+)
+
+// RelayConfig holds relay polling and retry configuration
+type RelayConfig struct {
+	PollInterval   time.Duration
+	BatchSize      int
+	Lease          time.Duration
+	BaseBackoff    time.Duration
+	MaxBackoff     time.Duration
+	PublishTimeout time.Duration
+	// Producer is recorded in the envelope of relayed events
+	Producer string
+}
+
+// Relay moves pending outbox messages to Kafka until they are acknowledged
+type Relay struct {
+	store   outbox.Store
+	pub     kafkaclient.Publisher
+	clock   clock.Clock
+	metrics metrics.OrderMetrics
+	log     logger.Logger
+	cfg     RelayConfig
+}
+
+// NewRelay creates a relay with defaults applied to zero config values
+func NewRelay(store outbox.Store, pub kafkaclient.Publisher, cfg RelayConfig) *Relay {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = 30 * time.Second
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 5 * time.Minute
+	}
+	if cfg.PublishTimeout <= 0 {
+		cfg.PublishTimeout = 5 * time.Second
+	}
+	if cfg.Producer == "" {
+		cfg.Producer = "order-service"
+	}
+	return &Relay{store: store, pub: pub, cfg: cfg}
+}
+
+// WithLogger sets logger for relay
+func (r *Relay) WithLogger(l logger.Logger) *Relay {
+	r.log = l
+	return r
+}
+
+// WithMetrics sets metrics sink for relay
+func (r *Relay) WithMetrics(m metrics.OrderMetrics) *Relay {
+	r.metrics = m
+	return r
+}
+
+// WithClock sets time source for relay
+func (r *Relay) WithClock(c clock.Clock) *Relay {
+	r.clock = c
+	return r
+}
+
+// Run polls the outbox until ctx is cancelled
+func (r *Relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := r.RelayOnce(ctx)
+			if err != nil && r.log != nil {
+				r.log.Error("outbox relay pass failed", "error", err)
+			}
+			// Keep draining while full batches come back
+			if err != nil || n < r.cfg.BatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+		r.reportLag(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce claims one batch of due messages and publishes them; returns number of claimed messages
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	msgs, err := r.store.ClaimPending(ctx, r.cfg.BatchSize, r.now(), r.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+	for _, m := range msgs {
+		r.relay(ctx, m)
+	}
+	return len(msgs), nil
+}
+
+func (r *Relay) relay(ctx context.Context, m *models.OutboxMessage) {
+	pctx, cancel := context.WithTimeout(ctx, r.cfg.PublishTimeout)
+	// Keyed by aggregate so consumers process events of one order in order.
+	// Outbox events start a flow: the outbox ID is both event and correlation ID.
+	env := kafkaclient.Envelope{
+		EventID:       m.ID,
+		EventType:     m.EventType,
+		SchemaVersion: m.SchemaVersion,
+		CorrelationID: m.ID,
+		Producer:      r.cfg.Producer,
+		OccurredAt:    m.CreatedAt,
+	}
+	if env.SchemaVersion == 0 {
+		env.SchemaVersion = kafkaclient.DefaultSchemaVersion // rows written before versioning
+	}
+	// Wait for the broker ack so a message is only marked sent once it landed
+	err := r.pub.PublishSync(pctx, kafkaclient.EventMessage(m.Topic, m.AggregateID, env, m.Payload))
+	cancel()
+
+	if err != nil {
+		if r.metrics != nil {
+			r.metrics.OutboxPublishFailed(m.Topic)
+		}
+		next := r.now().Add(r.backoff(m.Attempts))
+		if merr := r.store.MarkFailed(ctx, m.ID, err.Error(), next); merr != nil && r.log != nil {
+			r.log.Error("failed to record outbox failure", "id", m.ID, "error", merr)
+		}
+		if r.log != nil {
+			r.log.Warn("outbox publish failed", "id", m.ID, "topic", m.Topic, "aggregateID", m.AggregateID, "attempts", m.Attempts+1, "nextAttemptAt", next, "error", err)
+		}
+		return
+	}
+
+	if r.metrics != nil {
+		r.metrics.OutboxPublished(m.Topic)
+	}
+	// A failure here means the message is relayed again after the lease: consumers must tolerate duplicates
+	if err := r.store.MarkSent(ctx, m.ID, r.now()); err != nil && r.log != nil {
+		r.log.Error("failed to mark outbox message sent", "id", m.ID, "error", err)
+	}
+}
+
+// backoff doubles the base delay per previous attempt, capped at MaxBackoff
+func (r *Relay) backoff(attempts int32) time.Duration {
+	d := r.cfg.BaseBackoff
+	for i := int32(0); i < attempts; i++ {
+		d *= 2
+		if d >= r.cfg.MaxBackoff {
+			return r.cfg.MaxBackoff
+		}
+	}
+	return d
+}
+
+func (r *Relay) reportLag(ctx context.Context) {
+	if r.metrics == nil {
+		return
+	}
+	st, err := r.store.Stats(ctx)
+	if err != nil {
+		if r.log != nil {
+			r.log.Warn("failed to read outbox stats", "error", err)
+		}
+		return
+	}
+	var age time.Duration
+	if !st.OldestPendingAt.IsZero() {
+		age = r.now().Sub(st.OldestPendingAt)
+	}
+	r.metrics.OutboxLag(st.Pending, age)
+}
+
+func (r *Relay) now() time.Time {
+	if r.clock != nil {
+		return r.clock.Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,269 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	kafkaclient "github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/domain/models"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/outbox"
+)
+
+func TestRelayOnce(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	cfg := RelayConfig{BaseBackoff: time.Second, MaxBackoff: 10 * time.Second, Lease: time.Minute}
+
+	t.Run("publishes due messages and marks them sent", func(t *testing.T) {
+		store := newFakeStore()
+		first := store.add(models.NewOutboxMessage("ORD-1", "OrderCreated", 2, "order_created", []byte("one"), now.Add(-2*time.Second)))
+		second := store.add(models.NewOutboxMessage("ORD-2", "OrderCreated", 0, "order_created", []byte("two"), now.Add(-time.Second)))
+		later := store.add(models.NewOutboxMessage("ORD-3", "OrderCreated", 2, "order_created", []byte("later"), now.Add(time.Second)))
+		pub := kafkaclient.NewMemoryPublisher()
+
+		n, err := NewRelay(store, pub, cfg).WithClock(fixedClock{now}).RelayOnce(context.Background())
+		if err != nil {
+			t.Fatalf("RelayOnce: %v", err)
+		}
+		if n != 2 {
+			t.Fatalf("claimed %d messages, want 2", n)
+		}
+		sent := pub.Messages()
+		if len(sent) != 2 {
+			t.Fatalf("published %d messages, want 2", len(sent))
+		}
+		msg := sent[0]
+		if msg.Topic != "order_created" || string(msg.Key) != "ORD-1" || string(msg.Value) != "one" {
+			t.Errorf("published %s/%s %q, want order_created/ORD-1 \"one\"", msg.Topic, msg.Key, msg.Value)
+		}
+		if msg.Headers[kafkaclient.HeaderEventID] != first.ID || msg.Headers[kafkaclient.HeaderCorrelationID] != first.ID {
+			t.Errorf("event and correlation IDs = %q, %q, want the outbox ID %q", msg.Headers[kafkaclient.HeaderEventID], msg.Headers[kafkaclient.HeaderCorrelationID], first.ID)
+		}
+		if got := msg.Headers[kafkaclient.HeaderSchemaVersion]; got != "2" {
+			t.Errorf("schema version = %q, want 2", got)
+		}
+		// rows written before versioning are relayed as the default version
+		if got := sent[1].Headers[kafkaclient.HeaderSchemaVersion]; got != "1" {
+			t.Errorf("unversioned schema version = %q, want 1", got)
+		}
+		for _, m := range []*models.OutboxMessage{first, second} {
+			if got := store.get(m.ID); got.Status != models.OutboxStatusSent || got.SentAt == nil || !got.SentAt.Equal(now) {
+				t.Errorf("%s: status %s sent at %v, want SENT at %v", m.AggregateID, got.Status, got.SentAt, now)
+			}
+		}
+		if got := store.get(later.ID); got.Status != models.OutboxStatusPending {
+			t.Errorf("message not due yet was relayed")
+		}
+	})
+
+	t.Run("failed publish is retried with backoff", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			attempts int32
+			want     time.Duration
+		}{
+			{name: "first failure", attempts: 0, want: time.Second},
+			{name: "third failure", attempts: 2, want: 4 * time.Second},
+			{name: "capped", attempts: 10, want: 10 * time.Second},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				store := newFakeStore()
+				m := models.NewOutboxMessage("ORD-1", "OrderCreated", 2, "order_created", []byte("one"), now)
+				m.Attempts = tt.attempts
+				store.add(m)
+				pub := kafkaclient.NewMemoryPublisher()
+				pub.FailWith(errors.New("broker unavailable"))
+
+				if _, err := NewRelay(store, pub, cfg).WithClock(fixedClock{now}).RelayOnce(context.Background()); err != nil {
+					t.Fatalf("RelayOnce: %v", err)
+				}
+				got := store.get(m.ID)
+				if got.Status != models.OutboxStatusPending {
+					t.Fatalf("status = %s, want PENDING", got.Status)
+				}
+				if got.Attempts != tt.attempts+1 || got.LastError != "broker unavailable" {
+					t.Errorf("attempts %d error %q, want %d \"broker unavailable\"", got.Attempts, got.LastError, tt.attempts+1)
+				}
+				if want := now.Add(tt.want); !got.NextAttemptAt.Equal(want) {
+					t.Errorf("next attempt at %v, want %v", got.NextAttemptAt, want)
+				}
+			})
+		}
+	})
+
+	t.Run("claimed messages are leased", func(t *testing.T) {
+		store := newFakeStore()
+		store.add(models.NewOutboxMessage("ORD-1", "OrderCreated", 2, "order_created", []byte("one"), now))
+		// the publisher keeps failing without the store recording it, like a relay that crashed
+		pub := kafkaclient.NewMemoryPublisher()
+		pub.FailWith(errors.New("broker unavailable"))
+		store.failMarks = true
+		relay := NewRelay(store, pub, cfg).WithClock(fixedClock{now})
+
+		if n, _ := relay.RelayOnce(context.Background()); n != 1 {
+			t.Fatalf("first pass claimed %d messages, want 1", n)
+		}
+		if n, _ := relay.RelayOnce(context.Background()); n != 0 {
+			t.Errorf("second pass within the lease claimed %d messages, want 0", n)
+		}
+		if n, _ := relay.WithClock(fixedClock{now.Add(cfg.Lease)}).RelayOnce(context.Background()); n != 1 {
+			t.Errorf("pass after the lease claimed %d messages, want 1", n)
+		}
+	})
+
+	t.Run("messages of an aggregate keep their order across failures", func(t *testing.T) {
+		store := newFakeStore()
+		created := store.add(models.NewOutboxMessage("ORD-1", "OrderCreated", 2, "order_created", []byte("created"), now.Add(-2*time.Second)))
+		cancelled := store.add(models.NewOutboxMessage("ORD-1", "OrderCancelled", 1, "order_cancelled", []byte("cancelled"), now.Add(-time.Second)))
+		other := store.add(models.NewOutboxMessage("ORD-2", "OrderCreated", 2, "order_created", []byte("other"), now.Add(-time.Second)))
+		pub := kafkaclient.NewMemoryPublisher()
+		pub.FailWith(errors.New("broker unavailable"))
+
+		if n, _ := NewRelay(store, pub, cfg).WithClock(fixedClock{now}).RelayOnce(context.Background()); n != 2 {
+			t.Fatalf("failing pass claimed %d messages, want the first of each order", n)
+		}
+		pub.FailWith(nil)
+		// the cancellation is due but must not overtake the creation that is backing off
+		if n, _ := NewRelay(store, pub, cfg).WithClock(fixedClock{now}).RelayOnce(context.Background()); n != 0 {
+			t.Fatalf("pass during the backoff claimed %d messages, want 0", n)
+		}
+		later := NewRelay(store, pub, cfg).WithClock(fixedClock{now.Add(cfg.BaseBackoff)})
+		for pass := 0; pass < 3; pass++ {
+			if _, err := later.RelayOnce(context.Background()); err != nil {
+				t.Fatalf("RelayOnce: %v", err)
+			}
+		}
+
+		var got []string
+		for _, m := range pub.Messages() {
+			got = append(got, string(m.Value))
+		}
+		if want := []string{"created", "other", "cancelled"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("published %q, want %q", got, want)
+		}
+		for _, m := range []*models.OutboxMessage{created, cancelled, other} {
+			if got := store.get(m.ID); got.Status != models.OutboxStatusSent {
+				t.Errorf("%s %s: status %s, want SENT", m.AggregateID, m.EventType, got.Status)
+			}
+		}
+	})
+}
+
+type fixedClock struct{ t time.Time }
+
+func (c fixedClock) Now() time.Time { return c.t }
+
+// fakeStore is an in-memory outbox.Store with the claim and ordering semantics of the GORM store
+type fakeStore struct {
+	mu   sync.Mutex
+	msgs map[string]models.OutboxMessage
+	// failMarks makes MarkSent and MarkFailed fail
+	failMarks bool
+}
+
+var _ outbox.Store = (*fakeStore)(nil)
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{msgs: make(map[string]models.OutboxMessage)}
+}
+
+func (s *fakeStore) add(m *models.OutboxMessage) *models.OutboxMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.msgs[m.ID] = *m
+	return m
+}
+
+func (s *fakeStore) get(id string) models.OutboxMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.msgs[id]
+}
+
+func (s *fakeStore) ClaimPending(_ context.Context, limit int, now time.Time, lease time.Duration) ([]*models.OutboxMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []*models.OutboxMessage
+	for _, m := range s.msgs {
+		if m.Status == models.OutboxStatusPending && !m.NextAttemptAt.After(now) && !s.hasEarlierPending(m) {
+			m := m
+			due = append(due, &m)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return before(due[i], due[j]) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	for _, m := range due {
+		stored := s.msgs[m.ID]
+		stored.NextAttemptAt = now.Add(lease)
+		s.msgs[m.ID] = stored
+	}
+	return due, nil
+}
+
+func (s *fakeStore) hasEarlierPending(m models.OutboxMessage) bool {
+	for _, other := range s.msgs {
+		if other.AggregateID == m.AggregateID && other.Status == models.OutboxStatusPending && before(&other, &m) {
+			return true
+		}
+	}
+	return false
+}
+
+// before orders messages by creation, ties broken by ID as in the GORM store
+func before(a, b *models.OutboxMessage) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
+
+func (s *fakeStore) MarkSent(_ context.Context, id string, sentAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failMarks {
+		return errors.New("store unavailable")
+	}
+	m := s.msgs[id]
+	m.Status, m.SentAt = models.OutboxStatusSent, &sentAt
+	s.msgs[id] = m
+	return nil
+}
+
+func (s *fakeStore) MarkFailed(_ context.Context, id string, reason string, nextAttemptAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failMarks {
+		return errors.New("store unavailable")
+	}
+	m := s.msgs[id]
+	if m.Status != models.OutboxStatusPending {
+		return nil
+	}
+	m.Attempts++
+	m.LastError, m.NextAttemptAt = reason, nextAttemptAt
+	s.msgs[id] = m
+	return nil
+}
+
+func (s *fakeStore) Stats(context.Context) (outbox.Stats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var st outbox.Stats
+	for _, m := range s.msgs {
+		if m.Status != models.OutboxStatusPending {
+			continue
+		}
+		st.Pending++
+		if st.OldestPendingAt.IsZero() || m.CreatedAt.Before(st.OldestPendingAt) {
+			st.OldestPendingAt = m.CreatedAt
+		}
+	}
+	return st, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
 	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/productinfo"
 	invpb "github.com/kubernetestest/ecommerce-platform/proto-go/inventory"
 )
@@ -37,8 +38,9 @@ func (p *InventoryProvider) GetProduct(ctx context.Context, productID string) (*
 	}
 	pr := resp.GetProduct()
 	return &productinfo.ProductInfo{
-		Name:     pr.GetName(),
-		Price:    pr.GetPrice().GetAmount(),
-		Currency: pr.GetPrice().GetCurrency(),
+		Name:        pr.GetName(),
+		Price:       money.FromProto(pr.GetPrice()),
+		TaxCategory: pr.GetTaxCategory(),
+		WeightGrams: pr.GetWeightGrams(),
 	}, nil
 }
@@ -25,9 +25,30 @@ func (r *GormOrderRepository) Create(ctx context.Context, order *models.Order) e
 	return result.Error
 }
 
+// CreateWithOutbox saves the order, its saga and outbox messages in a single transaction
+func (r *GormOrderRepository) CreateWithOutbox(ctx context.Context, order *models.Order, saga *models.OrderSaga, msgs ...*models.OutboxMessage) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Session(&gorm.Session{FullSaveAssociations: true}).Create(order).Error; err != nil {
+			return err
+		}
+		if saga != nil {
+			if err := tx.Omit(clause.Associations).Create(saga).Error; err != nil {
+				return err
+			}
+			if err := appendSagaLog(tx, saga); err != nil {
+				return err
+			}
+		}
+		if len(msgs) == 0 {
+			return nil
+		}
+		return tx.Create(msgs).Error
+	})
+}
+
 func (r *GormOrderRepository) GetByID(ctx context.Context, id string) (*models.Order, error) {
 	var order models.Order
-	result := r.db.WithContext(ctx).Preload("Items").First(&order, "id = ?", id)
+	result := dbFrom(ctx, r.db).Preload("Items.TaxLines").Preload("ShortProducts").First(&order, "id = ?", id)
 
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, domainerrors.ErrOrderNotFound
@@ -50,7 +71,7 @@ func (r *GormOrderRepository) GetByUserID(ctx context.Context, userID string, pa
 	// Get orders with pagination
 	offset := (page - 1) * limit
 	result := r.db.WithContext(ctx).
-		Preload("Items").
+		Preload("Items.TaxLines").Preload("ShortProducts").
 		Where("user_id = ?", userID).
 		Order("created_at DESC").
 		Offset(offset).
@@ -62,10 +83,55 @@ func (r *GormOrderRepository) GetByUserID(ctx context.Context, userID string, pa
 
 func (r *GormOrderRepository) Update(ctx context.Context, order *models.Order) error {
 	// Save Order + all related OrderItems
-	result := r.db.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).Save(order)
+	result := dbFrom(ctx, r.db).Session(&gorm.Session{FullSaveAssociations: true}).Save(order)
 	return result.Error
 }
 
+// UpdateWithOutbox saves the order and outbox messages in a single transaction
+func (r *GormOrderRepository) UpdateWithOutbox(ctx context.Context, order *models.Order, msgs ...*models.OutboxMessage) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return saveOrder(tx, order, msgs)
+	})
+}
+
+// Modify locks the order row for the duration of fn, then saves the order together with the
+// outbox messages fn returns. Nothing is saved when fn returns an error.
+func (r *GormOrderRepository) Modify(ctx context.Context, id string, fn func(*models.Order) ([]*models.OutboxMessage, error)) (*models.Order, error) {
+	var order models.Order
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// lock with a plain select, preloads would otherwise lock the item rows as well
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Order{}, "id = ?", id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domainerrors.ErrOrderNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.Preload("Items.TaxLines").Preload("ShortProducts").First(&order, "id = ?", id).Error; err != nil {
+			return err
+		}
+		msgs, err := fn(&order)
+		if err != nil {
+			return err
+		}
+		return saveOrder(tx, &order, msgs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func saveOrder(tx *gorm.DB, order *models.Order, msgs []*models.OutboxMessage) error {
+	if err := tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(order).Error; err != nil {
+		return err
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return tx.Create(msgs).Error
+}
+
 func (r *GormOrderRepository) Delete(ctx context.Context, id string) error {
 	// GORM automatically deletes related OrderItems with OnDelete:CASCADE
 	result := r.db.WithContext(ctx).Delete(&models.Order{}, "id = ?", id)
@@ -81,7 +147,7 @@ func (r *GormOrderRepository) Delete(ctx context.Context, id string) error {
 func (r *GormOrderRepository) GetByStatus(ctx context.Context, status models.OrderStatus) ([]*models.Order, error) {
 	var orders []*models.Order
 	result := r.db.WithContext(ctx).
-		Preload("Items").
+		Preload("Items.TaxLines").Preload("ShortProducts").
 		Where("status = ?", status).
 		Order("created_at DESC").
 		Find(&orders)
@@ -91,7 +157,7 @@ func (r *GormOrderRepository) GetByStatus(ctx context.Context, status models.Ord
 
 // AutoMigrate creates tables
 func (r *GormOrderRepository) AutoMigrate() error {
-	return r.db.AutoMigrate(&models.Order{}, &models.OrderItem{})
+	return r.db.AutoMigrate(&models.Order{}, &models.OrderItem{}, &models.OrderItemTax{}, &models.OrderShortfall{}, &models.OutboxMessage{}, &models.OrderSaga{}, &models.SagaLogEntry{})
 }
 
 // NextOrderNumber returns next sequential number per user (transaction-safe)
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/domain/models"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/outbox"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormOutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewGormOutboxRepository(db *gorm.DB) *GormOutboxRepository {
+	return &GormOutboxRepository{db: db}
+}
+
+// ClaimPending selects due messages with SKIP LOCKED and leases them in the same transaction.
+// Only the oldest pending message of an aggregate is claimed: while it is leased or backing off,
+// later messages of the same aggregate wait, so they are published in the order they were written.
+func (r *GormOutboxRepository) ClaimPending(ctx context.Context, limit int, now time.Time, lease time.Duration) ([]*models.OutboxMessage, error) {
+	var msgs []*models.OutboxMessage
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.OutboxStatusPending, now).
+			Where(`NOT EXISTS (SELECT 1 FROM outbox_messages earlier
+				WHERE earlier.aggregate_id = outbox_messages.aggregate_id AND earlier.status = ?
+				AND (earlier.created_at < outbox_messages.created_at OR (earlier.created_at = outbox_messages.created_at AND earlier.id < outbox_messages.id)))`,
+				models.OutboxStatusPending).
+			Order("created_at ASC, id ASC").
+			Limit(limit).
+			Find(&msgs).Error; err != nil {
+			return err
+		}
+		if len(msgs) == 0 {
+			return nil
+		}
+		ids := make([]string, 0, len(msgs))
+		for _, m := range msgs {
+			ids = append(ids, m.ID)
+		}
+		return tx.Model(&models.OutboxMessage{}).
+			Where("id IN ?", ids).
+			UpdateColumn("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+func (r *GormOutboxRepository) MarkSent(ctx context.Context, id string, sentAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     models.OutboxStatusSent,
+			"sent_at":    sentAt,
+			"last_error": "",
+		}).Error
+}
+
+func (r *GormOutboxRepository) MarkFailed(ctx context.Context, id string, reason string, nextAttemptAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.OutboxMessage{}).
+		Where("id = ? AND status = ?", id, models.OutboxStatusPending).
+		Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"last_error":      reason,
+			"next_attempt_at": nextAttemptAt,
+		}).Error
+}
+
+func (r *GormOutboxRepository) Stats(ctx context.Context) (outbox.Stats, error) {
+	var row struct {
+		Pending int64
+		Oldest  *time.Time
+	}
+	err := r.db.WithContext(ctx).Model(&models.OutboxMessage{}).
+		Select("COUNT(*) AS pending, MIN(created_at) AS oldest").
+		Where("status = ?", models.OutboxStatusPending).
+		Scan(&row).Error
+	if err != nil {
+		return outbox.Stats{}, err
+	}
+	st := outbox.Stats{Pending: row.Pending}
+	if row.Oldest != nil {
+		st.OldestPendingAt = *row.Oldest
+	}
+	return st, nil
+}
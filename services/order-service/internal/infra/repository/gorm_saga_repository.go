@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domainerrors "github.com/kubernetestest/ecommerce-platform/services/order-service/internal/domain/errors"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/domain/models"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/saga"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var terminalSagaStates = []models.SagaState{models.SagaStateCompleted, models.SagaStateFailed}
+
+type GormSagaRepository struct {
+	db *gorm.DB
+}
+
+func NewGormSagaRepository(db *gorm.DB) *GormSagaRepository {
+	return &GormSagaRepository{db: db}
+}
+
+func (r *GormSagaRepository) Get(ctx context.Context, orderID string) (*models.OrderSaga, error) {
+	var s models.OrderSaga
+	err := r.db.WithContext(ctx).Preload("Log", sagaLogOrder).First(&s, "order_id = ?", orderID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domainerrors.ErrSagaNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Modify locks the saga row for the duration of fn so concurrent events are applied one at a time.
+// Repositories of this package given the context passed to fn write in the same transaction.
+func (r *GormSagaRepository) Modify(ctx context.Context, orderID string, fn func(context.Context, *models.OrderSaga) error) (*models.OrderSaga, error) {
+	var s models.OrderSaga
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&s, "order_id = ?", orderID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domainerrors.ErrSagaNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(withTx(ctx, tx), &s); err != nil {
+			return err
+		}
+		return saveSaga(tx, &s)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ClaimDue selects due sagas with SKIP LOCKED and leases them in the same transaction
+func (r *GormSagaRepository) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*models.OrderSaga, error) {
+	var sagas []*models.OrderSaga
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("state NOT IN ? AND step_deadline <= ?", terminalSagaStates, now).
+			Order("step_deadline ASC").
+			Limit(limit).
+			Find(&sagas).Error; err != nil {
+			return err
+		}
+		if len(sagas) == 0 {
+			return nil
+		}
+		ids := make([]string, 0, len(sagas))
+		for _, s := range sagas {
+			ids = append(ids, s.OrderID)
+		}
+		return tx.Model(&models.OrderSaga{}).
+			Where("order_id IN ?", ids).
+			UpdateColumn("step_deadline", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sagas, nil
+}
+
+func (r *GormSagaRepository) List(ctx context.Context, filter saga.ListFilter) ([]*models.OrderSaga, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+	q := r.db.WithContext(ctx).Model(&models.OrderSaga{}).Preload("Log", sagaLogOrder)
+	if len(filter.States) > 0 {
+		q = q.Where("state IN ?", filter.States)
+	}
+	if filter.StuckAt != nil {
+		q = q.Where("state NOT IN ? AND step_deadline <= ?", terminalSagaStates, *filter.StuckAt)
+	}
+	var sagas []*models.OrderSaga
+	if err := q.Order("updated_at ASC").Limit(filter.Limit).Find(&sagas).Error; err != nil {
+		return nil, err
+	}
+	return sagas, nil
+}
+
+// saveSaga updates saga columns and appends its new log entries
+func saveSaga(tx *gorm.DB, s *models.OrderSaga) error {
+	if err := tx.Omit(clause.Associations).Save(s).Error; err != nil {
+		return err
+	}
+	return appendSagaLog(tx, s)
+}
+
+// appendSagaLog inserts log entries recorded since the saga was loaded
+func appendSagaLog(tx *gorm.DB, s *models.OrderSaga) error {
+	if entries := s.NewEntries(); len(entries) > 0 {
+		if err := tx.Create(&entries).Error; err != nil {
+			return err
+		}
+	}
+	s.ClearNewEntries()
+	return nil
+}
+
+func sagaLogOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("created_at ASC")
+}
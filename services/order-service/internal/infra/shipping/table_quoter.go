@@ -0,0 +1,203 @@
+package shippingimpl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/shipping"
+)
+
+// Method is a shipping service offered to customers
+type Method struct {
+	Code    string `json:"code"` // e.g. STANDARD, EXPRESS
+	Name    string `json:"name"`
+	MinDays int    `json:"min_days"`
+	MaxDays int    `json:"max_days"`
+}
+
+// Bracket prices parcels up to a weight
+type Bracket struct {
+	UpToGrams int64 `json:"up_to_grams"`
+	Amount    int64 `json:"amount"` // minor units of the rate currency
+}
+
+// Rate prices a method for a set of destination countries by weight brackets
+type Rate struct {
+	Method string `json:"method"`
+	// Countries the rate applies to (ISO 3166-1 alpha-2); empty: every country without a rate
+	// of its own for the method
+	Countries []string  `json:"countries,omitempty"`
+	Currency  string    `json:"currency"`
+	Brackets  []Bracket `json:"brackets"`
+	// ExtraPerKg is added for every started kilogram above the last bracket; 0: parcels heavier
+	// than the last bracket cannot use the method
+	ExtraPerKg int64 `json:"extra_per_kg,omitempty"`
+}
+
+// Table is the set of methods and rates, the format of SHIPPING_RATES_FILE
+type Table struct {
+	Methods []Method `json:"methods"`
+	Rates   []Rate   `json:"rates"`
+}
+
+// DefaultTable returns example rates for local runs, shipping from the US; configure the real
+// ones with a rates file
+func DefaultTable() Table {
+	return Table{
+		Methods: []Method{
+			{Code: "STANDARD", Name: "Standard", MinDays: 3, MaxDays: 7},
+			{Code: "EXPRESS", Name: "Express", MinDays: 1, MaxDays: 2},
+		},
+		Rates: []Rate{
+			{Method: "STANDARD", Countries: []string{"US"}, Currency: "USD", ExtraPerKg: 150,
+				Brackets: []Bracket{{UpToGrams: 500, Amount: 499}, {UpToGrams: 2000, Amount: 799}, {UpToGrams: 5000, Amount: 1299}}},
+			{Method: "EXPRESS", Countries: []string{"US"}, Currency: "USD", ExtraPerKg: 300,
+				Brackets: []Bracket{{UpToGrams: 500, Amount: 1499}, {UpToGrams: 2000, Amount: 1999}, {UpToGrams: 5000, Amount: 2999}}},
+			{Method: "STANDARD", Countries: []string{"CA", "MX"}, Currency: "USD", ExtraPerKg: 400,
+				Brackets: []Bracket{{UpToGrams: 500, Amount: 1299}, {UpToGrams: 2000, Amount: 1999}, {UpToGrams: 5000, Amount: 3499}}},
+			{Method: "EXPRESS", Countries: []string{"CA", "MX"}, Currency: "USD",
+				Brackets: []Bracket{{UpToGrams: 500, Amount: 2999}, {UpToGrams: 2000, Amount: 4499}, {UpToGrams: 5000, Amount: 6999}}},
+			{Method: "STANDARD", Currency: "USD", ExtraPerKg: 800,
+				Brackets: []Bracket{{UpToGrams: 500, Amount: 1999}, {UpToGrams: 2000, Amount: 3499}, {UpToGrams: 5000, Amount: 5999}}},
+			{Method: "EXPRESS", Currency: "USD",
+				Brackets: []Bracket{{UpToGrams: 500, Amount: 4999}, {UpToGrams: 2000, Amount: 7999}}},
+		},
+	}
+}
+
+// LoadTable reads a JSON rates file in the Table format
+func LoadTable(path string) (Table, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Table{}, fmt.Errorf("shipping rates file: %w", err)
+	}
+	var t Table
+	if err := json.Unmarshal(data, &t); err != nil {
+		return Table{}, fmt.Errorf("shipping rates file %s: %w", path, err)
+	}
+	return t, nil
+}
+
+type rateKey struct {
+	method, country string // country "" for the fallback rate of the method
+}
+
+// TableQuoter quotes every method that has a rate for the destination country and can carry
+// the weight
+type TableQuoter struct {
+	methods []Method
+	rates   map[rateKey]Rate
+}
+
+func NewTableQuoter(t Table) (*TableQuoter, error) {
+	q := &TableQuoter{rates: make(map[rateKey]Rate)}
+	known := make(map[string]bool, len(t.Methods))
+	for _, m := range t.Methods {
+		m.Code = strings.ToUpper(strings.TrimSpace(m.Code))
+		if m.Code == "" || m.Name == "" {
+			return nil, fmt.Errorf("shipping method %q: code and name are required", m.Code)
+		}
+		if known[m.Code] {
+			return nil, fmt.Errorf("duplicate shipping method %s", m.Code)
+		}
+		if m.MinDays < 0 || m.MaxDays < m.MinDays {
+			return nil, fmt.Errorf("shipping method %s: invalid delivery days %d-%d", m.Code, m.MinDays, m.MaxDays)
+		}
+		known[m.Code] = true
+		q.methods = append(q.methods, m)
+	}
+	for _, r := range t.Rates {
+		r.Method = strings.ToUpper(strings.TrimSpace(r.Method))
+		if !known[r.Method] {
+			return nil, fmt.Errorf("shipping rate for unknown method %q", r.Method)
+		}
+		cur, ok := money.LookupCurrency(r.Currency)
+		if !ok {
+			return nil, fmt.Errorf("shipping rate %s: unknown currency %q", r.Method, r.Currency)
+		}
+		r.Currency = cur.Code
+		if err := validateBrackets(r); err != nil {
+			return nil, err
+		}
+		countries := r.Countries
+		if len(countries) == 0 {
+			countries = []string{""}
+		}
+		for _, c := range countries {
+			c = strings.ToUpper(strings.TrimSpace(c))
+			k := rateKey{r.Method, c}
+			if _, dup := q.rates[k]; dup {
+				return nil, fmt.Errorf("duplicate shipping rate %s for %q", r.Method, c)
+			}
+			q.rates[k] = r
+		}
+	}
+	return q, nil
+}
+
+func validateBrackets(r Rate) error {
+	if len(r.Brackets) == 0 {
+		return fmt.Errorf("shipping rate %s %v: no weight brackets", r.Method, r.Countries)
+	}
+	var prev int64
+	for _, b := range r.Brackets {
+		if b.UpToGrams <= prev || b.Amount < 0 {
+			return fmt.Errorf("shipping rate %s %v: brackets must have increasing weights and non-negative amounts", r.Method, r.Countries)
+		}
+		prev = b.UpToGrams
+	}
+	if r.ExtraPerKg < 0 {
+		return fmt.Errorf("shipping rate %s %v: negative extra per kg", r.Method, r.Countries)
+	}
+	return nil
+}
+
+// Quote returns the available methods in the order of the table
+func (q *TableQuoter) Quote(ctx context.Context, req shipping.Request) ([]shipping.Quote, error) {
+	if req.WeightGrams < 0 {
+		return nil, fmt.Errorf("shipping: negative weight %d", req.WeightGrams)
+	}
+	country := strings.ToUpper(strings.TrimSpace(req.Destination.Country))
+	var out []shipping.Quote
+	for _, m := range q.methods {
+		r, ok := q.rates[rateKey{m.Code, country}]
+		if !ok {
+			r, ok = q.rates[rateKey{m.Code, ""}]
+		}
+		if !ok {
+			continue
+		}
+		amount, ok := r.price(req.WeightGrams)
+		if !ok {
+			continue
+		}
+		out = append(out, shipping.Quote{
+			Method:  m.Code,
+			Name:    m.Name,
+			Cost:    money.Money{Amount: amount, Currency: r.Currency},
+			MinDays: m.MinDays,
+			MaxDays: m.MaxDays,
+		})
+	}
+	return out, nil
+}
+
+// price returns the amount of the first bracket the weight fits in, or of the last one plus the
+// extra kilograms
+func (r Rate) price(grams int64) (int64, bool) {
+	for _, b := range r.Brackets {
+		if grams <= b.UpToGrams {
+			return b.Amount, true
+		}
+	}
+	if r.ExtraPerKg == 0 {
+		return 0, false
+	}
+	last := r.Brackets[len(r.Brackets)-1]
+	extraKg := (grams - last.UpToGrams + 999) / 1000
+	return last.Amount + extraKg*r.ExtraPerKg, true
+}
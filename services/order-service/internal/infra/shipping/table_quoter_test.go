@@ -0,0 +1,40 @@
+package shippingimpl
+
+import "testing"
+
+func TestRatePrice(t *testing.T) {
+	rate := Rate{
+		Method:     "STANDARD",
+		Currency:   "USD",
+		Brackets:   []Bracket{{UpToGrams: 500, Amount: 499}, {UpToGrams: 2000, Amount: 799}},
+		ExtraPerKg: 150,
+	}
+
+	tests := []struct {
+		name   string
+		rate   Rate
+		grams  int64
+		want   int64
+		wantOK bool
+	}{
+		{name: "empty parcel", rate: rate, grams: 0, want: 499, wantOK: true},
+		{name: "first bracket limit", rate: rate, grams: 500, want: 499, wantOK: true},
+		{name: "next bracket", rate: rate, grams: 501, want: 799, wantOK: true},
+		{name: "last bracket limit", rate: rate, grams: 2000, want: 799, wantOK: true},
+		{name: "one started kg above", rate: rate, grams: 2001, want: 949, wantOK: true},
+		{name: "whole kg above", rate: rate, grams: 3000, want: 949, wantOK: true},
+		{name: "two started kg above", rate: rate, grams: 3001, want: 1099, wantOK: true},
+		{name: "too heavy without extra", rate: Rate{Brackets: rate.Brackets}, grams: 2001, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.rate.price(tt.grams)
+			if ok != tt.wantOK {
+				t.Fatalf("price(%d) ok = %v, want %v", tt.grams, ok, tt.wantOK)
+			}
+			if ok && got != tt.want {
+				t.Errorf("price(%d) = %d, want %d", tt.grams, got, tt.want)
+			}
+		})
+	}
+}
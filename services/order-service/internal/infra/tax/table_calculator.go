@@ -0,0 +1,222 @@
+package taximpl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/ports/tax"
+)
+
+// StandardCategory is the tax category of products without a more specific one
+const StandardCategory = "standard"
+
+// ratePPM is the scale rates are applied with: parts per million, so 8.875% is exact
+const ratePPM = 1_000_000
+
+// Rule is the tax rate of a country, or of one of its regions, for a product tax category.
+// Regional rules apply on top of the country rule (e.g. Canadian GST and provincial PST).
+type Rule struct {
+	Country string `json:"country"`          // ISO 3166-1 alpha-2
+	Region  string `json:"region,omitempty"` // ISO 3166-2 subdivision without the country prefix; empty: whole country
+	// Category of the products the rule applies to; empty or standard: products without a
+	// more specific rule
+	Category string  `json:"category,omitempty"`
+	Name     string  `json:"name"`
+	Rate     float64 `json:"rate"` // 0.19 for 19%, 0 for exempt products
+	// Inclusive rules are contained in the catalog price (VAT), exclusive ones are added to it
+	// (US sales tax). All rules of a country must agree.
+	Inclusive bool `json:"inclusive,omitempty"`
+}
+
+// Table is the set of tax rules, the format of TAX_RATES_FILE
+type Table struct {
+	Rules []Rule `json:"rules"`
+}
+
+// DefaultTable returns example rates for local runs; configure the real ones with a rates file
+func DefaultTable() Table {
+	return Table{Rules: []Rule{
+		{Country: "US", Region: "CA", Name: "Sales tax", Rate: 0.0725},
+		{Country: "US", Region: "CA", Category: "food", Name: "Sales tax", Rate: 0},
+		{Country: "US", Region: "NY", Name: "Sales tax", Rate: 0.04},
+		{Country: "US", Region: "NY", Category: "food", Name: "Sales tax", Rate: 0},
+		{Country: "US", Region: "TX", Name: "Sales tax", Rate: 0.0625},
+		{Country: "US", Region: "TX", Category: "food", Name: "Sales tax", Rate: 0},
+		{Country: "US", Region: "WA", Name: "Sales tax", Rate: 0.065},
+		{Country: "CA", Name: "GST", Rate: 0.05},
+		{Country: "CA", Category: "food", Name: "GST", Rate: 0},
+		{Country: "CA", Region: "BC", Name: "PST", Rate: 0.07},
+		{Country: "CA", Region: "BC", Category: "food", Name: "PST", Rate: 0},
+		{Country: "CA", Region: "QC", Name: "QST", Rate: 0.09975},
+		{Country: "CA", Region: "QC", Category: "food", Name: "QST", Rate: 0},
+		{Country: "DE", Name: "VAT", Rate: 0.19, Inclusive: true},
+		{Country: "DE", Category: "food", Name: "VAT", Rate: 0.07, Inclusive: true},
+		{Country: "DE", Category: "books", Name: "VAT", Rate: 0.07, Inclusive: true},
+		{Country: "FR", Name: "VAT", Rate: 0.20, Inclusive: true},
+		{Country: "FR", Category: "food", Name: "VAT", Rate: 0.055, Inclusive: true},
+		{Country: "FR", Category: "books", Name: "VAT", Rate: 0.055, Inclusive: true},
+		{Country: "NL", Name: "VAT", Rate: 0.21, Inclusive: true},
+		{Country: "NL", Category: "food", Name: "VAT", Rate: 0.09, Inclusive: true},
+		{Country: "NL", Category: "books", Name: "VAT", Rate: 0.09, Inclusive: true},
+		{Country: "GB", Name: "VAT", Rate: 0.20, Inclusive: true},
+		{Country: "GB", Category: "food", Name: "VAT", Rate: 0, Inclusive: true},
+		{Country: "GB", Category: "books", Name: "VAT", Rate: 0, Inclusive: true},
+		{Country: "JP", Name: "Consumption tax", Rate: 0.10, Inclusive: true},
+		{Country: "JP", Category: "food", Name: "Consumption tax", Rate: 0.08, Inclusive: true},
+		{Country: "AU", Name: "GST", Rate: 0.10, Inclusive: true},
+		{Country: "AU", Category: "food", Name: "GST", Rate: 0, Inclusive: true},
+	}}
+}
+
+// LoadTable reads a JSON rates file: {"rules": [{"country": "DE", "name": "VAT", "rate": 0.19, "inclusive": true}]}
+func LoadTable(path string) (Table, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Table{}, fmt.Errorf("tax rates file: %w", err)
+	}
+	var t Table
+	if err := json.Unmarshal(data, &t); err != nil {
+		return Table{}, fmt.Errorf("tax rates file %s: %w", path, err)
+	}
+	return t, nil
+}
+
+type ruleKey struct {
+	country, region, category string
+}
+
+// TableCalculator looks up the rates of the shipping country and region by product tax category.
+// Countries without rules are not taxed.
+type TableCalculator struct {
+	rules     map[ruleKey]Rule
+	inclusive map[string]bool // per country
+}
+
+func NewTableCalculator(t Table) (*TableCalculator, error) {
+	c := &TableCalculator{rules: make(map[ruleKey]Rule), inclusive: make(map[string]bool)}
+	for _, r := range t.Rules {
+		r.Country = strings.ToUpper(strings.TrimSpace(r.Country))
+		r.Region = normalizeRegion(r.Country, r.Region)
+		r.Category = normalizeCategory(r.Category)
+		if len(r.Country) != 2 {
+			return nil, fmt.Errorf("tax rule %q: invalid country %q", r.Name, r.Country)
+		}
+		if r.Name == "" {
+			return nil, fmt.Errorf("tax rule for %s: name is required", r.Country)
+		}
+		if !(r.Rate >= 0 && r.Rate <= 1) {
+			return nil, fmt.Errorf("tax rule %s %s: rate %v out of range 0..1", r.Country, r.Name, r.Rate)
+		}
+		if inclusive, ok := c.inclusive[r.Country]; ok && inclusive != r.Inclusive {
+			return nil, fmt.Errorf("tax rules for %s mix inclusive and exclusive rates", r.Country)
+		}
+		k := ruleKey{r.Country, r.Region, r.Category}
+		if _, dup := c.rules[k]; dup {
+			return nil, fmt.Errorf("duplicate tax rule for %s/%s/%s", r.Country, r.Region, r.Category)
+		}
+		c.rules[k] = r
+		c.inclusive[r.Country] = r.Inclusive
+	}
+	return c, nil
+}
+
+func (c *TableCalculator) Calculate(ctx context.Context, req tax.Request) ([]tax.Line, error) {
+	country := strings.ToUpper(strings.TrimSpace(req.Address.Country))
+	if _, ok := c.inclusive[country]; !ok {
+		return nil, nil
+	}
+	region := normalizeRegion(country, req.Address.Region)
+
+	var lines []tax.Line
+	for _, item := range req.Items {
+		rules := c.rulesFor(country, region, normalizeCategory(item.Category))
+		if len(rules) == 0 {
+			continue
+		}
+		amounts, err := c.amounts(item.Amount, rules, c.inclusive[country])
+		if err != nil {
+			return nil, fmt.Errorf("tax on item %s: %w", item.ID, err)
+		}
+		for i, r := range rules {
+			jurisdiction := r.Country
+			if r.Region != "" {
+				jurisdiction += "-" + r.Region
+			}
+			lines = append(lines, tax.Line{
+				ItemID:       item.ID,
+				Name:         r.Name,
+				Jurisdiction: jurisdiction,
+				Rate:         r.Rate,
+				Amount:       amounts[i],
+				Inclusive:    r.Inclusive,
+			})
+		}
+	}
+	return lines, nil
+}
+
+// rulesFor returns the country rule and then the region rule for a category, each falling back
+// to the standard rule; exempt (zero) rates are left out
+func (c *TableCalculator) rulesFor(country, region, category string) []Rule {
+	var out []Rule
+	regions := []string{""}
+	if region != "" {
+		regions = append(regions, region)
+	}
+	for _, reg := range regions {
+		r, ok := c.rules[ruleKey{country, reg, category}]
+		if !ok {
+			r, ok = c.rules[ruleKey{country, reg, ""}]
+		}
+		if ok && r.Rate > 0 {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// amounts computes the tax of every rule on amount. Exclusive taxes are each rounded on the
+// amount; inclusive ones are extracted together from it and allocated by rate, so they add up
+// to exactly the tax contained in the price.
+func (c *TableCalculator) amounts(amount money.Money, rules []Rule, inclusive bool) ([]money.Money, error) {
+	ppm := make([]int64, len(rules))
+	var total int64
+	for i, r := range rules {
+		ppm[i] = int64(math.Round(r.Rate * ratePPM))
+		total += ppm[i]
+	}
+	if !inclusive {
+		out := make([]money.Money, len(rules))
+		for i := range rules {
+			t, err := amount.Ratio(ppm[i], ratePPM)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = t
+		}
+		return out, nil
+	}
+	contained, err := amount.Ratio(total, ratePPM+total)
+	if err != nil {
+		return nil, err
+	}
+	return contained.Allocate(ppm...)
+}
+
+func normalizeRegion(country, region string) string {
+	region = strings.ToUpper(strings.TrimSpace(region))
+	return strings.TrimPrefix(region, country+"-")
+}
+
+func normalizeCategory(category string) string {
+	category = strings.ToLower(strings.TrimSpace(category))
+	if category == StandardCategory {
+		return ""
+	}
+	return category
+}
@@ -0,0 +1,49 @@
+package taximpl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
+)
+
+func TestTableCalculatorAmounts(t *testing.T) {
+	vat := Rule{Country: "DE", Name: "VAT", Rate: 0.19, Inclusive: true}
+	reduced := Rule{Country: "DE", Name: "VAT", Rate: 0.07, Inclusive: true}
+	gst := Rule{Country: "CA", Name: "GST", Rate: 0.05}
+	qst := Rule{Country: "CA", Region: "QC", Name: "QST", Rate: 0.09975}
+
+	tests := []struct {
+		name      string
+		amount    int64
+		rules     []Rule
+		inclusive bool
+		want      []int64
+	}{
+		{name: "inclusive extracts the contained tax", amount: 11900, rules: []Rule{vat}, inclusive: true, want: []int64{1900}},
+		{name: "inclusive rounds half away from zero", amount: 1000, rules: []Rule{vat}, inclusive: true, want: []int64{160}},
+		{name: "inclusive reduced rate", amount: 999, rules: []Rule{reduced}, inclusive: true, want: []int64{65}},
+		{name: "inclusive too small to carry tax", amount: 1, rules: []Rule{vat}, inclusive: true, want: []int64{0}},
+		{name: "inclusive refund", amount: -11900, rules: []Rule{vat}, inclusive: true, want: []int64{-1900}},
+		// 1498 contained in total, split 5 : 9.975 with the remainder going to the first rule
+		{name: "inclusive rules share the contained tax", amount: 11498, rules: []Rule{gst, qst}, inclusive: true, want: []int64{501, 997}},
+		{name: "exclusive adds each rate", amount: 10000, rules: []Rule{gst, qst}, want: []int64{500, 998}},
+		{name: "exclusive rounds each rule", amount: 999, rules: []Rule{gst, qst}, want: []int64{50, 100}},
+	}
+	c := &TableCalculator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amounts, err := c.amounts(money.Money{Amount: tt.amount, Currency: "EUR"}, tt.rules, tt.inclusive)
+			if err != nil {
+				t.Fatalf("amounts: %v", err)
+			}
+			got := make([]int64, len(amounts))
+			for i, a := range amounts {
+				got[i] = a.Amount
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("amounts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
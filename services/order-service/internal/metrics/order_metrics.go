@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// OrderMetrics interface defines order service specific metrics
+type OrderMetrics interface {
+	// Outbox relay metrics
+	OutboxPublished(topic string)
+	OutboxPublishFailed(topic string)
+	OutboxLag(pending int64, oldestAge time.Duration)
+
+	// Saga metrics
+	SagaTransition(state string)
+	SagaCompensationFailed()
+
+	// HTTP metrics (reused from pkg/metrics)
+	metrics.Metrics
+}
+
+// OrderPrometheusMetrics implements OrderMetrics interface
+type OrderPrometheusMetrics struct {
+	*metrics.PrometheusMetrics
+
+	outboxPublishedTotal *prometheus.CounterVec
+	outboxFailedTotal    *prometheus.CounterVec
+	outboxPending        *prometheus.GaugeVec
+	outboxOldestAge      *prometheus.GaugeVec
+
+	sagaTransitionsTotal        *prometheus.CounterVec
+	sagaCompensationFailedTotal *prometheus.CounterVec
+}
+
+// NewOrderMetrics creates new order service metrics instance
+func NewOrderMetrics() OrderMetrics {
+	return &OrderPrometheusMetrics{
+		PrometheusMetrics: metrics.NewPrometheusMetrics("order-service"),
+
+		outboxPublishedTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "outbox_published_total",
+				Help: "Total number of outbox messages relayed to Kafka",
+			},
+			[]string{"service", "topic"},
+		),
+		outboxFailedTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "outbox_publish_failed_total",
+				Help: "Total number of failed outbox relay attempts",
+			},
+			[]string{"service", "topic"},
+		),
+		outboxPending: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "outbox_pending_messages",
+				Help: "Number of outbox messages waiting to be relayed",
+			},
+			[]string{"service"},
+		),
+		outboxOldestAge: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "outbox_oldest_pending_age_seconds",
+				Help: "Age of the oldest outbox message waiting to be relayed",
+			},
+			[]string{"service"},
+		),
+
+		sagaTransitionsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "order_saga_transitions_total",
+				Help: "Total number of order saga transitions by target state",
+			},
+			[]string{"service", "state"},
+		),
+		sagaCompensationFailedTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "order_saga_compensation_failed_total",
+				Help: "Total number of failed saga compensation attempts",
+			},
+			[]string{"service"},
+		),
+	}
+}
+
+// OutboxPublished increments relayed message counter
+func (m *OrderPrometheusMetrics) OutboxPublished(topic string) {
+	m.outboxPublishedTotal.WithLabelValues("order-service", topic).Inc()
+}
+
+// OutboxPublishFailed increments failed relay attempt counter
+func (m *OrderPrometheusMetrics) OutboxPublishFailed(topic string) {
+	m.outboxFailedTotal.WithLabelValues("order-service", topic).Inc()
+}
+
+// OutboxLag records current outbox backlog size and age
+func (m *OrderPrometheusMetrics) OutboxLag(pending int64, oldestAge time.Duration) {
+	m.outboxPending.WithLabelValues("order-service").Set(float64(pending))
+	m.outboxOldestAge.WithLabelValues("order-service").Set(oldestAge.Seconds())
+}
+
+// SagaTransition increments saga transition counter for the target state
+func (m *OrderPrometheusMetrics) SagaTransition(state string) {
+	m.sagaTransitionsTotal.WithLabelValues("order-service", state).Inc()
+}
+
+// SagaCompensationFailed increments failed compensation counter
+func (m *OrderPrometheusMetrics) SagaCompensationFailed() {
+	m.sagaCompensationFailedTotal.WithLabelValues("order-service").Inc()
+}
@@ -8,9 +8,17 @@ import (
 type (
 	Order                     = realpb.Order
 	OrderItem                 = realpb.OrderItem
+	ProductShortfall          = realpb.ProductShortfall
 	Money                     = realpb.Money
+	ExchangeRate              = realpb.ExchangeRate
+	TaxLine                   = realpb.TaxLine
+	Address                   = realpb.Address
+	ShippingQuote             = realpb.ShippingQuote
+	QuoteShippingRequest      = realpb.QuoteShippingRequest
+	QuoteShippingResponse     = realpb.QuoteShippingResponse
 	OrderStatus               = realpb.OrderStatus
 	CreateOrderRequest        = realpb.CreateOrderRequest
+	OrderItemRequest          = realpb.OrderItemRequest
 	CreateOrderResponse       = realpb.CreateOrderResponse
 	GetOrderRequest           = realpb.GetOrderRequest
 	GetOrderResponse          = realpb.GetOrderResponse
@@ -20,20 +28,36 @@ type (
 	UpdateOrderStatusResponse = realpb.UpdateOrderStatusResponse
 	CancelOrderRequest        = realpb.CancelOrderRequest
 	CancelOrderResponse       = realpb.CancelOrderResponse
+	PaymentSelection          = realpb.PaymentSelection
+	GetOrderPaymentRequest    = realpb.GetOrderPaymentRequest
+	GetOrderPaymentResponse   = realpb.GetOrderPaymentResponse
+	Saga                      = realpb.Saga
+	SagaLogEntry              = realpb.SagaLogEntry
+	GetSagaRequest            = realpb.GetSagaRequest
+	GetSagaResponse           = realpb.GetSagaResponse
+	ListSagasRequest          = realpb.ListSagasRequest
+	ListSagasResponse         = realpb.ListSagasResponse
 )
 
 var (
-	RegisterOrderServiceServer = realpb.RegisterOrderServiceServer
-
-	OrderStatus_PENDING    = realpb.OrderStatus_PENDING
-	OrderStatus_CONFIRMED  = realpb.OrderStatus_CONFIRMED
-	OrderStatus_PROCESSING = realpb.OrderStatus_PROCESSING
-	OrderStatus_SHIPPED    = realpb.OrderStatus_SHIPPED
-	OrderStatus_DELIVERED  = realpb.OrderStatus_DELIVERED
-	OrderStatus_CANCELLED  = realpb.OrderStatus_CANCELLED
+	RegisterOrderServiceServer          = realpb.RegisterOrderServiceServer
+	RegisterOrderSagaAdminServiceServer = realpb.RegisterOrderSagaAdminServiceServer
+
+	OrderStatus_PENDING        = realpb.OrderStatus_PENDING
+	OrderStatus_CONFIRMED      = realpb.OrderStatus_CONFIRMED
+	OrderStatus_PROCESSING     = realpb.OrderStatus_PROCESSING
+	OrderStatus_SHIPPED        = realpb.OrderStatus_SHIPPED
+	OrderStatus_DELIVERED      = realpb.OrderStatus_DELIVERED
+	OrderStatus_CANCELLED      = realpb.OrderStatus_CANCELLED
+	OrderStatus_PAYMENT_REVIEW = realpb.OrderStatus_PAYMENT_REVIEW
 )
 
 type OrderServiceServer = realpb.OrderServiceServer
 
 // Re-export the unimplemented server type for embedding
 type UnimplementedOrderServiceServer = realpb.UnimplementedOrderServiceServer
+
+type OrderSagaAdminServiceServer = realpb.OrderSagaAdminServiceServer
+
+// Re-export the unimplemented saga admin server type for embedding
+type UnimplementedOrderSagaAdminServiceServer = realpb.UnimplementedOrderSagaAdminServiceServer
@@ -0,0 +1,18 @@
+package compensation
+
+import (
+	"context"
+
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/domain/models"
+)
+
+// StockReleaser returns stock reserved for an order
+type StockReleaser interface {
+	ReleaseStock(ctx context.Context, orderID string, items []models.OrderItem) error
+}
+
+// PaymentRefunder gives the money of a payment back: voids an uncaptured authorization
+// or refunds the remaining captured amount
+type PaymentRefunder interface {
+	RefundPayment(ctx context.Context, paymentID, reason string) error
+}
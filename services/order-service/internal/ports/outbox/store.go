@@ -0,0 +1,25 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/domain/models"
+)
+
+// Stats describes the backlog of messages not yet relayed
+type Stats struct {
+	Pending         int64
+	OldestPendingAt time.Time // zero when nothing is pending
+}
+
+// Store provides access to outbox messages for the relay
+type Store interface {
+	// ClaimPending returns up to limit due messages and pushes their next attempt
+	// forward by lease, so concurrent relays do not pick them up again. A message is
+	// only claimed once every earlier message of its aggregate was sent.
+	ClaimPending(ctx context.Context, limit int, now time.Time, lease time.Duration) ([]*models.OutboxMessage, error)
+	MarkSent(ctx context.Context, id string, sentAt time.Time) error
+	MarkFailed(ctx context.Context, id string, reason string, nextAttemptAt time.Time) error
+	Stats(ctx context.Context) (Stats, error)
+}
@@ -1,12 +1,19 @@
 package productinfo
 
-import "context"
+import (
+	"context"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
+)
 
 // ProductInfo is a simple DTO used by order-service.
 type ProductInfo struct {
-	Name     string
-	Price    int64  // minor units
-	Currency string // ISO code
+	Name  string
+	Price money.Money // catalog price
+	// TaxCategory of the product, empty for the standard rate
+	TaxCategory string
+	// WeightGrams is the shipping weight of one unit, 0 if unknown
+	WeightGrams int32
 }
 
 // Provider abstracts product information lookup (e.g., via inventory-service).
@@ -7,9 +7,16 @@ import (
 
 type OrderRepository interface {
 	Create(ctx context.Context, order *models.Order) error
+	// CreateWithOutbox persists the order, its saga and its outbox messages atomically
+	CreateWithOutbox(ctx context.Context, order *models.Order, saga *models.OrderSaga, msgs ...*models.OutboxMessage) error
 	GetByID(ctx context.Context, id string) (*models.Order, error)
 	GetByUserID(ctx context.Context, userID string, page, limit int) ([]*models.Order, int64, error)
 	Update(ctx context.Context, order *models.Order) error
+	// UpdateWithOutbox persists the order and its outbox messages atomically
+	UpdateWithOutbox(ctx context.Context, order *models.Order, msgs ...*models.OutboxMessage) error
+	// Modify loads the order under a row lock, applies fn and saves the order with the outbox
+	// messages fn returns. Nothing is saved when fn returns an error.
+	Modify(ctx context.Context, id string, fn func(*models.Order) ([]*models.OutboxMessage, error)) (*models.Order, error)
 	Delete(ctx context.Context, id string) error
 	GetByStatus(ctx context.Context, status models.OrderStatus) ([]*models.Order, error)
 	NextOrderNumber(ctx context.Context, userID string) (int64, error)
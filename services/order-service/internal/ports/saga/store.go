@@ -0,0 +1,29 @@
+package saga
+
+import (
+	"context"
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/services/order-service/internal/domain/models"
+)
+
+// ListFilter narrows the sagas returned to operators
+type ListFilter struct {
+	States []models.SagaState
+	// StuckAt returns only unfinished sagas whose step deadline is at or before this time
+	StuckAt *time.Time
+	Limit   int
+}
+
+// Store persists order sagas together with their log
+type Store interface {
+	Get(ctx context.Context, orderID string) (*models.OrderSaga, error)
+	// Modify loads the saga under a row lock, applies fn and saves the saga with its new log entries.
+	// Nothing is saved when fn returns an error. Writes made with the context passed to fn
+	// commit or roll back together with the saga.
+	Modify(ctx context.Context, orderID string, fn func(context.Context, *models.OrderSaga) error) (*models.OrderSaga, error)
+	// ClaimDue returns up to limit unfinished sagas whose step deadline passed and pushes
+	// the deadline forward by lease, so concurrent orchestrators do not pick them up again.
+	ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*models.OrderSaga, error)
+	List(ctx context.Context, filter ListFilter) ([]*models.OrderSaga, error)
+}
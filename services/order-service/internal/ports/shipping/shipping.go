@@ -0,0 +1,32 @@
+package shipping
+
+import (
+	"context"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
+)
+
+// Quoter quotes the shipping methods available to a destination for a parcel weight
+type Quoter interface {
+	Quote(ctx context.Context, req Request) ([]Quote, error)
+}
+
+type Destination struct {
+	Country    string // ISO 3166-1 alpha-2
+	Region     string // ISO 3166-2 subdivision without the country prefix, optional
+	PostalCode string
+}
+
+type Request struct {
+	Destination Destination
+	WeightGrams int64
+}
+
+// Quote is the cost of one shipping method, in the currency of the rate table
+type Quote struct {
+	Method  string // e.g. STANDARD, EXPRESS
+	Name    string
+	Cost    money.Money
+	MinDays int // delivery estimate in business days
+	MaxDays int
+}
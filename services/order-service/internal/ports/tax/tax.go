@@ -0,0 +1,40 @@
+package tax
+
+import (
+	"context"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
+)
+
+// Calculator computes the taxes due on the items of an order where it ships to
+type Calculator interface {
+	Calculate(ctx context.Context, req Request) ([]Line, error)
+}
+
+// Address is where the order ships to; tax is due where goods are delivered
+type Address struct {
+	Country string // ISO 3166-1 alpha-2
+	Region  string // ISO 3166-2 subdivision without the country prefix (e.g. CA for US-CA), optional
+}
+
+type Request struct {
+	Address Address
+	Items   []Item
+}
+
+type Item struct {
+	ID       string
+	Category string      // product tax category, empty for the standard rate
+	Amount   money.Money // line total: unit price times quantity
+}
+
+// Line is one tax charged on an item. Inclusive taxes are contained in the item amount,
+// exclusive ones are charged on top of it.
+type Line struct {
+	ItemID       string
+	Name         string // e.g. VAT, GST, sales tax
+	Jurisdiction string // country, or country-region for regional taxes (e.g. US-CA)
+	Rate         float64
+	Amount       money.Money
+	Inclusive    bool
+}
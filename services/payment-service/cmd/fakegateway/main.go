@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/gateway/fakegateway"
+
+	"go.uber.org/zap"
+)
+
+// Local stand-in for the payment gateway; point payment-service at it with
+// PAYMENT_PROCESSOR=gateway and PAYMENT_GATEWAY_URL=http://localhost:8090.
+// FAKE_GATEWAY_WEBHOOK_URL (e.g. http://localhost:8091/webhooks/gateway) and
+// FAKE_GATEWAY_WEBHOOK_SECRET (= PAYMENT_WEBHOOK_SECRET) send webhooks;
+// FAKE_GATEWAY_ASYNC_DELAY (e.g. 2s) settles payments asynchronously.
+func main() {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+	log := logger.Sugar()
+
+	addr := getEnv("FAKE_GATEWAY_ADDR", ":8090")
+	apiKey := getEnv("FAKE_GATEWAY_API_KEY", "")
+	webhookURL := getEnv("FAKE_GATEWAY_WEBHOOK_URL", "")
+	webhookSecret := getEnv("FAKE_GATEWAY_WEBHOOK_SECRET", "")
+	var asyncDelay time.Duration
+	if v := os.Getenv("FAKE_GATEWAY_ASYNC_DELAY"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Errorw("invalid FAKE_GATEWAY_ASYNC_DELAY", "value", v, "error", err)
+			os.Exit(1)
+		}
+		asyncDelay = d
+	}
+	if asyncDelay > 0 && webhookURL == "" {
+		log.Warnw("async mode without FAKE_GATEWAY_WEBHOOK_URL; outcomes are never reported")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	gw := fakegateway.NewServer(apiKey).WithAsync(asyncDelay)
+	if webhookURL != "" {
+		gw.WithWebhooks(webhookURL, webhookSecret)
+	}
+	defer gw.Close()
+
+	server := &http.Server{Addr: addr, Handler: gw.Handler(), ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log.Infow("fake payment gateway listening", "addr", addr, "webhookURL", webhookURL, "asyncDelay", asyncDelay)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Errorw("fake payment gateway failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func getEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -2,20 +2,58 @@ package app
 
 import (
 	"os"
-	"strconv"
 	"time"
+
+	kafkaclient "github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
 )
 
 type Config struct {
 	Port                  string
 	MetricsPort           string
+	DBHost                string
+	DBPort                string
+	DBName                string
+	DBUser                string
+	DBPass                string
 	KafkaBrokers          string
 	KafkaAutoOffsetReset  string
 	PaymentProcessTimeout time.Duration
 	KafkaPublishTimeout   time.Duration
-	RedisAddr             string
-	RedisDB               int
-	OrderTotalTTL         time.Duration
+	// OrderServiceURL is where order totals and chosen payment methods are looked up
+	OrderServiceURL    string
+	OrderLookupTimeout time.Duration
+	OrderCacheTTL      time.Duration
+	// KafkaIdempotentConsumers enables the processed-message ledger for Kafka consumers
+	KafkaIdempotentConsumers bool
+	// PaymentProcessor selects the processor: "testcards" (deterministic, default) or "gateway" (HTTP)
+	PaymentProcessor      string
+	PaymentGatewayURL     string
+	PaymentGatewayAPIKey  string
+	PaymentGatewayTimeout time.Duration
+	// PaymentCaptureOn is the order status that captures the authorized payment: "CONFIRMED" (default) or "SHIPPED"
+	PaymentCaptureOn string
+	// CardVaultKey is the base64 AES-256 key encrypting saved cards; CardVaultPreviousKeys
+	// (comma separated) still decrypt cards saved before a key rotation.
+	// CardVaultFingerprintKey (base64, 32 bytes) keys card fingerprints and is never rotated.
+	CardVaultKey            string
+	CardVaultPreviousKeys   string
+	CardVaultFingerprintKey string
+	// WebhookPort serves processor webhooks; they are only accepted with a PaymentWebhookSecret.
+	// PaymentWebhookTolerance is how old a signed webhook may be before it is rejected.
+	WebhookPort             string
+	PaymentWebhookSecret    string
+	PaymentWebhookTolerance time.Duration
+	// RiskEngine screens payments before authorization: "rules" (default) or "none".
+	// Limits are "review/deny" (0 disables a level); empty values keep the defaults.
+	RiskEngine           string
+	RiskVelocityWindow   time.Duration
+	RiskUserVelocity     string
+	RiskCardVelocity     string
+	RiskFailureStreak    string
+	RiskAmountThresholds string // e.g. "USD=100000/500000,EUR=100000/500000", minor units
+	RiskCountryMismatch  bool
+	// Bus replaces the Kafka bus of KafkaBrokers, e.g. with a MemoryBus in tests
+	Bus kafkaclient.Bus
 }
 
 func LoadConfigFromEnv() *Config {
@@ -31,28 +69,70 @@ func LoadConfigFromEnv() *Config {
 			pubTimeout = d
 		}
 	}
-	redisDB := 0
-	if v := os.Getenv("REDIS_DB"); v != "" {
-		if d, err := strconv.Atoi(v); err == nil {
-			redisDB = d
+	orderLookupTimeout := 3 * time.Second
+	if v := os.Getenv("ORDER_LOOKUP_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			orderLookupTimeout = d
+		}
+	}
+	orderCacheTTL := 5 * time.Minute
+	if v := os.Getenv("ORDER_CACHE_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			orderCacheTTL = d
+		}
+	}
+	gatewayTimeout := 10 * time.Second
+	if v := os.Getenv("PAYMENT_GATEWAY_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			gatewayTimeout = d
+		}
+	}
+	webhookTolerance := 5 * time.Minute
+	if v := os.Getenv("PAYMENT_WEBHOOK_TOLERANCE"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			webhookTolerance = d
 		}
 	}
-	orderTTL := 30 * time.Minute
-	if v := os.Getenv("ORDER_TOTAL_TTL"); v != "" {
+	riskWindow := time.Hour
+	if v := os.Getenv("RISK_VELOCITY_WINDOW"); v != "" {
 		if d, err := time.ParseDuration(v); err == nil {
-			orderTTL = d
+			riskWindow = d
 		}
 	}
 	return &Config{
-		Port:                  getEnv("PORT", "50054"),
-		MetricsPort:           getEnv("METRICS_PORT", "9097"),
-		KafkaBrokers:          getEnv("KAFKA_BROKERS", "kafka:9092"),
-		KafkaAutoOffsetReset:  getEnv("KAFKA_AUTO_OFFSET_RESET", "earliest"),
-		PaymentProcessTimeout: procTimeout,
-		KafkaPublishTimeout:   pubTimeout,
-		RedisAddr:             getEnv("REDIS_ADDR", "redis:6379"),
-		RedisDB:               redisDB,
-		OrderTotalTTL:         orderTTL,
+		Port:                     getEnv("PORT", "50054"),
+		MetricsPort:              getEnv("METRICS_PORT", "9097"),
+		DBHost:                   getEnv("DB_HOST", "localhost"),
+		DBPort:                   getEnv("DB_PORT", "5432"),
+		DBName:                   getEnv("DB_NAME", "paymentdb"),
+		DBUser:                   getEnv("DB_USER", "admin"),
+		DBPass:                   getEnv("DB_PASSWORD", "password"),
+		KafkaBrokers:             getEnv("KAFKA_BROKERS", "kafka:9092"),
+		KafkaAutoOffsetReset:     getEnv("KAFKA_AUTO_OFFSET_RESET", "earliest"),
+		PaymentProcessTimeout:    procTimeout,
+		KafkaPublishTimeout:      pubTimeout,
+		OrderServiceURL:          getEnv("ORDER_SERVICE_URL", "order-service:50052"),
+		OrderLookupTimeout:       orderLookupTimeout,
+		OrderCacheTTL:            orderCacheTTL,
+		KafkaIdempotentConsumers: getEnv("KAFKA_IDEMPOTENT_CONSUMERS", "true") == "true",
+		PaymentProcessor:         getEnv("PAYMENT_PROCESSOR", "testcards"),
+		PaymentGatewayURL:        getEnv("PAYMENT_GATEWAY_URL", "http://localhost:8090"),
+		PaymentGatewayAPIKey:     getEnv("PAYMENT_GATEWAY_API_KEY", ""),
+		PaymentGatewayTimeout:    gatewayTimeout,
+		PaymentCaptureOn:         getEnv("PAYMENT_CAPTURE_ON", "CONFIRMED"),
+		CardVaultKey:             getEnv("CARD_VAULT_KEY", ""),
+		CardVaultPreviousKeys:    getEnv("CARD_VAULT_PREVIOUS_KEYS", ""),
+		CardVaultFingerprintKey:  getEnv("CARD_VAULT_FINGERPRINT_KEY", ""),
+		WebhookPort:              getEnv("WEBHOOK_PORT", "8091"),
+		PaymentWebhookSecret:     getEnv("PAYMENT_WEBHOOK_SECRET", ""),
+		PaymentWebhookTolerance:  webhookTolerance,
+		RiskEngine:               getEnv("RISK_ENGINE", "rules"),
+		RiskVelocityWindow:       riskWindow,
+		RiskUserVelocity:         getEnv("RISK_USER_VELOCITY", ""),
+		RiskCardVelocity:         getEnv("RISK_CARD_VELOCITY", ""),
+		RiskFailureStreak:        getEnv("RISK_FAILURE_STREAK", ""),
+		RiskAmountThresholds:     getEnv("RISK_AMOUNT_THRESHOLDS", ""),
+		RiskCountryMismatch:      getEnv("RISK_REVIEW_COUNTRY_MISMATCH", "true") == "true",
 	}
 }
 
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/proto-go/events"
+	app "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/app/services"
+	derrors "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/errors"
+	con "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/kafka/consumer"
+	kpub "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/kafka/publisher"
+	orderinfoimpl "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/orderinfo"
+
+	"go.uber.org/zap"
+)
+
+// stockReservedHandler authorizes the payment of orders whose stock was reserved and
+// publishes the outcome; paymentEvents and orderPayments may be nil
+func stockReservedHandler(paymentService *app.PaymentService, paymentEvents *kpub.PaymentEventsPublisher, orderPayments *orderinfoimpl.CachedProvider, cfg *Config, log *zap.SugaredLogger) con.StockReservedHandlerFunc {
+	return func(ctx context.Context, evt *events.StockReserved) error {
+		// A redelivered event republishes the recorded outcome instead of charging again;
+		// an authorization interrupted by a technical error is resumed
+		hctx, cancel := context.WithTimeout(ctx, cfg.PaymentProcessTimeout)
+		resp, err := paymentService.AuthorizeOrderPayment(hctx, evt.OrderId, evt.UserId)
+		cancel()
+		pe := &events.PaymentProcessed{OrderId: evt.OrderId, OccurredAt: time.Now().Format(time.RFC3339)}
+		switch {
+		case errors.Is(err, derrors.ErrOrderNotPayable):
+			// The order moved on meanwhile, e.g. it was cancelled; there is nothing to charge or to report
+			log.Infow("payment skipped", "orderID", evt.OrderId, "userID", evt.UserId, "error", err)
+			return nil
+		case errors.Is(err, derrors.ErrOrderNotFound), errors.Is(err, derrors.ErrInvalidPaymentAmount), errors.Is(err, derrors.ErrPaymentMethodNotFound):
+			// Nothing trustworthy to charge; fail the order rather than charge a guess
+			log.Errorw("payment refused", "orderID", evt.OrderId, "userID", evt.UserId, "error", err)
+			pe.Success, pe.Message = false, "Payment refused - "+err.Error()
+		case err != nil && !errors.Is(err, derrors.ErrPaymentDeclined):
+			log.Warnw("process payment failed (technical)", "orderID", evt.OrderId, "userID", evt.UserId, "error", err)
+			return err
+		case resp.Pending:
+			// The processor decides asynchronously; the webhook publishes the outcome
+			log.Infow("payment authorization pending", "orderID", evt.OrderId, "paymentID", resp.Payment.ID, "userID", evt.UserId)
+			return nil
+		case resp.InReview:
+			// Held until an admin decides (ReviewPayment), which publishes the outcome;
+			// the order waits for it
+			if paymentEvents == nil {
+				return nil
+			}
+			rr := &events.PaymentReviewRequired{
+				OrderId:    evt.OrderId,
+				PaymentId:  resp.Payment.ID,
+				UserId:     resp.Payment.UserID,
+				Reasons:    resp.Payment.RiskReasons,
+				OccurredAt: time.Now().Format(time.RFC3339),
+			}
+			pctx, pcancel := context.WithTimeout(ctx, cfg.KafkaPublishTimeout)
+			perr := paymentEvents.PublishPaymentReviewRequired(pctx, rr)
+			pcancel()
+			if perr != nil {
+				log.Errorw("publish PaymentReviewRequired failed", "orderID", evt.OrderId, "paymentID", rr.PaymentId, "userID", evt.UserId, "error", perr)
+				return perr
+			}
+			log.Infow("payment held for risk review", "orderID", evt.OrderId, "paymentID", rr.PaymentId, "userID", evt.UserId, "reasons", rr.Reasons)
+			return nil
+		default:
+			pe.PaymentId, pe.Success, pe.Message = resp.Payment.ID, resp.Success, resp.Message
+			pe.Amount, pe.Currency = resp.Payment.Amount.Amount, resp.Payment.Amount.Currency
+		}
+		// publish outcome (success, business-decline and refusal)
+		if paymentEvents == nil {
+			return nil
+		}
+		pctx, pcancel := context.WithTimeout(ctx, cfg.KafkaPublishTimeout)
+		// Wait for the broker ack; on failure the event is retried and the outcome republished
+		perr := paymentEvents.PublishPaymentProcessed(pctx, pe)
+		pcancel()
+		if perr != nil {
+			log.Errorw("publish PaymentProcessed failed", "orderID", pe.OrderId, "paymentID", pe.PaymentId, "userID", evt.UserId, "error", perr)
+			return perr
+		}
+		log.Infow("PaymentProcessed published", "orderID", pe.OrderId, "paymentID", pe.PaymentId, "userID", evt.UserId, "success", pe.Success)
+		if orderPayments != nil {
+			orderPayments.Invalidate(evt.OrderId)
+		}
+		return nil
+	}
+}
+
+// orderStatusChangedHandler captures payments of confirmed orders and releases those of
+// cancelled ones; orderPayments may be nil
+func orderStatusChangedHandler(paymentService *app.PaymentService, orderPayments *orderinfoimpl.CachedProvider, cfg *Config, log *zap.SugaredLogger) con.OrderStatusChangedHandlerFunc {
+	return func(ctx context.Context, evt *events.OrderStatusChanged) error {
+		if orderPayments != nil {
+			// a cached order would still look payable
+			orderPayments.Invalidate(evt.OrderId)
+		}
+		hctx, cancel := context.WithTimeout(ctx, cfg.PaymentProcessTimeout)
+		defer cancel()
+		switch evt.NewStatus {
+		case cfg.PaymentCaptureOn:
+			p, err := paymentService.CaptureOrderPayment(hctx, evt.OrderId)
+			if errors.Is(err, derrors.ErrCaptureDeclined) {
+				log.Errorw("payment capture declined", "orderID", evt.OrderId, "paymentID", p.ID, "reason", p.StatusReason)
+				return nil
+			}
+			if err != nil {
+				log.Warnw("payment capture failed (technical)", "orderID", evt.OrderId, "error", err)
+				return err
+			}
+			if p != nil {
+				log.Infow("order payment captured", "orderID", evt.OrderId, "paymentID", p.ID, "status", p.Status)
+			}
+		case "CANCELLED":
+			reason := evt.Reason
+			if reason == "" {
+				reason = "order cancelled"
+			}
+			p, err := paymentService.ReleaseOrderPayment(hctx, evt.OrderId, reason)
+			if errors.Is(err, derrors.ErrVoidDeclined) || errors.Is(err, derrors.ErrRefundDeclined) {
+				log.Errorw("order payment release declined", "orderID", evt.OrderId, "paymentID", p.ID, "error", err)
+				return nil
+			}
+			if err != nil {
+				log.Warnw("order payment release failed (technical)", "orderID", evt.OrderId, "error", err)
+				return err
+			}
+			if p != nil {
+				log.Infow("order payment released", "orderID", evt.OrderId, "paymentID", p.ID, "status", p.Status)
+			}
+		}
+		return nil
+	}
+}
@@ -2,135 +2,185 @@ package app
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
+	"fmt"
 	"io"
 	"net"
+	"net/http"
+	"strings"
 	"time"
 
+	"github.com/kubernetestest/ecommerce-platform/pkg/eventschema"
 	pub "github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
 	pkglogger "github.com/kubernetestest/ecommerce-platform/pkg/logger"
 	"github.com/kubernetestest/ecommerce-platform/pkg/metrics"
-	"github.com/kubernetestest/ecommerce-platform/proto-go/events"
+	orderpb "github.com/kubernetestest/ecommerce-platform/proto-go/order"
 	app "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/app/services"
-	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/entities"
-	derrors "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/errors"
-	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/valueobjects"
-	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/cache"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/gateway"
 	srv "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/grpc"
 	con "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/kafka/consumer"
-	mockproc "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/processor"
+	kpub "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/kafka/publisher"
+	orderinfoimpl "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/orderinfo"
+	proc "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/processor"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/repository"
+	riskimpl "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/risk"
+	vaultimpl "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/vault"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/webhook"
 	paymentmetrics "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/metrics"
+	procport "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/ports/processor"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/ports/risk"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
-	"google.golang.org/protobuf/proto"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
 )
 
 func Run(ctx context.Context, cfg *Config, logger *zap.Logger) error {
 	log := logger.Sugar()
 
+	db, err := connectDB(cfg)
+	if err != nil {
+		log.Errorw("db connect failed", "error", err)
+		return fmt.Errorf("connect db: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Errorw("db pool obtain failed", "error", err)
+		return fmt.Errorf("db pool: %w", err)
+	}
+	defer sqlDB.Close()
+
+	paymentRepo := repository.NewGormPaymentRepository(db)
+	if getEnv("AUTO_MIGRATE", "") == "true" {
+		if err := paymentRepo.AutoMigrate(); err != nil {
+			log.Errorw("automigrate failed", "error", err)
+			return fmt.Errorf("automigrate: %w", err)
+		}
+	}
+
+	cipher, err := newVaultCipher(cfg, log)
+	if err != nil {
+		log.Errorw("card vault init failed", "error", err)
+		return fmt.Errorf("card vault: %w", err)
+	}
+	cardVault := vaultimpl.NewGormVault(db, cipher)
+	if getEnv("AUTO_MIGRATE", "") == "true" {
+		if err := cardVault.AutoMigrate(); err != nil {
+			log.Errorw("card vault automigrate failed", "error", err)
+			return fmt.Errorf("automigrate card vault: %w", err)
+		}
+	}
+
 	server := grpc.NewServer()
-	processor := mockproc.NewMockPaymentProcessor()
+	processor, err := newProcessor(cfg)
+	if err != nil {
+		log.Errorw("payment processor init failed", "error", err)
+		return fmt.Errorf("payment processor: %w", err)
+	}
+	log.Infow("payment processor selected", "processor", cfg.PaymentProcessor)
+
+	riskEvaluator, err := newRiskEvaluator(cfg, paymentRepo)
+	if err != nil {
+		log.Errorw("risk engine init failed", "error", err)
+		return fmt.Errorf("risk engine: %w", err)
+	}
+	log.Infow("risk engine selected", "engine", cfg.RiskEngine)
 
 	// Initialize metrics
 	metricsInstance := paymentmetrics.NewPaymentMetrics()
 
-	paymentService := app.NewPaymentService(processor, metricsInstance)
-	srv.RegisterPaymentPBServer(server, paymentService, metricsInstance)
+	paymentService := app.NewPaymentService(processor, paymentRepo, metricsInstance).WithVault(cardVault).WithLogger(logger)
+	if riskEvaluator != nil {
+		paymentService.WithRisk(riskEvaluator)
+	}
+	paymentMethods := app.NewPaymentMethodService(cardVault)
+	srv.RegisterPaymentPBServer(server, paymentService, paymentMethods, metricsInstance)
+
+	// Processed-message ledger deduplicates redelivered Kafka events and webhook events
+	ledger := pub.NewGormProcessedStore(db)
+	if getEnv("AUTO_MIGRATE", "") == "true" {
+		if err := ledger.AutoMigrate(); err != nil {
+			log.Errorw("processed messages automigrate failed", "error", err)
+			return fmt.Errorf("automigrate processed messages: %w", err)
+		}
+	}
+	var processed pub.ProcessedStore
+	if cfg.KafkaIdempotentConsumers {
+		processed = ledger
+	}
 
 	// Kafka wiring (best-effort)
 	var prod pub.Publisher
+	var paymentEvents *kpub.PaymentEventsPublisher
 	var consSR *con.Consumer
-	var consOC *con.OrderCreatedConsumer
+	var consOSC *con.OrderStatusChangedConsumer
 
-	// Redis cache for order totals from OrderCreated
-	var totalsCache cache.OrderTotalsCache
+	// Order lookup: authoritative order totals and the payment methods users chose
 	var closers []io.Closer
-	if cfg.RedisAddr != "" {
-		totalsCache = cache.NewRedisOrderTotalsCache(cfg.RedisAddr, cfg.RedisDB, "order:total:")
-		closers = append(closers, totalsCache)
-		log.Infow("Redis cache initialized", "addr", cfg.RedisAddr, "db", cfg.RedisDB)
+	var orderPayments *orderinfoimpl.CachedProvider
+	if cfg.OrderServiceURL != "" {
+		if conn, err := grpc.DialContext(ctx, cfg.OrderServiceURL, grpc.WithInsecure()); err == nil {
+			closers = append(closers, conn)
+			provider := orderinfoimpl.NewOrderServiceProvider(orderpb.NewOrderServiceClient(conn), cfg.OrderLookupTimeout)
+			orderPayments = orderinfoimpl.NewCachedProvider(provider, cfg.OrderCacheTTL)
+			paymentService.WithOrders(orderPayments)
+		} else {
+			log.Warnw("order grpc dial failed", "url", cfg.OrderServiceURL, "error", err)
+		}
+	} else {
+		log.Infow("order lookup not configured; StockReserved payments are refused")
 	}
-	if cfg.KafkaBrokers != "" {
+	bus := cfg.Bus
+	if bus == nil && cfg.KafkaBrokers != "" {
+		bus = pub.NewKafkaBus(cfg.KafkaBrokers)
+	}
+	if bus != nil {
 		config := pub.PublisherConfig{
-			BootstrapServers: cfg.KafkaBrokers,
-			ClientID:         "payment-service",
+			ClientID: "payment-service",
 		}
-		if p, err := pub.NewKafkaPublisher(config); err != nil {
+		if p, err := bus.NewPublisher(config); err != nil {
 			log.Warnw("kafka producer init failed", "error", err)
 		} else {
 			prod = p.WithLogger(pkglogger.NewZapLogger(log))
 			closers = append(closers, prod)
+			paymentEvents = kpub.NewPaymentEventsPublisher(pub.NewProtoEventPublisher(prod, kpub.ProducerName, kpub.Routes()).WithSchemas(eventschema.Contracts()))
+			paymentService.WithPublisher(paymentEvents)
 			log.Infow("Kafka producer initialized", "brokers", cfg.KafkaBrokers)
 		}
-		// consume OrderCreated to cache totals
-		if oc, err := con.NewOrderCreatedConsumer(cfg.KafkaBrokers, "payment-service", con.OrderCreatedHandlerFunc(func(cctx context.Context, evt *events.OrderCreated) error {
-			if totalsCache != nil {
-				if err := totalsCache.Set(cctx, evt.OrderId, evt.TotalAmount, evt.Currency, cfg.OrderTotalTTL); err != nil {
-					log.Warnw("cache set failed", "orderID", evt.OrderId, "error", err)
-				}
-			}
-			return nil
-		})); err != nil {
-			log.Warnw("kafka order-created consumer init failed", "error", err)
-		} else {
-			consOC = oc.WithLogger(pkglogger.NewZapLogger(log))
-			closers = append(closers, consOC)
-			go consOC.Run(ctx, []string{"orders.v1.order_created"})
-			log.Infow("Kafka consumer started", "topic", "orders.v1.order_created")
-		}
-
-		// consume StockReserved to process payments
-		if c, err := con.NewConsumer(cfg.KafkaBrokers, "payment-service", con.StockReservedHandlerFunc(func(cctx context.Context, evt *events.StockReserved) error {
-			// Build amount from Redis cached order total if present
-			amt := func() valueobjects.Money {
-				if totalsCache != nil {
-					if a, c, ok, _ := totalsCache.Get(cctx, evt.OrderId); ok {
-						if m, e := valueobjects.NewMoney(a, c); e == nil {
-							return m
-						}
-					}
-				}
-				m, _ := valueobjects.NewMoney(0, "USD")
-				return m
-			}()
-			req := &app.ProcessPaymentRequest{OrderID: evt.OrderId, UserID: evt.UserId, Amount: amt, Method: entities.MethodCreditCard, CardNumber: "4111111111111111"}
-			// Timeout for processing to avoid hanging
-			hctx, cancel := context.WithTimeout(cctx, cfg.PaymentProcessTimeout)
-			resp, err := paymentService.ProcessPayment(hctx, req)
-			cancel()
-			if err != nil && !errors.Is(err, derrors.ErrPaymentDeclined) {
-				log.Warnw("process payment failed (technical)", "orderID", evt.OrderId, "userID", evt.UserId, "error", err)
-				return nil
-			}
-			// publish outcome (both success and business-decline)
-			if prod != nil && resp != nil && resp.Payment != nil {
-				pe := &events.PaymentProcessed{OrderId: resp.Payment.OrderID, PaymentId: resp.Payment.ID, Success: resp.Success, Message: resp.Message, Amount: resp.Payment.Amount.Amount, Currency: resp.Payment.Amount.Currency, OccurredAt: time.Now().Format(time.RFC3339)}
-				bytes, _ := proto.Marshal(pe)
-				pctx, pcancel := context.WithTimeout(cctx, cfg.KafkaPublishTimeout)
-				if perr := prod.Publish(pctx, "payments.v1.payment_processed", bytes); perr != nil {
-					log.Errorw("publish PaymentProcessed failed", "orderID", pe.OrderId, "paymentID", pe.PaymentId, "userID", evt.UserId, "error", perr)
-				} else {
-					log.Infow("PaymentProcessed published", "orderID", pe.OrderId, "paymentID", pe.PaymentId, "userID", evt.UserId, "success", pe.Success)
-				}
-				pcancel()
-				// best-effort cleanup of cached total
-				if totalsCache != nil {
-					if err := totalsCache.Del(cctx, evt.OrderId); err != nil {
-						log.Warnw("cache delete failed", "orderID", evt.OrderId, "error", err)
-					}
-				}
-			}
-			return nil
-		})); err != nil {
+		// consume StockReserved to authorize payments
+		if c, err := con.NewConsumer(bus, "payment-service", stockReservedHandler(paymentService, paymentEvents, orderPayments, cfg, log)); err != nil {
 			log.Warnw("kafka consumer init failed", "error", err)
 		} else {
 			consSR = c.WithLogger(pkglogger.NewZapLogger(log))
+			if processed != nil {
+				consSR.WithIdempotency(processed)
+			}
+			if prod != nil {
+				consSR.WithDeadLetter(prod)
+			}
 			closers = append(closers, consSR)
-			go consSR.Run(ctx, []string{"inventory.v1.stock_reserved"})
-			log.Infow("Kafka consumer started", "topic", "inventory.v1.stock_reserved")
+			go consSR.Run(ctx, []string{eventschema.StockReservedTopic})
+			log.Infow("Kafka consumer started", "topic", eventschema.StockReservedTopic)
+		}
+
+		// consume OrderStatusChanged to capture payments of confirmed orders and release those of cancelled ones
+		if c, err := con.NewOrderStatusChangedConsumer(bus, "payment-service", orderStatusChangedHandler(paymentService, orderPayments, cfg, log)); err != nil {
+			log.Warnw("kafka order-status consumer init failed", "error", err)
+		} else {
+			consOSC = c.WithLogger(pkglogger.NewZapLogger(log))
+			if processed != nil {
+				consOSC.WithIdempotency(processed)
+			}
+			if prod != nil {
+				consOSC.WithDeadLetter(prod)
+			}
+			closers = append(closers, consOSC)
+			go consOSC.Run(ctx, []string{eventschema.OrderStatusChangedTopic})
+			log.Infow("Kafka consumer started", "topic", eventschema.OrderStatusChangedTopic)
 		}
 	}
 
@@ -152,6 +202,29 @@ func Run(ctx context.Context, cfg *Config, logger *zap.Logger) error {
 		}
 	}()
 
+	// Processor webhooks: asynchronous authorization, capture and refund outcomes
+	if cfg.PaymentWebhookSecret != "" {
+		hooks := webhook.NewGatewayHandler(paymentService, cfg.PaymentWebhookSecret, cfg.PaymentWebhookTolerance).
+			WithProcessed(ledger).
+			WithLogger(logger)
+		webhookServer := &http.Server{Addr: ":" + cfg.WebhookPort, Handler: hooks.Handler(), ReadHeaderTimeout: 5 * time.Second}
+		go func() {
+			log.Infow("webhook server starting", "port", cfg.WebhookPort, "path", webhook.GatewayPath)
+			if err := webhookServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Errorw("webhook server failed", "error", err)
+			}
+		}()
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := webhookServer.Shutdown(shutdownCtx); err != nil {
+				log.Errorw("webhook server shutdown failed", "error", err)
+			}
+		}()
+	} else {
+		log.Infow("PAYMENT_WEBHOOK_SECRET not set; processor webhooks disabled")
+	}
+
 	healthServer := health.NewServer()
 	grpc_health_v1.RegisterHealthServer(server, healthServer)
 	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
@@ -191,3 +264,114 @@ func Run(ctx context.Context, cfg *Config, logger *zap.Logger) error {
 		return err
 	}
 }
+
+func connectDB(cfg *Config) (*gorm.DB, error) {
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPass, cfg.DBName)
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: nil})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if err := sqlDB.Ping(); err != nil {
+		_ = sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// newRiskEvaluator builds the risk engine selected by cfg.RiskEngine; "none" screens nothing
+func newRiskEvaluator(cfg *Config, history risk.History) (risk.RiskEvaluator, error) {
+	switch cfg.RiskEngine {
+	case "none":
+		return nil, nil
+	case "", "rules":
+	default:
+		return nil, fmt.Errorf("unknown risk engine %q", cfg.RiskEngine)
+	}
+	rules := riskimpl.DefaultRulesConfig()
+	rules.VelocityWindow = cfg.RiskVelocityWindow
+	rules.ReviewCountryMismatch = cfg.RiskCountryMismatch
+	for _, limit := range []struct {
+		value  string
+		limits *riskimpl.Limits
+	}{
+		{cfg.RiskUserVelocity, &rules.UserVelocity},
+		{cfg.RiskCardVelocity, &rules.CardVelocity},
+		{cfg.RiskFailureStreak, &rules.FailureStreak},
+	} {
+		if limit.value == "" {
+			continue
+		}
+		l, err := riskimpl.ParseLimits(limit.value)
+		if err != nil {
+			return nil, err
+		}
+		*limit.limits = l
+	}
+	if cfg.RiskAmountThresholds != "" {
+		thresholds, err := riskimpl.ParseAmountThresholds(cfg.RiskAmountThresholds)
+		if err != nil {
+			return nil, err
+		}
+		rules.AmountThresholds = thresholds
+	}
+	return riskimpl.NewRulesEngine(history, rules), nil
+}
+
+// newVaultCipher builds the card vault cipher from the configured keys. Without a key an
+// ephemeral one is generated: fine for local runs, but saved cards are unreadable after a restart.
+func newVaultCipher(cfg *Config, log *zap.SugaredLogger) (*vaultimpl.Cipher, error) {
+	if cfg.CardVaultKey == "" {
+		log.Warnw("CARD_VAULT_KEY not set; using ephemeral keys, saved cards are lost on restart")
+		keys := make([]byte, 2*vaultimpl.KeySize)
+		if _, err := rand.Read(keys); err != nil {
+			return nil, err
+		}
+		return vaultimpl.NewCipher(keys[vaultimpl.KeySize:], keys[:vaultimpl.KeySize])
+	}
+	// Card dedupe and risk velocity match cards by fingerprint, so its key must not rotate
+	if cfg.CardVaultFingerprintKey == "" {
+		return nil, errors.New("CARD_VAULT_FINGERPRINT_KEY is required with CARD_VAULT_KEY")
+	}
+	fingerprintKey, err := vaultimpl.ParseKey(cfg.CardVaultFingerprintKey)
+	if err != nil {
+		return nil, fmt.Errorf("fingerprint key: %w", err)
+	}
+	primary, err := vaultimpl.ParseKey(cfg.CardVaultKey)
+	if err != nil {
+		return nil, err
+	}
+	var previous [][]byte
+	for _, encoded := range strings.Split(cfg.CardVaultPreviousKeys, ",") {
+		if encoded = strings.TrimSpace(encoded); encoded == "" {
+			continue
+		}
+		key, err := vaultimpl.ParseKey(encoded)
+		if err != nil {
+			return nil, err
+		}
+		previous = append(previous, key)
+	}
+	return vaultimpl.NewCipher(fingerprintKey, primary, previous...)
+}
+
+// newProcessor builds the payment processor selected by cfg.PaymentProcessor
+func newProcessor(cfg *Config) (procport.PaymentProcessor, error) {
+	switch cfg.PaymentProcessor {
+	case "", "testcards":
+		return proc.NewTestCardProcessor(), nil
+	case "gateway":
+		client := gateway.NewClient(gateway.ClientConfig{
+			BaseURL: cfg.PaymentGatewayURL,
+			APIKey:  cfg.PaymentGatewayAPIKey,
+			Timeout: cfg.PaymentGatewayTimeout,
+		})
+		return proc.NewGatewayProcessor(client), nil
+	}
+	return nil, fmt.Errorf("unknown payment processor %q", cfg.PaymentProcessor)
+}
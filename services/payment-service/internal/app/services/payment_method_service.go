@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/entities"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/ports/vault"
+)
+
+// PaymentMethodService manages the cards users save in the vault. Callers only ever see the
+// token and the display details (brand, last4, expiry); card numbers stay encrypted in the vault.
+type PaymentMethodService struct {
+	vault vault.Vault
+}
+
+func NewPaymentMethodService(v vault.Vault) *PaymentMethodService {
+	return &PaymentMethodService{vault: v}
+}
+
+type AddPaymentMethodRequest struct {
+	UserID string
+	Method entities.PaymentMethod
+	Card   entities.CardDetails
+}
+
+// AddPaymentMethod validates and saves a card; saving a card twice returns the first token
+func (s *PaymentMethodService) AddPaymentMethod(ctx context.Context, req *AddPaymentMethodRequest) (*entities.SavedPaymentMethod, error) {
+	token := "pm_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+	m, err := entities.NewSavedCard(token, req.UserID, req.Method, req.Card, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return s.vault.Store(ctx, m, entities.NormalizeCardNumber(req.Card.Number))
+}
+
+// ListPaymentMethods returns the saved cards of a user, newest first
+func (s *PaymentMethodService) ListPaymentMethods(ctx context.Context, userID string) ([]*entities.SavedPaymentMethod, error) {
+	return s.vault.List(ctx, userID)
+}
+
+// DeletePaymentMethod removes a saved card of a user; payments already made with it are unaffected
+func (s *PaymentMethodService) DeletePaymentMethod(ctx context.Context, userID, token string) error {
+	return s.vault.Delete(ctx, userID, token)
+}
@@ -2,113 +2,778 @@ package services
 
 import (
 	"context"
-	"sync"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
+	events "github.com/kubernetestest/ecommerce-platform/proto-go/events"
 	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/entities"
 	derrors "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/errors"
-	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/valueobjects"
 	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/metrics"
+	evport "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/ports/events"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/ports/orderinfo"
 	procport "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/ports/processor"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/ports/repository"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/ports/risk"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/ports/vault"
+	"go.uber.org/zap"
 )
 
 type PaymentService struct {
 	processor procport.PaymentProcessor
+	repo      repository.PaymentRepository
 	metrics   metrics.PaymentMetrics
-	mu        sync.RWMutex
-	payments  map[string]*entities.Payment
+	pub       evport.Publisher
+	orders    orderinfo.Provider
+	vault     vault.Vault
+	risk      risk.RiskEvaluator
+	logger    *zap.SugaredLogger
 }
 
-func NewPaymentService(processor procport.PaymentProcessor, metrics metrics.PaymentMetrics) *PaymentService {
+func NewPaymentService(processor procport.PaymentProcessor, repo repository.PaymentRepository, metrics metrics.PaymentMetrics) *PaymentService {
 	return &PaymentService{
 		processor: processor,
+		repo:      repo,
 		metrics:   metrics,
-		payments:  make(map[string]*entities.Payment),
 	}
 }
 
+// WithPublisher sets event publisher for refund events and asynchronous authorization outcomes
+func (s *PaymentService) WithPublisher(p evport.Publisher) *PaymentService { s.pub = p; return s }
+
+// WithOrders sets the order lookup used to charge orders (AuthorizeOrderPayment)
+func (s *PaymentService) WithOrders(p orderinfo.Provider) *PaymentService { s.orders = p; return s }
+
+// WithVault sets the card vault that resolves saved payment method tokens
+func (s *PaymentService) WithVault(v vault.Vault) *PaymentService { s.vault = v; return s }
+
+// WithRisk sets the risk evaluator screening payments before they are authorized
+func (s *PaymentService) WithRisk(e risk.RiskEvaluator) *PaymentService { s.risk = e; return s }
+
+// WithLogger sets logger used for best-effort side effects
+func (s *PaymentService) WithLogger(l *zap.Logger) *PaymentService {
+	if l != nil {
+		s.logger = l.Sugar()
+	}
+	return s
+}
+
 // (Clock/IDGenerator injection removed as unused)
 
 type ProcessPaymentRequest struct {
 	OrderID string
 	UserID  string
-	Amount  valueobjects.Money
+	Amount  money.Money
 	Method  entities.PaymentMethod
-	// Card details (mock)
-	CardNumber string
+	// PaymentMethodToken is a saved card of the user to charge; it decides the method
+	PaymentMethodToken string
+	// CardNumber is a one-off card charged when no token is given, BillingCountry its
+	// billing country (ISO 3166-1 alpha-2) if known
+	CardNumber     string
+	BillingCountry string
+	// ShippingCountry of the order (ISO 3166-1 alpha-2), checked by the risk rules
+	ShippingCountry string
 }
 
 type ProcessPaymentResponse struct {
 	Payment *entities.Payment
 	Success bool
-	Message string
+	// Pending means the processor decides asynchronously; the outcome arrives by webhook
+	// (ApplyProcessorUpdate), which publishes PaymentProcessed
+	Pending bool
+	// InReview means the risk rules hold the payment until an admin decides (ReviewPayment)
+	InReview bool
+	Message  string
 }
 
+// ProcessPayment authorizes the amount of an order; the payment is captured or voided later.
+// The payment is recorded as PENDING before the processor is called, so an authorization
+// interrupted by a technical error can be resumed with AuthorizePendingPayment.
+// With a risk evaluator the payment is screened first: denied payments are declined
+// (ErrPaymentDeclined) and payments flagged for review are held IN_REVIEW.
 func (s *PaymentService) ProcessPayment(ctx context.Context, req *ProcessPaymentRequest) (*ProcessPaymentResponse, error) {
+	if !req.Amount.IsPositive() || req.Amount.Currency == "" {
+		return nil, fmt.Errorf("%w: %s", derrors.ErrInvalidPaymentAmount, req.Amount)
+	}
+	method, cardNumber, billingCountry := req.Method, req.CardNumber, req.BillingCountry
+	var saved *entities.SavedPaymentMethod
+	if req.PaymentMethodToken != "" {
+		var err error
+		saved, cardNumber, err = s.savedCard(ctx, req.UserID, req.PaymentMethodToken)
+		if err != nil {
+			return nil, err
+		}
+		method, billingCountry = saved.Method, saved.BillingCountry
+	}
+	payment := entities.NewPayment(s.newID("pay-"), req.OrderID, req.UserID, req.Amount, method, s.now())
+	payment.PaymentMethodID = req.PaymentMethodToken
+	payment.ShippingCountry = strings.ToUpper(req.ShippingCountry)
+	switch {
+	case saved != nil:
+		payment.CardFingerprint = saved.Fingerprint
+	case cardNumber != "" && s.vault != nil:
+		payment.CardFingerprint = s.vault.Fingerprint(cardNumber)
+	}
+	if err := s.repo.Create(ctx, payment); err != nil {
+		return nil, fmt.Errorf("failed to save payment: %w", err)
+	}
+	if resp, err := s.screen(ctx, payment, billingCountry); resp != nil || err != nil {
+		return resp, err
+	}
+	return s.authorize(ctx, payment, cardNumber)
+}
+
+// AuthorizePendingPayment resumes the authorization of a payment left PENDING. A payment made
+// with a saved card is charged with that card again, cardNumber is used for one-off cards.
+// Processors deduplicate by payment ID, so an authorization that reached them is not repeated;
+// one the processor is still deciding is reported as pending.
+func (s *PaymentService) AuthorizePendingPayment(ctx context.Context, paymentID, cardNumber string) (*ProcessPaymentResponse, error) {
+	payment, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != entities.PaymentPending {
+		return nil, fmt.Errorf("%w: payment %s is %s, not pending", derrors.ErrInvalidPaymentTransition, payment.ID, payment.Status)
+	}
+	if payment.TransactionID != "" {
+		return authorizationOutcome(payment), nil
+	}
+	billingCountry := ""
+	if payment.PaymentMethodID != "" {
+		var saved *entities.SavedPaymentMethod
+		if saved, cardNumber, err = s.savedCard(ctx, payment.UserID, payment.PaymentMethodID); err != nil {
+			return nil, err
+		}
+		billingCountry = saved.BillingCountry
+	}
+	if resp, err := s.screen(ctx, payment, billingCountry); resp != nil || err != nil {
+		return resp, err
+	}
+	return s.authorize(ctx, payment, cardNumber)
+}
+
+// screen evaluates the risk of a pending payment the risk rules have not seen yet and records
+// the decision. It returns the response of a payment held for review or denied, and nil for
+// a payment that may be authorized. An evaluation error leaves the payment PENDING.
+func (s *PaymentService) screen(ctx context.Context, payment *entities.Payment, billingCountry string) (*ProcessPaymentResponse, error) {
+	if s.risk == nil || payment.RiskDecision != "" {
+		return nil, nil
+	}
+	in := risk.Input{
+		PaymentID:       payment.ID,
+		OrderID:         payment.OrderID,
+		UserID:          payment.UserID,
+		Amount:          payment.Amount.Amount,
+		Currency:        payment.Amount.Currency,
+		Method:          string(payment.Method),
+		CardFingerprint: payment.CardFingerprint,
+		BillingCountry:  strings.ToUpper(billingCountry),
+		ShippingCountry: payment.ShippingCountry,
+	}
+	assessment, err := s.risk.Evaluate(ctx, in)
+	if err != nil {
+		s.metrics.PaymentFailed("risk_error")
+		return nil, fmt.Errorf("risk evaluation of %s: %w", payment.ID, err)
+	}
+	decision, reasons := assessment.Decision, assessment.Reasons
+	if decision == "" {
+		decision = entities.RiskAllow
+	}
+	if decision == entities.RiskReview && payment.PaymentMethodID == "" {
+		// One-off card numbers are not kept, so an approved payment could not be charged
+		decision = entities.RiskDeny
+		reasons = append(reasons, "review requires a saved card")
+	}
+	updated, err := s.repo.Modify(ctx, payment.ID, func(p *entities.Payment) error {
+		return p.ApplyRisk(decision, reasons, s.now())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save risk decision of %s: %w", payment.ID, err)
+	}
+	*payment = *updated
+	s.metrics.RiskDecision(string(decision))
+	switch decision {
+	case entities.RiskReview:
+		return authorizationOutcome(updated), nil
+	case entities.RiskDeny:
+		s.metrics.PaymentFailed("risk_denied")
+		return authorizationOutcome(updated), derrors.ErrPaymentDeclined
+	}
+	return nil, nil
+}
+
+// savedCard resolves a vault token of userID to the saved method and its card number
+func (s *PaymentService) savedCard(ctx context.Context, userID, token string) (*entities.SavedPaymentMethod, string, error) {
+	if s.vault == nil {
+		return nil, "", fmt.Errorf("%w: card vault not configured", derrors.ErrPaymentMethodNotFound)
+	}
+	saved, err := s.vault.Get(ctx, userID, token)
+	if err != nil {
+		return nil, "", err
+	}
+	number, err := s.vault.Reveal(ctx, userID, token)
+	if err != nil {
+		return nil, "", err
+	}
+	return saved, number, nil
+}
+
+func (s *PaymentService) authorize(ctx context.Context, payment *entities.Payment, cardNumber string) (*ProcessPaymentResponse, error) {
 	start := time.Now()
 
-	res, err := s.processor.Process(ctx, procport.ProcessRequest{CardNumber: req.CardNumber})
+	res, err := s.processor.Authorize(ctx, procport.AuthorizeRequest{
+		PaymentID:  payment.ID,
+		OrderID:    payment.OrderID,
+		Amount:     payment.Amount.Amount,
+		Currency:   payment.Amount.Currency,
+		Method:     string(payment.Method),
+		CardNumber: cardNumber,
+	})
 	if err != nil {
-		// Record failed payment
+		// The payment stays PENDING and can be resumed
 		s.metrics.PaymentFailed("processor_error")
-		s.metrics.PaymentProcessingDuration(time.Since(start), string(req.Method))
+		s.metrics.PaymentProcessingDuration(time.Since(start), string(payment.Method))
+		return nil, err
+	}
+	if res.Pending {
+		// The webhook may have decided the payment meanwhile; report whatever is recorded
+		updated, err := s.repo.Modify(ctx, payment.ID, func(p *entities.Payment) error {
+			if p.Status != entities.PaymentPending {
+				return nil
+			}
+			return p.AwaitAuthorization(res.TransactionID, s.now())
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to save payment: %w", err)
+		}
+		return authorizationOutcome(updated), nil
+	}
+
+	transactionID := res.TransactionID
+	if transactionID == "" {
+		transactionID = s.newID("txn-")
+	}
+	// The processor outcome is final at this point; a storage failure is reported as a technical error
+	updated, err := s.repo.Modify(ctx, payment.ID, func(p *entities.Payment) error {
+		if res.Success {
+			return p.Authorize(transactionID, s.now())
+		}
+		// Keep the processor reference of the declined attempt so its webhook matches
+		p.TransactionID = res.TransactionID
+		return p.Decline(res.FailureReason, s.now())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save payment: %w", err)
+	}
+	s.metrics.PaymentProcessingDuration(time.Since(start), string(payment.Method))
+
+	if !res.Success {
+		s.metrics.PaymentFailed(failureLabel(res))
+		message := "Payment failed"
+		if res.FailureReason != "" {
+			message = "Payment failed - " + res.FailureReason
+		}
+		return &ProcessPaymentResponse{Payment: updated, Success: false, Message: message}, derrors.ErrPaymentDeclined
+	}
+	s.metrics.PaymentSucceeded(string(payment.Method))
+	return &ProcessPaymentResponse{Payment: updated, Success: true, Message: "Payment authorized successfully"}, nil
+}
+
+// authorizationOutcome reports the recorded authorization outcome of a payment
+func authorizationOutcome(p *entities.Payment) *ProcessPaymentResponse {
+	switch p.Status {
+	case entities.PaymentPending:
+		return &ProcessPaymentResponse{Payment: p, Pending: true, Message: "Payment authorization pending"}
+	case entities.PaymentInReview:
+		return &ProcessPaymentResponse{Payment: p, InReview: true, Message: "Payment held for risk review"}
+	case entities.PaymentFailed:
+		message := "Payment failed"
+		if p.StatusReason != "" {
+			message = "Payment failed - " + p.StatusReason
+		}
+		return &ProcessPaymentResponse{Payment: p, Success: false, Message: message}
+	}
+	return &ProcessPaymentResponse{Payment: p, Success: true, Message: "Payment authorized successfully"}
+}
+
+// AuthorizeOrderPayment authorizes the total of an order whose stock is reserved with the payment
+// the user chose. The order is looked up via the orders provider; unknown orders, orders no longer
+// awaiting payment and zero amounts are refused (ErrOrderNotFound, ErrOrderNotPayable,
+// ErrInvalidPaymentAmount), as are saved cards the user no longer has (ErrPaymentMethodNotFound).
+// An order that already has a payment
+// gets its recorded outcome back (InReview while the risk review is open), and a PENDING
+// authorization is resumed unless the processor is still deciding it.
+func (s *PaymentService) AuthorizeOrderPayment(ctx context.Context, orderID, userID string) (*ProcessPaymentResponse, error) {
+	prior, err := s.LatestOrderPayment(ctx, orderID)
+	if err != nil {
 		return nil, err
 	}
+	if prior != nil && (prior.Status != entities.PaymentPending || prior.TransactionID != "") {
+		return authorizationOutcome(prior), nil
+	}
 
-	now := s.now()
-	message := "Payment failed"
-	status := entities.PaymentFailed
-	if res.Success {
-		status = entities.PaymentCompleted
-		message = "Payment processed successfully"
-	} else if res.FailureReason != "" {
-		message = "Payment failed - " + res.FailureReason
+	if s.orders == nil {
+		return nil, errors.New("order lookup not configured")
+	}
+	order, err := s.orders.GetOrderPayment(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != "" && order.UserID != userID {
+		return nil, fmt.Errorf("%w: order %s does not belong to user %s", derrors.ErrOrderNotFound, orderID, userID)
+	}
+	if !order.AwaitingPayment() {
+		return nil, fmt.Errorf("%w: order %s is %s", derrors.ErrOrderNotPayable, orderID, order.Status)
+	}
+	amount, err := money.New(order.Amount, order.Currency)
+	if err != nil || amount.Amount <= 0 {
+		return nil, fmt.Errorf("%w: order %s total is %d %q", derrors.ErrInvalidPaymentAmount, orderID, order.Amount, order.Currency)
 	}
 
-	payment := &entities.Payment{
-		ID:            s.newID("pay-"),
-		OrderID:       req.OrderID,
-		UserID:        req.UserID,
-		Amount:        req.Amount,
-		Status:        status,
-		Method:        req.Method,
-		TransactionID: s.newID("txn-"),
-		CreatedAt:     now,
-		UpdatedAt:     now,
+	if prior != nil {
+		return s.AuthorizePendingPayment(ctx, prior.ID, "")
 	}
+	return s.ProcessPayment(ctx, &ProcessPaymentRequest{
+		OrderID:            orderID,
+		UserID:             order.UserID,
+		Amount:             amount,
+		Method:             entities.PaymentMethod(order.Method),
+		PaymentMethodToken: order.PaymentMethodToken,
+		ShippingCountry:    order.ShippingCountry,
+	})
+}
 
-	// persist in-memory for subsequent reads
-	s.mu.Lock()
-	s.payments[payment.ID] = payment
-	s.mu.Unlock()
+// ReviewPayment decides a payment the risk rules held for review: an approved payment is
+// authorized, a rejected one declined with note as reason. The authorization outcome is
+// published as PaymentProcessed, also when a decided review is submitted again, so a failed
+// publish can be retried. Payments not held for review return ErrInvalidPaymentTransition.
+func (s *PaymentService) ReviewPayment(ctx context.Context, paymentID string, approve bool, note string) (*ProcessPaymentResponse, error) {
+	payment, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.RiskDecision != entities.RiskReview {
+		return nil, fmt.Errorf("%w: payment %s is not held for risk review", derrors.ErrInvalidPaymentTransition, payment.ID)
+	}
+
+	var resp *ProcessPaymentResponse
+	switch {
+	case payment.Status == entities.PaymentInReview && !approve:
+		reason := "rejected in risk review"
+		if note != "" {
+			reason += ": " + note
+		}
+		payment, err = s.repo.Modify(ctx, paymentID, func(p *entities.Payment) error {
+			if p.Status != entities.PaymentInReview {
+				return nil
+			}
+			return p.RejectReview(reason, s.now())
+		})
+		if err != nil {
+			return nil, err
+		}
+		if payment.Status == entities.PaymentFailed {
+			s.metrics.PaymentFailed("risk_rejected")
+		}
+		resp = authorizationOutcome(payment)
+	case payment.Status == entities.PaymentInReview,
+		payment.Status == entities.PaymentPending && payment.TransactionID == "":
+		// Approved now, or approved before and the authorization was interrupted
+		if payment.Status == entities.PaymentInReview {
+			if _, err := s.repo.Modify(ctx, paymentID, func(p *entities.Payment) error {
+				if p.Status != entities.PaymentInReview {
+					return nil
+				}
+				return p.ApproveReview(s.now())
+			}); err != nil {
+				return nil, err
+			}
+		}
+		resp, err = s.AuthorizePendingPayment(ctx, paymentID, "")
+		if err != nil && !errors.Is(err, derrors.ErrPaymentDeclined) {
+			return nil, err
+		}
+	default:
+		// Decided before; the outcome stands
+		resp = authorizationOutcome(payment)
+	}
+
+	if !resp.Pending && !resp.InReview {
+		if perr := s.publishProcessed(ctx, resp.Payment); perr != nil {
+			return nil, perr
+		}
+	}
+	if !resp.Success && !resp.Pending && !resp.InReview {
+		return resp, derrors.ErrPaymentDeclined
+	}
+	return resp, nil
+}
+
+// ListPaymentsInReview returns a page of payments held for risk review, oldest first
+func (s *PaymentService) ListPaymentsInReview(ctx context.Context, page, limit int) ([]*entities.Payment, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	return s.repo.GetByStatus(ctx, entities.PaymentInReview, page, limit)
+}
+
+type CapturePaymentResponse struct {
+	Payment *entities.Payment
+	Success bool
+	// Pending means the capture is in flight at the processor; the payment stays PROCESSING
+	// until the webhook reports the outcome
+	Pending bool
+	Message string
+}
 
-	// Record metrics based on result
-	if res.Success {
-		s.metrics.PaymentSucceeded(string(req.Method))
-	} else {
-		s.metrics.PaymentFailed(res.FailureReason)
+// CapturePayment collects the authorized amount of a payment. Capturing a captured payment is
+// a no-op and a capture interrupted by a technical error (PROCESSING) is resumed.
+func (s *PaymentService) CapturePayment(ctx context.Context, paymentID string) (*CapturePaymentResponse, error) {
+	payment, err := s.repo.Modify(ctx, paymentID, func(p *entities.Payment) error {
+		if p.Status == entities.PaymentProcessing || p.IsCaptured() {
+			return nil
+		}
+		return p.BeginCapture(s.now())
+	})
+	if err != nil {
+		return nil, err
+	}
+	if payment.IsCaptured() {
+		return &CapturePaymentResponse{Payment: payment, Success: true, Message: "Payment already captured"}, nil
+	}
+
+	res, err := s.processor.Capture(ctx, procport.CaptureRequest{
+		PaymentID:     payment.ID,
+		TransactionID: payment.TransactionID,
+		Amount:        payment.Amount.Amount,
+		Currency:      payment.Amount.Currency,
+	})
+	if err != nil {
+		s.metrics.PaymentFailed("capture_processor_error")
+		return nil, err
+	}
+	if res.Pending {
+		return &CapturePaymentResponse{Payment: payment, Pending: true, Message: "Payment capture pending"}, nil
+	}
+	payment, err = s.repo.Modify(ctx, paymentID, func(p *entities.Payment) error {
+		if res.Success {
+			if p.IsCaptured() {
+				return nil
+			}
+			return p.CompleteCapture(s.now())
+		}
+		return p.FailCapture(res.FailureReason, s.now())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save capture of %s: %w", paymentID, err)
 	}
-	s.metrics.PaymentProcessingDuration(time.Since(start), string(req.Method))
 
 	if !res.Success {
-		return &ProcessPaymentResponse{Payment: payment, Success: res.Success, Message: message}, derrors.ErrPaymentDeclined
+		s.metrics.PaymentFailed("capture_declined")
+		return &CapturePaymentResponse{Payment: payment, Success: false, Message: "Capture failed - " + res.FailureReason}, derrors.ErrCaptureDeclined
 	}
+	s.metrics.PaymentCaptured(string(payment.Method))
+	return &CapturePaymentResponse{Payment: payment, Success: true, Message: "Payment captured successfully"}, nil
+}
 
-	return &ProcessPaymentResponse{Payment: payment, Success: res.Success, Message: message}, nil
+type VoidPaymentResponse struct {
+	Payment *entities.Payment
+	Success bool
+	Message string
 }
 
-func (s *PaymentService) GetPayment(ctx context.Context, id string) (*entities.Payment, error) {
-	s.mu.RLock()
-	p := s.payments[id]
-	s.mu.RUnlock()
-	if p == nil {
-		return nil, derrors.ErrPaymentNotFound
+// VoidPayment releases the hold of an authorized payment; voiding a voided payment is a no-op
+func (s *PaymentService) VoidPayment(ctx context.Context, paymentID, reason string) (*VoidPaymentResponse, error) {
+	payment, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status == entities.PaymentVoided {
+		return &VoidPaymentResponse{Payment: payment, Success: true, Message: "Payment already voided"}, nil
+	}
+	if !payment.CanTransitionTo(entities.PaymentVoided) {
+		return nil, fmt.Errorf("%w: payment %s is %s, not authorized", derrors.ErrInvalidPaymentTransition, payment.ID, payment.Status)
+	}
+
+	res, err := s.processor.Void(ctx, procport.VoidRequest{PaymentID: payment.ID, TransactionID: payment.TransactionID})
+	if err != nil {
+		return nil, err
+	}
+	if !res.Success {
+		return &VoidPaymentResponse{Payment: payment, Success: false, Message: "Void failed - " + res.FailureReason}, derrors.ErrVoidDeclined
+	}
+	payment, err = s.repo.Modify(ctx, paymentID, func(p *entities.Payment) error {
+		if p.Status == entities.PaymentVoided {
+			return nil
+		}
+		return p.Void(reason, s.now())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save void of %s: %w", paymentID, err)
+	}
+	s.metrics.PaymentVoided(string(payment.Method))
+	return &VoidPaymentResponse{Payment: payment, Success: true, Message: "Payment voided successfully"}, nil
+}
+
+// LatestOrderPayment returns the most recent payment attempt of an order, or nil if there is none
+func (s *PaymentService) LatestOrderPayment(ctx context.Context, orderID string) (*entities.Payment, error) {
+	payments, err := s.repo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	var latest *entities.Payment
+	for _, p := range payments {
+		if latest == nil || p.CreatedAt.After(latest.CreatedAt) {
+			latest = p
+		}
+	}
+	return latest, nil
+}
+
+// CaptureOrderPayment captures the authorized payment of an order; orders without an
+// authorized payment are left alone. A declined capture returns ErrCaptureDeclined.
+func (s *PaymentService) CaptureOrderPayment(ctx context.Context, orderID string) (*entities.Payment, error) {
+	p, err := s.LatestOrderPayment(ctx, orderID)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	if p.Status != entities.PaymentAuthorized && p.Status != entities.PaymentProcessing {
+		return p, nil
+	}
+	resp, err := s.CapturePayment(ctx, p.ID)
+	if resp == nil {
+		return nil, err
+	}
+	return resp.Payment, err
+}
+
+// ReleaseOrderPayment gives the money of a cancelled order back: an authorization is voided
+// and a captured payment refunded. A payment held for risk review is rejected, and a PENDING
+// authorization is left to expire at the processor.
+func (s *PaymentService) ReleaseOrderPayment(ctx context.Context, orderID, reason string) (*entities.Payment, error) {
+	p, err := s.LatestOrderPayment(ctx, orderID)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	switch p.Status {
+	case entities.PaymentInReview:
+		return s.repo.Modify(ctx, p.ID, func(p *entities.Payment) error {
+			if p.Status != entities.PaymentInReview {
+				return nil
+			}
+			return p.RejectReview(reason, s.now())
+		})
+	case entities.PaymentAuthorized:
+		resp, err := s.VoidPayment(ctx, p.ID, reason)
+		if resp == nil {
+			return nil, err
+		}
+		return resp.Payment, err
+	case entities.PaymentProcessing:
+		// The capture may have reached the processor; finish it, then refund
+		if _, err := s.CapturePayment(ctx, p.ID); err != nil {
+			if errors.Is(err, derrors.ErrCaptureDeclined) {
+				return p, nil
+			}
+			return nil, err
+		}
+		fallthrough
+	case entities.PaymentCompleted, entities.PaymentPartiallyRefunded:
+		resp, err := s.RefundPayment(ctx, &RefundPaymentRequest{PaymentID: p.ID, Reason: reason})
+		if resp == nil {
+			return nil, err
+		}
+		return resp.Payment, err
 	}
 	return p, nil
 }
 
+type RefundPaymentRequest struct {
+	PaymentID string
+	// Amount to refund; nil refunds the remaining refundable amount
+	Amount *money.Money
+	Reason string
+}
+
+type RefundPaymentResponse struct {
+	Payment *entities.Payment
+	Refund  *entities.Refund
+	Success bool
+	// Pending means the refund is in flight at the processor; it stays PENDING, reserving
+	// its amount, until the webhook reports the outcome
+	Pending bool
+	Message string
+}
+
+// RefundPayment refunds a captured payment fully or partially.
+// The refund is reserved against the payment before calling the processor so concurrent
+// refunds can never exceed the captured amount. A technical processor error leaves the refund
+// PENDING; retrying the request sends the same refund again, and only a decline releases it.
+func (s *PaymentService) RefundPayment(ctx context.Context, req *RefundPaymentRequest) (*RefundPaymentResponse, error) {
+	var refund entities.Refund
+	payment, err := s.repo.Modify(ctx, req.PaymentID, func(p *entities.Payment) error {
+		if r := p.UnsentRefund(req.Amount, req.Reason); r != nil {
+			refund = *r
+			return nil
+		}
+		amount := money.Money{Amount: p.RefundableAmount(), Currency: p.Amount.Currency}
+		if req.Amount != nil {
+			amount = *req.Amount
+		}
+		r, err := p.RequestRefund(s.newID("rfd-"), amount, req.Reason, s.now())
+		if err != nil {
+			return err
+		}
+		refund = *r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res, perr := s.processor.Refund(ctx, procport.RefundRequest{
+		RefundID:      refund.ID,
+		TransactionID: payment.TransactionID,
+		Amount:        refund.Amount.Amount,
+		Currency:      refund.Amount.Currency,
+	})
+	if perr != nil {
+		// The processor may have executed the refund; it stays PENDING and reserved until a retry
+		// learns the outcome under the same refund ID
+		return nil, fmt.Errorf("refund %s pending: %w", refund.ID, perr)
+	}
+	if res.Pending {
+		awaiting, err := s.finalizeRefund(ctx, payment.ID, func(p *entities.Payment) (*entities.Refund, error) {
+			return p.AwaitRefund(refund.ID, res.TransactionID, s.now())
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to save refund %s: %w", refund.ID, err)
+		}
+		return &RefundPaymentResponse{Payment: awaiting.payment, Refund: awaiting.refund, Pending: true, Message: "Refund pending"}, nil
+	}
+	if !res.Success {
+		failed, err := s.finalizeRefund(ctx, payment.ID, func(p *entities.Payment) (*entities.Refund, error) {
+			return p.FailRefund(refund.ID, res.FailureReason, s.now())
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to release refund %s: %w", refund.ID, err)
+		}
+		s.metrics.RefundFailed(string(payment.Method), refundFailureLabel(res.DeclineCode))
+		return &RefundPaymentResponse{Payment: failed.payment, Refund: failed.refund, Success: false, Message: "Refund failed - " + res.FailureReason}, derrors.ErrRefundDeclined
+	}
+
+	done, err := s.finalizeRefund(ctx, payment.ID, func(p *entities.Payment) (*entities.Refund, error) {
+		return p.CompleteRefund(refund.ID, res.TransactionID, s.now())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete refund %s: %w", refund.ID, err)
+	}
+	s.metrics.PaymentRefunded(string(done.payment.Method))
+	s.publishRefunded(ctx, done.payment, done.refund)
+
+	return &RefundPaymentResponse{Payment: done.payment, Refund: done.refund, Success: true, Message: "Refund processed successfully"}, nil
+}
+
+type refundOutcome struct {
+	payment *entities.Payment
+	refund  *entities.Refund
+}
+
+func (s *PaymentService) finalizeRefund(ctx context.Context, paymentID string, fn func(*entities.Payment) (*entities.Refund, error)) (refundOutcome, error) {
+	var out refundOutcome
+	p, err := s.repo.Modify(ctx, paymentID, func(p *entities.Payment) error {
+		r, err := fn(p)
+		if err != nil {
+			return err
+		}
+		out.refund = r
+		return nil
+	})
+	if err != nil {
+		return refundOutcome{}, err
+	}
+	out.payment = p
+	return out, nil
+}
+
+func (s *PaymentService) publishRefunded(ctx context.Context, p *entities.Payment, r *entities.Refund) {
+	if s.pub == nil {
+		return
+	}
+	evt := &events.PaymentRefunded{
+		OrderId:       p.OrderID,
+		PaymentId:     p.ID,
+		RefundId:      r.ID,
+		Amount:        r.Amount.Amount,
+		Currency:      r.Amount.Currency,
+		RefundedTotal: p.RefundedAmount(),
+		FullyRefunded: p.Status == entities.PaymentRefunded,
+		Reason:        r.Reason,
+		OccurredAt:    s.now().Format(time.RFC3339),
+	}
+	if err := s.pub.PublishPaymentRefunded(ctx, evt); err != nil && s.logger != nil {
+		s.logger.Errorw("failed to publish PaymentRefunded", "paymentID", p.ID, "refundID", r.ID, "error", err)
+	}
+}
+
+func (s *PaymentService) GetPayment(ctx context.Context, id string) (*entities.Payment, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// GetOrderPayments returns all payment attempts for an order, newest first
+func (s *PaymentService) GetOrderPayments(ctx context.Context, orderID string) ([]*entities.Payment, error) {
+	return s.repo.GetByOrderID(ctx, orderID)
+}
+
+// GetUserPayments returns a page of user payments, newest first
+func (s *PaymentService) GetUserPayments(ctx context.Context, userID string, page, limit int) ([]*entities.Payment, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	return s.repo.GetByUserID(ctx, userID, page, limit)
+}
+
 func (s *PaymentService) now() time.Time { return time.Now() }
 
+// declineLabels are the decline codes counted under their own failure_reason label; gateways
+// may report any code
+var declineLabels = map[string]bool{
+	"insufficient_funds":         true,
+	"processing_error":           true,
+	"expired_card":               true,
+	"incorrect_number":           true,
+	"unsupported_payment_method": true,
+}
+
+// failureLabel keeps the failure_reason label of declined payments to a fixed set
+func failureLabel(res procport.AuthorizeResult) string {
+	switch {
+	case declineLabels[res.DeclineCode]:
+		return res.DeclineCode
+	case res.DeclineCode != "":
+		return "declined"
+	default:
+		return "processor_error"
+	}
+}
+
+// refundFailureLabel keeps the failure_reason label of refunds to the fixed decline codes
+func refundFailureLabel(code string) string {
+	if code == procport.RefundDeclineInvalidRequest {
+		return code
+	}
+	return procport.RefundDeclineRejected
+}
+
 func (s *PaymentService) newID(prefix string) string {
-	return prefix + time.Now().Format("20060102150405")
+	return prefix + uuid.New().String()
 }
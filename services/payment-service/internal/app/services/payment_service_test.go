@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/entities"
+	derrors "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/errors"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/metrics"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/ports/orderinfo"
+	procport "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/ports/processor"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/ports/repository"
+)
+
+func TestAuthorizeOrderPaymentRefusesOrdersNotAwaitingPayment(t *testing.T) {
+	for _, status := range []string{"CANCELLED", "CONFIRMED", "SHIPPED"} {
+		t.Run(status, func(t *testing.T) {
+			orders := &fakeOrders{order: testOrder(status)}
+			proc := &fakeProcessor{}
+			repo := newMemoryPaymentRepo()
+			svc := NewPaymentService(proc, repo, nopMetrics{}).WithOrders(orders)
+
+			_, err := svc.AuthorizeOrderPayment(context.Background(), "ORD-1", "user-1")
+			if !errors.Is(err, derrors.ErrOrderNotPayable) {
+				t.Fatalf("AuthorizeOrderPayment: %v, want %v", err, derrors.ErrOrderNotPayable)
+			}
+			if n := repo.count(); n != 0 || proc.authorizations() != 0 {
+				t.Errorf("%d payments recorded, %d authorizations, want none", n, proc.authorizations())
+			}
+		})
+	}
+
+	t.Run("cancelled before a redelivery", func(t *testing.T) {
+		ctx := context.Background()
+		orders := &fakeOrders{order: testOrder("PENDING")}
+		proc := &fakeProcessor{err: errors.New("processor unavailable")}
+		svc := NewPaymentService(proc, newMemoryPaymentRepo(), nopMetrics{}).WithOrders(orders)
+
+		// the first delivery of StockReserved is interrupted and leaves the payment PENDING
+		if _, err := svc.AuthorizeOrderPayment(ctx, "ORD-1", "user-1"); err == nil {
+			t.Fatal("AuthorizeOrderPayment succeeded, want the processor error")
+		}
+		orders.setStatus("CANCELLED")
+		proc.fail(nil)
+
+		_, err := svc.AuthorizeOrderPayment(ctx, "ORD-1", "user-1")
+		if !errors.Is(err, derrors.ErrOrderNotPayable) {
+			t.Fatalf("redelivery: %v, want %v", err, derrors.ErrOrderNotPayable)
+		}
+		if n := proc.authorizations(); n != 1 {
+			t.Errorf("processor asked %d times to authorize, want only the interrupted attempt", n)
+		}
+	})
+}
+
+func testOrder(status string) orderinfo.OrderPayment {
+	return orderinfo.OrderPayment{OrderID: "ORD-1", UserID: "user-1", Amount: 3000, Currency: "EUR", Method: "CREDIT_CARD", Status: status}
+}
+
+type fakeOrders struct {
+	mu    sync.Mutex
+	order orderinfo.OrderPayment
+}
+
+func (f *fakeOrders) GetOrderPayment(_ context.Context, orderID string) (*orderinfo.OrderPayment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if orderID != f.order.OrderID {
+		return nil, derrors.ErrOrderNotFound
+	}
+	op := f.order
+	return &op, nil
+}
+
+func (f *fakeOrders) setStatus(status string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.order.Status = status
+}
+
+// fakeProcessor authorizes every payment unless told to fail
+type fakeProcessor struct {
+	procport.PaymentProcessor
+	mu    sync.Mutex
+	err   error
+	calls int
+}
+
+func (p *fakeProcessor) Authorize(_ context.Context, req procport.AuthorizeRequest) (procport.AuthorizeResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.err != nil {
+		return procport.AuthorizeResult{}, p.err
+	}
+	return procport.AuthorizeResult{Success: true, TransactionID: "txn-" + req.PaymentID}, nil
+}
+
+func (p *fakeProcessor) fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+
+func (p *fakeProcessor) authorizations() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls
+}
+
+// memoryPaymentRepo implements the parts of repository.PaymentRepository authorizations use
+type memoryPaymentRepo struct {
+	repository.PaymentRepository
+	mu       sync.Mutex
+	payments map[string]entities.Payment
+}
+
+func newMemoryPaymentRepo() *memoryPaymentRepo {
+	return &memoryPaymentRepo{payments: make(map[string]entities.Payment)}
+}
+
+func (r *memoryPaymentRepo) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.payments)
+}
+
+func (r *memoryPaymentRepo) Create(_ context.Context, p *entities.Payment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.payments[p.ID] = *p
+	return nil
+}
+
+func (r *memoryPaymentRepo) GetByID(_ context.Context, id string) (*entities.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.payments[id]
+	if !ok {
+		return nil, derrors.ErrPaymentNotFound
+	}
+	return &p, nil
+}
+
+func (r *memoryPaymentRepo) GetByOrderID(_ context.Context, orderID string) ([]*entities.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*entities.Payment
+	for _, p := range r.payments {
+		if p.OrderID == orderID {
+			p := p
+			out = append(out, &p)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryPaymentRepo) Modify(_ context.Context, id string, fn func(*entities.Payment) error) (*entities.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.payments[id]
+	if !ok {
+		return nil, derrors.ErrPaymentNotFound
+	}
+	if err := fn(&p); err != nil {
+		return nil, err
+	}
+	r.payments[id] = p
+	return &p, nil
+}
+
+// nopMetrics discards the metrics authorizations record
+type nopMetrics struct{ metrics.PaymentMetrics }
+
+func (nopMetrics) PaymentSucceeded(string)                         {}
+func (nopMetrics) PaymentFailed(string)                            {}
+func (nopMetrics) PaymentProcessingDuration(time.Duration, string) {}
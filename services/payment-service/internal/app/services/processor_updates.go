@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	events "github.com/kubernetestest/ecommerce-platform/proto-go/events"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/entities"
+	derrors "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/errors"
+	procport "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/ports/processor"
+)
+
+// ApplyProcessorUpdate moves a payment to the outcome the processor reported asynchronously.
+// Updates are idempotent: an outcome already recorded is not applied twice, and updates that
+// no longer fit the payment (e.g. a late authorization of a voided payment) are ignored.
+// The authorization outcome is published as PaymentProcessed every time it is reported, so
+// a failed publish can be retried by redelivering the update; consumers dedupe by payment.
+// Unknown payments and refunds return ErrPaymentNotFound / ErrRefundNotFound: the update
+// may have overtaken the call that started it and should be redelivered.
+func (s *PaymentService) ApplyProcessorUpdate(ctx context.Context, u procport.Update) (*entities.Payment, error) {
+	payment, err := s.repo.GetByTransactionID(ctx, u.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Kind {
+	case procport.UpdateAuthorized, procport.UpdateAuthorizationFailed, procport.UpdateVoided:
+		return s.applyAuthorizationUpdate(ctx, payment.ID, u)
+	case procport.UpdateCaptured, procport.UpdateCaptureFailed:
+		return s.applyCaptureUpdate(ctx, payment.ID, u)
+	case procport.UpdateRefunded, procport.UpdateRefundFailed:
+		return s.applyRefundUpdate(ctx, payment.ID, u)
+	}
+	return nil, fmt.Errorf("unknown processor update %q", u.Kind)
+}
+
+func (s *PaymentService) applyAuthorizationUpdate(ctx context.Context, paymentID string, u procport.Update) (*entities.Payment, error) {
+	var before entities.PaymentStatus
+	payment, err := s.repo.Modify(ctx, paymentID, func(p *entities.Payment) error {
+		before = p.Status
+		switch {
+		case u.Kind == procport.UpdateAuthorized && p.Status == entities.PaymentPending:
+			return p.Authorize(p.TransactionID, s.now())
+		case u.Kind == procport.UpdateAuthorizationFailed && p.Status == entities.PaymentPending:
+			return p.Decline(u.FailureReason, s.now())
+		case u.Kind == procport.UpdateVoided && p.Status == entities.PaymentPending:
+			// Canceled before it was decided
+			return p.Decline(voidReason(u), s.now())
+		case u.Kind == procport.UpdateVoided && p.Status == entities.PaymentAuthorized:
+			return p.Void(voidReason(u), s.now())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != before {
+		switch payment.Status {
+		case entities.PaymentAuthorized:
+			s.metrics.PaymentSucceeded(string(payment.Method))
+		case entities.PaymentFailed:
+			s.metrics.PaymentFailed(failureLabel(procport.AuthorizeResult{FailureReason: u.FailureReason, DeclineCode: u.DeclineCode}))
+		case entities.PaymentVoided:
+			s.metrics.PaymentVoided(string(payment.Method))
+		}
+	}
+	// Publish only the outcome this update reports; a cancellation is one only before the
+	// authorization was decided
+	failed := payment.Status == entities.PaymentFailed
+	reported := (u.Kind == procport.UpdateAuthorized && !failed && payment.Status != entities.PaymentPending) ||
+		(u.Kind == procport.UpdateAuthorizationFailed && failed) ||
+		(u.Kind == procport.UpdateVoided && failed && before == entities.PaymentPending)
+	if !reported {
+		return payment, nil
+	}
+	if err := s.publishProcessed(ctx, payment); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+func (s *PaymentService) applyCaptureUpdate(ctx context.Context, paymentID string, u procport.Update) (*entities.Payment, error) {
+	var before entities.PaymentStatus
+	payment, err := s.repo.Modify(ctx, paymentID, func(p *entities.Payment) error {
+		before = p.Status
+		if u.Kind == procport.UpdateCaptureFailed {
+			if p.Status != entities.PaymentProcessing {
+				return nil
+			}
+			return p.FailCapture(u.FailureReason, s.now())
+		}
+		if p.Status == entities.PaymentAuthorized {
+			// Captured at the processor directly, or the capture call was lost
+			if err := p.BeginCapture(s.now()); err != nil {
+				return err
+			}
+		}
+		if p.Status != entities.PaymentProcessing {
+			return nil
+		}
+		return p.CompleteCapture(s.now())
+	})
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != before {
+		if payment.Status == entities.PaymentFailed {
+			s.metrics.PaymentFailed("capture_declined")
+		} else {
+			s.metrics.PaymentCaptured(string(payment.Method))
+		}
+	}
+	return payment, nil
+}
+
+func (s *PaymentService) applyRefundUpdate(ctx context.Context, paymentID string, u procport.Update) (*entities.Payment, error) {
+	var changed *entities.Refund
+	payment, err := s.repo.Modify(ctx, paymentID, func(p *entities.Payment) error {
+		changed = nil
+		r := p.RefundByTransactionID(u.RefundTransactionID)
+		if r == nil || u.RefundTransactionID == "" {
+			return fmt.Errorf("%w: processor refund %s of payment %s", derrors.ErrRefundNotFound, u.RefundTransactionID, p.ID)
+		}
+		if r.Status != entities.RefundPending {
+			return nil
+		}
+		var err error
+		if u.Kind == procport.UpdateRefunded {
+			changed, err = p.CompleteRefund(r.ID, u.RefundTransactionID, s.now())
+		} else {
+			changed, err = p.FailRefund(r.ID, u.FailureReason, s.now())
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if changed == nil {
+		return payment, nil
+	}
+	if changed.Status == entities.RefundFailed {
+		s.metrics.RefundFailed(string(payment.Method), refundFailureLabel(u.DeclineCode))
+		return payment, nil
+	}
+	s.metrics.PaymentRefunded(string(payment.Method))
+	s.publishRefunded(ctx, payment, changed)
+	return payment, nil
+}
+
+// publishProcessed publishes the authorization outcome of a decided payment
+func (s *PaymentService) publishProcessed(ctx context.Context, p *entities.Payment) error {
+	if s.pub == nil {
+		return nil
+	}
+	outcome := authorizationOutcome(p)
+	evt := &events.PaymentProcessed{
+		OrderId:    p.OrderID,
+		PaymentId:  p.ID,
+		Success:    outcome.Success,
+		Message:    outcome.Message,
+		Amount:     p.Amount.Amount,
+		Currency:   p.Amount.Currency,
+		OccurredAt: s.now().Format(time.RFC3339),
+	}
+	if err := s.pub.PublishPaymentProcessed(ctx, evt); err != nil {
+		return fmt.Errorf("publish PaymentProcessed of %s: %w", p.ID, err)
+	}
+	return nil
+}
+
+func voidReason(u procport.Update) string {
+	if u.FailureReason != "" {
+		return u.FailureReason
+	}
+	return "canceled by processor"
+}
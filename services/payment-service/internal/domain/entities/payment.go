@@ -1,32 +1,362 @@
 package entities
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
-	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/valueobjects"
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
+	derrors "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/errors"
 )
 
 type PaymentStatus string
 
+// A payment is authorized first and captured or voided later:
+// PENDING -> AUTHORIZED -> PROCESSING (capture in flight) -> COMPLETED -> (PARTIALLY_)REFUNDED.
+// Declined authorizations and captures end in FAILED, released authorizations in VOIDED.
+// Payments the risk rules flag wait IN_REVIEW before they are authorized (back to PENDING)
+// or rejected (FAILED).
 const (
-	PaymentCompleted PaymentStatus = "COMPLETED"
-	PaymentFailed    PaymentStatus = "FAILED"
+	PaymentPending           PaymentStatus = "PENDING"
+	PaymentInReview          PaymentStatus = "IN_REVIEW"
+	PaymentAuthorized        PaymentStatus = "AUTHORIZED"
+	PaymentProcessing        PaymentStatus = "PROCESSING"
+	PaymentCompleted         PaymentStatus = "COMPLETED"
+	PaymentFailed            PaymentStatus = "FAILED"
+	PaymentVoided            PaymentStatus = "VOIDED"
+	PaymentPartiallyRefunded PaymentStatus = "PARTIALLY_REFUNDED"
+	PaymentRefunded          PaymentStatus = "REFUNDED"
 )
 
 type PaymentMethod string
 
 const (
-	MethodCreditCard PaymentMethod = "CREDIT_CARD"
+	MethodCreditCard   PaymentMethod = "CREDIT_CARD"
+	MethodDebitCard    PaymentMethod = "DEBIT_CARD"
+	MethodPayPal       PaymentMethod = "PAYPAL"
+	MethodBankTransfer PaymentMethod = "BANK_TRANSFER"
 )
 
 type Payment struct {
 	ID            string
 	OrderID       string
 	UserID        string
-	Amount        valueobjects.Money
+	Amount        money.Money
 	Status        PaymentStatus
 	Method        PaymentMethod
 	TransactionID string
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
+	// PaymentMethodID is the saved card (vault token) charged, empty for one-off cards
+	PaymentMethodID string
+	// StatusReason explains a FAILED or VOIDED payment
+	StatusReason string
+	// ShippingCountry (ISO 3166-1 alpha-2) of the order and CardFingerprint of the card
+	// charged feed the risk rules; both may be empty
+	ShippingCountry string
+	CardFingerprint string
+	// RiskDecision is empty until the risk rules evaluated the payment; RiskReasons lists
+	// the rules that flagged it
+	RiskDecision RiskDecision
+	RiskReasons  []string
+	Refunds      []Refund
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// NewPayment creates a pending payment, not yet authorized by the processor
+func NewPayment(id, orderID, userID string, amount money.Money, method PaymentMethod, now time.Time) *Payment {
+	return &Payment{
+		ID:        id,
+		OrderID:   orderID,
+		UserID:    userID,
+		Amount:    amount,
+		Status:    PaymentPending,
+		Method:    method,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Authorize records that the processor holds the amount under transactionID
+func (p *Payment) Authorize(transactionID string, now time.Time) error {
+	if err := p.transitionTo(PaymentAuthorized, now); err != nil {
+		return err
+	}
+	p.TransactionID = transactionID
+	return nil
+}
+
+// AwaitAuthorization records the processor reference of an authorization the processor
+// decides asynchronously; the payment stays PENDING until the outcome arrives
+func (p *Payment) AwaitAuthorization(transactionID string, now time.Time) error {
+	if p.Status != PaymentPending {
+		return p.invalidTransition(PaymentPending)
+	}
+	p.TransactionID = transactionID
+	p.UpdatedAt = now
+	return nil
+}
+
+// ApplyRisk records the risk evaluation of a pending payment: a review holds it IN_REVIEW
+// and a deny declines it
+func (p *Payment) ApplyRisk(decision RiskDecision, reasons []string, now time.Time) error {
+	if p.Status != PaymentPending {
+		return p.invalidTransition(PaymentPending)
+	}
+	switch decision {
+	case RiskReview:
+		if err := p.transitionTo(PaymentInReview, now); err != nil {
+			return err
+		}
+	case RiskDeny:
+		reason := "denied by risk rules"
+		if len(reasons) > 0 {
+			reason += ": " + strings.Join(reasons, "; ")
+		}
+		if err := p.Decline(reason, now); err != nil {
+			return err
+		}
+	default:
+		p.UpdatedAt = now
+	}
+	p.RiskDecision = decision
+	p.RiskReasons = reasons
+	return nil
+}
+
+// ApproveReview releases a payment held for review so it can be authorized
+func (p *Payment) ApproveReview(now time.Time) error {
+	if p.Status != PaymentInReview {
+		return p.invalidTransition(PaymentPending)
+	}
+	return p.transitionTo(PaymentPending, now)
+}
+
+// RejectReview declines a payment held for review
+func (p *Payment) RejectReview(reason string, now time.Time) error {
+	if p.Status != PaymentInReview {
+		return p.invalidTransition(PaymentFailed)
+	}
+	if err := p.transitionTo(PaymentFailed, now); err != nil {
+		return err
+	}
+	p.StatusReason = reason
+	return nil
+}
+
+// Decline records that the processor refused the authorization
+func (p *Payment) Decline(reason string, now time.Time) error {
+	if p.Status != PaymentPending {
+		return p.invalidTransition(PaymentFailed)
+	}
+	if err := p.transitionTo(PaymentFailed, now); err != nil {
+		return err
+	}
+	p.StatusReason = reason
+	return nil
+}
+
+// BeginCapture marks the capture of an authorized payment as in flight
+func (p *Payment) BeginCapture(now time.Time) error {
+	return p.transitionTo(PaymentProcessing, now)
+}
+
+// CompleteCapture records that the authorized amount has been collected
+func (p *Payment) CompleteCapture(now time.Time) error {
+	return p.transitionTo(PaymentCompleted, now)
+}
+
+// FailCapture records that the processor refused to capture the authorization
+func (p *Payment) FailCapture(reason string, now time.Time) error {
+	if p.Status != PaymentProcessing {
+		return p.invalidTransition(PaymentFailed)
+	}
+	if err := p.transitionTo(PaymentFailed, now); err != nil {
+		return err
+	}
+	p.StatusReason = reason
+	return nil
+}
+
+// Void records that the authorization has been released without capture
+func (p *Payment) Void(reason string, now time.Time) error {
+	if err := p.transitionTo(PaymentVoided, now); err != nil {
+		return err
+	}
+	p.StatusReason = reason
+	return nil
+}
+
+// IsCaptured reports whether the amount has been collected (refunds may have followed)
+func (p *Payment) IsCaptured() bool {
+	return p.Status == PaymentCompleted || p.Status == PaymentPartiallyRefunded || p.Status == PaymentRefunded
+}
+
+// CanTransitionTo reports whether the payment may move to status
+func (p *Payment) CanTransitionTo(status PaymentStatus) bool {
+	transitions := map[PaymentStatus][]PaymentStatus{
+		PaymentPending:           {PaymentAuthorized, PaymentFailed, PaymentInReview},
+		PaymentInReview:          {PaymentPending, PaymentFailed},
+		PaymentAuthorized:        {PaymentProcessing, PaymentVoided},
+		PaymentProcessing:        {PaymentCompleted, PaymentFailed},
+		PaymentCompleted:         {PaymentPartiallyRefunded, PaymentRefunded},
+		PaymentPartiallyRefunded: {PaymentPartiallyRefunded, PaymentRefunded},
+		PaymentFailed:            {},
+		PaymentVoided:            {},
+		PaymentRefunded:          {},
+	}
+	allowed, ok := transitions[p.Status]
+	if !ok {
+		return false
+	}
+	for _, st := range allowed {
+		if st == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Payment) transitionTo(status PaymentStatus, now time.Time) error {
+	if !p.CanTransitionTo(status) {
+		return p.invalidTransition(status)
+	}
+	p.Status = status
+	p.UpdatedAt = now
+	return nil
+}
+
+func (p *Payment) invalidTransition(status PaymentStatus) error {
+	return fmt.Errorf("%w: %s -> %s", derrors.ErrInvalidPaymentTransition, p.Status, status)
+}
+
+// RefundedAmount returns the sum of succeeded refunds in minor units
+func (p *Payment) RefundedAmount() int64 {
+	var total int64
+	for _, r := range p.Refunds {
+		if r.Status == RefundSucceeded {
+			total += r.Amount.Amount
+		}
+	}
+	return total
+}
+
+// RefundableAmount returns captured amount not yet refunded or reserved by pending refunds
+func (p *Payment) RefundableAmount() int64 {
+	reserved := int64(0)
+	for _, r := range p.Refunds {
+		if r.Status == RefundSucceeded || r.Status == RefundPending {
+			reserved += r.Amount.Amount
+		}
+	}
+	return p.Amount.Amount - reserved
+}
+
+// RequestRefund registers a pending refund; pending refunds count against the captured amount
+func (p *Payment) RequestRefund(id string, amount money.Money, reason string, now time.Time) (*Refund, error) {
+	if p.Status != PaymentCompleted && p.Status != PaymentPartiallyRefunded {
+		return nil, derrors.ErrPaymentNotRefundable
+	}
+	if !amount.SameCurrency(p.Amount) {
+		return nil, derrors.ErrRefundCurrencyMismatch
+	}
+	if !amount.IsPositive() {
+		return nil, derrors.ErrInvalidRefundAmount
+	}
+	if amount.Amount > p.RefundableAmount() {
+		return nil, derrors.ErrRefundExceedsCaptured
+	}
+	p.Refunds = append(p.Refunds, Refund{
+		ID:        id,
+		PaymentID: p.ID,
+		Amount:    amount,
+		Reason:    reason,
+		Status:    RefundPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	p.UpdatedAt = now
+	return &p.Refunds[len(p.Refunds)-1], nil
+}
+
+// UnsentRefund returns a pending refund of the amount and reason the processor has not
+// acknowledged yet (no processor reference), or nil; amount nil matches any amount. A retried
+// refund is sent again under its ID, so the processor deduplicates it instead of paying twice.
+func (p *Payment) UnsentRefund(amount *money.Money, reason string) *Refund {
+	for i := range p.Refunds {
+		r := &p.Refunds[i]
+		if r.Status != RefundPending || r.TransactionID != "" || r.Reason != reason {
+			continue
+		}
+		if amount == nil || (amount.Amount == r.Amount.Amount && amount.SameCurrency(r.Amount)) {
+			return r
+		}
+	}
+	return nil
+}
+
+// CompleteRefund marks a pending refund as succeeded and updates payment status
+func (p *Payment) CompleteRefund(refundID, transactionID string, now time.Time) (*Refund, error) {
+	r, err := p.pendingRefund(refundID)
+	if err != nil {
+		return nil, err
+	}
+	next := PaymentPartiallyRefunded
+	if p.RefundedAmount()+r.Amount.Amount >= p.Amount.Amount {
+		next = PaymentRefunded
+	}
+	if err := p.transitionTo(next, now); err != nil {
+		return nil, err
+	}
+	r.Status = RefundSucceeded
+	r.TransactionID = transactionID
+	r.UpdatedAt = now
+	return r, nil
+}
+
+// AwaitRefund records the processor reference of a refund the processor completes
+// asynchronously; the refund stays PENDING until the outcome arrives
+func (p *Payment) AwaitRefund(refundID, transactionID string, now time.Time) (*Refund, error) {
+	r, err := p.pendingRefund(refundID)
+	if err != nil {
+		return nil, err
+	}
+	r.TransactionID = transactionID
+	r.UpdatedAt = now
+	p.UpdatedAt = now
+	return r, nil
+}
+
+// RefundByTransactionID returns the refund the processor knows as transactionID, or nil
+func (p *Payment) RefundByTransactionID(transactionID string) *Refund {
+	for i := range p.Refunds {
+		if p.Refunds[i].TransactionID == transactionID {
+			return &p.Refunds[i]
+		}
+	}
+	return nil
+}
+
+// FailRefund marks a pending refund as failed, releasing its amount
+func (p *Payment) FailRefund(refundID, reason string, now time.Time) (*Refund, error) {
+	r, err := p.pendingRefund(refundID)
+	if err != nil {
+		return nil, err
+	}
+	r.Status = RefundFailed
+	r.FailureReason = reason
+	r.UpdatedAt = now
+	p.UpdatedAt = now
+	return r, nil
+}
+
+func (p *Payment) pendingRefund(refundID string) (*Refund, error) {
+	for i := range p.Refunds {
+		if p.Refunds[i].ID == refundID {
+			if p.Refunds[i].Status != RefundPending {
+				return nil, derrors.ErrRefundNotPending
+			}
+			return &p.Refunds[i], nil
+		}
+	}
+	return nil, derrors.ErrRefundNotFound
 }
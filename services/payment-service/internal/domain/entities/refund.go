@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
+)
+
+type RefundStatus string
+
+const (
+	RefundPending   RefundStatus = "PENDING"
+	RefundSucceeded RefundStatus = "SUCCEEDED"
+	RefundFailed    RefundStatus = "FAILED"
+)
+
+// Refund is a full or partial return of a captured payment amount
+type Refund struct {
+	ID            string
+	PaymentID     string
+	Amount        money.Money
+	Reason        string
+	Status        RefundStatus
+	TransactionID string
+	FailureReason string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
@@ -0,0 +1,23 @@
+package entities
+
+// RiskDecision is the outcome of the risk evaluation of a payment, from least to most severe
+type RiskDecision string
+
+const (
+	RiskAllow  RiskDecision = "ALLOW"
+	RiskReview RiskDecision = "REVIEW"
+	RiskDeny   RiskDecision = "DENY"
+)
+
+// Severity orders decisions; unknown decisions (including unevaluated) rank lowest
+func (d RiskDecision) Severity() int {
+	switch d {
+	case RiskAllow:
+		return 1
+	case RiskReview:
+		return 2
+	case RiskDeny:
+		return 3
+	}
+	return 0
+}
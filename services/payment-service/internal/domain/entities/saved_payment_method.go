@@ -0,0 +1,121 @@
+package entities
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	derrors "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/errors"
+)
+
+// Card brands recognized from the card number prefix
+const (
+	BrandVisa       = "visa"
+	BrandMastercard = "mastercard"
+	BrandAmex       = "amex"
+	BrandDiscover   = "discover"
+	BrandUnknown    = "unknown"
+)
+
+// SavedPaymentMethod is a card stored in the vault for a user. Only the display details are
+// held here; the card number is kept encrypted by the vault and referenced by ID (the token).
+type SavedPaymentMethod struct {
+	ID          string
+	UserID      string
+	Method      PaymentMethod
+	Brand       string
+	Last4       string
+	ExpiryMonth int
+	ExpiryYear  int
+	CardHolder  string
+	// BillingCountry is the ISO 3166-1 alpha-2 country of the card, empty if not given
+	BillingCountry string
+	// Fingerprint identifies the card across users without revealing it; set by the vault
+	Fingerprint string
+	CreatedAt   time.Time
+}
+
+// CardDetails is a card as entered by the user
+type CardDetails struct {
+	Number      string
+	Holder      string
+	ExpiryMonth string // MM
+	ExpiryYear  string // YY or YYYY
+	CVV         string
+	// BillingCountry is the ISO 3166-1 alpha-2 country of the card, optional
+	BillingCountry string
+}
+
+// NewSavedCard validates card and creates the saved method of userID for it.
+// The CVV must be present but is not kept.
+func NewSavedCard(id, userID string, method PaymentMethod, card CardDetails, now time.Time) (*SavedPaymentMethod, error) {
+	if method != MethodCreditCard && method != MethodDebitCard {
+		return nil, fmt.Errorf("%w: only cards can be saved, not %s", derrors.ErrInvalidCard, method)
+	}
+	number := NormalizeCardNumber(card.Number)
+	if len(number) < 12 || len(number) > 19 || strings.Trim(number, "0123456789") != "" {
+		return nil, fmt.Errorf("%w: card number must be 12 to 19 digits", derrors.ErrInvalidCard)
+	}
+	month, err := strconv.Atoi(card.ExpiryMonth)
+	if err != nil || month < 1 || month > 12 {
+		return nil, fmt.Errorf("%w: expiry month must be 01 to 12", derrors.ErrInvalidCard)
+	}
+	year, err := strconv.Atoi(card.ExpiryYear)
+	if err != nil || (len(card.ExpiryYear) != 2 && len(card.ExpiryYear) != 4) {
+		return nil, fmt.Errorf("%w: expiry year must be YY or YYYY", derrors.ErrInvalidCard)
+	}
+	if len(card.ExpiryYear) == 2 {
+		year += 2000
+	}
+	// A card is valid through the last day of its expiry month
+	if !now.Before(time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, time.UTC)) {
+		return nil, fmt.Errorf("%w: card expired", derrors.ErrInvalidCard)
+	}
+	if len(card.CVV) < 3 || len(card.CVV) > 4 || strings.Trim(card.CVV, "0123456789") != "" {
+		return nil, fmt.Errorf("%w: CVV must be 3 or 4 digits", derrors.ErrInvalidCard)
+	}
+	country := strings.ToUpper(strings.TrimSpace(card.BillingCountry))
+	if country != "" && (len(country) != 2 || strings.Trim(country, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "") {
+		return nil, fmt.Errorf("%w: billing country must be an ISO 3166-1 alpha-2 code", derrors.ErrInvalidCard)
+	}
+	return &SavedPaymentMethod{
+		ID:             id,
+		UserID:         userID,
+		Method:         method,
+		Brand:          CardBrand(number),
+		Last4:          number[len(number)-4:],
+		ExpiryMonth:    month,
+		ExpiryYear:     year,
+		CardHolder:     strings.TrimSpace(card.Holder),
+		BillingCountry: country,
+		CreatedAt:      now,
+	}, nil
+}
+
+// NormalizeCardNumber strips the spaces and dashes users type between digit groups
+func NormalizeCardNumber(number string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(number)
+}
+
+// CardBrand recognizes the card network from the number prefix
+func CardBrand(number string) string {
+	prefix := func(n int) int {
+		if len(number) < n {
+			return -1
+		}
+		v, _ := strconv.Atoi(number[:n])
+		return v
+	}
+	switch {
+	case strings.HasPrefix(number, "4"):
+		return BrandVisa
+	case prefix(2) >= 51 && prefix(2) <= 55, prefix(4) >= 2221 && prefix(4) <= 2720:
+		return BrandMastercard
+	case prefix(2) == 34, prefix(2) == 37:
+		return BrandAmex
+	case prefix(4) == 6011, prefix(2) == 65:
+		return BrandDiscover
+	}
+	return BrandUnknown
+}
@@ -7,4 +7,35 @@ var (
 	ErrPaymentDeclined = errors.New("payment declined")
 	// ErrPaymentNotFound indicates that payment with given id does not exist
 	ErrPaymentNotFound = errors.New("payment not found")
+	// ErrOrderNotFound indicates that the order to charge is unknown to order-service
+	ErrOrderNotFound = errors.New("order not found")
+	// ErrOrderNotPayable indicates that the order to charge is no longer awaiting payment, e.g. cancelled
+	ErrOrderNotPayable = errors.New("order is not awaiting payment")
+	// ErrInvalidPaymentAmount indicates a zero, negative or currency-less amount to charge
+	ErrInvalidPaymentAmount = errors.New("payment amount must be positive")
+	// ErrPaymentMethodNotFound indicates that the saved payment method (vault token) is unknown to the user
+	ErrPaymentMethodNotFound = errors.New("payment method not found")
+	// ErrInvalidCard indicates card details that cannot be saved (malformed number, expired card, ...)
+	ErrInvalidCard = errors.New("invalid card")
+	// ErrInvalidPaymentTransition indicates that payment status does not allow the requested step
+	ErrInvalidPaymentTransition = errors.New("invalid payment status transition")
+	// ErrCaptureDeclined indicates that the processor refused to capture an authorization
+	ErrCaptureDeclined = errors.New("capture declined")
+	// ErrVoidDeclined indicates that the processor refused to release an authorization
+	ErrVoidDeclined = errors.New("void declined")
+
+	// ErrPaymentNotRefundable indicates that payment is not in a state that allows refunds
+	ErrPaymentNotRefundable = errors.New("payment is not refundable")
+	// ErrInvalidRefundAmount indicates a non-positive refund amount
+	ErrInvalidRefundAmount = errors.New("refund amount must be positive")
+	// ErrRefundCurrencyMismatch indicates that refund currency differs from payment currency
+	ErrRefundCurrencyMismatch = errors.New("refund currency does not match payment currency")
+	// ErrRefundExceedsCaptured indicates that refunds would exceed the captured amount
+	ErrRefundExceedsCaptured = errors.New("refund exceeds captured amount")
+	// ErrRefundNotFound indicates that refund with given id does not exist on the payment
+	ErrRefundNotFound = errors.New("refund not found")
+	// ErrRefundNotPending indicates that refund has already been finalized
+	ErrRefundNotPending = errors.New("refund is not pending")
+	// ErrRefundDeclined indicates that the processor rejected the refund
+	ErrRefundDeclined = errors.New("refund declined")
 )
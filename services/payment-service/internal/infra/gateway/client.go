@@ -0,0 +1,211 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Payment intent statuses; refunds use pending, succeeded and failed. Processing (and pending
+// refunds) are decided asynchronously and reported by webhook.
+const (
+	StatusProcessing      = "processing"
+	StatusRequiresCapture = "requires_capture"
+	StatusSucceeded       = "succeeded"
+	StatusCanceled        = "canceled"
+	StatusFailed          = "failed"
+	StatusPending         = "pending"
+)
+
+// Error types returned by the gateway
+const (
+	ErrorTypeCard           = "card_error"
+	ErrorTypeInvalidRequest = "invalid_request_error"
+	ErrorTypeAPI            = "api_error"
+)
+
+// PaymentIntent is an authorization of an amount on a card, captured or voided later
+type PaymentIntent struct {
+	ID               string    `json:"id"`
+	Amount           int64     `json:"amount"`            // minor units
+	AmountCapturable int64     `json:"amount_capturable"` // minor units
+	AmountReceived   int64     `json:"amount_received"`   // minor units
+	Currency         string    `json:"currency"`
+	Status           string    `json:"status"`
+	Reference        string    `json:"reference,omitempty"`
+	LastPaymentError *APIError `json:"last_payment_error,omitempty"`
+}
+
+// Refund returns captured funds of a payment intent
+type Refund struct {
+	ID            string `json:"id"`
+	PaymentIntent string `json:"payment_intent"`
+	Amount        int64  `json:"amount"` // minor units
+	Currency      string `json:"currency"`
+	Status        string `json:"status"`
+	FailureReason string `json:"failure_reason,omitempty"`
+}
+
+// APIError is an error response of the gateway; card errors are declines
+type APIError struct {
+	Type    string `json:"type"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+	// PaymentIntent is the ID of the payment intent a card error declined
+	PaymentIntent string `json:"payment_intent,omitempty"`
+	HTTPStatus    int    `json:"-"`
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("gateway %s (%s): %s", e.Type, e.Code, e.Message)
+	}
+	return fmt.Sprintf("gateway %s: %s", e.Type, e.Message)
+}
+
+// IsCardError reports whether the card was declined
+func (e *APIError) IsCardError() bool { return e.Type == ErrorTypeCard }
+
+type errorResponse struct {
+	Error *APIError `json:"error"`
+}
+
+// AuthorizeParams describe an amount to hold on a card
+type AuthorizeParams struct {
+	Amount         int64 // minor units
+	Currency       string
+	CardNumber     string
+	Reference      string
+	IdempotencyKey string
+}
+
+// RefundParams describe an amount to return from a captured payment intent
+type RefundParams struct {
+	PaymentIntent  string
+	Amount         int64 // minor units
+	IdempotencyKey string
+}
+
+// ClientConfig holds gateway endpoint and credentials
+type ClientConfig struct {
+	BaseURL string
+	APIKey  string
+	Timeout time.Duration
+}
+
+// Client calls a Stripe-like payment gateway over HTTP: amounts are authorized on a
+// payment intent, then captured or voided, and captured amounts refunded.
+// Every mutating call carries an Idempotency-Key so retries are safe.
+type Client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+// NewClient creates a gateway client with defaults applied to zero config values
+func NewClient(cfg ClientConfig) *Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &Client{
+		baseURL: strings.TrimRight(cfg.BaseURL, "/"),
+		apiKey:  cfg.APIKey,
+		http:    &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Authorize holds an amount on a card; declines are returned as card errors
+func (c *Client) Authorize(ctx context.Context, p AuthorizeParams) (*PaymentIntent, error) {
+	body := map[string]any{
+		"amount":         p.Amount,
+		"currency":       p.Currency,
+		"card_number":    p.CardNumber,
+		"capture_method": "manual",
+		"reference":      p.Reference,
+	}
+	var pi PaymentIntent
+	if err := c.post(ctx, "/v1/payment_intents", p.IdempotencyKey, body, &pi); err != nil {
+		return nil, err
+	}
+	return &pi, nil
+}
+
+// Capture collects amount (minor units) of an authorized payment intent
+func (c *Client) Capture(ctx context.Context, id string, amount int64, idempotencyKey string) (*PaymentIntent, error) {
+	var pi PaymentIntent
+	path := "/v1/payment_intents/" + url.PathEscape(id) + "/capture"
+	if err := c.post(ctx, path, idempotencyKey, map[string]any{"amount_to_capture": amount}, &pi); err != nil {
+		return nil, err
+	}
+	return &pi, nil
+}
+
+// Void releases the hold of an uncaptured payment intent
+func (c *Client) Void(ctx context.Context, id, idempotencyKey string) (*PaymentIntent, error) {
+	var pi PaymentIntent
+	path := "/v1/payment_intents/" + url.PathEscape(id) + "/cancel"
+	if err := c.post(ctx, path, idempotencyKey, map[string]any{}, &pi); err != nil {
+		return nil, err
+	}
+	return &pi, nil
+}
+
+// Refund returns part or all of the captured amount of a payment intent
+func (c *Client) Refund(ctx context.Context, p RefundParams) (*Refund, error) {
+	body := map[string]any{
+		"payment_intent": p.PaymentIntent,
+		"amount":         p.Amount,
+	}
+	var r Refund
+	if err := c.post(ctx, "/v1/refunds", p.IdempotencyKey, body, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (c *Client) post(ctx context.Context, path, idempotencyKey string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("gateway request %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("gateway response %s: %w", path, err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var er errorResponse
+		if err := json.Unmarshal(data, &er); err != nil || er.Error == nil {
+			return &APIError{Type: ErrorTypeAPI, Message: strings.TrimSpace(string(data)), HTTPStatus: resp.StatusCode}
+		}
+		er.Error.HTTPStatus = resp.StatusCode
+		return er.Error
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("gateway response %s: %w", path, err)
+	}
+	return nil
+}
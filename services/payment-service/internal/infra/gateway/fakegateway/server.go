@@ -0,0 +1,409 @@
+package fakegateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/gateway"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/processor"
+)
+
+// Server is an in-memory stand-in for the payment gateway API used by gateway.Client,
+// for local runs and tests. Cards follow the test card rules (processor.TestCardDecline).
+// Responses of mutating calls are replayed for a repeated Idempotency-Key.
+// With webhooks every state change is also sent as a signed event, and in async mode
+// authorizations, captures and refunds are decided after a delay and only reported by webhook.
+type Server struct {
+	apiKey     string
+	asyncDelay time.Duration
+	webhooks   *webhookSender
+
+	mu      sync.Mutex
+	intents map[string]*gateway.PaymentIntent
+	refunds map[string]*gateway.Refund
+	replies map[string]reply
+}
+
+type reply struct {
+	status int
+	body   []byte
+}
+
+// NewServer creates a gateway; requests must carry apiKey as bearer token unless it is empty
+func NewServer(apiKey string) *Server {
+	return &Server{
+		apiKey:  apiKey,
+		intents: make(map[string]*gateway.PaymentIntent),
+		refunds: make(map[string]*gateway.Refund),
+		replies: make(map[string]reply),
+	}
+}
+
+// WithWebhooks sends events of every state change to url, signed with secret
+func (s *Server) WithWebhooks(url, secret string) *Server {
+	s.webhooks = newWebhookSender(url, secret)
+	return s
+}
+
+// WithAsync makes authorizations, captures and refunds return processing (pending) and
+// settle after delay; the outcome is only reported by webhook
+func (s *Server) WithAsync(delay time.Duration) *Server { s.asyncDelay = delay; return s }
+
+// Close stops webhook delivery; undelivered events are dropped
+func (s *Server) Close() error {
+	if s.webhooks != nil {
+		s.webhooks.close()
+	}
+	return nil
+}
+
+// Handler returns the HTTP API of the gateway
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/payment_intents", s.mutating(s.authorize))
+	mux.HandleFunc("POST /v1/payment_intents/{id}/capture", s.mutating(s.capture))
+	mux.HandleFunc("POST /v1/payment_intents/{id}/cancel", s.mutating(s.void))
+	mux.HandleFunc("GET /v1/payment_intents/{id}", s.authorized(s.getIntent))
+	mux.HandleFunc("POST /v1/refunds", s.mutating(s.refund))
+	return mux
+}
+
+// PaymentIntent returns a copy of a payment intent for inspection
+func (s *Server) PaymentIntent(id string) (gateway.PaymentIntent, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pi, ok := s.intents[id]
+	if !ok {
+		return gateway.PaymentIntent{}, false
+	}
+	return *pi, true
+}
+
+type handlerFunc func(r *http.Request) (int, any)
+
+func (s *Server) authorized(h handlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.apiKey != "" && r.Header.Get("Authorization") != "Bearer "+s.apiKey {
+			writeJSON(w, http.StatusUnauthorized, errorBody(apiError(http.StatusUnauthorized, gateway.ErrorTypeInvalidRequest, "", "invalid API key")))
+			return
+		}
+		s.mu.Lock()
+		status, body := h(r)
+		s.mu.Unlock()
+		writeJSON(w, status, body)
+	}
+}
+
+func (s *Server) mutating(h handlerFunc) http.HandlerFunc {
+	return s.authorized(func(r *http.Request) (int, any) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			return h(r)
+		}
+		key = r.URL.Path + "\x00" + key
+		if prev, ok := s.replies[key]; ok {
+			return prev.status, json.RawMessage(prev.body)
+		}
+		status, body := h(r)
+		data, _ := json.Marshal(body)
+		s.replies[key] = reply{status: status, body: data}
+		return status, json.RawMessage(data)
+	})
+}
+
+func (s *Server) authorize(r *http.Request) (int, any) {
+	var req struct {
+		Amount     int64  `json:"amount"`
+		Currency   string `json:"currency"`
+		CardNumber string `json:"card_number"`
+		Reference  string `json:"reference"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return invalidRequest("malformed request body")
+	}
+	if req.Amount <= 0 || len(req.Currency) != 3 {
+		return invalidRequest("amount must be positive and currency a 3-letter code")
+	}
+
+	pi := &gateway.PaymentIntent{
+		ID:        "pi_" + strings.ReplaceAll(uuid.New().String(), "-", ""),
+		Amount:    req.Amount,
+		Currency:  strings.ToUpper(req.Currency),
+		Reference: req.Reference,
+	}
+	s.intents[pi.ID] = pi
+	decline := processor.TestCardDecline(req.CardNumber)
+	if s.asyncDelay > 0 {
+		pi.Status = gateway.StatusProcessing
+		s.later(func() { s.decideAuthorization(pi, decline) })
+		return http.StatusOK, pi
+	}
+	s.decideAuthorization(pi, decline)
+	if decline != "" {
+		return http.StatusPaymentRequired, errorBody(pi.LastPaymentError)
+	}
+	return http.StatusOK, pi
+}
+
+func (s *Server) decideAuthorization(pi *gateway.PaymentIntent, decline string) {
+	if decline != "" {
+		pi.Status = gateway.StatusFailed
+		pi.LastPaymentError = apiError(http.StatusPaymentRequired, gateway.ErrorTypeCard, decline, processor.DeclineMessage(decline))
+		pi.LastPaymentError.PaymentIntent = pi.ID
+		s.emit(gateway.EventPaymentIntentPaymentFailed, pi)
+		return
+	}
+	pi.Status = gateway.StatusRequiresCapture
+	pi.AmountCapturable = pi.Amount
+	s.emit(gateway.EventPaymentIntentAuthorized, pi)
+}
+
+func (s *Server) capture(r *http.Request) (int, any) {
+	var req struct {
+		AmountToCapture int64 `json:"amount_to_capture"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return invalidRequest("malformed request body")
+	}
+	pi, ok := s.intents[r.PathValue("id")]
+	if !ok {
+		return notFound("payment intent")
+	}
+	if pi.Status != gateway.StatusRequiresCapture {
+		return invalidRequest("payment intent is " + pi.Status + ", not capturable")
+	}
+	amount := req.AmountToCapture
+	if amount == 0 {
+		amount = pi.AmountCapturable
+	}
+	if amount <= 0 || amount > pi.AmountCapturable {
+		return invalidRequest("amount_to_capture exceeds the capturable amount")
+	}
+	pi.AmountCapturable = 0
+	settle := func() {
+		pi.AmountReceived = amount
+		pi.Status = gateway.StatusSucceeded
+		s.emit(gateway.EventPaymentIntentSucceeded, pi)
+	}
+	if s.asyncDelay > 0 {
+		pi.Status = gateway.StatusProcessing
+		s.later(settle)
+		return http.StatusOK, pi
+	}
+	settle()
+	return http.StatusOK, pi
+}
+
+func (s *Server) void(r *http.Request) (int, any) {
+	pi, ok := s.intents[r.PathValue("id")]
+	if !ok {
+		return notFound("payment intent")
+	}
+	if pi.Status != gateway.StatusRequiresCapture {
+		return invalidRequest("payment intent is " + pi.Status + ", not cancelable")
+	}
+	pi.AmountCapturable = 0
+	pi.Status = gateway.StatusCanceled
+	s.emit(gateway.EventPaymentIntentCanceled, pi)
+	return http.StatusOK, pi
+}
+
+func (s *Server) getIntent(r *http.Request) (int, any) {
+	pi, ok := s.intents[r.PathValue("id")]
+	if !ok {
+		return notFound("payment intent")
+	}
+	return http.StatusOK, pi
+}
+
+func (s *Server) refund(r *http.Request) (int, any) {
+	var req struct {
+		PaymentIntent string `json:"payment_intent"`
+		Amount        int64  `json:"amount"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return invalidRequest("malformed request body")
+	}
+	pi, ok := s.intents[req.PaymentIntent]
+	if !ok {
+		return notFound("payment intent")
+	}
+	if pi.Status != gateway.StatusSucceeded {
+		return invalidRequest("payment intent is " + pi.Status + ", nothing to refund")
+	}
+	refunded := int64(0)
+	for _, rf := range s.refunds {
+		if rf.PaymentIntent == pi.ID && (rf.Status == gateway.StatusSucceeded || rf.Status == gateway.StatusPending) {
+			refunded += rf.Amount
+		}
+	}
+	amount := req.Amount
+	if amount == 0 {
+		amount = pi.AmountReceived - refunded
+	}
+	if amount <= 0 || refunded+amount > pi.AmountReceived {
+		return invalidRequest("refund exceeds the captured amount")
+	}
+	rf := &gateway.Refund{
+		ID:            "re_" + strings.ReplaceAll(uuid.New().String(), "-", ""),
+		PaymentIntent: pi.ID,
+		Amount:        amount,
+		Currency:      pi.Currency,
+	}
+	s.refunds[rf.ID] = rf
+	settle := func() {
+		rf.Status = gateway.StatusSucceeded
+		s.emit(gateway.EventRefundSucceeded, rf)
+	}
+	if s.asyncDelay > 0 {
+		rf.Status = gateway.StatusPending
+		s.later(settle)
+		return http.StatusOK, rf
+	}
+	settle()
+	return http.StatusOK, rf
+}
+
+// later runs fn under the lock after the async delay
+func (s *Server) later(fn func()) {
+	time.AfterFunc(s.asyncDelay, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		fn()
+	})
+}
+
+// emit queues an event with a snapshot of object; callers hold the lock, so events are
+// queued in the order of the state changes
+func (s *Server) emit(eventType string, object any) {
+	if s.webhooks == nil {
+		return
+	}
+	data, _ := json.Marshal(object)
+	s.webhooks.enqueue(gateway.Event{
+		ID:      "evt_" + strings.ReplaceAll(uuid.New().String(), "-", ""),
+		Type:    eventType,
+		Created: time.Now().Unix(),
+		Data:    gateway.EventData{Object: data},
+	})
+}
+
+// webhookSender delivers events one at a time in order, retrying failed deliveries with
+// backoff like a real gateway; an event still failing after the last attempt is dropped
+type webhookSender struct {
+	url      string
+	secret   string
+	client   *http.Client
+	attempts int
+	backoff  time.Duration
+
+	mu     sync.Mutex
+	queue  []gateway.Event
+	wake   chan struct{}
+	done   chan struct{}
+	closed sync.Once
+}
+
+func newWebhookSender(url, secret string) *webhookSender {
+	w := &webhookSender{
+		url:      url,
+		secret:   secret,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		attempts: 5,
+		backoff:  500 * time.Millisecond,
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *webhookSender) enqueue(evt gateway.Event) {
+	w.mu.Lock()
+	w.queue = append(w.queue, evt)
+	w.mu.Unlock()
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *webhookSender) close() { w.closed.Do(func() { close(w.done) }) }
+
+func (w *webhookSender) run() {
+	for {
+		w.mu.Lock()
+		if len(w.queue) == 0 {
+			w.mu.Unlock()
+			select {
+			case <-w.wake:
+				continue
+			case <-w.done:
+				return
+			}
+		}
+		evt := w.queue[0]
+		w.queue = w.queue[1:]
+		w.mu.Unlock()
+
+		payload, _ := json.Marshal(evt)
+		delay := w.backoff
+		for attempt := 1; attempt <= w.attempts; attempt++ {
+			if w.deliver(payload) {
+				break
+			}
+			if attempt == w.attempts {
+				break
+			}
+			select {
+			case <-time.After(delay):
+				delay *= 2
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+// deliver posts the event, signed at the time of the attempt; any 2xx acknowledges it
+func (w *webhookSender) deliver(payload []byte) bool {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(payload))
+	if err != nil {
+		return false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(gateway.SignatureHeader, gateway.SignPayload(payload, w.secret, time.Now()))
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode >= 200 && resp.StatusCode < 300
+}
+
+func apiError(status int, typ, code, message string) *gateway.APIError {
+	return &gateway.APIError{Type: typ, Code: code, Message: message, HTTPStatus: status}
+}
+
+func errorBody(e *gateway.APIError) map[string]any {
+	return map[string]any{"error": e}
+}
+
+func invalidRequest(message string) (int, any) {
+	return http.StatusBadRequest, errorBody(apiError(http.StatusBadRequest, gateway.ErrorTypeInvalidRequest, "", message))
+}
+
+func notFound(what string) (int, any) {
+	return http.StatusNotFound, errorBody(apiError(http.StatusNotFound, gateway.ErrorTypeInvalidRequest, "resource_missing", what+" not found"))
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
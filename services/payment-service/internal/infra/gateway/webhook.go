@@ -0,0 +1,123 @@
+package gateway
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook event types; payment intent events carry a PaymentIntent, refund events a Refund
+const (
+	EventPaymentIntentAuthorized    = "payment_intent.authorized"
+	EventPaymentIntentPaymentFailed = "payment_intent.payment_failed"
+	EventPaymentIntentSucceeded     = "payment_intent.succeeded"
+	EventPaymentIntentCaptureFailed = "payment_intent.capture_failed"
+	EventPaymentIntentCanceled      = "payment_intent.canceled"
+	EventRefundSucceeded            = "refund.succeeded"
+	EventRefundFailed               = "refund.failed"
+)
+
+// SignatureHeader carries the webhook signature: "t=<unix seconds>,v1=<hex HMAC-SHA256>"
+// of "<t>.<body>" keyed by the webhook secret
+const SignatureHeader = "Gateway-Signature"
+
+var (
+	ErrInvalidSignature   = errors.New("gateway webhook: invalid signature")
+	ErrTimestampTolerance = errors.New("gateway webhook: timestamp outside tolerance")
+)
+
+// Event is a webhook notification of a state change at the gateway. Deliveries of the same
+// event carry the same ID.
+type Event struct {
+	ID      string    `json:"id"`
+	Type    string    `json:"type"`
+	Created int64     `json:"created"` // unix seconds
+	Data    EventData `json:"data"`
+}
+
+type EventData struct {
+	Object json.RawMessage `json:"object"`
+}
+
+// PaymentIntent decodes the payment intent of a payment_intent.* event
+func (e *Event) PaymentIntent() (*PaymentIntent, error) {
+	var pi PaymentIntent
+	if err := json.Unmarshal(e.Data.Object, &pi); err != nil {
+		return nil, fmt.Errorf("gateway webhook %s: %w", e.ID, err)
+	}
+	return &pi, nil
+}
+
+// Refund decodes the refund of a refund.* event
+func (e *Event) Refund() (*Refund, error) {
+	var r Refund
+	if err := json.Unmarshal(e.Data.Object, &r); err != nil {
+		return nil, fmt.Errorf("gateway webhook %s: %w", e.ID, err)
+	}
+	return &r, nil
+}
+
+// SignPayload returns the signature header value of payload sent at t
+func SignPayload(payload []byte, secret string, t time.Time) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + computeSignature(ts, payload, secret)
+}
+
+// ParseEvent verifies the signature of a webhook payload and decodes it. Signatures older or
+// newer than tolerance are rejected so captured requests cannot be replayed later.
+func ParseEvent(payload []byte, header, secret string, tolerance time.Duration, now time.Time) (*Event, error) {
+	var ts string
+	var sigs []string
+	for _, part := range strings.Split(header, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sigs = append(sigs, v)
+		}
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(sigs) == 0 {
+		return nil, ErrInvalidSignature
+	}
+	expected := computeSignature(ts, payload, secret)
+	valid := false
+	for _, sig := range sigs {
+		if hmac.Equal([]byte(sig), []byte(expected)) {
+			valid = true
+		}
+	}
+	if !valid {
+		return nil, ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(sec, 0)); age > tolerance || age < -tolerance {
+		return nil, ErrTimestampTolerance
+	}
+
+	var evt Event
+	if err := json.Unmarshal(payload, &evt); err != nil {
+		return nil, fmt.Errorf("gateway webhook: %w", err)
+	}
+	if evt.ID == "" || evt.Type == "" {
+		return nil, errors.New("gateway webhook: event without id or type")
+	}
+	return &evt, nil
+}
+
+func computeSignature(ts string, payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
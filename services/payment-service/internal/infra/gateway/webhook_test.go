@@ -0,0 +1,71 @@
+package gateway
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseEvent(t *testing.T) {
+	const secret = "whsec_test"
+	now := time.Unix(1_700_000_000, 0)
+	payload := []byte(`{"id":"evt_1","type":"payment_intent.succeeded","created":1700000000,"data":{"object":{"id":"pi_1"}}}`)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	valid := SignPayload(payload, secret, now)
+	sig := computeSignature(ts, payload, secret)
+	otherSig := SignPayload(payload, "whsec_other", now)
+
+	tests := []struct {
+		name    string
+		payload []byte
+		header  string
+		wantErr error
+		anyErr  bool // any error, when wantErr is nil
+	}{
+		{name: "valid", payload: payload, header: valid},
+		{name: "signed a little earlier", payload: payload, header: SignPayload(payload, secret, now.Add(-4*time.Minute))},
+		{name: "clock skew ahead", payload: payload, header: SignPayload(payload, secret, now.Add(4*time.Minute))},
+		{name: "one of several signatures matches", payload: payload, header: otherSig + ",v1=" + sig},
+		{name: "spaces around parts", payload: payload, header: "t=" + ts + ", v1=" + sig},
+		{name: "wrong secret", payload: payload, header: otherSig, wantErr: ErrInvalidSignature},
+		{name: "tampered body", payload: []byte(`{"id":"evt_1","type":"payment_intent.succeeded","created":1700000000,"data":{"object":{"id":"pi_2"}}}`), header: valid, wantErr: ErrInvalidSignature},
+		{name: "timestamp swapped", payload: payload, header: "t=" + strconv.FormatInt(now.Unix()+1, 10) + ",v1=" + sig, wantErr: ErrInvalidSignature},
+		{name: "replayed too late", payload: payload, header: SignPayload(payload, secret, now.Add(-6*time.Minute)), wantErr: ErrTimestampTolerance},
+		{name: "too far in the future", payload: payload, header: SignPayload(payload, secret, now.Add(6*time.Minute)), wantErr: ErrTimestampTolerance},
+		{name: "missing header", payload: payload, header: "", wantErr: ErrInvalidSignature},
+		{name: "missing signature", payload: payload, header: "t=" + ts, wantErr: ErrInvalidSignature},
+		{name: "malformed timestamp", payload: payload, header: "t=soon,v1=" + sig, wantErr: ErrInvalidSignature},
+		{name: "not JSON", payload: []byte("not json"), header: SignPayload([]byte("not json"), secret, now), anyErr: true},
+		{name: "event without id", payload: []byte(`{"type":"refund.succeeded"}`), header: SignPayload([]byte(`{"type":"refund.succeeded"}`), secret, now), anyErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evt, err := ParseEvent(tt.payload, tt.header, secret, 5*time.Minute, now)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParseEvent error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.anyErr:
+				if err == nil {
+					t.Fatal("ParseEvent succeeded, want error")
+				}
+			default:
+				if err != nil {
+					t.Fatalf("ParseEvent: %v", err)
+				}
+				if evt.ID != "evt_1" || evt.Type != EventPaymentIntentSucceeded {
+					t.Errorf("event = %s %s, want evt_1 %s", evt.ID, evt.Type, EventPaymentIntentSucceeded)
+				}
+				pi, err := evt.PaymentIntent()
+				if err != nil {
+					t.Fatalf("PaymentIntent: %v", err)
+				}
+				if pi.ID != "pi_1" {
+					t.Errorf("payment intent = %s, want pi_1", pi.ID)
+				}
+			}
+		})
+	}
+}
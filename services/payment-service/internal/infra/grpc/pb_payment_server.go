@@ -2,26 +2,32 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
 	pb "github.com/kubernetestest/ecommerce-platform/proto-go/payment"
 	appsvc "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/app/services"
 	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/entities"
-	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/valueobjects"
+	derrors "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/errors"
 	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/metrics"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type PBPaymentServer struct {
 	pb.UnimplementedPaymentServiceServer
 	svc     *appsvc.PaymentService
+	methods *appsvc.PaymentMethodService
 	metrics metrics.PaymentMetrics
 }
 
-func NewPBPaymentServer(svc *appsvc.PaymentService, metrics metrics.PaymentMetrics) *PBPaymentServer {
+func NewPBPaymentServer(svc *appsvc.PaymentService, methods *appsvc.PaymentMethodService, metrics metrics.PaymentMetrics) *PBPaymentServer {
 	return &PBPaymentServer{
 		svc:     svc,
+		methods: methods,
 		metrics: metrics,
 	}
 }
@@ -29,8 +35,22 @@ func NewPBPaymentServer(svc *appsvc.PaymentService, metrics metrics.PaymentMetri
 // helpers
 func toPBStatus(s entities.PaymentStatus) pb.PaymentStatus {
 	switch s {
+	case entities.PaymentPending:
+		return pb.PaymentStatus_PAYMENT_PENDING
+	case entities.PaymentInReview:
+		return pb.PaymentStatus_PAYMENT_IN_REVIEW
+	case entities.PaymentAuthorized:
+		return pb.PaymentStatus_PAYMENT_AUTHORIZED
+	case entities.PaymentProcessing:
+		return pb.PaymentStatus_PAYMENT_PROCESSING
+	case entities.PaymentVoided:
+		return pb.PaymentStatus_PAYMENT_VOIDED
 	case entities.PaymentCompleted:
 		return pb.PaymentStatus_PAYMENT_COMPLETED
+	case entities.PaymentPartiallyRefunded:
+		return pb.PaymentStatus_PAYMENT_PARTIALLY_REFUNDED
+	case entities.PaymentRefunded:
+		return pb.PaymentStatus_PAYMENT_REFUNDED
 	case entities.PaymentFailed:
 		fallthrough
 	default:
@@ -38,10 +58,55 @@ func toPBStatus(s entities.PaymentStatus) pb.PaymentStatus {
 	}
 }
 
+func toPBRiskDecision(d entities.RiskDecision) pb.RiskDecision {
+	switch d {
+	case entities.RiskAllow:
+		return pb.RiskDecision_RISK_ALLOW
+	case entities.RiskReview:
+		return pb.RiskDecision_RISK_REVIEW
+	case entities.RiskDeny:
+		return pb.RiskDecision_RISK_DENY
+	default:
+		return pb.RiskDecision_RISK_UNEVALUATED
+	}
+}
+
+func toPBRefundStatus(s entities.RefundStatus) pb.RefundStatus {
+	switch s {
+	case entities.RefundSucceeded:
+		return pb.RefundStatus_REFUND_SUCCEEDED
+	case entities.RefundFailed:
+		return pb.RefundStatus_REFUND_FAILED
+	default:
+		return pb.RefundStatus_REFUND_PENDING
+	}
+}
+
+func toPBRefund(r *entities.Refund) *pb.Refund {
+	if r == nil {
+		return nil
+	}
+	return &pb.Refund{
+		Id:            r.ID,
+		PaymentId:     r.PaymentID,
+		Amount:        money.ToProto[pb.Money](r.Amount),
+		Reason:        r.Reason,
+		Status:        toPBRefundStatus(r.Status),
+		TransactionId: r.TransactionID,
+		CreatedAt:     timestamppb.New(r.CreatedAt.UTC()),
+	}
+}
+
 func toPBMethod(m entities.PaymentMethod) pb.PaymentMethod {
 	switch m {
 	case entities.MethodCreditCard:
 		return pb.PaymentMethod_CREDIT_CARD
+	case entities.MethodDebitCard:
+		return pb.PaymentMethod_DEBIT_CARD
+	case entities.MethodPayPal:
+		return pb.PaymentMethod_PAYPAL
+	case entities.MethodBankTransfer:
+		return pb.PaymentMethod_BANK_TRANSFER
 	default:
 		return pb.PaymentMethod_CREDIT_CARD
 	}
@@ -51,53 +116,98 @@ func fromPBMethod(m pb.PaymentMethod) entities.PaymentMethod {
 	switch m {
 	case pb.PaymentMethod_CREDIT_CARD:
 		return entities.MethodCreditCard
+	case pb.PaymentMethod_DEBIT_CARD:
+		return entities.MethodDebitCard
+	case pb.PaymentMethod_PAYPAL:
+		return entities.MethodPayPal
+	case pb.PaymentMethod_BANK_TRANSFER:
+		return entities.MethodBankTransfer
 	default:
 		return entities.MethodCreditCard
 	}
 }
 
+func toPBSavedMethod(m *entities.SavedPaymentMethod) *pb.SavedPaymentMethod {
+	return &pb.SavedPaymentMethod{
+		Token:          m.ID,
+		UserId:         m.UserID,
+		Method:         toPBMethod(m.Method),
+		Brand:          m.Brand,
+		Last4:          m.Last4,
+		ExpiryMonth:    int32(m.ExpiryMonth),
+		ExpiryYear:     int32(m.ExpiryYear),
+		CardHolder:     m.CardHolder,
+		BillingCountry: m.BillingCountry,
+		CreatedAt:      timestamppb.New(m.CreatedAt.UTC()),
+	}
+}
+
 func toPBPayment(p *entities.Payment) *pb.Payment {
 	if p == nil {
 		return nil
 	}
 	return &pb.Payment{
-		Id:            p.ID,
-		OrderId:       p.OrderID,
-		UserId:        p.UserID,
-		Amount:        &pb.Money{Amount: p.Amount.Amount, Currency: p.Amount.Currency},
-		Status:        toPBStatus(p.Status),
-		Method:        toPBMethod(p.Method),
-		TransactionId: p.TransactionID,
-		CreatedAt:     timestamppb.New(p.CreatedAt.UTC()),
-		UpdatedAt:     timestamppb.New(p.UpdatedAt.UTC()),
+		Id:             p.ID,
+		OrderId:        p.OrderID,
+		UserId:         p.UserID,
+		Amount:         money.ToProto[pb.Money](p.Amount),
+		Status:         toPBStatus(p.Status),
+		Method:         toPBMethod(p.Method),
+		TransactionId:  p.TransactionID,
+		CreatedAt:      timestamppb.New(p.CreatedAt.UTC()),
+		UpdatedAt:      timestamppb.New(p.UpdatedAt.UTC()),
+		RefundedAmount: money.ToProto[pb.Money](money.Money{Amount: p.RefundedAmount(), Currency: p.Amount.Currency}),
+		RiskDecision:   toPBRiskDecision(p.RiskDecision),
+		RiskReasons:    p.RiskReasons,
+	}
+}
+
+// toStatusErr maps domain/service errors to gRPC statuses
+func toStatusErr(err error) error {
+	switch {
+	case errors.Is(err, derrors.ErrPaymentNotFound), errors.Is(err, derrors.ErrRefundNotFound), errors.Is(err, derrors.ErrPaymentMethodNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, derrors.ErrInvalidRefundAmount), errors.Is(err, derrors.ErrRefundCurrencyMismatch), errors.Is(err, derrors.ErrInvalidPaymentAmount),
+		errors.Is(err, derrors.ErrInvalidCard):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, derrors.ErrPaymentNotRefundable), errors.Is(err, derrors.ErrRefundExceedsCaptured), errors.Is(err, derrors.ErrRefundNotPending),
+		errors.Is(err, derrors.ErrInvalidPaymentTransition):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
 	}
 }
 
 func (s *PBPaymentServer) ProcessPayment(ctx context.Context, req *pb.ProcessPaymentRequest) (*pb.ProcessPaymentResponse, error) {
 	start := time.Now()
 
-	amt, err := valueobjects.NewMoney(req.Amount.Amount, req.Amount.Currency)
+	amt, err := money.New(req.Amount.Amount, req.Amount.Currency)
 	if err != nil {
 		s.metrics.HTTPRequestsTotal("POST", "/ProcessPayment", "400")
 		s.metrics.HTTPRequestDuration("POST", "/ProcessPayment", time.Since(start))
 		return nil, err
 	}
 	method := fromPBMethod(req.Method)
-	card := ""
+	card, billingCountry := "", ""
 	if req.Details != nil {
-		card = req.Details.CardNumber
+		card, billingCountry = req.Details.CardNumber, req.Details.BillingCountry
 	}
 	resp, err := s.svc.ProcessPayment(ctx, &appsvc.ProcessPaymentRequest{
-		OrderID:    req.OrderId,
-		UserID:     req.UserId,
-		Amount:     amt,
-		Method:     method,
-		CardNumber: card,
+		OrderID:            req.OrderId,
+		UserID:             req.UserId,
+		Amount:             amt,
+		Method:             method,
+		PaymentMethodToken: req.PaymentMethodToken,
+		CardNumber:         card,
+		BillingCountry:     billingCountry,
+		ShippingCountry:    req.ShippingCountry,
 	})
-	if err != nil {
-		s.metrics.HTTPRequestsTotal("POST", "/ProcessPayment", "500")
+	// A declined payment is a business outcome, not a transport error
+	if err != nil && !(errors.Is(err, derrors.ErrPaymentDeclined) && resp != nil) {
+		st := toStatusErr(err)
+		s.metrics.HTTPRequestsTotal("POST", "/ProcessPayment", httpCode(st))
 		s.metrics.HTTPRequestDuration("POST", "/ProcessPayment", time.Since(start))
-		return nil, err
+		return nil, st
 	}
 
 	s.metrics.HTTPRequestsTotal("POST", "/ProcessPayment", "200")
@@ -117,7 +227,7 @@ func (s *PBPaymentServer) GetPayment(ctx context.Context, req *pb.GetPaymentRequ
 	if err != nil {
 		s.metrics.HTTPRequestsTotal("GET", "/GetPayment", "404")
 		s.metrics.HTTPRequestDuration("GET", "/GetPayment", time.Since(start))
-		return nil, err
+		return nil, toStatusErr(err)
 	}
 
 	s.metrics.HTTPRequestsTotal("GET", "/GetPayment", "200")
@@ -125,3 +235,194 @@ func (s *PBPaymentServer) GetPayment(ctx context.Context, req *pb.GetPaymentRequ
 
 	return &pb.GetPaymentResponse{Payment: toPBPayment(pay)}, nil
 }
+
+func (s *PBPaymentServer) RefundPayment(ctx context.Context, req *pb.RefundPaymentRequest) (*pb.RefundPaymentResponse, error) {
+	start := time.Now()
+
+	if req.PaymentId == "" {
+		s.metrics.HTTPRequestsTotal("POST", "/RefundPayment", "400")
+		s.metrics.HTTPRequestDuration("POST", "/RefundPayment", time.Since(start))
+		return nil, status.Error(codes.InvalidArgument, "payment_id is required")
+	}
+	var amount *money.Money
+	if req.Amount != nil {
+		amt, err := money.New(req.Amount.Amount, req.Amount.Currency)
+		if err != nil {
+			s.metrics.HTTPRequestsTotal("POST", "/RefundPayment", "400")
+			s.metrics.HTTPRequestDuration("POST", "/RefundPayment", time.Since(start))
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		amount = &amt
+	}
+
+	resp, err := s.svc.RefundPayment(ctx, &appsvc.RefundPaymentRequest{
+		PaymentID: req.PaymentId,
+		Amount:    amount,
+		Reason:    req.Reason,
+	})
+	// A declined refund is a business outcome, not a transport error
+	if err != nil && !(errors.Is(err, derrors.ErrRefundDeclined) && resp != nil) {
+		st := toStatusErr(err)
+		s.metrics.HTTPRequestsTotal("POST", "/RefundPayment", httpCode(st))
+		s.metrics.HTTPRequestDuration("POST", "/RefundPayment", time.Since(start))
+		return nil, st
+	}
+
+	s.metrics.HTTPRequestsTotal("POST", "/RefundPayment", "200")
+	s.metrics.HTTPRequestDuration("POST", "/RefundPayment", time.Since(start))
+
+	return &pb.RefundPaymentResponse{
+		Payment: toPBPayment(resp.Payment),
+		Refund:  toPBRefund(resp.Refund),
+		Success: resp.Success,
+		Message: resp.Message,
+	}, nil
+}
+
+func (s *PBPaymentServer) CapturePayment(ctx context.Context, req *pb.CapturePaymentRequest) (*pb.CapturePaymentResponse, error) {
+	start := time.Now()
+
+	if req.PaymentId == "" {
+		s.metrics.HTTPRequestsTotal("POST", "/CapturePayment", "400")
+		s.metrics.HTTPRequestDuration("POST", "/CapturePayment", time.Since(start))
+		return nil, status.Error(codes.InvalidArgument, "payment_id is required")
+	}
+	resp, err := s.svc.CapturePayment(ctx, req.PaymentId)
+	// A declined capture is a business outcome, not a transport error
+	if err != nil && !(errors.Is(err, derrors.ErrCaptureDeclined) && resp != nil) {
+		st := toStatusErr(err)
+		s.metrics.HTTPRequestsTotal("POST", "/CapturePayment", httpCode(st))
+		s.metrics.HTTPRequestDuration("POST", "/CapturePayment", time.Since(start))
+		return nil, st
+	}
+
+	s.metrics.HTTPRequestsTotal("POST", "/CapturePayment", "200")
+	s.metrics.HTTPRequestDuration("POST", "/CapturePayment", time.Since(start))
+
+	return &pb.CapturePaymentResponse{
+		Payment: toPBPayment(resp.Payment),
+		Success: resp.Success,
+		Message: resp.Message,
+	}, nil
+}
+
+func (s *PBPaymentServer) VoidPayment(ctx context.Context, req *pb.VoidPaymentRequest) (*pb.VoidPaymentResponse, error) {
+	start := time.Now()
+
+	if req.PaymentId == "" {
+		s.metrics.HTTPRequestsTotal("POST", "/VoidPayment", "400")
+		s.metrics.HTTPRequestDuration("POST", "/VoidPayment", time.Since(start))
+		return nil, status.Error(codes.InvalidArgument, "payment_id is required")
+	}
+	resp, err := s.svc.VoidPayment(ctx, req.PaymentId, req.Reason)
+	// A declined void is a business outcome, not a transport error
+	if err != nil && !(errors.Is(err, derrors.ErrVoidDeclined) && resp != nil) {
+		st := toStatusErr(err)
+		s.metrics.HTTPRequestsTotal("POST", "/VoidPayment", httpCode(st))
+		s.metrics.HTTPRequestDuration("POST", "/VoidPayment", time.Since(start))
+		return nil, st
+	}
+
+	s.metrics.HTTPRequestsTotal("POST", "/VoidPayment", "200")
+	s.metrics.HTTPRequestDuration("POST", "/VoidPayment", time.Since(start))
+
+	return &pb.VoidPaymentResponse{
+		Payment: toPBPayment(resp.Payment),
+		Success: resp.Success,
+		Message: resp.Message,
+	}, nil
+}
+
+func (s *PBPaymentServer) AddPaymentMethod(ctx context.Context, req *pb.AddPaymentMethodRequest) (*pb.AddPaymentMethodResponse, error) {
+	start := time.Now()
+
+	if req.UserId == "" || req.Card == nil {
+		s.metrics.HTTPRequestsTotal("POST", "/AddPaymentMethod", "400")
+		s.metrics.HTTPRequestDuration("POST", "/AddPaymentMethod", time.Since(start))
+		return nil, status.Error(codes.InvalidArgument, "user_id and card are required")
+	}
+	m, err := s.methods.AddPaymentMethod(ctx, &appsvc.AddPaymentMethodRequest{
+		UserID: req.UserId,
+		Method: fromPBMethod(req.Method),
+		Card: entities.CardDetails{
+			Number:         req.Card.CardNumber,
+			Holder:         req.Card.CardHolder,
+			ExpiryMonth:    req.Card.ExpiryMonth,
+			ExpiryYear:     req.Card.ExpiryYear,
+			CVV:            req.Card.Cvv,
+			BillingCountry: req.Card.BillingCountry,
+		},
+	})
+	if err != nil {
+		st := toStatusErr(err)
+		s.metrics.HTTPRequestsTotal("POST", "/AddPaymentMethod", httpCode(st))
+		s.metrics.HTTPRequestDuration("POST", "/AddPaymentMethod", time.Since(start))
+		return nil, st
+	}
+
+	s.metrics.HTTPRequestsTotal("POST", "/AddPaymentMethod", "200")
+	s.metrics.HTTPRequestDuration("POST", "/AddPaymentMethod", time.Since(start))
+
+	return &pb.AddPaymentMethodResponse{PaymentMethod: toPBSavedMethod(m)}, nil
+}
+
+func (s *PBPaymentServer) ListPaymentMethods(ctx context.Context, req *pb.ListPaymentMethodsRequest) (*pb.ListPaymentMethodsResponse, error) {
+	start := time.Now()
+
+	if req.UserId == "" {
+		s.metrics.HTTPRequestsTotal("GET", "/ListPaymentMethods", "400")
+		s.metrics.HTTPRequestDuration("GET", "/ListPaymentMethods", time.Since(start))
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	methods, err := s.methods.ListPaymentMethods(ctx, req.UserId)
+	if err != nil {
+		st := toStatusErr(err)
+		s.metrics.HTTPRequestsTotal("GET", "/ListPaymentMethods", httpCode(st))
+		s.metrics.HTTPRequestDuration("GET", "/ListPaymentMethods", time.Since(start))
+		return nil, st
+	}
+
+	s.metrics.HTTPRequestsTotal("GET", "/ListPaymentMethods", "200")
+	s.metrics.HTTPRequestDuration("GET", "/ListPaymentMethods", time.Since(start))
+
+	out := make([]*pb.SavedPaymentMethod, 0, len(methods))
+	for _, m := range methods {
+		out = append(out, toPBSavedMethod(m))
+	}
+	return &pb.ListPaymentMethodsResponse{PaymentMethods: out}, nil
+}
+
+func (s *PBPaymentServer) DeletePaymentMethod(ctx context.Context, req *pb.DeletePaymentMethodRequest) (*pb.DeletePaymentMethodResponse, error) {
+	start := time.Now()
+
+	if req.UserId == "" || req.Token == "" {
+		s.metrics.HTTPRequestsTotal("DELETE", "/DeletePaymentMethod", "400")
+		s.metrics.HTTPRequestDuration("DELETE", "/DeletePaymentMethod", time.Since(start))
+		return nil, status.Error(codes.InvalidArgument, "user_id and token are required")
+	}
+	if err := s.methods.DeletePaymentMethod(ctx, req.UserId, req.Token); err != nil {
+		st := toStatusErr(err)
+		s.metrics.HTTPRequestsTotal("DELETE", "/DeletePaymentMethod", httpCode(st))
+		s.metrics.HTTPRequestDuration("DELETE", "/DeletePaymentMethod", time.Since(start))
+		return nil, st
+	}
+
+	s.metrics.HTTPRequestsTotal("DELETE", "/DeletePaymentMethod", "200")
+	s.metrics.HTTPRequestDuration("DELETE", "/DeletePaymentMethod", time.Since(start))
+
+	return &pb.DeletePaymentMethodResponse{Success: true}, nil
+}
+
+// httpCode maps gRPC status of err to an HTTP-like status label for metrics
+func httpCode(err error) string {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return "404"
+	case codes.InvalidArgument:
+		return "400"
+	case codes.FailedPrecondition:
+		return "409"
+	default:
+		return "500"
+	}
+}
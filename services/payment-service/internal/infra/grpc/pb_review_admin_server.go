@@ -0,0 +1,74 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	pb "github.com/kubernetestest/ecommerce-platform/proto-go/payment"
+	appsvc "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/app/services"
+	derrors "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/errors"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/metrics"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PBPaymentReviewAdminServer lets admins decide payments held by the risk rules
+type PBPaymentReviewAdminServer struct {
+	pb.UnimplementedPaymentReviewAdminServiceServer
+	svc     *appsvc.PaymentService
+	metrics metrics.PaymentMetrics
+}
+
+func NewPBPaymentReviewAdminServer(svc *appsvc.PaymentService, metrics metrics.PaymentMetrics) *PBPaymentReviewAdminServer {
+	return &PBPaymentReviewAdminServer{svc: svc, metrics: metrics}
+}
+
+func (s *PBPaymentReviewAdminServer) ListPaymentsInReview(ctx context.Context, req *pb.ListPaymentsInReviewRequest) (*pb.ListPaymentsInReviewResponse, error) {
+	start := time.Now()
+
+	payments, total, err := s.svc.ListPaymentsInReview(ctx, int(req.Page), int(req.Limit))
+	if err != nil {
+		st := toStatusErr(err)
+		s.metrics.HTTPRequestsTotal("GET", "/ListPaymentsInReview", httpCode(st))
+		s.metrics.HTTPRequestDuration("GET", "/ListPaymentsInReview", time.Since(start))
+		return nil, st
+	}
+
+	s.metrics.HTTPRequestsTotal("GET", "/ListPaymentsInReview", "200")
+	s.metrics.HTTPRequestDuration("GET", "/ListPaymentsInReview", time.Since(start))
+
+	out := make([]*pb.Payment, 0, len(payments))
+	for _, p := range payments {
+		out = append(out, toPBPayment(p))
+	}
+	return &pb.ListPaymentsInReviewResponse{Payments: out, Total: int32(total)}, nil
+}
+
+func (s *PBPaymentReviewAdminServer) ReviewPayment(ctx context.Context, req *pb.ReviewPaymentRequest) (*pb.ReviewPaymentResponse, error) {
+	start := time.Now()
+
+	if req.PaymentId == "" {
+		s.metrics.HTTPRequestsTotal("POST", "/ReviewPayment", "400")
+		s.metrics.HTTPRequestDuration("POST", "/ReviewPayment", time.Since(start))
+		return nil, status.Error(codes.InvalidArgument, "payment_id is required")
+	}
+	resp, err := s.svc.ReviewPayment(ctx, req.PaymentId, req.Approve, req.Note)
+	// A rejected or declined payment is a business outcome, not a transport error
+	if err != nil && !(errors.Is(err, derrors.ErrPaymentDeclined) && resp != nil) {
+		st := toStatusErr(err)
+		s.metrics.HTTPRequestsTotal("POST", "/ReviewPayment", httpCode(st))
+		s.metrics.HTTPRequestDuration("POST", "/ReviewPayment", time.Since(start))
+		return nil, st
+	}
+
+	s.metrics.HTTPRequestsTotal("POST", "/ReviewPayment", "200")
+	s.metrics.HTTPRequestDuration("POST", "/ReviewPayment", time.Since(start))
+
+	return &pb.ReviewPaymentResponse{
+		Payment: toPBPayment(resp.Payment),
+		Success: resp.Success || resp.Pending,
+		Message: resp.Message,
+	}, nil
+}
@@ -9,6 +9,7 @@ import (
 )
 
 // RegisterPaymentPBServer registers the protobuf server implementation
-func RegisterPaymentPBServer(server *grpc.Server, svc *appsvc.PaymentService, metrics metrics.PaymentMetrics) {
-	pb.RegisterPaymentServiceServer(server, NewPBPaymentServer(svc, metrics))
+func RegisterPaymentPBServer(server *grpc.Server, svc *appsvc.PaymentService, methods *appsvc.PaymentMethodService, metrics metrics.PaymentMetrics) {
+	pb.RegisterPaymentServiceServer(server, NewPBPaymentServer(svc, methods, metrics))
+	pb.RegisterPaymentReviewAdminServiceServer(server, NewPBPaymentReviewAdminServer(svc, metrics))
 }
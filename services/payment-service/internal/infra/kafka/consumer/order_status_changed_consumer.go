@@ -0,0 +1,73 @@
+package consumer
+
+import (
+	"context"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/eventschema"
+	kafkaclient "github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
+	"github.com/kubernetestest/ecommerce-platform/pkg/logger"
+
+	events "github.com/kubernetestest/ecommerce-platform/proto-go/events"
+
+	"google.golang.org/protobuf/proto"
+)
+
+type OrderStatusChangedHandler interface {
+	Handle(ctx context.Context, evt *events.OrderStatusChanged) error
+}
+
+type OrderStatusChangedHandlerFunc func(ctx context.Context, evt *events.OrderStatusChanged) error
+
+func (f OrderStatusChangedHandlerFunc) Handle(ctx context.Context, evt *events.OrderStatusChanged) error {
+	return f(ctx, evt)
+}
+
+// OrderStatusChangedConsumer drives capture and void of order payments
+type OrderStatusChangedConsumer struct {
+	c   *kafkaclient.Consumer
+	h   OrderStatusChangedHandler
+	log logger.Logger
+}
+
+func NewOrderStatusChangedConsumer(bus kafkaclient.Bus, groupID string, handler OrderStatusChangedHandler) (*OrderStatusChangedConsumer, error) {
+	config := kafkaclient.ConsumerConfig{
+		GroupID:         groupID,
+		AutoOffsetReset: "earliest",
+	}
+
+	c, err := bus.NewConsumer(config)
+	if err != nil {
+		return nil, err
+	}
+	return &OrderStatusChangedConsumer{c: c, h: handler}, nil
+}
+
+func (c *OrderStatusChangedConsumer) WithLogger(l logger.Logger) *OrderStatusChangedConsumer {
+	c.log = l
+	c.c.WithLogger(l)
+	return c
+}
+
+func (c *OrderStatusChangedConsumer) Close() error { return c.c.Close() }
+
+// WithDeadLetter routes events that keep failing to "<topic>.dlq"
+func (c *OrderStatusChangedConsumer) WithDeadLetter(p kafkaclient.DeadLetterPublisher) *OrderStatusChangedConsumer {
+	c.c.WithDeadLetter(p)
+	return c
+}
+
+// WithIdempotency skips redelivered status changes already handled for an order
+func (c *OrderStatusChangedConsumer) WithIdempotency(store kafkaclient.ProcessedStore) *OrderStatusChangedConsumer {
+	c.c.WithIdempotency(store, eventschema.KeyFunc, 0)
+	return c
+}
+
+func (c *OrderStatusChangedConsumer) Run(ctx context.Context, topics []string) error {
+	return c.c.RunEventLoop(ctx, topics, eventschema.Contracts(), func(hctx context.Context, msg proto.Message) error {
+		evt, err := eventschema.As[*events.OrderStatusChanged](msg)
+		if err != nil {
+			return err
+		}
+		return c.h.Handle(hctx, evt)
+	})
+}
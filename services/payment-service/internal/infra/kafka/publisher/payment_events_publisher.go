@@ -0,0 +1,53 @@
+package publisher
+
+import (
+	"context"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/eventschema"
+	kafkaclient "github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
+
+	events "github.com/kubernetestest/ecommerce-platform/proto-go/events"
+)
+
+// ProducerName is recorded in the envelope of events published by payment-service
+const ProducerName = "payment-service"
+
+// Payment event topics
+const (
+	PaymentProcessedTopic = eventschema.PaymentProcessedTopic
+	PaymentRefundedTopic  = eventschema.PaymentRefundedTopic
+	// PaymentReviewRequiredTopic carries payments held for risk review
+	PaymentReviewRequiredTopic = eventschema.PaymentReviewRequiredTopic
+)
+
+// Routes maps payment events to their topics, keyed by order ID
+func Routes() *kafkaclient.EventRoutes {
+	r := kafkaclient.NewEventRoutes()
+	kafkaclient.Route(r, PaymentProcessedTopic, func(e *events.PaymentProcessed) string { return e.GetOrderId() })
+	kafkaclient.Route(r, PaymentRefundedTopic, func(e *events.PaymentRefunded) string { return e.GetOrderId() })
+	kafkaclient.Route(r, PaymentReviewRequiredTopic, func(e *events.PaymentReviewRequired) string { return e.GetOrderId() })
+	return r
+}
+
+// PaymentEventsPublisher publishes payment events through pkg/kafkaclient
+type PaymentEventsPublisher struct {
+	events kafkaclient.EventPublisher
+}
+
+func NewPaymentEventsPublisher(events kafkaclient.EventPublisher) *PaymentEventsPublisher {
+	return &PaymentEventsPublisher{events: events}
+}
+
+// PublishPaymentProcessed returns once the broker acknowledged the event
+func (p *PaymentEventsPublisher) PublishPaymentProcessed(ctx context.Context, evt *events.PaymentProcessed) error {
+	return p.events.PublishSync(ctx, evt)
+}
+
+// PublishPaymentReviewRequired returns once the broker acknowledged the event
+func (p *PaymentEventsPublisher) PublishPaymentReviewRequired(ctx context.Context, evt *events.PaymentReviewRequired) error {
+	return p.events.PublishSync(ctx, evt)
+}
+
+func (p *PaymentEventsPublisher) PublishPaymentRefunded(ctx context.Context, evt *events.PaymentRefunded) error {
+	return p.events.Publish(ctx, evt)
+}
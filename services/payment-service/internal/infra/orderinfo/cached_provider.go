@@ -0,0 +1,81 @@
+package orderinfoimpl
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/ports/orderinfo"
+)
+
+// maxCachedOrders bounds the cache; expired entries are swept when it is full
+const maxCachedOrders = 10000
+
+// CachedProvider keeps order payments looked up from next in memory for ttl.
+// Orders are charged once their stock is reserved, when items and total no longer change;
+// their status does change, so entries are invalidated on status changes.
+// Lookup errors are not cached.
+type CachedProvider struct {
+	next orderinfo.Provider
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cachedOrder
+}
+
+type cachedOrder struct {
+	payment   orderinfo.OrderPayment
+	expiresAt time.Time
+}
+
+func NewCachedProvider(next orderinfo.Provider, ttl time.Duration) *CachedProvider {
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+	return &CachedProvider{next: next, ttl: ttl, entries: make(map[string]cachedOrder)}
+}
+
+func (p *CachedProvider) GetOrderPayment(ctx context.Context, orderID string) (*orderinfo.OrderPayment, error) {
+	now := time.Now()
+	p.mu.Lock()
+	e, ok := p.entries[orderID]
+	p.mu.Unlock()
+	if ok && now.Before(e.expiresAt) {
+		op := e.payment
+		return &op, nil
+	}
+
+	op, err := p.next.GetOrderPayment(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.entries) >= maxCachedOrders {
+		p.sweep(now)
+	}
+	p.entries[orderID] = cachedOrder{payment: *op, expiresAt: now.Add(p.ttl)}
+	return op, nil
+}
+
+// Invalidate drops a cached order, e.g. once it is paid
+func (p *CachedProvider) Invalidate(orderID string) {
+	p.mu.Lock()
+	delete(p.entries, orderID)
+	p.mu.Unlock()
+}
+
+// sweep removes expired entries, and arbitrary ones if the cache is still full
+func (p *CachedProvider) sweep(now time.Time) {
+	for id, e := range p.entries {
+		if !now.Before(e.expiresAt) {
+			delete(p.entries, id)
+		}
+	}
+	for id := range p.entries {
+		if len(p.entries) < maxCachedOrders {
+			return
+		}
+		delete(p.entries, id)
+	}
+}
@@ -0,0 +1,50 @@
+package orderinfoimpl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	orderpb "github.com/kubernetestest/ecommerce-platform/proto-go/order"
+	derrors "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/errors"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/ports/orderinfo"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// OrderServiceProvider implements orderinfo.Provider using order-service gRPC client.
+type OrderServiceProvider struct {
+	client  orderpb.OrderServiceClient
+	timeout time.Duration
+}
+
+func NewOrderServiceProvider(client orderpb.OrderServiceClient, timeout time.Duration) *OrderServiceProvider {
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	return &OrderServiceProvider{client: client, timeout: timeout}
+}
+
+func (p *OrderServiceProvider) GetOrderPayment(ctx context.Context, orderID string) (*orderinfo.OrderPayment, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	resp, err := p.client.GetOrderPayment(ctx, &orderpb.GetOrderPaymentRequest{OrderId: orderID})
+	if status.Code(err) == codes.NotFound {
+		return nil, fmt.Errorf("%w: %s", derrors.ErrOrderNotFound, orderID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("order get payment %s: %w", orderID, err)
+	}
+	return &orderinfo.OrderPayment{
+		OrderID:            resp.GetOrderId(),
+		UserID:             resp.GetUserId(),
+		Amount:             resp.GetAmount().GetAmount(),
+		Currency:           resp.GetAmount().GetCurrency(),
+		Method:             resp.GetPayment().GetMethod(),
+		PaymentMethodToken: resp.GetPayment().GetPaymentMethodToken(),
+		ShippingCountry:    resp.GetShippingCountry(),
+		Status:             resp.GetStatus().String(),
+	}, nil
+}
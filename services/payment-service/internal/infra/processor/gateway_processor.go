@@ -0,0 +1,154 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/gateway"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/ports/processor"
+)
+
+// GatewayProcessor processes payments through the HTTP payment gateway. The authorization
+// is a payment intent with manual capture; its ID is the transaction ID captured or voided later.
+// Calls the gateway is still processing return a Pending result; the outcome follows by webhook.
+type GatewayProcessor struct {
+	client *gateway.Client
+}
+
+func NewGatewayProcessor(client *gateway.Client) *GatewayProcessor {
+	return &GatewayProcessor{client: client}
+}
+
+func (p *GatewayProcessor) Authorize(ctx context.Context, req processor.AuthorizeRequest) (processor.AuthorizeResult, error) {
+	if !IsCardMethod(req.Method) {
+		return processor.AuthorizeResult{Success: false, FailureReason: DeclineMessage(DeclineUnsupportedMethod), DeclineCode: DeclineUnsupportedMethod}, nil
+	}
+	pi, err := p.client.Authorize(ctx, gateway.AuthorizeParams{
+		Amount:         req.Amount,
+		Currency:       req.Currency,
+		CardNumber:     req.CardNumber,
+		Reference:      req.OrderID,
+		IdempotencyKey: req.PaymentID + "-authorize",
+	})
+	if apiErr, ok := asAPIError(err); ok && apiErr.IsCardError() {
+		return processor.AuthorizeResult{Success: false, FailureReason: apiErr.Message, DeclineCode: apiErr.Code, TransactionID: apiErr.PaymentIntent}, nil
+	}
+	if err != nil {
+		return processor.AuthorizeResult{}, err
+	}
+	if pi.Status == gateway.StatusProcessing {
+		return processor.AuthorizeResult{Pending: true, TransactionID: pi.ID}, nil
+	}
+	if pi.Status != gateway.StatusRequiresCapture {
+		return processor.AuthorizeResult{}, fmt.Errorf("authorize %s: unexpected status %s", pi.ID, pi.Status)
+	}
+	return processor.AuthorizeResult{Success: true, TransactionID: pi.ID}, nil
+}
+
+func (p *GatewayProcessor) Capture(ctx context.Context, req processor.CaptureRequest) (processor.CaptureResult, error) {
+	pi, err := p.client.Capture(ctx, req.TransactionID, req.Amount, req.PaymentID+"-capture")
+	if apiErr, ok := asAPIError(err); ok && apiErr.Type != gateway.ErrorTypeAPI {
+		return processor.CaptureResult{Success: false, FailureReason: apiErr.Message}, nil
+	}
+	if err != nil {
+		return processor.CaptureResult{}, err
+	}
+	if pi.Status == gateway.StatusProcessing {
+		return processor.CaptureResult{Pending: true}, nil
+	}
+	if pi.Status != gateway.StatusSucceeded {
+		return processor.CaptureResult{}, fmt.Errorf("capture %s: unexpected status %s", pi.ID, pi.Status)
+	}
+	return processor.CaptureResult{Success: true}, nil
+}
+
+func (p *GatewayProcessor) Void(ctx context.Context, req processor.VoidRequest) (processor.VoidResult, error) {
+	pi, err := p.client.Void(ctx, req.TransactionID, req.PaymentID+"-void")
+	if apiErr, ok := asAPIError(err); ok && apiErr.Type != gateway.ErrorTypeAPI {
+		return processor.VoidResult{Success: false, FailureReason: apiErr.Message}, nil
+	}
+	if err != nil {
+		return processor.VoidResult{}, err
+	}
+	if pi.Status != gateway.StatusCanceled {
+		return processor.VoidResult{}, fmt.Errorf("void %s: unexpected status %s", pi.ID, pi.Status)
+	}
+	return processor.VoidResult{Success: true}, nil
+}
+
+func (p *GatewayProcessor) Refund(ctx context.Context, req processor.RefundRequest) (processor.RefundResult, error) {
+	r, err := p.client.Refund(ctx, gateway.RefundParams{
+		PaymentIntent:  req.TransactionID,
+		Amount:         req.Amount,
+		IdempotencyKey: req.RefundID,
+	})
+	if apiErr, ok := asAPIError(err); ok && apiErr.Type != gateway.ErrorTypeAPI {
+		code := processor.RefundDeclineRejected
+		if apiErr.Type == gateway.ErrorTypeInvalidRequest {
+			code = processor.RefundDeclineInvalidRequest
+		}
+		return processor.RefundResult{Success: false, FailureReason: apiErr.Message, DeclineCode: code}, nil
+	}
+	if err != nil {
+		return processor.RefundResult{}, err
+	}
+	if r.Status == gateway.StatusPending {
+		return processor.RefundResult{Pending: true, TransactionID: r.ID}, nil
+	}
+	if r.Status != gateway.StatusSucceeded {
+		return processor.RefundResult{Success: false, FailureReason: r.FailureReason, TransactionID: r.ID, DeclineCode: processor.RefundDeclineRejected}, nil
+	}
+	return processor.RefundResult{Success: true, TransactionID: r.ID}, nil
+}
+
+// GatewayUpdate translates a gateway webhook event into a processor update; ok is false for
+// event types that carry no payment outcome
+func GatewayUpdate(evt *gateway.Event) (u processor.Update, ok bool, err error) {
+	switch evt.Type {
+	case gateway.EventPaymentIntentAuthorized, gateway.EventPaymentIntentPaymentFailed,
+		gateway.EventPaymentIntentSucceeded, gateway.EventPaymentIntentCaptureFailed, gateway.EventPaymentIntentCanceled:
+		pi, err := evt.PaymentIntent()
+		if err != nil {
+			return processor.Update{}, false, err
+		}
+		u = processor.Update{TransactionID: pi.ID}
+		if pi.LastPaymentError != nil {
+			u.FailureReason, u.DeclineCode = pi.LastPaymentError.Message, pi.LastPaymentError.Code
+		}
+		switch evt.Type {
+		case gateway.EventPaymentIntentAuthorized:
+			u.Kind = processor.UpdateAuthorized
+		case gateway.EventPaymentIntentPaymentFailed:
+			u.Kind = processor.UpdateAuthorizationFailed
+		case gateway.EventPaymentIntentSucceeded:
+			u.Kind = processor.UpdateCaptured
+		case gateway.EventPaymentIntentCaptureFailed:
+			u.Kind = processor.UpdateCaptureFailed
+		case gateway.EventPaymentIntentCanceled:
+			u.Kind = processor.UpdateVoided
+		}
+		return u, true, nil
+	case gateway.EventRefundSucceeded, gateway.EventRefundFailed:
+		r, err := evt.Refund()
+		if err != nil {
+			return processor.Update{}, false, err
+		}
+		u = processor.Update{Kind: processor.UpdateRefunded, TransactionID: r.PaymentIntent, RefundTransactionID: r.ID}
+		if evt.Type == gateway.EventRefundFailed {
+			u.Kind, u.FailureReason, u.DeclineCode = processor.UpdateRefundFailed, r.FailureReason, processor.RefundDeclineRejected
+		}
+		return u, true, nil
+	}
+	return processor.Update{}, false, nil
+}
+
+// asAPIError extracts a gateway error response; card and invalid request errors are
+// business outcomes, api errors are technical failures
+func asAPIError(err error) (*gateway.APIError, bool) {
+	var apiErr *gateway.APIError
+	if !errors.As(err, &apiErr) {
+		return nil, false
+	}
+	return apiErr, true
+}
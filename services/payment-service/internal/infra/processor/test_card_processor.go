@@ -0,0 +1,74 @@
+package processor
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/ports/processor"
+)
+
+// TestCardProcessor is a deterministic processor following the test card rules
+// (see TestCardDecline) with simulated latency
+type TestCardProcessor struct {
+	latency time.Duration
+}
+
+func NewTestCardProcessor() *TestCardProcessor {
+	return &TestCardProcessor{latency: 300 * time.Millisecond}
+}
+
+// WithLatency sets the simulated processing latency
+func (p *TestCardProcessor) WithLatency(d time.Duration) *TestCardProcessor {
+	p.latency = d
+	return p
+}
+
+func (p *TestCardProcessor) Authorize(ctx context.Context, req processor.AuthorizeRequest) (processor.AuthorizeResult, error) {
+	if err := p.wait(ctx, p.latency); err != nil {
+		return processor.AuthorizeResult{Success: false, FailureReason: "context canceled"}, err
+	}
+	if !IsCardMethod(req.Method) {
+		return processor.AuthorizeResult{Success: false, FailureReason: DeclineMessage(DeclineUnsupportedMethod), DeclineCode: DeclineUnsupportedMethod}, nil
+	}
+	if code := TestCardDecline(req.CardNumber); code != "" {
+		return processor.AuthorizeResult{Success: false, FailureReason: DeclineMessage(code), DeclineCode: code}, nil
+	}
+	return processor.AuthorizeResult{Success: true, TransactionID: "txn-" + uuid.New().String()}, nil
+}
+
+// Capture always succeeds after simulated latency; declines happen at authorization
+func (p *TestCardProcessor) Capture(ctx context.Context, req processor.CaptureRequest) (processor.CaptureResult, error) {
+	if err := p.wait(ctx, p.latency/3); err != nil {
+		return processor.CaptureResult{Success: false, FailureReason: "context canceled"}, err
+	}
+	return processor.CaptureResult{Success: true}, nil
+}
+
+// Void always succeeds after simulated latency
+func (p *TestCardProcessor) Void(ctx context.Context, req processor.VoidRequest) (processor.VoidResult, error) {
+	if err := p.wait(ctx, p.latency/3); err != nil {
+		return processor.VoidResult{Success: false, FailureReason: "context canceled"}, err
+	}
+	return processor.VoidResult{Success: true}, nil
+}
+
+// Refund always succeeds after simulated latency
+func (p *TestCardProcessor) Refund(ctx context.Context, req processor.RefundRequest) (processor.RefundResult, error) {
+	if err := p.wait(ctx, p.latency/3); err != nil {
+		return processor.RefundResult{Success: false, FailureReason: "context canceled"}, err
+	}
+	return processor.RefundResult{Success: true, TransactionID: "txn-" + uuid.New().String()}, nil
+}
+
+func (p *TestCardProcessor) wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
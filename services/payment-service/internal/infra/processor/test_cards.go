@@ -0,0 +1,83 @@
+package processor
+
+// Test card numbers with fixed outcomes, as advertised by the API gateway
+const (
+	TestCardVisa            = "4111111111111111"
+	TestCardMastercard      = "5555555555554444"
+	TestCardDeclined        = "4000000000000002"
+	TestCardProcessingError = "4000000000000119"
+	TestCardExpired         = "4000000000000341"
+)
+
+// Decline codes reported by processors
+const (
+	DeclineInsufficientFunds = "insufficient_funds"
+	DeclineProcessingError   = "processing_error"
+	DeclineExpiredCard       = "expired_card"
+	DeclineIncorrectNumber   = "incorrect_number"
+	DeclineUnsupportedMethod = "unsupported_payment_method"
+)
+
+var testCardDeclines = map[string]string{
+	TestCardDeclined:        DeclineInsufficientFunds,
+	TestCardProcessingError: DeclineProcessingError,
+	TestCardExpired:         DeclineExpiredCard,
+}
+
+var declineMessages = map[string]string{
+	DeclineInsufficientFunds: "card declined: insufficient funds",
+	DeclineProcessingError:   "card declined: processing error",
+	DeclineExpiredCard:       "card declined: card expired",
+	DeclineIncorrectNumber:   "card declined: incorrect card number",
+	DeclineUnsupportedMethod: "payment method not supported by the processor",
+}
+
+// IsCardMethod reports whether processors can charge a payment method; an empty method is a card
+func IsCardMethod(method string) bool {
+	return method == "" || method == "CREDIT_CARD" || method == "DEBIT_CARD"
+}
+
+// TestCardDecline returns the decline code of a card under the test card rules: the
+// advertised test cards fail with their code, numbers failing the Luhn check are
+// incorrect and every other card is approved (empty code)
+func TestCardDecline(cardNumber string) string {
+	if code, ok := testCardDeclines[cardNumber]; ok {
+		return code
+	}
+	if !luhnValid(cardNumber) {
+		return DeclineIncorrectNumber
+	}
+	return ""
+}
+
+// DeclineMessage returns a human readable reason for a decline code
+func DeclineMessage(code string) string {
+	if msg, ok := declineMessages[code]; ok {
+		return msg
+	}
+	return "card declined: " + code
+}
+
+func luhnValid(number string) bool {
+	if len(number) < 12 || len(number) > 19 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
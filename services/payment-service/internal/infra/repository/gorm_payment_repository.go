@@ -0,0 +1,360 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/money"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/entities"
+	derrors "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PaymentRecord is a GORM model separated from the domain entity
+type PaymentRecord struct {
+	ID              string         `gorm:"primaryKey;type:varchar(255)"`
+	OrderID         string         `gorm:"not null;type:varchar(255);index"`
+	UserID          string         `gorm:"not null;type:varchar(255);index"`
+	Amount          int64          `gorm:"type:bigint;not null"`
+	Currency        string         `gorm:"type:varchar(3);not null"`
+	Status          string         `gorm:"type:varchar(20);not null"`
+	Method          string         `gorm:"type:varchar(32);not null"`
+	TransactionID   string         `gorm:"type:varchar(255);index"`
+	PaymentMethodID string         `gorm:"type:varchar(64)"`
+	StatusReason    string         `gorm:"type:text"`
+	ShippingCountry string         `gorm:"type:varchar(2)"`
+	CardFingerprint string         `gorm:"type:varchar(64);index"`
+	RiskDecision    string         `gorm:"type:varchar(16)"`
+	RiskReasons     string         `gorm:"type:text"` // newline separated
+	Refunds         []RefundRecord `gorm:"foreignKey:PaymentID;constraint:OnDelete:CASCADE"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime"`
+}
+
+// RefundRecord is a GORM model for refunds of a payment
+type RefundRecord struct {
+	ID            string    `gorm:"primaryKey;type:varchar(255)"`
+	PaymentID     string    `gorm:"not null;type:varchar(255);index"`
+	Amount        int64     `gorm:"type:bigint;not null"`
+	Currency      string    `gorm:"type:varchar(3);not null"`
+	Reason        string    `gorm:"type:text"`
+	Status        string    `gorm:"type:varchar(20);not null"`
+	TransactionID string    `gorm:"type:varchar(255)"`
+	FailureReason string    `gorm:"type:text"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
+
+func (PaymentRecord) TableName() string { return "payments" }
+func (RefundRecord) TableName() string  { return "payment_refunds" }
+
+func recordFromEntity(p *entities.Payment) PaymentRecord {
+	refunds := make([]RefundRecord, 0, len(p.Refunds))
+	for _, r := range p.Refunds {
+		refunds = append(refunds, RefundRecord{
+			ID:            r.ID,
+			PaymentID:     p.ID,
+			Amount:        r.Amount.Amount,
+			Currency:      r.Amount.Currency,
+			Reason:        r.Reason,
+			Status:        string(r.Status),
+			TransactionID: r.TransactionID,
+			FailureReason: r.FailureReason,
+			CreatedAt:     r.CreatedAt,
+			UpdatedAt:     r.UpdatedAt,
+		})
+	}
+	return PaymentRecord{
+		ID:              p.ID,
+		OrderID:         p.OrderID,
+		UserID:          p.UserID,
+		Amount:          p.Amount.Amount,
+		Currency:        p.Amount.Currency,
+		Status:          string(p.Status),
+		Method:          string(p.Method),
+		TransactionID:   p.TransactionID,
+		PaymentMethodID: p.PaymentMethodID,
+		StatusReason:    p.StatusReason,
+		ShippingCountry: p.ShippingCountry,
+		CardFingerprint: p.CardFingerprint,
+		RiskDecision:    string(p.RiskDecision),
+		RiskReasons:     strings.Join(p.RiskReasons, "\n"),
+		Refunds:         refunds,
+		CreatedAt:       p.CreatedAt,
+		UpdatedAt:       p.UpdatedAt,
+	}
+}
+
+func entityFromRecord(r PaymentRecord) (*entities.Payment, error) {
+	amount, err := money.New(r.Amount, r.Currency)
+	if err != nil {
+		return nil, err
+	}
+	refunds := make([]entities.Refund, 0, len(r.Refunds))
+	for _, rr := range r.Refunds {
+		ramount, err := money.New(rr.Amount, rr.Currency)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, entities.Refund{
+			ID:            rr.ID,
+			PaymentID:     rr.PaymentID,
+			Amount:        ramount,
+			Reason:        rr.Reason,
+			Status:        entities.RefundStatus(rr.Status),
+			TransactionID: rr.TransactionID,
+			FailureReason: rr.FailureReason,
+			CreatedAt:     rr.CreatedAt,
+			UpdatedAt:     rr.UpdatedAt,
+		})
+	}
+	var reasons []string
+	if r.RiskReasons != "" {
+		reasons = strings.Split(r.RiskReasons, "\n")
+	}
+	return &entities.Payment{
+		ID:              r.ID,
+		OrderID:         r.OrderID,
+		UserID:          r.UserID,
+		Amount:          amount,
+		Status:          entities.PaymentStatus(r.Status),
+		Method:          entities.PaymentMethod(r.Method),
+		TransactionID:   r.TransactionID,
+		PaymentMethodID: r.PaymentMethodID,
+		StatusReason:    r.StatusReason,
+		ShippingCountry: r.ShippingCountry,
+		CardFingerprint: r.CardFingerprint,
+		RiskDecision:    entities.RiskDecision(r.RiskDecision),
+		RiskReasons:     reasons,
+		Refunds:         refunds,
+		CreatedAt:       r.CreatedAt,
+		UpdatedAt:       r.UpdatedAt,
+	}, nil
+}
+
+func entitiesFromRecords(recs []PaymentRecord) ([]*entities.Payment, error) {
+	out := make([]*entities.Payment, 0, len(recs))
+	for _, rec := range recs {
+		p, err := entityFromRecord(rec)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+type GormPaymentRepository struct {
+	db *gorm.DB
+}
+
+func NewGormPaymentRepository(db *gorm.DB) *GormPaymentRepository {
+	return &GormPaymentRepository{db: db}
+}
+
+func (r *GormPaymentRepository) Create(ctx context.Context, payment *entities.Payment) error {
+	rec := recordFromEntity(payment)
+	return r.db.WithContext(ctx).Create(&rec).Error
+}
+
+func (r *GormPaymentRepository) Update(ctx context.Context, payment *entities.Payment) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return saveRecord(tx, recordFromEntity(payment))
+	})
+}
+
+// Modify loads payment under a row lock, applies fn and saves the result in one transaction
+func (r *GormPaymentRepository) Modify(ctx context.Context, id string, fn func(*entities.Payment) error) (*entities.Payment, error) {
+	var payment *entities.Payment
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rec PaymentRecord
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&rec, "id = ?", id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return derrors.ErrPaymentNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("payment_id = ?", id).Order("created_at ASC").Find(&rec.Refunds).Error; err != nil {
+			return err
+		}
+		p, err := entityFromRecord(rec)
+		if err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+		payment = p
+		return saveRecord(tx, recordFromEntity(p))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+func saveRecord(tx *gorm.DB, rec PaymentRecord) error {
+	result := tx.Model(&PaymentRecord{}).Where("id = ?", rec.ID).Updates(map[string]interface{}{
+		"status":         rec.Status,
+		"transaction_id": rec.TransactionID,
+		"status_reason":  rec.StatusReason,
+		"risk_decision":  rec.RiskDecision,
+		"risk_reasons":   rec.RiskReasons,
+		"updated_at":     rec.UpdatedAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return derrors.ErrPaymentNotFound
+	}
+	for i := range rec.Refunds {
+		if err := tx.Save(&rec.Refunds[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *GormPaymentRepository) GetByID(ctx context.Context, id string) (*entities.Payment, error) {
+	var rec PaymentRecord
+	result := r.db.WithContext(ctx).Preload("Refunds", refundsOrder).First(&rec, "id = ?", id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, derrors.ErrPaymentNotFound
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return entityFromRecord(rec)
+}
+
+func (r *GormPaymentRepository) GetByTransactionID(ctx context.Context, transactionID string) (*entities.Payment, error) {
+	if transactionID == "" {
+		return nil, derrors.ErrPaymentNotFound
+	}
+	var rec PaymentRecord
+	result := r.db.WithContext(ctx).Preload("Refunds", refundsOrder).First(&rec, "transaction_id = ?", transactionID)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, derrors.ErrPaymentNotFound
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return entityFromRecord(rec)
+}
+
+func (r *GormPaymentRepository) GetByOrderID(ctx context.Context, orderID string) ([]*entities.Payment, error) {
+	var recs []PaymentRecord
+	if err := r.db.WithContext(ctx).
+		Preload("Refunds", refundsOrder).
+		Where("order_id = ?", orderID).
+		Order("created_at DESC").
+		Find(&recs).Error; err != nil {
+		return nil, err
+	}
+	return entitiesFromRecords(recs)
+}
+
+func (r *GormPaymentRepository) GetByUserID(ctx context.Context, userID string, page, limit int) ([]*entities.Payment, int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&PaymentRecord{}).
+		Where("user_id = ?", userID).
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var recs []PaymentRecord
+	offset := (page - 1) * limit
+	if err := r.db.WithContext(ctx).
+		Preload("Refunds", refundsOrder).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&recs).Error; err != nil {
+		return nil, 0, err
+	}
+	payments, err := entitiesFromRecords(recs)
+	if err != nil {
+		return nil, 0, err
+	}
+	return payments, total, nil
+}
+
+// GetByStatus returns a page of payments in status, oldest first
+func (r *GormPaymentRepository) GetByStatus(ctx context.Context, status entities.PaymentStatus, page, limit int) ([]*entities.Payment, int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&PaymentRecord{}).
+		Where("status = ?", string(status)).
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var recs []PaymentRecord
+	offset := (page - 1) * limit
+	if err := r.db.WithContext(ctx).
+		Preload("Refunds", refundsOrder).
+		Where("status = ?", string(status)).
+		Order("created_at ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&recs).Error; err != nil {
+		return nil, 0, err
+	}
+	payments, err := entitiesFromRecords(recs)
+	if err != nil {
+		return nil, 0, err
+	}
+	return payments, total, nil
+}
+
+func (r *GormPaymentRepository) CountUserAttempts(ctx context.Context, userID string, since time.Time) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&PaymentRecord{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Count(&n).Error
+	return n, err
+}
+
+func (r *GormPaymentRepository) CountCardAttempts(ctx context.Context, fingerprint string, since time.Time) (int64, error) {
+	if fingerprint == "" {
+		return 0, nil
+	}
+	var n int64
+	err := r.db.WithContext(ctx).Model(&PaymentRecord{}).
+		Where("card_fingerprint = ? AND created_at >= ?", fingerprint, since).
+		Count(&n).Error
+	return n, err
+}
+
+// FailureStreak skips payments not decided yet (pending or held for review)
+func (r *GormPaymentRepository) FailureStreak(ctx context.Context, userID string, limit int) (int, error) {
+	var statuses []string
+	if err := r.db.WithContext(ctx).Model(&PaymentRecord{}).
+		Where("user_id = ? AND status NOT IN ?", userID, []string{string(entities.PaymentPending), string(entities.PaymentInReview)}).
+		Order("created_at DESC").
+		Limit(limit).
+		Pluck("status", &statuses).Error; err != nil {
+		return 0, err
+	}
+	streak := 0
+	for _, s := range statuses {
+		if s != string(entities.PaymentFailed) {
+			break
+		}
+		streak++
+	}
+	return streak, nil
+}
+
+// AutoMigrate creates tables
+func (r *GormPaymentRepository) AutoMigrate() error {
+	return r.db.AutoMigrate(&PaymentRecord{}, &RefundRecord{})
+}
+
+func refundsOrder(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }
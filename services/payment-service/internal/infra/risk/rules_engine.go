@@ -0,0 +1,166 @@
+package riskimpl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/entities"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/ports/risk"
+)
+
+// Limits are the values at which a rule asks for a review and denies a payment; 0 disables a level
+type Limits struct {
+	Review int64
+	Deny   int64
+}
+
+func (l Limits) decide(n int64) entities.RiskDecision {
+	switch {
+	case l.Deny > 0 && n >= l.Deny:
+		return entities.RiskDeny
+	case l.Review > 0 && n >= l.Review:
+		return entities.RiskReview
+	}
+	return entities.RiskAllow
+}
+
+// RulesConfig tunes the RulesEngine; zero limits disable a rule
+type RulesConfig struct {
+	// VelocityWindow is the period UserVelocity and CardVelocity count payments in
+	VelocityWindow time.Duration
+	UserVelocity   Limits
+	CardVelocity   Limits
+	// AmountThresholds per currency, in minor units; other currencies are not checked
+	AmountThresholds map[string]Limits
+	// FailureStreak counts the most recent payments of the user that failed in a row
+	FailureStreak Limits
+	// ReviewCountryMismatch holds payments shipped to another country than the card's billing country
+	ReviewCountryMismatch bool
+}
+
+// DefaultRulesConfig returns the limits used when none are configured
+func DefaultRulesConfig() RulesConfig {
+	return RulesConfig{
+		VelocityWindow: time.Hour,
+		UserVelocity:   Limits{Review: 5, Deny: 10},
+		CardVelocity:   Limits{Review: 5, Deny: 10},
+		AmountThresholds: map[string]Limits{
+			"USD": {Review: 100000, Deny: 500000},
+			"EUR": {Review: 100000, Deny: 500000},
+			"GBP": {Review: 80000, Deny: 400000},
+			"JPY": {Review: 150000, Deny: 750000},
+		},
+		FailureStreak:         Limits{Review: 3, Deny: 5},
+		ReviewCountryMismatch: true,
+	}
+}
+
+// RulesEngine evaluates payments against fixed rules: velocity per user and per card, amount
+// thresholds per currency, shipping and billing country mismatch and failed attempts in a row.
+// The most severe decision of all rules wins and every rule that flagged the payment is
+// reported as a reason.
+type RulesEngine struct {
+	history risk.History
+	cfg     RulesConfig
+	now     func() time.Time
+}
+
+func NewRulesEngine(history risk.History, cfg RulesConfig) *RulesEngine {
+	if cfg.VelocityWindow <= 0 {
+		cfg.VelocityWindow = time.Hour
+	}
+	return &RulesEngine{history: history, cfg: cfg, now: time.Now}
+}
+
+func (e *RulesEngine) Evaluate(ctx context.Context, in risk.Input) (risk.Assessment, error) {
+	out := risk.Assessment{Decision: entities.RiskAllow}
+	flag := func(d entities.RiskDecision, reason string) {
+		if d.Severity() <= entities.RiskAllow.Severity() {
+			return
+		}
+		if d.Severity() > out.Decision.Severity() {
+			out.Decision = d
+		}
+		out.Reasons = append(out.Reasons, reason)
+	}
+
+	since := e.now().Add(-e.cfg.VelocityWindow)
+	if e.cfg.UserVelocity != (Limits{}) && in.UserID != "" {
+		n, err := e.history.CountUserAttempts(ctx, in.UserID, since)
+		if err != nil {
+			return risk.Assessment{}, fmt.Errorf("user velocity: %w", err)
+		}
+		flag(e.cfg.UserVelocity.decide(n), fmt.Sprintf("%d payments by the user within %s", n, e.cfg.VelocityWindow))
+	}
+	if e.cfg.CardVelocity != (Limits{}) && in.CardFingerprint != "" {
+		n, err := e.history.CountCardAttempts(ctx, in.CardFingerprint, since)
+		if err != nil {
+			return risk.Assessment{}, fmt.Errorf("card velocity: %w", err)
+		}
+		flag(e.cfg.CardVelocity.decide(n), fmt.Sprintf("%d payments with the card within %s", n, e.cfg.VelocityWindow))
+	}
+	if limits, ok := e.cfg.AmountThresholds[strings.ToUpper(in.Currency)]; ok {
+		d := limits.decide(in.Amount)
+		threshold := limits.Review
+		if d == entities.RiskDeny {
+			threshold = limits.Deny
+		}
+		flag(d, fmt.Sprintf("amount %d %s reaches the %s threshold of %d", in.Amount, in.Currency, strings.ToLower(string(d)), threshold))
+	}
+	if e.cfg.ReviewCountryMismatch && in.BillingCountry != "" && in.ShippingCountry != "" &&
+		!strings.EqualFold(in.BillingCountry, in.ShippingCountry) {
+		flag(entities.RiskReview, fmt.Sprintf("shipping country %s differs from card billing country %s", in.ShippingCountry, in.BillingCountry))
+	}
+	if e.cfg.FailureStreak != (Limits{}) && in.UserID != "" {
+		limit := max(e.cfg.FailureStreak.Review, e.cfg.FailureStreak.Deny)
+		n, err := e.history.FailureStreak(ctx, in.UserID, int(limit))
+		if err != nil {
+			return risk.Assessment{}, fmt.Errorf("failure streak: %w", err)
+		}
+		flag(e.cfg.FailureStreak.decide(int64(n)), fmt.Sprintf("%d failed payments in a row", n))
+	}
+	return out, nil
+}
+
+// ParseLimits parses "review/deny" (e.g. "5/10"); either side may be 0 to disable it
+func ParseLimits(s string) (Limits, error) {
+	review, deny, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Limits{}, fmt.Errorf("risk limits %q: want review/deny", s)
+	}
+	var l Limits
+	var err error
+	if l.Review, err = strconv.ParseInt(strings.TrimSpace(review), 10, 64); err != nil || l.Review < 0 {
+		return Limits{}, fmt.Errorf("risk limits %q: invalid review limit", s)
+	}
+	if l.Deny, err = strconv.ParseInt(strings.TrimSpace(deny), 10, 64); err != nil || l.Deny < 0 {
+		return Limits{}, fmt.Errorf("risk limits %q: invalid deny limit", s)
+	}
+	return l, nil
+}
+
+// ParseAmountThresholds parses comma separated "CUR=review/deny" thresholds in minor units,
+// e.g. "USD=100000/500000,EUR=100000/500000"
+func ParseAmountThresholds(s string) (map[string]Limits, error) {
+	out := make(map[string]Limits)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		currency, limits, ok := strings.Cut(part, "=")
+		currency = strings.ToUpper(strings.TrimSpace(currency))
+		if !ok || len(currency) != 3 {
+			return nil, fmt.Errorf("risk amount threshold %q: want CUR=review/deny", part)
+		}
+		l, err := ParseLimits(limits)
+		if err != nil {
+			return nil, err
+		}
+		out[currency] = l
+	}
+	return out, nil
+}
@@ -0,0 +1,128 @@
+package vaultimpl
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the length of vault keys (AES-256)
+const KeySize = 32
+
+const (
+	formatV1  byte = 1
+	keyIDSize      = 4
+)
+
+// Cipher encrypts card numbers with AES-256-GCM. Sealed data starts with a format byte and the
+// ID of its key, so keys can be rotated: data is sealed with the primary key and previous keys
+// still open what they sealed. Fingerprints use a separate key that is never rotated.
+type Cipher struct {
+	primary        keyEntry
+	keys           map[string]cipher.AEAD
+	fingerprintKey []byte
+}
+
+type keyEntry struct {
+	id   []byte
+	aead cipher.AEAD
+}
+
+// NewCipher creates a cipher fingerprinting cards with fingerprintKey, sealing with primary and
+// also opening data sealed with previous keys
+func NewCipher(fingerprintKey, primary []byte, previous ...[]byte) (*Cipher, error) {
+	if len(fingerprintKey) != KeySize {
+		return nil, fmt.Errorf("vault fingerprint key must be %d bytes, got %d", KeySize, len(fingerprintKey))
+	}
+	c := &Cipher{keys: make(map[string]cipher.AEAD)}
+	for i, key := range append([][]byte{primary}, previous...) {
+		e, err := newKeyEntry(key)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			c.primary = e
+		}
+		c.keys[string(e.id)] = e.aead
+	}
+	mac := hmac.New(sha256.New, fingerprintKey)
+	mac.Write([]byte("card-fingerprint"))
+	c.fingerprintKey = mac.Sum(nil)
+	return c, nil
+}
+
+// ParseKey decodes a base64 encoded vault key
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("vault key is not base64: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("vault key must be %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+func newKeyEntry(key []byte) (keyEntry, error) {
+	if len(key) != KeySize {
+		return keyEntry{}, fmt.Errorf("vault key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return keyEntry{}, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return keyEntry{}, err
+	}
+	sum := sha256.Sum256(key)
+	return keyEntry{id: sum[:keyIDSize], aead: aead}, nil
+}
+
+// Seal encrypts plaintext; aad binds the result to its context (e.g. token and user) and must
+// be passed to Open unchanged
+func (c *Cipher) Seal(plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, c.primary.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, 1+keyIDSize+len(nonce)+len(plaintext)+c.primary.aead.Overhead())
+	out = append(out, formatV1)
+	out = append(out, c.primary.id...)
+	out = append(out, nonce...)
+	return c.primary.aead.Seal(out, nonce, plaintext, aad), nil
+}
+
+// Open decrypts data sealed by Seal with any of the cipher keys
+func (c *Cipher) Open(sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < 1+keyIDSize || sealed[0] != formatV1 {
+		return nil, errors.New("vault: unknown ciphertext format")
+	}
+	aead, ok := c.keys[string(sealed[1:1+keyIDSize])]
+	if !ok {
+		return nil, fmt.Errorf("vault: no key %s to open ciphertext", hex.EncodeToString(sealed[1:1+keyIDSize]))
+	}
+	rest := sealed[1+keyIDSize:]
+	if len(rest) < aead.NonceSize() {
+		return nil, errors.New("vault: ciphertext too short")
+	}
+	plaintext, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], aad)
+	if err != nil {
+		return nil, fmt.Errorf("vault: open ciphertext: %w", err)
+	}
+	return plaintext, nil
+}
+
+// Fingerprint is a keyed hash of a card number that identifies a card without revealing it.
+// It does not depend on the encryption keys, so it stays the same across key rotations.
+func (c *Cipher) Fingerprint(cardNumber string) string {
+	mac := hmac.New(sha256.New, c.fingerprintKey)
+	mac.Write([]byte(cardNumber))
+	return hex.EncodeToString(mac.Sum(nil))
+}
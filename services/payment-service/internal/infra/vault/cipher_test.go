@@ -0,0 +1,112 @@
+package vaultimpl
+
+import (
+	"bytes"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, KeySize)
+}
+
+func TestCipherRotation(t *testing.T) {
+	fingerprintKey, oldKey, newKey, otherKey := testKey(0xf0), testKey(1), testKey(2), testKey(3)
+	aad := []byte("tok_1|user-1")
+
+	before, err := NewCipher(fingerprintKey, oldKey)
+	if err != nil {
+		t.Fatalf("NewCipher: %v", err)
+	}
+	sealed, err := before.Seal([]byte("4242424242424242"), aad)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		primary  []byte
+		previous [][]byte
+		aad      []byte
+		wantErr  bool
+	}{
+		{name: "same key", primary: oldKey, aad: aad},
+		{name: "rotated with previous key", primary: newKey, previous: [][]byte{oldKey}, aad: aad},
+		{name: "rotated among several previous keys", primary: newKey, previous: [][]byte{otherKey, oldKey}, aad: aad},
+		{name: "previous key dropped", primary: newKey, previous: [][]byte{otherKey}, aad: aad, wantErr: true},
+		{name: "other context", primary: newKey, previous: [][]byte{oldKey}, aad: []byte("tok_1|user-2"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after, err := NewCipher(fingerprintKey, tt.primary, tt.previous...)
+			if err != nil {
+				t.Fatalf("NewCipher: %v", err)
+			}
+			got, err := after.Open(sealed, tt.aad)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Open succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			if string(got) != "4242424242424242" {
+				t.Errorf("Open = %q", got)
+			}
+			// data sealed after the rotation uses the new primary key
+			resealed, err := after.Seal(got, tt.aad)
+			if err != nil {
+				t.Fatalf("Seal: %v", err)
+			}
+			primaryOnly, err := NewCipher(fingerprintKey, tt.primary)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := primaryOnly.Open(resealed, tt.aad); err != nil {
+				t.Errorf("resealed data needs a previous key: %v", err)
+			}
+		})
+	}
+}
+
+func TestCipherFingerprint(t *testing.T) {
+	fingerprintKey := testKey(0xf0)
+	before, err := NewCipher(fingerprintKey, testKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		fingerprintKey []byte
+		primary        []byte
+		card           string
+		wantSame       bool
+	}{
+		{name: "same card after key rotation", fingerprintKey: fingerprintKey, primary: testKey(2), card: "4242424242424242", wantSame: true},
+		{name: "other card", fingerprintKey: fingerprintKey, primary: testKey(1), card: "4000000000000002"},
+		{name: "other fingerprint key", fingerprintKey: testKey(0xf1), primary: testKey(1), card: "4242424242424242"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after, err := NewCipher(tt.fingerprintKey, tt.primary, testKey(1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			same := before.Fingerprint("4242424242424242") == after.Fingerprint(tt.card)
+			if same != tt.wantSame {
+				t.Errorf("fingerprints equal = %v, want %v", same, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestNewCipherRejectsShortKeys(t *testing.T) {
+	if _, err := NewCipher(testKey(1)[:16], testKey(2)); err == nil {
+		t.Error("short fingerprint key accepted")
+	}
+	if _, err := NewCipher(testKey(1), testKey(2)[:16]); err == nil {
+		t.Error("short primary key accepted")
+	}
+}
@@ -0,0 +1,166 @@
+package vaultimpl
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/entities"
+	derrors "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PaymentMethodRecord is a saved card; the card number is only stored sealed by the Cipher
+type PaymentMethodRecord struct {
+	ID             string    `gorm:"primaryKey;type:varchar(64)"`
+	UserID         string    `gorm:"not null;type:varchar(255);index:idx_payment_methods_user_card,unique"`
+	Fingerprint    string    `gorm:"not null;type:varchar(64);index:idx_payment_methods_user_card,unique"`
+	Method         string    `gorm:"type:varchar(32);not null"`
+	Brand          string    `gorm:"type:varchar(16);not null"`
+	Last4          string    `gorm:"type:varchar(4);not null"`
+	ExpiryMonth    int       `gorm:"not null"`
+	ExpiryYear     int       `gorm:"not null"`
+	CardHolder     string    `gorm:"type:varchar(100)"`
+	BillingCountry string    `gorm:"type:varchar(2)"`
+	EncryptedCard  []byte    `gorm:"type:bytea;not null"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+}
+
+func (PaymentMethodRecord) TableName() string { return "payment_methods" }
+
+func (r PaymentMethodRecord) entity() *entities.SavedPaymentMethod {
+	return &entities.SavedPaymentMethod{
+		ID:             r.ID,
+		UserID:         r.UserID,
+		Method:         entities.PaymentMethod(r.Method),
+		Brand:          r.Brand,
+		Last4:          r.Last4,
+		ExpiryMonth:    r.ExpiryMonth,
+		ExpiryYear:     r.ExpiryYear,
+		CardHolder:     r.CardHolder,
+		BillingCountry: r.BillingCountry,
+		Fingerprint:    r.Fingerprint,
+		CreatedAt:      r.CreatedAt,
+	}
+}
+
+// GormVault keeps saved cards in Postgres, card numbers encrypted with the Cipher.
+// The sealed number is bound to its token and user, so it cannot be moved to another row.
+type GormVault struct {
+	db     *gorm.DB
+	cipher *Cipher
+}
+
+func NewGormVault(db *gorm.DB, cipher *Cipher) *GormVault {
+	return &GormVault{db: db, cipher: cipher}
+}
+
+func (v *GormVault) Store(ctx context.Context, m *entities.SavedPaymentMethod, cardNumber string) (*entities.SavedPaymentMethod, error) {
+	sealed, err := v.cipher.Seal([]byte(cardNumber), aad(m.UserID, m.ID))
+	if err != nil {
+		return nil, err
+	}
+	rec := PaymentMethodRecord{
+		ID:             m.ID,
+		UserID:         m.UserID,
+		Fingerprint:    v.cipher.Fingerprint(cardNumber),
+		Method:         string(m.Method),
+		Brand:          m.Brand,
+		Last4:          m.Last4,
+		ExpiryMonth:    m.ExpiryMonth,
+		ExpiryYear:     m.ExpiryYear,
+		CardHolder:     m.CardHolder,
+		BillingCountry: m.BillingCountry,
+		EncryptedCard:  sealed,
+		CreatedAt:      m.CreatedAt,
+	}
+	result := v.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "fingerprint"}},
+		DoNothing: true,
+	}).Create(&rec)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		// The user saved this card before
+		var existing PaymentMethodRecord
+		if err := v.db.WithContext(ctx).First(&existing, "user_id = ? AND fingerprint = ?", rec.UserID, rec.Fingerprint).Error; err != nil {
+			return nil, err
+		}
+		return existing.entity(), nil
+	}
+	return rec.entity(), nil
+}
+
+func (v *GormVault) Get(ctx context.Context, userID, token string) (*entities.SavedPaymentMethod, error) {
+	rec, err := v.find(ctx, userID, token)
+	if err != nil {
+		return nil, err
+	}
+	return rec.entity(), nil
+}
+
+func (v *GormVault) List(ctx context.Context, userID string) ([]*entities.SavedPaymentMethod, error) {
+	var recs []PaymentMethodRecord
+	if err := v.db.WithContext(ctx).
+		Omit("encrypted_card").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&recs).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*entities.SavedPaymentMethod, 0, len(recs))
+	for _, rec := range recs {
+		out = append(out, rec.entity())
+	}
+	return out, nil
+}
+
+// Delete removes the saved card including its sealed number
+func (v *GormVault) Delete(ctx context.Context, userID, token string) error {
+	result := v.db.WithContext(ctx).Where("id = ? AND user_id = ?", token, userID).Delete(&PaymentMethodRecord{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return derrors.ErrPaymentMethodNotFound
+	}
+	return nil
+}
+
+func (v *GormVault) Reveal(ctx context.Context, userID, token string) (string, error) {
+	rec, err := v.find(ctx, userID, token)
+	if err != nil {
+		return "", err
+	}
+	number, err := v.cipher.Open(rec.EncryptedCard, aad(rec.UserID, rec.ID))
+	if err != nil {
+		return "", err
+	}
+	return string(number), nil
+}
+
+// Fingerprint identifies a card number the way saved cards are identified
+func (v *GormVault) Fingerprint(cardNumber string) string {
+	return v.cipher.Fingerprint(entities.NormalizeCardNumber(cardNumber))
+}
+
+// AutoMigrate creates the payment methods table
+func (v *GormVault) AutoMigrate() error {
+	return v.db.AutoMigrate(&PaymentMethodRecord{})
+}
+
+func (v *GormVault) find(ctx context.Context, userID, token string) (PaymentMethodRecord, error) {
+	var rec PaymentMethodRecord
+	err := v.db.WithContext(ctx).First(&rec, "id = ? AND user_id = ?", token, userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return PaymentMethodRecord{}, derrors.ErrPaymentMethodNotFound
+	}
+	return rec, err
+}
+
+func aad(userID, token string) []byte {
+	return []byte(userID + "\x00" + token)
+}
@@ -0,0 +1,162 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/pkg/kafkaclient"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/entities"
+	derrors "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/errors"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/gateway"
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/infra/processor"
+	procport "github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/ports/processor"
+
+	"go.uber.org/zap"
+)
+
+// GatewayPath is where the payment gateway delivers its webhooks
+const GatewayPath = "/webhooks/gateway"
+
+const maxPayload = 1 << 20
+
+// Updater applies asynchronous processor outcomes to payments
+type Updater interface {
+	ApplyProcessorUpdate(ctx context.Context, u procport.Update) (*entities.Payment, error)
+}
+
+// GatewayHandler receives payment gateway webhooks. Requests must be signed with the shared
+// secret and recent (tolerance), and each event ID is applied once via the processed ledger,
+// so captured or redelivered requests cannot replay an outcome.
+// A 2xx response acknowledges the event; anything else makes the gateway deliver it again.
+type GatewayHandler struct {
+	updates   Updater
+	secret    string
+	tolerance time.Duration
+	processed kafkaclient.ProcessedStore
+	lease     time.Duration
+	timeout   time.Duration
+	logger    *zap.SugaredLogger
+}
+
+// NewGatewayHandler creates the webhook receiver; tolerance defaults to 5 minutes
+func NewGatewayHandler(updates Updater, secret string, tolerance time.Duration) *GatewayHandler {
+	if tolerance <= 0 {
+		tolerance = 5 * time.Minute
+	}
+	return &GatewayHandler{
+		updates:   updates,
+		secret:    secret,
+		tolerance: tolerance,
+		lease:     time.Minute,
+		timeout:   10 * time.Second,
+	}
+}
+
+// WithProcessed sets the ledger deduplicating event IDs
+func (h *GatewayHandler) WithProcessed(store kafkaclient.ProcessedStore) *GatewayHandler {
+	h.processed = store
+	return h
+}
+
+// WithLogger sets logger
+func (h *GatewayHandler) WithLogger(l *zap.Logger) *GatewayHandler {
+	if l != nil {
+		h.logger = l.Sugar()
+	}
+	return h
+}
+
+// Handler returns the HTTP handler serving GatewayPath
+func (h *GatewayHandler) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("POST "+GatewayPath, h)
+	return mux
+}
+
+func (h *GatewayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxPayload+1))
+	if err != nil || len(payload) > maxPayload {
+		http.Error(w, "unreadable payload", http.StatusBadRequest)
+		return
+	}
+	evt, err := gateway.ParseEvent(payload, r.Header.Get(gateway.SignatureHeader), h.secret, h.tolerance, time.Now())
+	if err != nil {
+		h.warn("gateway webhook rejected", "error", err)
+		http.Error(w, "invalid webhook", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+	key := "gateway-webhook|" + evt.ID
+	if h.processed != nil {
+		ok, err := h.processed.Begin(ctx, key, h.lease)
+		if err != nil {
+			h.error("gateway webhook ledger failed", "eventID", evt.ID, "error", err)
+			http.Error(w, "try again", http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+
+	status := h.apply(ctx, evt)
+	if h.processed != nil {
+		// The ledger outlives the request; a client gone away must not leave the claim behind
+		lctx := context.WithoutCancel(ctx)
+		if status == http.StatusOK {
+			err = h.processed.Complete(lctx, key)
+		} else {
+			err = h.processed.Abort(lctx, key)
+		}
+		if err != nil {
+			h.error("gateway webhook ledger failed", "eventID", evt.ID, "error", err)
+		}
+	}
+	w.WriteHeader(status)
+}
+
+// apply translates the event and applies it, returning the response status
+func (h *GatewayHandler) apply(ctx context.Context, evt *gateway.Event) int {
+	u, ok, err := processor.GatewayUpdate(evt)
+	if err != nil {
+		h.warn("gateway webhook malformed", "eventID", evt.ID, "type", evt.Type, "error", err)
+		return http.StatusBadRequest
+	}
+	if !ok {
+		// Events without a payment outcome are acknowledged and ignored
+		return http.StatusOK
+	}
+	p, err := h.updates.ApplyProcessorUpdate(ctx, u)
+	switch {
+	case errors.Is(err, derrors.ErrPaymentNotFound), errors.Is(err, derrors.ErrRefundNotFound):
+		// The event may have overtaken the call that created the payment or refund; the
+		// gateway retries it
+		h.warn("gateway webhook for unknown payment", "eventID", evt.ID, "type", evt.Type, "transactionID", u.TransactionID, "error", err)
+		return http.StatusNotFound
+	case err != nil:
+		h.error("gateway webhook failed", "eventID", evt.ID, "type", evt.Type, "transactionID", u.TransactionID, "error", err)
+		return http.StatusInternalServerError
+	}
+	if h.logger != nil {
+		h.logger.Infow("gateway webhook applied", "eventID", evt.ID, "type", evt.Type, "paymentID", p.ID, "status", p.Status)
+	}
+	return http.StatusOK
+}
+
+func (h *GatewayHandler) warn(msg string, kv ...any) {
+	if h.logger != nil {
+		h.logger.Warnw(msg, kv...)
+	}
+}
+
+func (h *GatewayHandler) error(msg string, kv ...any) {
+	if h.logger != nil {
+		h.logger.Errorw(msg, kv...)
+	}
+}
@@ -13,6 +13,12 @@ type PaymentMetrics interface {
 	// Payment business metrics
 	PaymentSucceeded(method string)
 	PaymentFailed(reason string)
+	PaymentCaptured(method string)
+	PaymentVoided(method string)
+	PaymentRefunded(method string)
+	// RefundFailed counts declined refunds; reason is one of the processor refund decline codes
+	RefundFailed(method, reason string)
+	RiskDecision(decision string)
 
 	// Processing time metrics
 	PaymentProcessingDuration(duration time.Duration, method string)
@@ -29,6 +35,11 @@ type PaymentPrometheusMetrics struct {
 	paymentSucceededTotal *prometheus.CounterVec
 	paymentFailedTotal    *prometheus.CounterVec
 	paymentDuration       *prometheus.HistogramVec
+	captureTotal          *prometheus.CounterVec
+	voidTotal             *prometheus.CounterVec
+	refundSucceededTotal  *prometheus.CounterVec
+	refundFailedTotal     *prometheus.CounterVec
+	riskDecisionTotal     *prometheus.CounterVec
 }
 
 // NewPaymentMetrics creates new payment service metrics instance
@@ -59,6 +70,41 @@ func NewPaymentMetrics() PaymentMetrics {
 			},
 			[]string{"service", "method"},
 		),
+		captureTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "payment_captured_total",
+				Help: "Total number of captured payment authorizations",
+			},
+			[]string{"service", "method", "status"},
+		),
+		voidTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "payment_voided_total",
+				Help: "Total number of voided payment authorizations",
+			},
+			[]string{"service", "method", "status"},
+		),
+		refundSucceededTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "payment_refund_succeeded_total",
+				Help: "Total number of successful refunds",
+			},
+			[]string{"service", "method"},
+		),
+		refundFailedTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "payment_refund_failed_total",
+				Help: "Total number of failed refunds",
+			},
+			[]string{"service", "method", "failure_reason"},
+		),
+		riskDecisionTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "payment_risk_decision_total",
+				Help: "Total number of payment risk evaluations by decision",
+			},
+			[]string{"service", "decision"},
+		),
 	}
 }
 
@@ -76,3 +122,28 @@ func (m *PaymentPrometheusMetrics) PaymentFailed(reason string) {
 func (m *PaymentPrometheusMetrics) PaymentProcessingDuration(duration time.Duration, method string) {
 	m.paymentDuration.WithLabelValues("payment-service", method).Observe(duration.Seconds())
 }
+
+// PaymentCaptured increments captured authorization counter
+func (m *PaymentPrometheusMetrics) PaymentCaptured(method string) {
+	m.captureTotal.WithLabelValues("payment-service", method, "success").Inc()
+}
+
+// PaymentVoided increments voided authorization counter
+func (m *PaymentPrometheusMetrics) PaymentVoided(method string) {
+	m.voidTotal.WithLabelValues("payment-service", method, "success").Inc()
+}
+
+// PaymentRefunded increments successful refund counter
+func (m *PaymentPrometheusMetrics) PaymentRefunded(method string) {
+	m.refundSucceededTotal.WithLabelValues("payment-service", method).Inc()
+}
+
+// RefundFailed increments failed refund counter with reason
+func (m *PaymentPrometheusMetrics) RefundFailed(method, reason string) {
+	m.refundFailedTotal.WithLabelValues("payment-service", method, reason).Inc()
+}
+
+// RiskDecision increments risk evaluation counter by decision
+func (m *PaymentPrometheusMetrics) RiskDecision(decision string) {
+	m.riskDecisionTotal.WithLabelValues("payment-service", decision).Inc()
+}
@@ -0,0 +1,14 @@
+package events
+
+import (
+	"context"
+
+	events "github.com/kubernetestest/ecommerce-platform/proto-go/events"
+)
+
+// Publisher defines contract to publish payment domain events
+type Publisher interface {
+	PublishPaymentProcessed(ctx context.Context, evt *events.PaymentProcessed) error
+	PublishPaymentRefunded(ctx context.Context, evt *events.PaymentRefunded) error
+	PublishPaymentReviewRequired(ctx context.Context, evt *events.PaymentReviewRequired) error
+}
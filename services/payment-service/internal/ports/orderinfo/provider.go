@@ -0,0 +1,29 @@
+package orderinfo
+
+import "context"
+
+// OrderPayment is the authoritative amount of an order and the payment the user chose for it
+type OrderPayment struct {
+	OrderID  string
+	UserID   string
+	Amount   int64  // minor units
+	Currency string // ISO 4217
+	Method   string // CREDIT_CARD, DEBIT_CARD, PAYPAL, BANK_TRANSFER
+	// PaymentMethodToken is the saved card (vault token) to charge, card methods only
+	PaymentMethodToken string
+	// ShippingCountry is where the order ships (ISO 3166-1 alpha-2), empty if not given
+	ShippingCountry string
+	// Status of the order: PENDING, PAYMENT_REVIEW, CONFIRMED, PROCESSING, SHIPPED, DELIVERED or CANCELLED
+	Status string
+}
+
+// AwaitingPayment reports whether the order may still be charged
+func (o *OrderPayment) AwaitingPayment() bool {
+	return o.Status == "PENDING" || o.Status == "PAYMENT_REVIEW"
+}
+
+// Provider abstracts order lookup (e.g., via order-service).
+// Unknown orders are reported as errors.ErrOrderNotFound.
+type Provider interface {
+	GetOrderPayment(ctx context.Context, orderID string) (*OrderPayment, error)
+}
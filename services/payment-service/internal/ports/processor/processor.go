@@ -2,16 +2,110 @@ package processor
 
 import "context"
 
-// PaymentProcessor abstracts payment processing decision logic
+// PaymentProcessor abstracts payment processing decision logic. Payments are two-phase:
+// an authorization holds the amount on the card and is later captured or voided.
+// Processors that decide asynchronously return a Pending result and report the outcome
+// later as an Update (webhook).
 type PaymentProcessor interface {
-	Process(ctx context.Context, req ProcessRequest) (ProcessResult, error)
+	Authorize(ctx context.Context, req AuthorizeRequest) (AuthorizeResult, error)
+	Capture(ctx context.Context, req CaptureRequest) (CaptureResult, error)
+	Void(ctx context.Context, req VoidRequest) (VoidResult, error)
+	Refund(ctx context.Context, req RefundRequest) (RefundResult, error)
 }
 
-type ProcessRequest struct {
+type AuthorizeRequest struct {
+	// PaymentID identifies the attempt; processors use it as idempotency key
+	PaymentID  string
+	OrderID    string
+	Amount     int64  // minor units
+	Currency   string // ISO 4217
+	Method     string // CREDIT_CARD, DEBIT_CARD, PAYPAL, BANK_TRANSFER
 	CardNumber string
 }
 
-type ProcessResult struct {
+type AuthorizeResult struct {
 	Success       bool
 	FailureReason string
+	// DeclineCode is a short machine-readable reason of a declined payment (e.g. "expired_card")
+	DeclineCode string
+	// TransactionID is the processor reference of the authorization attempt, empty if it assigns none
+	TransactionID string
+	// Pending means the processor has not decided yet; the outcome follows as an Update
+	Pending bool
+}
+
+type CaptureRequest struct {
+	// PaymentID identifies the payment; processors use it as idempotency key
+	PaymentID     string
+	TransactionID string
+	Amount        int64  // minor units
+	Currency      string // ISO 4217
+}
+
+type CaptureResult struct {
+	Success       bool
+	FailureReason string
+	// Pending means the capture is in flight at the processor; the outcome follows as an Update
+	Pending bool
+}
+
+type VoidRequest struct {
+	// PaymentID identifies the payment; processors use it as idempotency key
+	PaymentID     string
+	TransactionID string
+}
+
+type VoidResult struct {
+	Success       bool
+	FailureReason string
+}
+
+type RefundRequest struct {
+	// RefundID identifies the refund; processors use it as idempotency key
+	RefundID      string
+	TransactionID string
+	Amount        int64  // minor units
+	Currency      string // ISO 4217
+}
+
+// Decline codes of refunds, a fixed set processors map their refusals to
+const (
+	RefundDeclineRejected       = "rejected"        // the processor refused the refund
+	RefundDeclineInvalidRequest = "invalid_request" // the refund is invalid at the processor, e.g. exceeds what it can refund
+)
+
+type RefundResult struct {
+	Success       bool
+	FailureReason string
+	// DeclineCode is RefundDeclineRejected or RefundDeclineInvalidRequest for declined refunds
+	DeclineCode string
+	// TransactionID is the processor reference of the refund, empty if it assigns none
+	TransactionID string
+	// Pending means the refund is in flight at the processor; the outcome follows as an Update
+	Pending bool
+}
+
+// UpdateKind is the kind of an asynchronous processor outcome
+type UpdateKind string
+
+const (
+	UpdateAuthorized          UpdateKind = "authorized"
+	UpdateAuthorizationFailed UpdateKind = "authorization_failed"
+	UpdateCaptured            UpdateKind = "captured"
+	UpdateCaptureFailed       UpdateKind = "capture_failed"
+	UpdateVoided              UpdateKind = "voided"
+	UpdateRefunded            UpdateKind = "refunded"
+	UpdateRefundFailed        UpdateKind = "refund_failed"
+)
+
+// Update is an outcome the processor reports after the call that started it returned,
+// typically delivered by webhook
+type Update struct {
+	Kind UpdateKind
+	// TransactionID is the processor reference of the payment (authorization)
+	TransactionID string
+	// RefundTransactionID is the processor reference of the refund for refund updates
+	RefundTransactionID string
+	FailureReason       string
+	DeclineCode         string
 }
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/entities"
+)
+
+type PaymentRepository interface {
+	Create(ctx context.Context, payment *entities.Payment) error
+	Update(ctx context.Context, payment *entities.Payment) error
+	// Modify loads the payment under a lock, applies fn and persists it atomically
+	Modify(ctx context.Context, id string, fn func(*entities.Payment) error) (*entities.Payment, error)
+	GetByID(ctx context.Context, id string) (*entities.Payment, error)
+	// GetByTransactionID finds the payment the processor knows as transactionID
+	GetByTransactionID(ctx context.Context, transactionID string) (*entities.Payment, error)
+	GetByOrderID(ctx context.Context, orderID string) ([]*entities.Payment, error)
+	GetByUserID(ctx context.Context, userID string, page, limit int) ([]*entities.Payment, int64, error)
+	// GetByStatus returns a page of payments in status, oldest first
+	GetByStatus(ctx context.Context, status entities.PaymentStatus, page, limit int) ([]*entities.Payment, int64, error)
+}
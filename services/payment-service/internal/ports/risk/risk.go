@@ -0,0 +1,46 @@
+package risk
+
+import (
+	"context"
+	"time"
+
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/entities"
+)
+
+// RiskEvaluator screens a payment before it is sent to the processor. Payments it denies are
+// declined, payments it flags for review are held until an admin decides.
+type RiskEvaluator interface {
+	Evaluate(ctx context.Context, in Input) (Assessment, error)
+}
+
+type Input struct {
+	PaymentID string
+	OrderID   string
+	UserID    string
+	Amount    int64  // minor units
+	Currency  string // ISO 4217
+	Method    string // CREDIT_CARD, DEBIT_CARD, PAYPAL, BANK_TRANSFER
+	// CardFingerprint identifies the card charged, empty for non-card methods
+	CardFingerprint string
+	// BillingCountry of the card and ShippingCountry of the order (ISO 3166-1 alpha-2), if known
+	BillingCountry  string
+	ShippingCountry string
+}
+
+type Assessment struct {
+	Decision entities.RiskDecision
+	// Reasons lists the rules that flagged the payment, empty when it is allowed
+	Reasons []string
+}
+
+// History is the payment history risk rules look at. Counts include the payment being
+// evaluated, which is recorded before it is screened.
+type History interface {
+	// CountUserAttempts counts the payments a user started since the given time
+	CountUserAttempts(ctx context.Context, userID string, since time.Time) (int64, error)
+	// CountCardAttempts counts the payments made with a card (fingerprint) since the given time
+	CountCardAttempts(ctx context.Context, fingerprint string, since time.Time) (int64, error)
+	// FailureStreak counts how many of the most recent decided payments of a user failed in a
+	// row, looking at no more than limit payments
+	FailureStreak(ctx context.Context, userID string, limit int) (int, error)
+}
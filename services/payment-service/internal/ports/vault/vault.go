@@ -0,0 +1,24 @@
+package vault
+
+import (
+	"context"
+
+	"github.com/kubernetestest/ecommerce-platform/services/payment-service/internal/domain/entities"
+)
+
+// Vault stores card numbers encrypted at rest and hands out tokens (saved method IDs) for them.
+// Methods are scoped to their user: a token of another user is reported as
+// errors.ErrPaymentMethodNotFound.
+type Vault interface {
+	// Store saves method with its card number. Saving a card the user already saved returns the
+	// existing method instead of a duplicate.
+	Store(ctx context.Context, method *entities.SavedPaymentMethod, cardNumber string) (*entities.SavedPaymentMethod, error)
+	Get(ctx context.Context, userID, token string) (*entities.SavedPaymentMethod, error)
+	List(ctx context.Context, userID string) ([]*entities.SavedPaymentMethod, error)
+	Delete(ctx context.Context, userID, token string) error
+	// Reveal decrypts the card number of a saved method, for charging it only
+	Reveal(ctx context.Context, userID, token string) (string, error)
+	// Fingerprint identifies a card number without revealing it, matching the Fingerprint
+	// of saved methods of the same card
+	Fingerprint(cardNumber string) string
+}